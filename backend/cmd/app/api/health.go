@@ -0,0 +1,59 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/payvue/payvue-backend/cmd/app/container"
+	"github.com/payvue/payvue-backend/pkg/health"
+	"github.com/payvue/payvue-backend/pkg/repository/database"
+	"github.com/payvue/payvue-backend/pkg/rest"
+	"github.com/payvue/payvue-backend/pkg/scheduler"
+	"github.com/payvue/payvue-backend/pkg/utils/fileupload"
+)
+
+// NewHealthChecker monta los checks de /readyz. Los binarios que escriben
+// comprueban además que pueden escribir en la base de datos y en la carpeta de
+// recibos; jobScheduler es nil si el binario no ejecuta las tareas periódicas.
+func NewHealthChecker(name string, c *container.Container, mode rest.Mode, jobScheduler *scheduler.Scheduler) *health.Checker {
+	checks := []health.Check{
+		{Name: "database", Run: func(ctx context.Context) (interface{}, error) {
+			return nil, database.Ping(ctx, c.DB)
+		}},
+		{Name: "migrations", Run: func(ctx context.Context) (interface{}, error) {
+			pending, err := database.PendingMigrations(ctx, c.DB)
+			if err != nil {
+				return nil, err
+			}
+			if len(pending) > 0 {
+				return map[string]interface{}{"pending": pending}, errors.New("there are pending migrations")
+			}
+			return nil, nil
+		}},
+	}
+
+	if mode.Writes() {
+		checks = append(checks,
+			health.Check{Name: "database_writable", Run: func(ctx context.Context) (interface{}, error) {
+				return nil, database.CheckWritable(ctx, c.DB)
+			}},
+			health.Check{Name: "storage", Run: func(ctx context.Context) (interface{}, error) {
+				return nil, fileupload.CheckWritable()
+			}},
+		)
+	}
+
+	if jobScheduler != nil {
+		checks = append(checks, health.Check{Name: "scheduler", Run: func(ctx context.Context) (interface{}, error) {
+			// Un job fallido no impide atender peticiones: solo se informa
+			status := jobScheduler.Status()
+			if !status.Running {
+				return status, errors.New("scheduler is not running")
+			}
+			return status, nil
+		}})
+	}
+
+	return health.New(name, 2*time.Second, checks...)
+}
@@ -0,0 +1,24 @@
+package api
+
+import (
+	"github.com/payvue/payvue-backend/cmd/app/config"
+	"github.com/payvue/payvue-backend/cmd/app/container"
+	"github.com/payvue/payvue-backend/pkg/domain/security"
+	appjobs "github.com/payvue/payvue-backend/pkg/jobs"
+	"github.com/payvue/payvue-backend/pkg/scheduler"
+)
+
+// Jobs devuelve las tareas periódicas que ejecutan los binarios con escritura:
+// caducidad de claves de idempotencia, limpieza de intentos de login, borrado
+// de cuentas y purga de la papelera.
+func Jobs(cfg config.Config, c *container.Container) []scheduler.Job {
+	jobs := []scheduler.Job{
+		appjobs.IdempotencyKeys(c.IdempotencyService),
+		appjobs.LoginAttempts(c.SecurityService, security.DefaultPolicy().Window),
+		appjobs.AccountDeletion(c.AccountService),
+	}
+	if cfg.TrashRetentionDays > 0 {
+		jobs = append(jobs, appjobs.TrashRetention(c.DebtService, c.IncomeService, c.PaymentService, cfg.TrashRetentionDays))
+	}
+	return jobs
+}
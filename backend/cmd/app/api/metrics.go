@@ -0,0 +1,31 @@
+package api
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"github.com/payvue/payvue-backend/cmd/app/config"
+	"github.com/payvue/payvue-backend/pkg/metrics"
+	"github.com/payvue/payvue-backend/pkg/rest"
+)
+
+// MetricsHandler sirve las métricas de Prometheus. Si hay METRICS_TOKEN exige
+// Authorization: Bearer con ese token (bearer_token en la configuración del scrape).
+func MetricsHandler(cfg config.Config) http.Handler {
+	handler := metrics.Default.Handler()
+	if cfg.MetricsToken == "" {
+		return handler
+	}
+
+	expected := []byte(cfg.MetricsToken)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		scheme, token, _ := strings.Cut(r.Header.Get("Authorization"), " ")
+		if !strings.EqualFold(scheme, "Bearer") || subtle.ConstantTimeCompare([]byte(strings.TrimSpace(token)), expected) != 1 {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="metrics"`)
+			rest.RespondProblem(w, r, http.StatusUnauthorized, "unauthorized", "Se necesita el token de métricas")
+			return
+		}
+		handler.ServeHTTP(w, r)
+	})
+}
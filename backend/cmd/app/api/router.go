@@ -0,0 +1,147 @@
+package api
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/cors"
+	"github.com/payvue/payvue-backend/cmd/app/config"
+	"github.com/payvue/payvue-backend/cmd/app/container"
+	"github.com/payvue/payvue-backend/pkg/health"
+	"github.com/payvue/payvue-backend/pkg/ratelimit"
+	"github.com/payvue/payvue-backend/pkg/rest"
+	"github.com/payvue/payvue-backend/pkg/rest/accesstoken"
+	"github.com/payvue/payvue-backend/pkg/rest/account"
+	"github.com/payvue/payvue-backend/pkg/rest/audit"
+	"github.com/payvue/payvue-backend/pkg/rest/auth"
+	"github.com/payvue/payvue-backend/pkg/rest/debt"
+	"github.com/payvue/payvue-backend/pkg/rest/household"
+	"github.com/payvue/payvue-backend/pkg/rest/income"
+	"github.com/payvue/payvue-backend/pkg/rest/openapi"
+	"github.com/payvue/payvue-backend/pkg/rest/payment"
+	"github.com/payvue/payvue-backend/pkg/rest/security"
+)
+
+// NewRouter construye el router de los tres binarios. Middlewares y handlers son
+// los mismos; el modo solo decide qué rutas se montan.
+func NewRouter(cfg config.Config, c *container.Container, mode rest.Mode, checker *health.Checker) *chi.Mux {
+	router := chi.NewRouter()
+
+	router.Use(middleware.RequestID)
+	router.Use(rest.RequestTracing)
+	router.Use(rest.RequestLogger(c.Logger))
+	if cfg.MetricsEnabled {
+		router.Use(rest.RequestMetrics)
+	}
+	router.Use(middleware.Recoverer)
+	router.Use(middleware.Timeout(time.Duration(cfg.ServerTimeout) * time.Second))
+	router.Use(rest.BearerAuth(c.AccessTokenService))
+	router.Use(rest.ActorContext)
+
+	router.Use(cors.Handler(cors.Options{
+		AllowedOrigins:   cfg.CORSAllowedOrigins,
+		AllowedMethods:   allowedMethods(mode),
+		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token", "X-User-ID", "If-Match", "Idempotency-Key", "traceparent"},
+		ExposedHeaders:   []string{"Link", "ETag", "Retry-After", "Idempotent-Replayed", "Content-Disposition", "X-Trace-ID"},
+		AllowCredentials: true,
+		MaxAge:           300,
+	}))
+	router.Use(ratelimit.Middleware(ratelimit.Limiter{
+		Name:   "api",
+		Store:  c.RateLimitStore,
+		Limit:  cfg.APIRateLimit,
+		Window: time.Minute,
+	}))
+	if cfg.EmailVerificationRequired {
+		router.Use(rest.RequireVerifiedEmail(c.UserService))
+	}
+	// En desarrollo se valida contra el documento OpenAPI antes de guardar la
+	// respuesta para la idempotencia; en producción no se paga el coste
+	development := cfg.Environment == "development"
+	if development {
+		router.Use(openapi.Validator(router))
+	}
+	// El reader no atiende escrituras: con la base de datos en solo lectura la
+	// clave no se podría guardar y la petición acabaría en 500 en vez de 405
+	if mode.Writes() {
+		router.Use(rest.Idempotency(c.IdempotencyService))
+	}
+
+	authLimiter := ratelimit.Middleware(ratelimit.Limiter{
+		Name:   "auth",
+		Store:  c.RateLimitStore,
+		Limit:  cfg.AuthRateLimit,
+		Window: time.Minute,
+	})
+
+	// Se registran antes de montar los módulos para que los subrouters los hereden.
+	router.NotFound(func(w http.ResponseWriter, r *http.Request) {
+		rest.RespondProblem(w, r, http.StatusNotFound, "not_found", "Recurso no encontrado")
+	})
+	router.MethodNotAllowed(func(w http.ResponseWriter, r *http.Request) {
+		rest.RespondProblem(w, r, http.StatusMethodNotAllowed, "method_not_allowed", "Método no permitido")
+	})
+
+	handlers := []rest.Handler{
+		auth.NewHandler(c.UserService, c.AccountService, authLimiter),
+		income.NewHandler(c.IncomeService),
+		debt.NewHandler(c.DebtService),
+		payment.NewHandler(c.PaymentService),
+		household.NewHandler(c.HouseholdService),
+		audit.NewHandler(c.AuditService),
+		security.NewHandler(c.SecurityService),
+		account.NewHandler(c.AccountService),
+		accesstoken.NewHandler(c.AccessTokenService),
+		openapi.NewHandler(),
+	}
+	for _, handler := range handlers {
+		handler.RouteURLs(router, mode)
+	}
+	if development {
+		for _, problem := range openapi.CheckRoutes(router, mode) {
+			c.Logger.Warn("OpenAPI specification is out of date", slog.String("problem", problem))
+		}
+	}
+
+	router.Get("/health", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(healthMessage(mode)))
+	})
+
+	// Las sondas y /metrics quedan fuera de los middlewares: no deben contar para
+	// el límite de peticiones ni llenar el log, y BearerAuth tomaría el token de
+	// métricas por un token de acceso y lo rechazaría
+	root := chi.NewRouter()
+	root.Get("/healthz", checker.LiveHandler)
+	root.Get("/readyz", checker.ReadyHandler)
+	if cfg.MetricsEnabled && cfg.MetricsAddr == "" {
+		root.Handle("/metrics", MetricsHandler(cfg))
+	}
+	root.Mount("/", router)
+	return root
+}
+
+func allowedMethods(mode rest.Mode) []string {
+	var methods []string
+	if mode.Reads() {
+		methods = append(methods, "GET")
+	}
+	if mode.Writes() {
+		methods = append(methods, "POST", "PUT", "PATCH", "DELETE")
+	}
+	return append(methods, "OPTIONS")
+}
+
+func healthMessage(mode rest.Mode) string {
+	switch mode {
+	case rest.ModeRead:
+		return "OK - Reader Service"
+	case rest.ModeWrite:
+		return "OK - Writer Service"
+	default:
+		return "OK - PayVue API Server"
+	}
+}
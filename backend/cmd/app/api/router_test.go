@@ -0,0 +1,152 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/payvue/payvue-backend/cmd/app/config"
+	"github.com/payvue/payvue-backend/cmd/app/container"
+	"github.com/payvue/payvue-backend/pkg/rest"
+	"github.com/payvue/payvue-backend/pkg/rest/entities"
+)
+
+// newTestRouter construye el router de un binario contra una base de datos
+// SQLite temporal. El writer la crea y migra antes de que el reader la abra en
+// solo lectura, como en producción.
+func newTestRouter(t *testing.T, mode rest.Mode, environment string) http.Handler {
+	t.Helper()
+	dir := t.TempDir()
+	cfg, err := config.Load([]string{
+		"--database-driver", "sqlite",
+		"--database-path", filepath.Join(dir, "payvue.db"),
+		"--upload-dir", filepath.Join(dir, "uploads"),
+		"--environment", environment,
+		"--log-level", "error",
+		"--scheduler-enabled=false",
+		"--metrics-enabled=false",
+		"--rate-limit-store", "memory",
+		"--rate-limit-api", "0",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	writer := container.New(cfg, false)
+	c := writer
+	if !mode.Writes() {
+		writer.Close()
+		c = container.New(cfg, true)
+	}
+	t.Cleanup(func() { c.Close() })
+
+	return NewRouter(cfg, c, mode, NewHealthChecker("test", c, mode, nil))
+}
+
+func TestRouterModes(t *testing.T) {
+	tests := []struct {
+		name        string
+		mode        rest.Mode
+		environment string
+		method      string
+		path        string
+		headers     map[string]string
+		body        string
+		status      int
+	}{
+		{"reader serves reads", rest.ModeRead, "staging", http.MethodGet, "/finances/debt", nil, "", http.StatusOK},
+		{"reader rejects writes", rest.ModeRead, "staging", http.MethodPost, "/finances/debt", nil, `{}`, http.StatusMethodNotAllowed},
+		{"reader rejects writes before validating", rest.ModeRead, "development", http.MethodPost, "/finances/debt",
+			map[string]string{"Content-Type": "text/plain"}, `no es json`, http.StatusMethodNotAllowed},
+		{"reader ignores idempotency keys", rest.ModeRead, "development", http.MethodPost, "/finances/debt",
+			map[string]string{"Idempotency-Key": "reader-key"}, `{}`, http.StatusMethodNotAllowed},
+		{"reader rejects deletes", rest.ModeRead, "development", http.MethodDelete, "/finances/debt/1", nil, "", http.StatusMethodNotAllowed},
+		{"writer rejects reads", rest.ModeWrite, "staging", http.MethodGet, "/finances/debt", nil, "", http.StatusMethodNotAllowed},
+		{"writer rejects reads in development", rest.ModeWrite, "development", http.MethodGet, "/finances/debt/1", nil, "", http.StatusMethodNotAllowed},
+		{"writer validates writes", rest.ModeWrite, "development", http.MethodPost, "/finances/debt", nil, `{}`, http.StatusBadRequest},
+		{"server serves reads", rest.ModeAll, "development", http.MethodGet, "/finances/debt", nil, "", http.StatusOK},
+		{"server validates writes", rest.ModeAll, "development", http.MethodPost, "/finances/debt", nil, `{}`, http.StatusBadRequest},
+		{"unknown route", rest.ModeRead, "development", http.MethodPost, "/no-existe", nil, `{}`, http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := newTestRouter(t, tt.mode, tt.environment)
+
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			req.Header.Set("X-User-ID", "1")
+			if tt.body != "" {
+				req.Header.Set("Content-Type", "application/json")
+			}
+			for name, value := range tt.headers {
+				req.Header.Set(name, value)
+			}
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			if rec.Code != tt.status {
+				t.Fatalf("%s %s: status = %d, want %d (body %s)", tt.method, tt.path, rec.Code, tt.status, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestRouterHealth(t *testing.T) {
+	for mode, want := range map[rest.Mode]string{
+		rest.ModeRead:  "OK - Reader Service",
+		rest.ModeWrite: "OK - Writer Service",
+		rest.ModeAll:   "OK - PayVue API Server",
+	} {
+		rec := httptest.NewRecorder()
+		newTestRouter(t, mode, "staging").ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/health", nil))
+		if rec.Code != http.StatusOK || rec.Body.String() != want {
+			t.Errorf("mode %d: /health = %d %q, want 200 %q", mode, rec.Code, rec.Body.String(), want)
+		}
+	}
+}
+
+func TestCreateTokenRequiresPassword(t *testing.T) {
+	router := newTestRouter(t, rest.ModeAll, "development")
+	post := func(path, userID, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		if userID != "" {
+			req.Header.Set("X-User-ID", userID)
+		}
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := post("/auth/register", "", `{"email":"ana@example.com","password":"securepass"}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("register: status = %d (body %s)", rec.Code, rec.Body.String())
+	}
+	var registered entities.AuthResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &registered); err != nil {
+		t.Fatal(err)
+	}
+	userID := strconv.Itoa(registered.UserID)
+
+	tests := []struct {
+		name   string
+		body   string
+		status int
+	}{
+		{"X-User-ID alone", `{"name":"script","scope":"read"}`, http.StatusUnauthorized},
+		{"wrong password", `{"name":"script","scope":"read","password":"otra"}`, http.StatusUnauthorized},
+		{"password", `{"name":"script","scope":"read","password":"securepass"}`, http.StatusCreated},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := post("/tokens", userID, tt.body)
+			if rec.Code != tt.status {
+				t.Fatalf("POST /tokens: status = %d, want %d (body %s)", rec.Code, tt.status, rec.Body.String())
+			}
+		})
+	}
+}
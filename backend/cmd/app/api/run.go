@@ -0,0 +1,136 @@
+package api
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"os"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/payvue/payvue-backend/cmd/app/config"
+	"github.com/payvue/payvue-backend/cmd/app/container"
+	"github.com/payvue/payvue-backend/pkg/lifecycle"
+	"github.com/payvue/payvue-backend/pkg/metrics"
+	"github.com/payvue/payvue-backend/pkg/repository/stats"
+	"github.com/payvue/payvue-backend/pkg/rest"
+	"github.com/payvue/payvue-backend/pkg/scheduler"
+	"github.com/payvue/payvue-backend/pkg/utils/logger"
+)
+
+// reloadable son las opciones que se aplican con SIGHUP sin reiniciar: el nivel
+// de log y todo lo que se resuelve al construir el router.
+var reloadable = map[string]bool{
+	"log_level":            true,
+	"cors_allowed_origins": true,
+	"server_timeout":       true,
+	"rate_limit_auth":      true,
+	"rate_limit_api":       true,
+}
+
+// Run arranca el binario name en el modo indicado y bloquea hasta que recibe
+// SIGINT o SIGTERM. Al parar deja de aceptar conexiones, espera a las peticiones
+// en curso y a los jobs hasta SHUTDOWN_TIMEOUT y después cierra la base de datos.
+// Con SIGHUP vuelve a leer la configuración.
+func Run(name string, mode rest.Mode) {
+	cfg := config.LoadOrExit()
+	c := container.New(cfg, !mode.Writes())
+	appLogger := c.Logger
+
+	var jobScheduler *scheduler.Scheduler
+	if mode.Writes() && cfg.SchedulerEnabled {
+		jobScheduler = scheduler.New(appLogger, Jobs(cfg, c)...)
+	}
+	checker := NewHealthChecker(name, c, mode, jobScheduler)
+
+	handler := &swappableHandler{}
+	handler.Swap(NewRouter(cfg, c, mode, checker))
+
+	manager := lifecycle.New(appLogger, time.Duration(cfg.ShutdownTimeout)*time.Second)
+	// El primero en añadirse es el último en pararse: así se envían también los
+	// spans de las peticiones y los jobs que terminan durante el apagado
+	manager.Add(lifecycle.Component{
+		Name: "tracing",
+		Stop: c.Tracer.Shutdown,
+	})
+	manager.Add(lifecycle.Component{
+		Name: "database",
+		Stop: func(ctx context.Context) error { return c.Close() },
+	})
+	if cfg.MetricsEnabled {
+		metrics.RegisterRuntime(metrics.Default)
+		stats.Register(metrics.Default, c.DB)
+		if cfg.MetricsAddr != "" {
+			manager.AddHTTPServer("metrics", &http.Server{
+				Addr:              cfg.MetricsAddr,
+				Handler:           MetricsHandler(cfg),
+				ReadHeaderTimeout: 5 * time.Second,
+			})
+		}
+	}
+	if jobScheduler != nil {
+		manager.Add(lifecycle.Component{
+			Name: "scheduler",
+			Start: func(ctx context.Context) error {
+				jobScheduler.Start(ctx)
+				return nil
+			},
+			Stop: jobScheduler.Shutdown,
+		})
+	}
+	manager.AddHTTPServer("http", NewServer(cfg, handler))
+
+	current := cfg
+	manager.OnReload(func(ctx context.Context) error {
+		next, err := config.Load(os.Args[1:])
+		if err != nil {
+			return err
+		}
+
+		var applied []string
+		for _, key := range current.Changed(next) {
+			if reloadable[key] {
+				applied = append(applied, key)
+			}
+		}
+		c.LogLevel.Set(logger.ParseLevel(next.LogLevel))
+		handler.Swap(NewRouter(next, c, mode, checker))
+
+		// Lo que no se puede recargar se compara con lo que sigue en uso, que es
+		// la configuración del arranque
+		var pending []string
+		for _, key := range cfg.Changed(next) {
+			if !reloadable[key] {
+				pending = append(pending, key)
+			}
+		}
+		if len(pending) > 0 {
+			appLogger.Warn("some settings only take effect after a restart", slog.Any("settings", pending))
+		}
+		appLogger.Info("configuration reloaded", slog.Any("applied", applied))
+		current = next
+		return nil
+	})
+
+	appLogger.Info("starting PayVue "+name, slog.String("port", cfg.Port), slog.String("environment", cfg.Environment))
+	if err := manager.Run(context.Background()); err != nil {
+		appLogger.Error("stopped with errors", slog.Any("error", err))
+		os.Exit(1)
+	}
+	appLogger.Info("stopped")
+}
+
+// swappableHandler deja cambiar el router en caliente; las peticiones en curso
+// terminan con el anterior.
+type swappableHandler struct {
+	router atomic.Pointer[chi.Mux]
+}
+
+func (h *swappableHandler) Swap(router *chi.Mux) {
+	h.router.Store(router)
+}
+
+func (h *swappableHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.router.Load().ServeHTTP(w, r)
+}
@@ -0,0 +1,19 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/payvue/payvue-backend/cmd/app/config"
+)
+
+// NewServer crea el http.Server con el puerto y los timeouts de la configuración.
+func NewServer(cfg config.Config, handler http.Handler) *http.Server {
+	return &http.Server{
+		Addr:         ":" + cfg.Port,
+		Handler:      handler,
+		ReadTimeout:  time.Duration(cfg.ReadTimeout) * time.Second,
+		WriteTimeout: time.Duration(cfg.WriteTimeout) * time.Second,
+		IdleTimeout:  time.Duration(cfg.IdleTimeout) * time.Second,
+	}
+}
@@ -0,0 +1,331 @@
+package config
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// readFile devuelve las opciones del fichero como texto, igual que si vinieran
+// del entorno. Las claves son las de settings; en TOML una tabla [oidc] y en YAML
+// un bloque "oidc:" añaden el prefijo "oidc_" a sus claves. Solo se admite un
+// nivel de anidamiento, que es todo lo que necesita la configuración.
+//
+// TOML y YAML se leen con un parser propio que entiende claves sueltas con
+// valores escalares o listas de una línea y, en YAML, listas "- x". El resto de
+// la sintaxis (tablas en línea, cadenas de varias líneas, anclas, bloques | y >,
+// varios niveles...) se rechaza con un error en vez de leerse a medias.
+func readFile(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading config file: %w", err)
+	}
+
+	var values map[string]string
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".json":
+		values, err = parseJSON(data)
+	case ".toml":
+		values, err = parseLines(data, parseTOMLLine)
+	case ".yaml", ".yml":
+		values, err = parseLines(data, parseYAMLLine)
+	default:
+		return nil, fmt.Errorf("config file %s: unsupported extension %q (use .toml, .yaml or .json)", path, ext)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("config file %s: %w", path, err)
+	}
+	return values, nil
+}
+
+func parseJSON(data []byte) (map[string]string, error) {
+	var document map[string]interface{}
+	if err := json.Unmarshal(data, &document); err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]string)
+	var walk func(prefix string, document map[string]interface{}) error
+	walk = func(prefix string, document map[string]interface{}) error {
+		for key, value := range document {
+			if nested, ok := value.(map[string]interface{}); ok && prefix == "" {
+				if err := walk(key+"_", nested); err != nil {
+					return err
+				}
+				continue
+			}
+			text, err := jsonText(value)
+			if err != nil {
+				return fmt.Errorf("%s%s: %w", prefix, key, err)
+			}
+			values[prefix+key] = text
+		}
+		return nil
+	}
+	return values, walk("", document)
+}
+
+func jsonText(value interface{}) (string, error) {
+	switch v := value.(type) {
+	case string:
+		return v, nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case []interface{}:
+		items := make([]string, len(v))
+		for i, item := range v {
+			text, err := jsonText(item)
+			if err != nil {
+				return "", err
+			}
+			items[i] = text
+		}
+		return strings.Join(items, ","), nil
+	default:
+		return "", fmt.Errorf("unsupported value %v", value)
+	}
+}
+
+// lineParser interpreta una línea ya sin comentarios; indent es el número de
+// espacios o tabuladores con que empieza. section es el prefijo activo,
+// sectionIndent la sangría de sus claves y list, si no está vacía, la clave cuyo
+// bloque de elementos "- x" se está leyendo.
+type lineParser func(state *parseState, line string, indent int) error
+
+type parseState struct {
+	values        map[string]string
+	section       string
+	sectionIndent int
+	list          string
+}
+
+// set guarda una opción; repetir una clave es casi siempre un error de edición.
+func (s *parseState) set(key, value string) error {
+	if !isKey(key) {
+		return fmt.Errorf("unsupported key %q", key)
+	}
+	if _, ok := s.values[key]; ok {
+		return fmt.Errorf("duplicate key %q", key)
+	}
+	s.values[key] = value
+	return nil
+}
+
+// isKey admite solo letras, números y guiones bajos, como las claves de
+// settings: una clave con puntos o entre comillas no significa lo mismo aquí que
+// en TOML o YAML.
+func isKey(key string) bool {
+	if key == "" {
+		return false
+	}
+	for _, c := range key {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_') {
+			return false
+		}
+	}
+	return true
+}
+
+func parseLines(data []byte, parse lineParser) (map[string]string, error) {
+	state := &parseState{values: make(map[string]string)}
+	scanner := bufio.NewScanner(strings.NewReader(string(data)))
+	for number := 1; scanner.Scan(); number++ {
+		raw := scanner.Text()
+		line := strings.TrimSpace(stripComment(raw))
+		if line == "" {
+			continue
+		}
+		indent := len(raw) - len(strings.TrimLeft(raw, " \t"))
+		if err := parse(state, line, indent); err != nil {
+			return nil, fmt.Errorf("line %d: %w", number, err)
+		}
+	}
+	return state.values, scanner.Err()
+}
+
+func parseTOMLLine(state *parseState, line string, indent int) error {
+	if strings.HasPrefix(line, "[") {
+		name, ok := strings.CutSuffix(line[1:], "]")
+		name = strings.TrimSpace(name)
+		if !ok || !isKey(name) {
+			// [[tabla]] y [a.b] no tienen equivalente en la configuración
+			return fmt.Errorf("unsupported table %s", line)
+		}
+		state.section = name + "_"
+		return nil
+	}
+
+	key, value, ok := strings.Cut(line, "=")
+	if !ok {
+		return fmt.Errorf("expected key = value, got %q", line)
+	}
+	text, err := scalarOrList(strings.TrimSpace(value))
+	if err != nil {
+		return err
+	}
+	return state.set(state.section+strings.TrimSpace(key), text)
+}
+
+func parseYAMLLine(state *parseState, line string, indent int) error {
+	if line == "---" || line == "..." {
+		return fmt.Errorf("multiple documents are not supported")
+	}
+	if indent == 0 {
+		state.section, state.sectionIndent, state.list = "", 0, ""
+	}
+
+	if item, ok := strings.CutPrefix(line, "- "); ok {
+		if state.list == "" {
+			return fmt.Errorf("list item %q outside a list", item)
+		}
+		item = strings.TrimSpace(item)
+		if strings.HasPrefix(item, "- ") || strings.Contains(stripQuoted(item), ": ") {
+			return fmt.Errorf("unsupported list item %q: only scalars are allowed", item)
+		}
+		text, err := scalar(item)
+		if err != nil {
+			return err
+		}
+		if previous, ok := state.values[state.list]; ok {
+			text = previous + "," + text
+		}
+		state.values[state.list] = text
+		return nil
+	}
+
+	key, value, ok := strings.Cut(line, ":")
+	if !ok {
+		return fmt.Errorf("expected key: value, got %q", line)
+	}
+	key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+	if indent > 0 {
+		if state.section == "" {
+			return fmt.Errorf("unexpected indentation before %q", key)
+		}
+		// Todas las claves de un bloque van a la misma altura; más sangría sería
+		// un segundo nivel de anidamiento
+		if state.sectionIndent == 0 {
+			state.sectionIndent = indent
+		}
+		if indent != state.sectionIndent {
+			return fmt.Errorf("unsupported nesting at %q: only one level is allowed", key)
+		}
+		key = state.section + key
+	}
+
+	if value == "" {
+		// Empieza un bloque: una lista "- x" o, en el primer nivel, opciones anidadas
+		if indent == 0 {
+			state.section = key + "_"
+		}
+		if _, ok := state.values[key]; ok {
+			return fmt.Errorf("duplicate key %q", key)
+		}
+		state.list = key
+		return nil
+	}
+
+	state.list = ""
+	text, err := scalarOrList(value)
+	if err != nil {
+		return err
+	}
+	return state.set(key, text)
+}
+
+// scalarOrList acepta un valor suelto o entre comillas, o una lista [a, "b"]
+// en una sola línea.
+func scalarOrList(value string) (string, error) {
+	if !strings.HasPrefix(value, "[") {
+		return scalar(value)
+	}
+	if !strings.HasSuffix(value, "]") {
+		return "", fmt.Errorf("unterminated list %q", value)
+	}
+
+	var items []string
+	for _, item := range strings.Split(value[1:len(value)-1], ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		if strings.HasPrefix(item, "[") {
+			return "", fmt.Errorf("nested lists are not supported")
+		}
+		text, err := scalar(item)
+		if err != nil {
+			return "", err
+		}
+		items = append(items, text)
+	}
+	return strings.Join(items, ","), nil
+}
+
+// scalar lee un valor suelto o entre comillas. Los que empiezan por un carácter
+// con significado propio en TOML o YAML (tablas en línea, anclas, etiquetas,
+// bloques de varias líneas) se rechazan en vez de tomarse como texto.
+func scalar(value string) (string, error) {
+	if value != "" && strings.ContainsRune("{&*!|>%@`", rune(value[0])) {
+		return "", fmt.Errorf("unsupported value %q", value)
+	}
+	if strings.HasPrefix(value, `"""`) || strings.HasPrefix(value, "'''") {
+		return "", fmt.Errorf("multi-line strings are not supported")
+	}
+	return unquote(value)
+}
+
+func unquote(value string) (string, error) {
+	if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') {
+		if value[len(value)-1] != value[0] {
+			return "", fmt.Errorf("unterminated string %s", value)
+		}
+		if value[0] == '\'' {
+			return value[1 : len(value)-1], nil
+		}
+		return strconv.Unquote(value)
+	}
+	return value, nil
+}
+
+// stripQuoted quita el texto entre comillas para buscar separadores fuera de él.
+func stripQuoted(value string) string {
+	var b strings.Builder
+	var quote byte
+	for i := 0; i < len(value); i++ {
+		switch c := value[i]; {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
+
+// stripComment quita lo que sigue a un # que no esté dentro de comillas.
+func stripComment(line string) string {
+	var quote byte
+	for i := 0; i < len(line); i++ {
+		switch c := line[i]; {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '#':
+			return line[:i]
+		}
+	}
+	return line
+}
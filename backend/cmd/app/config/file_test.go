@@ -0,0 +1,154 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func writeConfig(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestReadFileExample(t *testing.T) {
+	values, err := readFile("../../../config.example.toml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for key, want := range map[string]string{
+		"port":                 "8080",
+		"cors_allowed_origins": "http://localhost:3000",
+		"metrics_addr":         "127.0.0.1:9090",
+		"oidc_scopes":          "openid,email,profile",
+		"mail_from":            "PayVue <no-reply@payvue.local>",
+	} {
+		if values[key] != want {
+			t.Errorf("%s = %q, want %q", key, values[key], want)
+		}
+	}
+}
+
+func TestReadFileFormats(t *testing.T) {
+	want := map[string]string{
+		"port":                 "9000",
+		"cors_allowed_origins": "https://a.example,https://b.example",
+		"oidc_issuer":          "https://issuer.example",
+		"oidc_scopes":          "openid,email",
+		"metrics_token":        "",
+	}
+	files := map[string]string{
+		"config.toml": `
+port = 9000 # comentario
+cors_allowed_origins = ["https://a.example", 'https://b.example']
+metrics_token = ""
+
+[oidc]
+issuer = "https://issuer.example"
+scopes = ["openid", "email"]
+`,
+		"config.yaml": `
+port: 9000
+cors_allowed_origins:
+  - https://a.example
+  - "https://b.example"
+metrics_token: ""
+oidc:
+  issuer: https://issuer.example
+  scopes:
+    - openid
+    - email
+`,
+		"config.json": `{
+  "port": 9000,
+  "cors_allowed_origins": ["https://a.example", "https://b.example"],
+  "metrics_token": "",
+  "oidc": {"issuer": "https://issuer.example", "scopes": ["openid", "email"]}
+}`,
+	}
+	for name, content := range files {
+		t.Run(name, func(t *testing.T) {
+			values, err := readFile(writeConfig(t, name, content))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(values, want) {
+				t.Errorf("values = %v, want %v", values, want)
+			}
+		})
+	}
+}
+
+func TestReadFileRejectsUnsupportedSyntax(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+	}{
+		{"toml inline table", "c.toml", `oidc = { issuer = "x" }`},
+		{"toml array of tables", "c.toml", "[[oidc]]\nissuer = \"x\""},
+		{"toml dotted table", "c.toml", "[oidc.extra]\nissuer = \"x\""},
+		{"toml dotted key", "c.toml", `oidc.issuer = "x"`},
+		{"toml quoted key", "c.toml", `"port" = 8080`},
+		{"toml multi-line string", "c.toml", `mail_from = """PayVue"""`},
+		{"toml multi-line list", "c.toml", "cors_allowed_origins = [\n  \"a\",\n]"},
+		{"toml nested list", "c.toml", `cors_allowed_origins = [["a"]]`},
+		{"toml duplicate key", "c.toml", "port = 1\nport = 2"},
+		{"toml duplicate key in table", "c.toml", "metrics_addr = \"a\"\n[metrics]\naddr = \"b\""},
+		{"yaml anchor", "c.yaml", "port: &port 8080"},
+		{"yaml alias", "c.yaml", "port: *port"},
+		{"yaml tag", "c.yaml", "port: !!int 8080"},
+		{"yaml block scalar", "c.yaml", "mail_from: |\n  PayVue"},
+		{"yaml folded scalar", "c.yaml", "mail_from: >\n  PayVue"},
+		{"yaml flow mapping", "c.yaml", "oidc: {issuer: x}"},
+		{"yaml two levels", "c.yaml", "smtp:\n  host:\n    port: 25"},
+		{"yaml misaligned keys", "c.yaml", "smtp:\n  host: a\n    port: 25"},
+		{"yaml list of mappings", "c.yaml", "cors_allowed_origins:\n  - origin: a"},
+		{"yaml nested list", "c.yaml", "cors_allowed_origins:\n  - - a"},
+		{"yaml several documents", "c.yml", "port: 1\n---\nport: 2"},
+		{"yaml duplicate key", "c.yaml", "port: 1\nport: 2"},
+		{"yaml duplicate list", "c.yaml", "cors_allowed_origins: a\ncors_allowed_origins:\n  - b"},
+		{"json nested object", "c.json", `{"oidc": {"extra": {"issuer": "x"}}}`},
+		{"json null", "c.json", `{"port": null}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values, err := readFile(writeConfig(t, tt.file, tt.content))
+			if err == nil {
+				t.Fatalf("readFile accepted %q as %v", tt.content, values)
+			}
+		})
+	}
+}
+
+func TestLoadEmptyEnvironmentOverridesFile(t *testing.T) {
+	path := writeConfig(t, "config.toml", "metrics_addr = \"127.0.0.1:9100\"\nmetrics_token = \"secreto\"\n")
+	t.Setenv("METRICS_ADDR", "")
+	t.Setenv("DATABASE_PATH", filepath.Join(t.TempDir(), "payvue.db"))
+
+	cfg, err := Load([]string{"--config", path})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.MetricsAddr != "" {
+		t.Errorf("MetricsAddr = %q, want the empty value from the environment", cfg.MetricsAddr)
+	}
+	if cfg.MetricsToken != "secreto" {
+		t.Errorf("MetricsToken = %q, want the value from the file", cfg.MetricsToken)
+	}
+}
+
+func TestLoadEmptyEnvironmentIsValidated(t *testing.T) {
+	t.Setenv("PORT", "")
+
+	_, err := Load(nil)
+	if err == nil || !strings.Contains(err.Error(), "port") {
+		t.Fatalf("Load error = %v, want a problem with port", err)
+	}
+}
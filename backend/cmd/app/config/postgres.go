@@ -0,0 +1,7 @@
+//go:build postgres
+
+package config
+
+// postgresSupported indica si el binario lleva el driver de PostgreSQL; sigue al
+// tag postgres, como pkg/repository/database/postgres.go.
+const postgresSupported = true
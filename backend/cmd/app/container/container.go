@@ -1,39 +1,119 @@
 package container
 
 import (
+	"crypto/rand"
 	"database/sql"
-	"log"
+	"log/slog"
+	"os"
+	"time"
 
 	"github.com/payvue/payvue-backend/cmd/app/config"
+	"github.com/payvue/payvue-backend/pkg/domain/accesstoken"
+	"github.com/payvue/payvue-backend/pkg/domain/account"
+	"github.com/payvue/payvue-backend/pkg/domain/audit"
 	"github.com/payvue/payvue-backend/pkg/domain/debt"
+	"github.com/payvue/payvue-backend/pkg/domain/household"
+	"github.com/payvue/payvue-backend/pkg/domain/idempotency"
 	"github.com/payvue/payvue-backend/pkg/domain/income"
 	"github.com/payvue/payvue-backend/pkg/domain/payment"
+	"github.com/payvue/payvue-backend/pkg/domain/security"
 	"github.com/payvue/payvue-backend/pkg/domain/user"
+	"github.com/payvue/payvue-backend/pkg/ratelimit"
+	accessTokenRepo "github.com/payvue/payvue-backend/pkg/repository/accesstoken"
+	accountRepo "github.com/payvue/payvue-backend/pkg/repository/account"
+	auditRepo "github.com/payvue/payvue-backend/pkg/repository/audit"
 	"github.com/payvue/payvue-backend/pkg/repository/database"
 	debtRepo "github.com/payvue/payvue-backend/pkg/repository/debt"
+	householdRepo "github.com/payvue/payvue-backend/pkg/repository/household"
+	idempotencyRepo "github.com/payvue/payvue-backend/pkg/repository/idempotency"
 	incomeRepo "github.com/payvue/payvue-backend/pkg/repository/income"
 	paymentRepo "github.com/payvue/payvue-backend/pkg/repository/payment"
+	rateLimitRepo "github.com/payvue/payvue-backend/pkg/repository/ratelimit"
+	securityRepo "github.com/payvue/payvue-backend/pkg/repository/security"
 	userRepo "github.com/payvue/payvue-backend/pkg/repository/user"
+	"github.com/payvue/payvue-backend/pkg/tracing"
+	"github.com/payvue/payvue-backend/pkg/utils/fileupload"
+	"github.com/payvue/payvue-backend/pkg/utils/logger"
+	"github.com/payvue/payvue-backend/pkg/utils/mailer"
+	"github.com/payvue/payvue-backend/pkg/utils/oidc"
+	"github.com/payvue/payvue-backend/pkg/utils/signedtoken"
 )
 
 type Container struct {
-	DebtService    debt.Service
-	IncomeService  income.Service
-	PaymentService payment.Service
-	UserService    user.Service
-	DB             *sql.DB
+	AccessTokenService accesstoken.Service
+	AccountService     account.Service
+	AuditService       audit.Service
+	DebtService        debt.Service
+	HouseholdService   household.Service
+	IdempotencyService idempotency.Service
+	IncomeService      income.Service
+	PaymentService     payment.Service
+	SecurityService    security.Service
+	UserService        user.Service
+	RateLimitStore     ratelimit.Store
+	Logger             *slog.Logger
+	// LogLevel permite cambiar el nivel del Logger sin reiniciar.
+	LogLevel *slog.LevelVar
+	// Tracer es también el tracer por defecto; hay que pararlo con Shutdown
+	// para no perder los últimos spans.
+	Tracer *tracing.Tracer
+	DB     *sql.DB
 }
 
-func New(cfg config.Config) *Container {
-	db, err := database.InitDB(cfg.DatabasePath)
+// New construye las dependencias. Con readOnly, el del reader, la base de datos
+// se abre en solo lectura y se prescinde de lo que escribe en cada petición.
+func New(cfg config.Config, readOnly bool) *Container {
+	// También es el logger por defecto, así lo usan log.Printf y las dependencias
+	// que no lo reciben inyectado.
+	logLevel := new(slog.LevelVar)
+	logLevel.Set(logger.ParseLevel(cfg.LogLevel))
+	appLogger := logger.New(os.Stdout, logLevel, cfg.LogFormat)
+	slog.SetDefault(appLogger)
+
+	tracer := tracing.New(newTraceExporter(cfg), cfg.TracingSampleRatio)
+	tracing.SetDefault(tracer)
+
+	db, err := database.InitDB(database.Config{
+		Engine:       database.Engine(cfg.DatabaseDriver),
+		Path:         cfg.DatabasePath,
+		URL:          cfg.DatabaseURL,
+		ReadOnly:     readOnly,
+		JournalMode:  cfg.DatabaseJournalMode,
+		Synchronous:  cfg.DatabaseSynchronous,
+		BusyTimeout:  time.Duration(cfg.DatabaseBusyTimeoutMS) * time.Millisecond,
+		BusyRetries:  cfg.DatabaseBusyRetries,
+		MaxOpenConns: cfg.DatabaseMaxOpenConns,
+		MaxIdleConns: cfg.DatabaseMaxIdleConns,
+	})
 	if err != nil {
-		log.Fatalf("Failed to initialize database: %v", err)
+		fatal(appLogger, "failed to initialize database", err)
 	}
 
+	if err := fileupload.Init(cfg.UploadDir); err != nil {
+		fatal(appLogger, "failed to initialize uploads folder", err)
+	}
+
+	// Household
+	householdRepository := householdRepo.NewRepository(db)
+	householdContainer := &household.Container{
+		Repository: householdRepository,
+	}
+	householdService := household.New(householdContainer)
+
+	// Audit
+	auditRepository := auditRepo.NewRepository(db)
+	auditContainer := &audit.Container{
+		Repository: auditRepository,
+		Households: householdService,
+	}
+	auditService := audit.New(auditContainer)
+
 	// Debt
 	debtRepository := debtRepo.NewRepository(db)
 	debtContainer := &debt.Container{
 		Repository: debtRepository,
+		Audit:      auditService,
+		Households: householdService,
 	}
 	debtService := debt.New(debtContainer)
 
@@ -41,30 +121,174 @@ func New(cfg config.Config) *Container {
 	incomeRepository := incomeRepo.NewRepository(db)
 	incomeContainer := &income.Container{
 		Repository: incomeRepository,
+		Audit:      auditService,
+		Households: householdService,
 	}
 	incomeService := income.New(incomeContainer)
 
+	// Los enlaces de verificación y las URLs de los recibos comparten la clave
+	signer := signedtoken.New(signingKey(cfg, appLogger))
+
 	// Payment
 	paymentRepository := paymentRepo.NewRepository(db)
 	paymentContainer := &payment.Container{
-		Repository: paymentRepository,
+		Repository:    paymentRepository,
+		Audit:         auditService,
+		Households:    householdService,
+		ReceiptSigner: signer,
 	}
 	paymentService := payment.New(paymentContainer)
 
+	// Security
+	policy := security.DefaultPolicy()
+	if cfg.LoginMaxFailures > 0 {
+		policy.MaxFailures = cfg.LoginMaxFailures
+		policy.IPMaxFailures = 4 * cfg.LoginMaxFailures
+	}
+	if cfg.LoginLockoutMinutes > 0 {
+		policy.Lockout = time.Duration(cfg.LoginLockoutMinutes) * time.Minute
+	}
+	securityRepository := securityRepo.NewRepository(db)
+	securityContainer := &security.Container{
+		Repository: securityRepository,
+		Policy:     policy,
+	}
+	securityService := security.New(securityContainer)
+
 	// User
 	userRepository := userRepo.NewRepository(db)
 	userContainer := &user.Container{
 		Repository: userRepository,
+		Guard:      securityService,
+		Mailer:     newMailer(cfg, appLogger),
+		EmailVerification: user.EmailVerification{
+			Signer:   signer,
+			URL:      cfg.EmailVerificationURL,
+			TTL:      time.Duration(cfg.EmailVerificationTTLHours) * time.Hour,
+			Required: cfg.EmailVerificationRequired,
+		},
+	}
+	if cfg.OIDCIssuer != "" {
+		userContainer.IdentityProvider = oidc.NewProvider(oidc.Config{
+			Name:         cfg.OIDCProviderName,
+			Issuer:       cfg.OIDCIssuer,
+			ClientID:     cfg.OIDCClientID,
+			ClientSecret: cfg.OIDCClientSecret,
+			RedirectURL:  cfg.OIDCRedirectURL,
+			Scopes:       cfg.OIDCScopes,
+		})
 	}
 	userService := user.New(userContainer)
 
+	// Account
+	accountRepository := accountRepo.NewRepository(db)
+	accountContainer := &account.Container{
+		Repository:  accountRepository,
+		Users:       userService,
+		GracePeriod: time.Duration(cfg.AccountDeletionGraceDays) * 24 * time.Hour,
+	}
+	accountService := account.New(accountContainer)
+
+	// Access tokens
+	accessTokenRepository := accessTokenRepo.NewRepository(db)
+	accessTokenContainer := &accesstoken.Container{
+		Repository: accessTokenRepository,
+		Users:      userService,
+		ReadOnly:   readOnly,
+	}
+	accessTokenService := accesstoken.New(accessTokenContainer)
+
+	// Idempotency
+	idempotencyRepository := idempotencyRepo.NewRepository(db)
+	idempotencyContainer := &idempotency.Container{
+		Repository: idempotencyRepository,
+	}
+	idempotencyService := idempotency.New(idempotencyContainer)
+
+	// Rate limiting
+	var rateLimitStore ratelimit.Store
+	switch cfg.RateLimitStore {
+	case "database", "sqlite":
+		if readOnly {
+			// Sin escritura no se pueden compartir los contadores; cada reader cuenta los suyos
+			appLogger.Warn("RATE_LIMIT_STORE=database needs a writable database; the reader counts requests in memory")
+			rateLimitStore = ratelimit.NewMemoryStore()
+			break
+		}
+		rateLimitStore = rateLimitRepo.NewStore(db)
+	default:
+		rateLimitStore = ratelimit.NewMemoryStore()
+	}
+
 	return &Container{
-		DebtService:    debtService,
-		IncomeService:  incomeService,
-		PaymentService: paymentService,
-		UserService:    userService,
-		DB:             db,
+		AccessTokenService: accessTokenService,
+		AccountService:     accountService,
+		AuditService:       auditService,
+		DebtService:        debtService,
+		HouseholdService:   householdService,
+		IdempotencyService: idempotencyService,
+		IncomeService:      incomeService,
+		PaymentService:     paymentService,
+		SecurityService:    securityService,
+		UserService:        userService,
+		RateLimitStore:     rateLimitStore,
+		Logger:             appLogger,
+		LogLevel:           logLevel,
+		Tracer:             tracer,
+		DB:                 db,
+	}
+}
+
+func fatal(logger *slog.Logger, msg string, err error) {
+	logger.Error(msg, slog.Any("error", err))
+	os.Exit(1)
+}
+
+func newMailer(cfg config.Config, logger *slog.Logger) mailer.Mailer {
+	switch cfg.MailDriver {
+	case "smtp":
+		return mailer.NewSMTPMailer(mailer.SMTPConfig{
+			Host:     cfg.SMTPHost,
+			Port:     cfg.SMTPPort,
+			Username: cfg.SMTPUsername,
+			Password: cfg.SMTPPassword,
+			From:     cfg.MailFrom,
+		})
+	case "file":
+		fileMailer, err := mailer.NewFileMailer(cfg.MailDir)
+		if err != nil {
+			fatal(logger, "failed to initialize mail directory", err)
+		}
+		return fileMailer
+	default:
+		return mailer.NewLogMailer(logger)
+	}
+}
+
+func newTraceExporter(cfg config.Config) tracing.Exporter {
+	switch cfg.TracingExporter {
+	case "stdout":
+		return tracing.NewStdoutExporter(os.Stdout)
+	case "otlp":
+		return tracing.NewOTLPExporter(cfg.TracingEndpoint, cfg.TracingServiceName)
+	default:
+		return nil
+	}
+}
+
+// signingKey usa una clave aleatoria si no se configuró ninguna: los enlaces
+// enviados dejan de valer al reiniciar el servicio.
+func signingKey(cfg config.Config, logger *slog.Logger) []byte {
+	if cfg.EmailVerificationSecret != "" {
+		return []byte(cfg.EmailVerificationSecret)
+	}
+
+	logger.Warn("EMAIL_VERIFICATION_SECRET is not set; verification links and receipt URLs will not survive a restart")
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		fatal(logger, "failed to generate verification key", err)
 	}
+	return key
 }
 
 func (c *Container) Close() error {
@@ -0,0 +1,243 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strings"
+
+	"github.com/payvue/payvue-backend/pkg/client"
+	"github.com/payvue/payvue-backend/pkg/domain/accesstoken"
+	"github.com/payvue/payvue-backend/pkg/rest/entities"
+)
+
+var loginCommand = &command{
+	name:    "login",
+	summary: "inicia sesión y guarda un token de acceso en la configuración",
+	run:     runLogin,
+}
+
+var logoutCommand = &command{
+	name:    "logout",
+	summary: "revoca el token guardado y lo borra de la configuración",
+	run:     runLogout,
+}
+
+// runLogin inicia sesión con email y contraseña (y el código de la verificación
+// en dos pasos si está activa) y crea un token de acceso personal, que es lo
+// que se guarda: la contraseña nunca se escribe en disco.
+func runLogin(a *app, args []string) error {
+	fs := a.flagSet("payvuectl login", "[flags]")
+	email := fs.String("email", "", "email de la cuenta; si falta se pregunta")
+	passwordStdin := fs.Bool("password-stdin", false, "lee la contraseña de la entrada estándar")
+	code := fs.String("code", "", "código de la verificación en dos pasos o de recuperación")
+	withToken := fs.Bool("with-token", false, "lee un token de acceso ya creado de la entrada estándar en lugar de iniciar sesión")
+	tokenName := fs.String("token-name", defaultTokenName(), "nombre del token que se crea")
+	expiresInDays := fs.Int("expires-days", 90, "días de validez del token; 0 no caduca")
+	if _, err := a.parse(fs, args, 0); err != nil {
+		return err
+	}
+
+	if *withToken {
+		token, err := a.readLine("")
+		if err != nil {
+			return err
+		}
+		return a.saveToken(token, 0, "")
+	}
+
+	anonymous, err := client.New(client.Config{BaseURL: a.options.server, UserAgent: userAgent()})
+	if err != nil {
+		return err
+	}
+
+	if *email == "" {
+		if *email, err = a.readLine("Email: "); err != nil {
+			return err
+		}
+	}
+	var password string
+	if *passwordStdin {
+		password, err = a.readLine("")
+	} else {
+		password, err = a.readPassword("Contraseña: ")
+	}
+	if err != nil {
+		return err
+	}
+
+	result, err := anonymous.Login(a.ctx, entities.LoginRequest{Email: *email, Password: password})
+	if err != nil {
+		return err
+	}
+	session := result.Session
+	var tokenCode string
+	if result.Challenge != nil {
+		if *code == "" {
+			if *code, err = a.readLine("Código de verificación: "); err != nil {
+				return err
+			}
+		}
+		session, err = anonymous.LoginTwoFactor(a.ctx, entities.LoginTwoFactorRequest{
+			ChallengeToken: result.Challenge.ChallengeToken,
+			Code:           *code,
+		})
+		if err != nil {
+			return err
+		}
+		// El servidor no acepta dos veces el mismo código: crear el token pide
+		// otro
+		if tokenCode, err = a.readLine("Siguiente código de verificación: "); err != nil {
+			return err
+		}
+	}
+
+	// Con la sesión el cliente se identifica como el frontend, por X-User-ID,
+	// solo para crear el token, que vuelve a pedir las credenciales
+	user, err := client.New(client.Config{BaseURL: a.options.server, UserID: session.UserID, UserAgent: userAgent()})
+	if err != nil {
+		return err
+	}
+	token, err := user.CreateToken(a.ctx, accesstoken.CreateTokenRequest{
+		Name:          *tokenName,
+		Scope:         accesstoken.ScopeReadWrite,
+		ExpiresInDays: *expiresInDays,
+		Password:      password,
+		Code:          tokenCode,
+	})
+	if err != nil {
+		return err
+	}
+	return a.saveToken(token.Token, token.ID, session.Email)
+}
+
+// saveToken comprueba que el token funciona antes de guardarlo.
+func (a *app) saveToken(token string, tokenID int, email string) error {
+	a.options.token = strings.TrimSpace(token)
+	if !strings.HasPrefix(a.options.token, accesstoken.TokenPrefix) {
+		return fmt.Errorf("invalid token: access tokens start with %q", accesstoken.TokenPrefix)
+	}
+	c, err := a.client()
+	if err != nil {
+		return err
+	}
+	if _, err := c.ListTokens(a.ctx); err != nil {
+		return err
+	}
+
+	a.config.Server = a.options.server
+	a.config.Token = a.options.token
+	a.config.TokenID = tokenID
+	a.config.Email = email
+	if err := saveConfig(a.options.configPath, a.config); err != nil {
+		return err
+	}
+
+	if email != "" {
+		a.message("Sesión iniciada como %s en %s.", email, a.options.server)
+	} else {
+		a.message("Token guardado para %s.", a.options.server)
+	}
+	a.message("Configuración: %s", a.options.configPath)
+	return nil
+}
+
+func runLogout(a *app, args []string) error {
+	fs := a.flagSet("payvuectl logout", "[flags]")
+	if _, err := a.parse(fs, args, 0); err != nil {
+		return err
+	}
+	if a.config.Token == "" {
+		a.message("No hay ninguna sesión guardada.")
+		return nil
+	}
+
+	// Un token que ya no existe o ha caducado no impide borrar la configuración
+	if a.config.TokenID > 0 {
+		c, err := a.client()
+		if err != nil {
+			return err
+		}
+		if err := c.RevokeToken(a.ctx, a.config.TokenID); err != nil && !isUnauthorized(err) && !client.IsNotFound(err) {
+			return err
+		}
+	}
+
+	a.config.Token = ""
+	a.config.TokenID = 0
+	a.config.Email = ""
+	if err := saveConfig(a.options.configPath, a.config); err != nil {
+		return err
+	}
+	a.message("Sesión cerrada.")
+	return nil
+}
+
+func isUnauthorized(err error) bool {
+	var apiErr *client.Error
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusUnauthorized
+}
+
+func defaultTokenName() string {
+	host, err := os.Hostname()
+	if err != nil || host == "" {
+		return "payvuectl"
+	}
+	return "payvuectl@" + host
+}
+
+// readLine muestra prompt en stderr, para no mezclarlo con la salida, y lee una
+// línea de la entrada estándar.
+func (a *app) readLine(prompt string) (string, error) {
+	if prompt != "" {
+		fmt.Fprint(a.stderr, prompt)
+	}
+	line, err := a.stdin.ReadString('\n')
+	line = strings.TrimRight(line, "\r\n")
+	if err != nil && line == "" {
+		return "", errors.New("no input: expected a line on standard input")
+	}
+	return line, nil
+}
+
+// readPassword lee la contraseña sin mostrarla. Sin dependencias para manejar
+// el terminal se apaga el eco con stty; si no hay terminal (Windows, una
+// tubería) se lee tal cual.
+func (a *app) readPassword(prompt string) (string, error) {
+	if info, err := os.Stdin.Stat(); err != nil || info.Mode()&os.ModeCharDevice == 0 {
+		return a.readLine(prompt)
+	}
+	if err := stty("-echo"); err != nil {
+		return a.readLine(prompt)
+	}
+
+	// Con Ctrl+C a mitad hay que devolver el eco antes de salir
+	interrupted := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	signal.Notify(interrupted, os.Interrupt)
+	go func() {
+		select {
+		case <-interrupted:
+			stty("echo")
+			fmt.Fprintln(a.stderr)
+			os.Exit(130)
+		case <-done:
+		}
+	}()
+	defer func() {
+		signal.Stop(interrupted)
+		close(done)
+		stty("echo")
+		fmt.Fprintln(a.stderr)
+	}()
+	return a.readLine(prompt)
+}
+
+func stty(arg string) error {
+	cmd := exec.Command("stty", arg)
+	cmd.Stdin = os.Stdin
+	return cmd.Run()
+}
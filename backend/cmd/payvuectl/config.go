@@ -0,0 +1,72 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// fileConfig es el fichero que escribe login. Lleva el token en claro, así que
+// se guarda solo legible por el usuario.
+type fileConfig struct {
+	Server string `json:"server,omitempty"`
+	Token  string `json:"token,omitempty"`
+	// TokenID es el id del token creado por login, para revocarlo en logout.
+	TokenID int    `json:"token_id,omitempty"`
+	Email   string `json:"email,omitempty"`
+	// Output es el formato por defecto: table o json.
+	Output string `json:"output,omitempty"`
+}
+
+// defaultConfigPath es payvue/payvuectl.json en el directorio de configuración
+// del usuario (~/.config en Linux).
+func defaultConfigPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ".payvuectl.json"
+	}
+	return filepath.Join(dir, "payvue", "payvuectl.json")
+}
+
+// loadConfig devuelve una configuración vacía si el fichero no existe.
+func loadConfig(path string) (fileConfig, error) {
+	var config fileConfig
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return config, nil
+	}
+	if err != nil {
+		return config, err
+	}
+	if err := json.Unmarshal(data, &config); err != nil {
+		return config, fmt.Errorf("reading %s: %w", path, err)
+	}
+	return config, nil
+}
+
+// saveConfig escribe el fichero entero de una vez para no dejarlo a medias.
+func saveConfig(path string, config fileConfig) error {
+	data, err := json.MarshalIndent(config, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".payvuectl-*.json")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
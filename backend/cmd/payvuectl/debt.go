@@ -0,0 +1,227 @@
+package main
+
+import (
+	"flag"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/payvue/payvue-backend/pkg/domain/debt"
+	"github.com/payvue/payvue-backend/pkg/rest/entities"
+)
+
+var debtCommand = &command{
+	name:    "debt",
+	summary: "deudas",
+	subs: []*command{
+		{name: "list", summary: "lista las deudas (--trash: las de la papelera)", run: runDebtList},
+		{name: "add", summary: "crea una deuda", run: runDebtAdd},
+		{name: "show", args: "<id>", summary: "muestra una deuda", run: runDebtShow},
+		{name: "edit", args: "<id>", summary: "cambia los campos indicados con flags", run: runDebtEdit},
+		{name: "rm", args: "<id>", summary: "mueve una deuda a la papelera", run: runDebtRemove},
+		{name: "restore", args: "<id>", summary: "recupera una deuda de la papelera", run: runDebtRestore},
+	},
+}
+
+// debtPatchFields traduce los flags de debt edit a los campos de la API.
+var debtPatchFields = map[string]string{
+	"name":         "name",
+	"total":        "total_amount",
+	"remaining":    "remaining_amount",
+	"due":          "due_date",
+	"rate":         "interest_rate",
+	"installments": "num_installments",
+	"installment":  "installment_amount",
+	"payment-day":  "payment_day",
+	"paid":         "paid",
+}
+
+func registerDebtFlags(fs *flag.FlagSet, req *entities.CreateDebtRequest) {
+	fs.StringVar(&req.Name, "name", "", "nombre")
+	fs.Float64Var(&req.TotalAmount, "total", 0, "importe total")
+	fs.Float64Var(&req.RemainingAmount, "remaining", 0, "importe pendiente (por defecto, el total)")
+	fs.StringVar(&req.DueDate, "due", "", "fecha de vencimiento, YYYY-MM-DD")
+	fs.Float64Var(&req.InterestRate, "rate", 0, "tipo de interés")
+	fs.IntVar(&req.NumInstallments, "installments", 0, "número de cuotas")
+	fs.Float64Var(&req.InstallmentAmount, "installment", 0, "importe de la cuota (por defecto, total entre cuotas)")
+	fs.IntVar(&req.PaymentDay, "payment-day", 0, "día del mes en que se paga (por defecto, el del vencimiento)")
+}
+
+func runDebtList(a *app, args []string) error {
+	fs := a.flagSet("payvuectl debt list", "[--trash] [flags]")
+	trash := fs.Bool("trash", false, "lista la papelera")
+	if _, err := a.parse(fs, args, 0); err != nil {
+		return err
+	}
+	c, err := a.client()
+	if err != nil {
+		return err
+	}
+
+	list := c.ListDebts
+	if *trash {
+		list = c.ListDeletedDebts
+	}
+	debts, err := list(a.ctx)
+	if err != nil {
+		return err
+	}
+	return a.render(debts, func(t *table) {
+		header := []string{"ID", "NOMBRE", "PENDIENTE", "TOTAL", "CUOTA", "CUOTAS", "DÍA", "VENCE", "PAGADA", "HOGAR"}
+		if *trash {
+			header = append(header, "BORRADA")
+		}
+		t.row(header...)
+		for _, d := range debts {
+			row := []string{
+				strconv.Itoa(d.ID),
+				d.Name,
+				money(d.RemainingAmount),
+				money(d.TotalAmount),
+				money(d.InstallmentAmount),
+				strconv.Itoa(d.RemainingPayments) + "/" + strconv.Itoa(d.NumInstallments),
+				strconv.Itoa(d.PaymentDay),
+				d.DueDate,
+				yesNo(d.Paid),
+				optionalID(d.HouseholdID),
+			}
+			if *trash {
+				row = append(row, d.DeletedAt)
+			}
+			t.row(row...)
+		}
+	})
+}
+
+func runDebtAdd(a *app, args []string) error {
+	fs := a.flagSet("payvuectl debt add", "--name N --total T --due YYYY-MM-DD --installments N [flags]")
+	var req entities.CreateDebtRequest
+	registerDebtFlags(fs, &req)
+	fs.IntVar(&req.HouseholdID, "household", 0, "id del hogar con el que se comparte")
+	if _, err := a.parse(fs, args, 0); err != nil {
+		return err
+	}
+	if req.Name == "" || req.TotalAmount <= 0 || req.DueDate == "" || req.NumInstallments <= 0 {
+		return usagef("--name, --total, --due and --installments are required")
+	}
+
+	set := visited(fs)
+	if !set["remaining"] {
+		req.RemainingAmount = req.TotalAmount
+	}
+	if !set["installment"] {
+		req.InstallmentAmount = math.Round(req.TotalAmount/float64(req.NumInstallments)*100) / 100
+	}
+	if !set["payment-day"] {
+		if due, err := time.Parse("2006-01-02", req.DueDate); err == nil {
+			req.PaymentDay = due.Day()
+		}
+	}
+
+	c, err := a.client()
+	if err != nil {
+		return err
+	}
+	created, err := c.CreateDebt(a.ctx, req)
+	if err != nil {
+		return err
+	}
+	return a.renderDebt(created)
+}
+
+func runDebtShow(a *app, args []string) error {
+	fs := a.flagSet("payvuectl debt show", "<id> [flags]")
+	id, err := a.parseID(fs, args)
+	if err != nil {
+		return err
+	}
+	c, err := a.client()
+	if err != nil {
+		return err
+	}
+	d, err := c.GetDebt(a.ctx, id)
+	if err != nil {
+		return err
+	}
+	return a.renderDebt(d)
+}
+
+// runDebtEdit envía como JSON Merge Patch solo los flags indicados.
+func runDebtEdit(a *app, args []string) error {
+	fs := a.flagSet("payvuectl debt edit", "<id> [--name N] [--remaining R] [--paid] ... [flags]")
+	var req entities.CreateDebtRequest
+	registerDebtFlags(fs, &req)
+	fs.Bool("paid", false, "marca la deuda como pagada (--paid=false la vuelve a abrir)")
+	id, err := a.parseID(fs, args)
+	if err != nil {
+		return err
+	}
+	patch := patchFromFlags(fs, debtPatchFields)
+	if len(patch) == 0 {
+		return usagef("nothing to change: pass the fields to edit as flags")
+	}
+
+	c, err := a.client()
+	if err != nil {
+		return err
+	}
+	updated, err := c.PatchDebt(a.ctx, id, patch, 0)
+	if err != nil {
+		return err
+	}
+	return a.renderDebt(updated)
+}
+
+func runDebtRemove(a *app, args []string) error {
+	fs := a.flagSet("payvuectl debt rm", "<id> [flags]")
+	id, err := a.parseID(fs, args)
+	if err != nil {
+		return err
+	}
+	c, err := a.client()
+	if err != nil {
+		return err
+	}
+	if err := c.DeleteDebt(a.ctx, id); err != nil {
+		return err
+	}
+	a.message("Deuda %d movida a la papelera.", id)
+	return nil
+}
+
+func runDebtRestore(a *app, args []string) error {
+	fs := a.flagSet("payvuectl debt restore", "<id> [flags]")
+	id, err := a.parseID(fs, args)
+	if err != nil {
+		return err
+	}
+	c, err := a.client()
+	if err != nil {
+		return err
+	}
+	restored, err := c.RestoreDebt(a.ctx, id)
+	if err != nil {
+		return err
+	}
+	return a.renderDebt(restored)
+}
+
+func (a *app) renderDebt(d *debt.DebtResponse) error {
+	return a.render(d, func(t *table) {
+		t.field("ID", strconv.Itoa(d.ID))
+		t.field("Nombre", d.Name)
+		t.field("Total", money(d.TotalAmount))
+		t.field("Pendiente", money(d.RemainingAmount))
+		t.field("Interés", strconv.FormatFloat(d.InterestRate, 'f', -1, 64))
+		t.field("Cuota", money(d.InstallmentAmount))
+		t.field("Cuotas restantes", strconv.Itoa(d.RemainingPayments)+" de "+strconv.Itoa(d.NumInstallments))
+		t.field("Día de pago", strconv.Itoa(d.PaymentDay))
+		t.field("Vencimiento", d.DueDate)
+		t.field("Pagada", yesNo(d.Paid))
+		t.field("Hogar", optionalID(d.HouseholdID))
+		t.field("Versión", strconv.Itoa(d.Version))
+		if d.DeletedAt != "" {
+			t.field("Borrada", d.DeletedAt)
+		}
+	})
+}
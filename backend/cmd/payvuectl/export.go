@@ -0,0 +1,26 @@
+package main
+
+import (
+	"io"
+)
+
+var exportCommand = &command{
+	name:    "export",
+	summary: "descarga un zip con todos los datos de la cuenta",
+	run:     runExport,
+}
+
+func runExport(a *app, args []string) error {
+	fs := a.flagSet("payvuectl export", "[--out fichero.zip] [flags]")
+	out := fs.String("out", "", "fichero de destino (por defecto, el nombre que propone el servidor; - para la salida estándar)")
+	if _, err := a.parse(fs, args, 0); err != nil {
+		return err
+	}
+	c, err := a.client()
+	if err != nil {
+		return err
+	}
+	return a.writeOutput(*out, func(w io.Writer) (string, error) {
+		return c.Export(a.ctx, w)
+	})
+}
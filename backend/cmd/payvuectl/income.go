@@ -0,0 +1,178 @@
+package main
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/payvue/payvue-backend/pkg/domain/income"
+	"github.com/payvue/payvue-backend/pkg/rest/entities"
+)
+
+var incomeCommand = &command{
+	name:    "income",
+	summary: "ingresos",
+	subs: []*command{
+		{name: "list", summary: "lista los ingresos (--trash: los de la papelera)", run: runIncomeList},
+		{name: "add", summary: "registra un ingreso", run: runIncomeAdd},
+		{name: "show", args: "<id>", summary: "muestra un ingreso", run: runIncomeShow},
+		{name: "edit", args: "<id>", summary: "cambia los campos indicados con flags", run: runIncomeEdit},
+		{name: "rm", args: "<id>", summary: "mueve un ingreso a la papelera", run: runIncomeRemove},
+		{name: "restore", args: "<id>", summary: "recupera un ingreso de la papelera", run: runIncomeRestore},
+	},
+}
+
+var incomePatchFields = map[string]string{
+	"amount": "amount",
+	"source": "source",
+	"date":   "date",
+}
+
+func runIncomeList(a *app, args []string) error {
+	fs := a.flagSet("payvuectl income list", "[--trash] [flags]")
+	trash := fs.Bool("trash", false, "lista la papelera")
+	if _, err := a.parse(fs, args, 0); err != nil {
+		return err
+	}
+	c, err := a.client()
+	if err != nil {
+		return err
+	}
+
+	list := c.ListIncomes
+	if *trash {
+		list = c.ListDeletedIncomes
+	}
+	incomes, err := list(a.ctx)
+	if err != nil {
+		return err
+	}
+	return a.render(incomes, func(t *table) {
+		header := []string{"ID", "FECHA", "FUENTE", "IMPORTE", "HOGAR"}
+		if *trash {
+			header = append(header, "BORRADO")
+		}
+		t.row(header...)
+		for _, i := range incomes {
+			row := []string{strconv.Itoa(i.ID), i.Date, i.Source, money(i.Amount), optionalID(i.HouseholdID)}
+			if *trash {
+				row = append(row, i.DeletedAt)
+			}
+			t.row(row...)
+		}
+	})
+}
+
+func runIncomeAdd(a *app, args []string) error {
+	fs := a.flagSet("payvuectl income add", "--amount A --source S [flags]")
+	var req entities.CreateIncomeRequest
+	fs.Float64Var(&req.Amount, "amount", 0, "importe")
+	fs.StringVar(&req.Source, "source", "", "origen del ingreso (nómina, alquiler...)")
+	fs.StringVar(&req.Date, "date", time.Now().Format("2006-01-02"), "fecha, YYYY-MM-DD")
+	fs.IntVar(&req.HouseholdID, "household", 0, "id del hogar con el que se comparte")
+	if _, err := a.parse(fs, args, 0); err != nil {
+		return err
+	}
+	if req.Amount <= 0 || req.Source == "" {
+		return usagef("--amount and --source are required")
+	}
+
+	c, err := a.client()
+	if err != nil {
+		return err
+	}
+	created, err := c.CreateIncome(a.ctx, req)
+	if err != nil {
+		return err
+	}
+	return a.renderIncome(created)
+}
+
+func runIncomeShow(a *app, args []string) error {
+	fs := a.flagSet("payvuectl income show", "<id> [flags]")
+	id, err := a.parseID(fs, args)
+	if err != nil {
+		return err
+	}
+	c, err := a.client()
+	if err != nil {
+		return err
+	}
+	i, err := c.GetIncome(a.ctx, id)
+	if err != nil {
+		return err
+	}
+	return a.renderIncome(i)
+}
+
+func runIncomeEdit(a *app, args []string) error {
+	fs := a.flagSet("payvuectl income edit", "<id> [--amount A] [--source S] [--date YYYY-MM-DD] [flags]")
+	fs.Float64("amount", 0, "importe")
+	fs.String("source", "", "origen del ingreso")
+	fs.String("date", "", "fecha, YYYY-MM-DD")
+	id, err := a.parseID(fs, args)
+	if err != nil {
+		return err
+	}
+	patch := patchFromFlags(fs, incomePatchFields)
+	if len(patch) == 0 {
+		return usagef("nothing to change: pass the fields to edit as flags")
+	}
+
+	c, err := a.client()
+	if err != nil {
+		return err
+	}
+	updated, err := c.PatchIncome(a.ctx, id, patch, 0)
+	if err != nil {
+		return err
+	}
+	return a.renderIncome(updated)
+}
+
+func runIncomeRemove(a *app, args []string) error {
+	fs := a.flagSet("payvuectl income rm", "<id> [flags]")
+	id, err := a.parseID(fs, args)
+	if err != nil {
+		return err
+	}
+	c, err := a.client()
+	if err != nil {
+		return err
+	}
+	if err := c.DeleteIncome(a.ctx, id); err != nil {
+		return err
+	}
+	a.message("Ingreso %d movido a la papelera.", id)
+	return nil
+}
+
+func runIncomeRestore(a *app, args []string) error {
+	fs := a.flagSet("payvuectl income restore", "<id> [flags]")
+	id, err := a.parseID(fs, args)
+	if err != nil {
+		return err
+	}
+	c, err := a.client()
+	if err != nil {
+		return err
+	}
+	restored, err := c.RestoreIncome(a.ctx, id)
+	if err != nil {
+		return err
+	}
+	return a.renderIncome(restored)
+}
+
+func (a *app) renderIncome(i *income.IncomeResponse) error {
+	return a.render(i, func(t *table) {
+		t.field("ID", strconv.Itoa(i.ID))
+		t.field("Fecha", i.Date)
+		t.field("Fuente", i.Source)
+		t.field("Importe", money(i.Amount))
+		t.field("Hogar", optionalID(i.HouseholdID))
+		t.field("Versión", strconv.Itoa(i.Version))
+		if i.DeletedAt != "" {
+			t.field("Borrado", i.DeletedAt)
+		}
+	})
+}
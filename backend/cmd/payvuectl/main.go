@@ -0,0 +1,319 @@
+// payvuectl registra deudas, ingresos y pagos y consulta el saldo desde la
+// terminal, con la misma API que el frontend (pkg/client).
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/payvue/payvue-backend/pkg/buildinfo"
+	"github.com/payvue/payvue-backend/pkg/client"
+)
+
+const defaultServer = "http://localhost:8080"
+
+// command es un subcomando; los que tienen subs (debt, income, payment) solo
+// reparten a sus acciones.
+type command struct {
+	name    string
+	args    string
+	summary string
+	run     func(a *app, args []string) error
+	subs    []*command
+}
+
+var commands = []*command{
+	loginCommand,
+	logoutCommand,
+	debtCommand,
+	incomeCommand,
+	paymentCommand,
+	summaryCommand,
+	exportCommand,
+}
+
+// options son los flags que aceptan todos los comandos, antes o después del
+// nombre del comando.
+type options struct {
+	configPath string
+	server     string
+	token      string
+	output     string
+}
+
+func (o *options) register(fs *flag.FlagSet) {
+	fs.StringVar(&o.configPath, "config", o.configPath, "fichero de configuración (env PAYVUECTL_CONFIG)")
+	fs.StringVar(&o.server, "server", o.server, "URL del servidor (env PAYVUE_SERVER)")
+	fs.StringVar(&o.token, "token", o.token, "token de acceso personal (env PAYVUE_TOKEN)")
+	fs.StringVar(&o.output, "o", o.output, "formato de salida: table o json")
+	fs.StringVar(&o.output, "output", o.output, "formato de salida: table o json")
+}
+
+type app struct {
+	options options
+	config  fileConfig
+	ctx     context.Context
+	stdin   *bufio.Reader
+	stdout  io.Writer
+	stderr  io.Writer
+}
+
+// usageError es un error en los argumentos; sale con 2 en lugar de 1.
+type usageError struct {
+	message string
+	// reported indica que el mensaje ya se ha escrito, como hace el paquete flag.
+	reported bool
+}
+
+func (e *usageError) Error() string { return e.message }
+
+func usagef(format string, args ...interface{}) error {
+	return &usageError{message: fmt.Sprintf(format, args...)}
+}
+
+func main() {
+	// Sin manejar señales: Ctrl+C debe cortar también las preguntas de login
+	a := &app{
+		ctx:    context.Background(),
+		stdin:  bufio.NewReader(os.Stdin),
+		stdout: os.Stdout,
+		stderr: os.Stderr,
+	}
+	os.Exit(a.main(os.Args[1:]))
+}
+
+func (a *app) main(args []string) int {
+	fs := a.flagSet("payvuectl", "<comando> [argumentos]")
+	if err := fs.Parse(args); err != nil {
+		return a.exitCode(usageFlagError(err))
+	}
+	args = fs.Args()
+
+	if len(args) == 0 {
+		a.printCommands(commands, "payvuectl")
+		return 2
+	}
+	switch args[0] {
+	case "help":
+		a.printCommands(commands, "payvuectl")
+		return 0
+	case "version":
+		fmt.Fprintln(a.stdout, "payvuectl", buildinfo.Get().Version)
+		return 0
+	}
+
+	cmd, path, rest := findCommand(commands, args)
+	if cmd == nil {
+		fmt.Fprintf(a.stderr, "payvuectl: unknown command %q\n\n", args[0])
+		a.printCommands(commands, "payvuectl")
+		return 2
+	}
+	name := "payvuectl " + strings.Join(path, " ")
+	if cmd.run == nil {
+		if len(rest) > 0 && rest[0] == "help" {
+			a.printCommands(cmd.subs, name)
+			return 0
+		}
+		if len(rest) > 0 {
+			fmt.Fprintf(a.stderr, "payvuectl: unknown command %q\n\n", name+" "+rest[0])
+		}
+		a.printCommands(cmd.subs, name)
+		return 2
+	}
+
+	return a.exitCode(cmd.run(a, rest))
+}
+
+func (a *app) exitCode(err error) int {
+	var usageErr *usageError
+	switch {
+	case err == nil, errors.Is(err, flag.ErrHelp):
+		return 0
+	case errors.As(err, &usageErr):
+		if !usageErr.reported {
+			fmt.Fprintln(a.stderr, "payvuectl:", usageErr.message)
+		}
+		return 2
+	}
+
+	fmt.Fprintln(a.stderr, "payvuectl:", err)
+	if client.Code(err) == "invalid_token" {
+		fmt.Fprintln(a.stderr, "Ejecuta 'payvuectl login' para iniciar sesión.")
+	}
+	return 1
+}
+
+// usageFlagError marca como de uso los errores de fs.Parse, que el paquete flag
+// ya ha escrito junto con la ayuda.
+func usageFlagError(err error) error {
+	if errors.Is(err, flag.ErrHelp) {
+		return err
+	}
+	return &usageError{message: err.Error(), reported: true}
+}
+
+// findCommand busca el comando por sus nombres, p. ej. "debt list". Devuelve
+// también los nombres que ha reconocido y los argumentos que quedan.
+func findCommand(list []*command, args []string) (*command, []string, []string) {
+	var found *command
+	var path []string
+	for len(args) > 0 {
+		var next *command
+		for _, cmd := range list {
+			if cmd.name == args[0] {
+				next = cmd
+			}
+		}
+		if next == nil {
+			break
+		}
+		found, path, args = next, append(path, next.name), args[1:]
+		if next.run != nil {
+			break
+		}
+		list = next.subs
+	}
+	return found, path, args
+}
+
+func (a *app) printCommands(list []*command, prefix string) {
+	fmt.Fprintf(a.stderr, "Uso: %s <comando> [flags]\n\nComandos:\n", prefix)
+	w := tabwriter.NewWriter(a.stderr, 0, 4, 3, ' ', 0)
+	for _, cmd := range list {
+		usage := strings.TrimSpace(cmd.name + " " + cmd.args)
+		if cmd.subs != nil {
+			names := make([]string, 0, len(cmd.subs))
+			for _, sub := range cmd.subs {
+				names = append(names, sub.name)
+			}
+			usage = cmd.name + " " + strings.Join(names, "|")
+		}
+		fmt.Fprintf(w, "  %s\t%s\n", usage, cmd.summary)
+	}
+	w.Flush()
+	fmt.Fprintf(a.stderr, "\nFlags comunes: --server, --token, --config, -o table|json. '%s <comando> -h' muestra los flags de cada uno.\n", prefix)
+}
+
+// flagSet crea los flags de un comando con los comunes ya registrados.
+func (a *app) flagSet(name, args string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(a.stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(a.stderr, "Uso: %s %s\n\nFlags:\n", name, args)
+		fs.PrintDefaults()
+	}
+	a.options.register(fs)
+	return fs
+}
+
+// parse lee los flags estén antes o después de los argumentos, como en
+// "debt show 3 -o json"; el paquete flag se para en el primer argumento. Después
+// carga la configuración.
+func (a *app) parse(fs *flag.FlagSet, args []string, positional int) ([]string, error) {
+	var values []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, usageFlagError(err)
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			break
+		}
+		values = append(values, args[0])
+		args = args[1:]
+	}
+	if len(values) != positional {
+		fs.Usage()
+		return nil, usagef("expected %d argument(s), got %d", positional, len(values))
+	}
+	return values, a.resolve()
+}
+
+// parseID es parse para los comandos que reciben un id.
+func (a *app) parseID(fs *flag.FlagSet, args []string) (int, error) {
+	values, err := a.parse(fs, args, 1)
+	if err != nil {
+		return 0, err
+	}
+	id, err := strconv.Atoi(values[0])
+	if err != nil || id <= 0 {
+		return 0, usagef("invalid id %q", values[0])
+	}
+	return id, nil
+}
+
+// resolve completa las opciones: gana el flag, después la variable de entorno,
+// después el fichero de configuración y por último el valor por defecto.
+func (a *app) resolve() error {
+	if a.options.configPath == "" {
+		a.options.configPath = os.Getenv("PAYVUECTL_CONFIG")
+	}
+	if a.options.configPath == "" {
+		a.options.configPath = defaultConfigPath()
+	}
+	config, err := loadConfig(a.options.configPath)
+	if err != nil {
+		return err
+	}
+	a.config = config
+
+	a.options.server = firstNonEmpty(a.options.server, os.Getenv("PAYVUE_SERVER"), config.Server, defaultServer)
+	a.options.token = firstNonEmpty(a.options.token, os.Getenv("PAYVUE_TOKEN"), config.Token)
+	a.options.output = firstNonEmpty(a.options.output, os.Getenv("PAYVUE_OUTPUT"), config.Output, outputTable)
+	if a.options.output != outputTable && a.options.output != outputJSON {
+		return usagef("invalid output %q: use table or json", a.options.output)
+	}
+	return nil
+}
+
+// client devuelve un cliente autenticado con el token de la configuración.
+func (a *app) client() (*client.Client, error) {
+	if a.options.token == "" {
+		return nil, errors.New("not logged in: run 'payvuectl login' or set PAYVUE_TOKEN")
+	}
+	return client.New(client.Config{
+		BaseURL:   a.options.server,
+		Token:     a.options.token,
+		UserAgent: userAgent(),
+	})
+}
+
+func userAgent() string {
+	return "payvuectl/" + buildinfo.Get().Version
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// visited devuelve los flags que se han pasado en la línea de comandos.
+func visited(fs *flag.FlagSet) map[string]bool {
+	set := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) { set[f.Name] = true })
+	return set
+}
+
+// patchFromFlags construye un JSON Merge Patch con los flags pasados; fields
+// traduce cada flag al campo de la API.
+func patchFromFlags(fs *flag.FlagSet, fields map[string]string) map[string]interface{} {
+	patch := make(map[string]interface{})
+	fs.Visit(func(f *flag.Flag) {
+		if field, ok := fields[f.Name]; ok {
+			patch[field] = f.Value.(flag.Getter).Get()
+		}
+	})
+	return patch
+}
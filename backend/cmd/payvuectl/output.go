@@ -0,0 +1,116 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+const (
+	outputTable = "table"
+	outputJSON  = "json"
+)
+
+// render escribe value en JSON, tal y como lo devuelve la API, o como tabla
+// con printTable.
+func (a *app) render(value interface{}, printTable func(t *table)) error {
+	if a.options.output == outputJSON {
+		encoder := json.NewEncoder(a.stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(value)
+	}
+
+	t := &table{w: tabwriter.NewWriter(a.stdout, 0, 4, 2, ' ', 0)}
+	printTable(t)
+	return t.w.Flush()
+}
+
+// message escribe una confirmación solo en la salida de tabla; con JSON la
+// salida queda vacía para no romper a quien la lea con jq.
+func (a *app) message(format string, args ...interface{}) {
+	if a.options.output == outputTable {
+		fmt.Fprintf(a.stdout, format+"\n", args...)
+	}
+}
+
+type table struct {
+	w *tabwriter.Writer
+}
+
+func (t *table) row(cells ...string) {
+	fmt.Fprintln(t.w, strings.Join(cells, "\t"))
+}
+
+// field es una fila clave-valor de las vistas de detalle.
+func (t *table) field(name, value string) {
+	if value == "" {
+		value = "-"
+	}
+	t.row(name+":", value)
+}
+
+func money(amount float64) string {
+	return strconv.FormatFloat(amount, 'f', 2, 64)
+}
+
+func yesNo(value bool) string {
+	if value {
+		return "sí"
+	}
+	return "no"
+}
+
+func optionalID(id int) string {
+	if id == 0 {
+		return "-"
+	}
+	return strconv.Itoa(id)
+}
+
+// writeOutput guarda lo que escribe write en path, o en la salida estándar si
+// path es "-". Sin path usa el nombre que devuelve write, el que propone el
+// servidor. Se escribe en un temporal que se renombra al terminar para no dejar
+// un fichero a medias si la descarga falla; como el temporal, el fichero queda
+// solo legible por el usuario.
+func (a *app) writeOutput(path string, write func(w io.Writer) (string, error)) error {
+	if path == "-" {
+		_, err := write(a.stdout)
+		return err
+	}
+
+	dir := "."
+	if path != "" {
+		dir = filepath.Dir(path)
+	}
+	tmp, err := os.CreateTemp(dir, ".payvuectl-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	name, err := write(tmp)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+
+	if path == "" {
+		path = filepath.Base(name)
+	}
+	if path == "" || path == "." || path == "/" {
+		return errors.New("the server did not suggest a file name: use --out")
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return err
+	}
+	a.message("Guardado en %s", path)
+	return nil
+}
@@ -0,0 +1,200 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+
+	"github.com/payvue/payvue-backend/pkg/client"
+	"github.com/payvue/payvue-backend/pkg/domain/debt"
+	"github.com/payvue/payvue-backend/pkg/domain/payment"
+)
+
+var paymentCommand = &command{
+	name:    "payment",
+	summary: "pagos de las deudas",
+	subs: []*command{
+		{name: "list", summary: "lista los pagos (--debt: los de una deuda; --trash: los de la papelera)", run: runPaymentList},
+		{name: "add", summary: "registra un pago, con --receipt para adjuntar el recibo", run: runPaymentAdd},
+		{name: "receipt", args: "<id>", summary: "descarga el recibo de un pago", run: runPaymentReceipt},
+		{name: "rm", args: "<id>", summary: "mueve un pago a la papelera", run: runPaymentRemove},
+		{name: "restore", args: "<id>", summary: "recupera un pago de la papelera", run: runPaymentRestore},
+	},
+}
+
+func runPaymentList(a *app, args []string) error {
+	fs := a.flagSet("payvuectl payment list", "[--debt ID] [--trash] [flags]")
+	debtID := fs.Int("debt", 0, "solo los pagos de esta deuda")
+	trash := fs.Bool("trash", false, "lista la papelera")
+	if _, err := a.parse(fs, args, 0); err != nil {
+		return err
+	}
+	c, err := a.client()
+	if err != nil {
+		return err
+	}
+
+	list := c.ListPayments
+	if *trash {
+		list = c.ListDeletedPayments
+	}
+	all, err := list(a.ctx)
+	if err != nil {
+		return err
+	}
+	payments := make([]payment.PaymentResponse, 0, len(all))
+	for _, p := range all {
+		if *debtID == 0 || p.DebtID == *debtID {
+			payments = append(payments, p)
+		}
+	}
+
+	return a.render(payments, func(t *table) {
+		header := []string{"ID", "FECHA", "DEUDA", "IMPORTE", "PENDIENTE", "CUOTAS", "RECIBO"}
+		if *trash {
+			header = append(header, "BORRADO")
+		}
+		t.row(header...)
+		for _, p := range payments {
+			row := []string{
+				strconv.Itoa(p.ID),
+				p.Date,
+				fmt.Sprintf("%s (%d)", p.DebtName, p.DebtID),
+				money(p.Amount),
+				money(p.RemainingAmount),
+				strconv.Itoa(p.RemainingInstallments),
+				yesNo(p.ReceiptURL != ""),
+			}
+			if *trash {
+				row = append(row, p.DeletedAt)
+			}
+			t.row(row...)
+		}
+	})
+}
+
+func runPaymentAdd(a *app, args []string) error {
+	fs := a.flagSet("payvuectl payment add", "--debt ID --amount A [--date YYYY-MM-DD] [--receipt fichero.pdf] [flags]")
+	var req client.CreatePaymentRequest
+	fs.IntVar(&req.DebtID, "debt", 0, "id de la deuda")
+	fs.Float64Var(&req.Amount, "amount", 0, "importe")
+	fs.StringVar(&req.Date, "date", "", "fecha, YYYY-MM-DD (por defecto, hoy)")
+	receipt := fs.String("receipt", "", "recibo: PDF o imagen")
+	if _, err := a.parse(fs, args, 0); err != nil {
+		return err
+	}
+	if req.DebtID <= 0 || req.Amount <= 0 {
+		return usagef("--debt and --amount are required")
+	}
+
+	if *receipt != "" {
+		file, err := os.Open(*receipt)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		req.Receipt = file
+		req.ReceiptName = filepath.Base(*receipt)
+	}
+
+	c, err := a.client()
+	if err != nil {
+		return err
+	}
+	created, err := c.CreatePayment(a.ctx, req)
+	if err != nil {
+		return err
+	}
+	// En la tabla se añade el saldo de la deuda, que es lo que se quiere ver al
+	// pagar; si no se puede leer el pago ya está registrado y no es un error
+	var balance *debt.DebtResponse
+	if a.options.output == outputTable {
+		balance, _ = c.GetDebt(a.ctx, created.DebtID)
+	}
+	return a.render(created, func(t *table) {
+		t.field("ID", strconv.Itoa(created.ID))
+		t.field("Fecha", created.Date.Format("2006-01-02"))
+		t.field("Deuda", strconv.Itoa(created.DebtID))
+		t.field("Importe", money(created.Amount))
+		t.field("Recibo", created.ReceiptFilename)
+		if balance != nil {
+			t.field("Pendiente", money(balance.RemainingAmount))
+			t.field("Cuotas restantes", strconv.Itoa(balance.RemainingPayments)+" de "+strconv.Itoa(balance.NumInstallments))
+		}
+	})
+}
+
+func runPaymentReceipt(a *app, args []string) error {
+	fs := a.flagSet("payvuectl payment receipt", "<id> [--out fichero] [flags]")
+	out := fs.String("out", "", "fichero de destino (por defecto, el nombre del recibo; - para la salida estándar)")
+	id, err := a.parseID(fs, args)
+	if err != nil {
+		return err
+	}
+	c, err := a.client()
+	if err != nil {
+		return err
+	}
+
+	payments, err := c.ListPayments(a.ctx)
+	if err != nil {
+		return err
+	}
+	var receiptURL string
+	for _, p := range payments {
+		if p.ID == id {
+			receiptURL = p.ReceiptURL
+		}
+	}
+	if receiptURL == "" {
+		return fmt.Errorf("payment %d not found or has no receipt", id)
+	}
+
+	return a.writeOutput(*out, func(w io.Writer) (string, error) {
+		_, err := c.DownloadReceipt(a.ctx, receiptURL, w)
+		return path.Base(receiptURL), err
+	})
+}
+
+func runPaymentRemove(a *app, args []string) error {
+	fs := a.flagSet("payvuectl payment rm", "<id> [flags]")
+	id, err := a.parseID(fs, args)
+	if err != nil {
+		return err
+	}
+	c, err := a.client()
+	if err != nil {
+		return err
+	}
+	if err := c.DeletePayment(a.ctx, id); err != nil {
+		return err
+	}
+	a.message("Pago %d movido a la papelera.", id)
+	return nil
+}
+
+func runPaymentRestore(a *app, args []string) error {
+	fs := a.flagSet("payvuectl payment restore", "<id> [flags]")
+	id, err := a.parseID(fs, args)
+	if err != nil {
+		return err
+	}
+	c, err := a.client()
+	if err != nil {
+		return err
+	}
+	restored, err := c.RestorePayment(a.ctx, id)
+	if err != nil {
+		return err
+	}
+	return a.render(restored, func(t *table) {
+		t.field("ID", strconv.Itoa(restored.ID))
+		t.field("Fecha", restored.Date.Format("2006-01-02"))
+		t.field("Deuda", strconv.Itoa(restored.DebtID))
+		t.field("Importe", money(restored.Amount))
+		t.field("Recibo", restored.ReceiptFilename)
+	})
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var summaryCommand = &command{
+	name:    "summary",
+	summary: "ingresos, pagos y saldo del mes y lo que queda de cada deuda",
+	run:     runSummary,
+}
+
+// summary se calcula en el cliente con los listados de la API; no hay un
+// endpoint de resumen.
+type summary struct {
+	Month    string  `json:"month"`
+	Income   float64 `json:"income"`
+	Payments float64 `json:"payments"`
+	// Balance es Income menos Payments del mes.
+	Balance float64 `json:"balance"`
+	// OutstandingDebt es lo que queda por pagar de las deudas abiertas y
+	// MonthlyInstallments la suma de sus cuotas.
+	OutstandingDebt     float64       `json:"outstanding_debt"`
+	MonthlyInstallments float64       `json:"monthly_installments"`
+	Debts               []debtSummary `json:"debts"`
+}
+
+type debtSummary struct {
+	ID                int     `json:"id"`
+	Name              string  `json:"name"`
+	RemainingAmount   float64 `json:"remaining_amount"`
+	InstallmentAmount float64 `json:"installment_amount"`
+	RemainingPayments int     `json:"remaining_payments"`
+	PaymentDay        int     `json:"payment_day"`
+	PaidThisMonth     float64 `json:"paid_this_month"`
+}
+
+func runSummary(a *app, args []string) error {
+	fs := a.flagSet("payvuectl summary", "[--month YYYY-MM] [flags]")
+	month := fs.String("month", time.Now().Format("2006-01"), "mes, YYYY-MM")
+	if _, err := a.parse(fs, args, 0); err != nil {
+		return err
+	}
+	if _, err := time.Parse("2006-01", *month); err != nil {
+		return usagef("invalid month %q: use YYYY-MM", *month)
+	}
+	c, err := a.client()
+	if err != nil {
+		return err
+	}
+
+	debts, err := c.ListDebts(a.ctx)
+	if err != nil {
+		return err
+	}
+	incomes, err := c.ListIncomes(a.ctx)
+	if err != nil {
+		return err
+	}
+	payments, err := c.ListPayments(a.ctx)
+	if err != nil {
+		return err
+	}
+
+	// Las fechas llegan como YYYY-MM-DD, así que el mes es un prefijo
+	inMonth := func(date string) bool { return strings.HasPrefix(date, *month+"-") }
+
+	s := summary{Month: *month, Debts: []debtSummary{}}
+	for _, i := range incomes {
+		if inMonth(i.Date) {
+			s.Income += i.Amount
+		}
+	}
+	paidByDebt := make(map[int]float64)
+	for _, p := range payments {
+		if inMonth(p.Date) {
+			s.Payments += p.Amount
+			paidByDebt[p.DebtID] += p.Amount
+		}
+	}
+	for _, d := range debts {
+		if d.Paid || d.RemainingAmount <= 0 {
+			continue
+		}
+		s.OutstandingDebt += d.RemainingAmount
+		s.MonthlyInstallments += d.InstallmentAmount
+		s.Debts = append(s.Debts, debtSummary{
+			ID:                d.ID,
+			Name:              d.Name,
+			RemainingAmount:   d.RemainingAmount,
+			InstallmentAmount: d.InstallmentAmount,
+			RemainingPayments: d.RemainingPayments,
+			PaymentDay:        d.PaymentDay,
+			PaidThisMonth:     cents(paidByDebt[d.ID]),
+		})
+	}
+	s.Income = cents(s.Income)
+	s.Payments = cents(s.Payments)
+	s.Balance = cents(s.Income - s.Payments)
+	s.OutstandingDebt = cents(s.OutstandingDebt)
+	s.MonthlyInstallments = cents(s.MonthlyInstallments)
+
+	return a.render(s, func(t *table) {
+		t.field("Mes", s.Month)
+		t.field("Ingresos", money(s.Income))
+		t.field("Pagos", money(s.Payments))
+		t.field("Saldo", money(s.Balance))
+		t.field("Deuda pendiente", money(s.OutstandingDebt))
+		t.field("Cuotas al mes", money(s.MonthlyInstallments))
+		if len(s.Debts) == 0 {
+			return
+		}
+		t.row()
+		t.row("ID", "DEUDA", "PENDIENTE", "CUOTA", "CUOTAS", "DÍA", "PAGADO EN EL MES")
+		for _, d := range s.Debts {
+			t.row(
+				strconv.Itoa(d.ID),
+				d.Name,
+				money(d.RemainingAmount),
+				money(d.InstallmentAmount),
+				strconv.Itoa(d.RemainingPayments),
+				strconv.Itoa(d.PaymentDay),
+				money(d.PaidThisMonth),
+			)
+		}
+	})
+}
+
+// cents redondea las sumas de importes, que en float64 arrastran decimales.
+func cents(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
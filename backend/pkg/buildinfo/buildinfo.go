@@ -0,0 +1,56 @@
+package buildinfo
+
+import (
+	"runtime"
+	"runtime/debug"
+)
+
+// Se fijan al compilar, p. ej.:
+//
+//	go build -ldflags "-X github.com/payvue/payvue-backend/pkg/buildinfo.Version=1.4.0"
+//
+// Sin ellos, Commit se toma de la información de VCS que Go añade al compilar
+// desde un repositorio git.
+var (
+	Version   = "dev"
+	Commit    = ""
+	BuildTime = ""
+)
+
+// Info describe el binario en ejecución.
+type Info struct {
+	Version string `json:"version"`
+	Commit  string `json:"commit,omitempty"`
+	// CommitTime y Modified vienen de la información de VCS, si la hay.
+	CommitTime string `json:"commit_time,omitempty"`
+	Modified   bool   `json:"modified,omitempty"`
+	BuildTime  string `json:"build_time,omitempty"`
+	GoVersion  string `json:"go_version"`
+}
+
+func Get() Info {
+	info := Info{
+		Version:   Version,
+		Commit:    Commit,
+		BuildTime: BuildTime,
+		GoVersion: runtime.Version(),
+	}
+
+	build, ok := debug.ReadBuildInfo()
+	if !ok {
+		return info
+	}
+	for _, setting := range build.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			if info.Commit == "" {
+				info.Commit = setting.Value
+			}
+		case "vcs.time":
+			info.CommitTime = setting.Value
+		case "vcs.modified":
+			info.Modified = setting.Value == "true"
+		}
+	}
+	return info
+}
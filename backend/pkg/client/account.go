@@ -0,0 +1,72 @@
+package client
+
+import (
+	"context"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/payvue/payvue-backend/pkg/domain/audit"
+	"github.com/payvue/payvue-backend/pkg/domain/security"
+)
+
+// Export escribe en w el zip con todos los datos del usuario y devuelve el
+// nombre de fichero que propone el servidor.
+func (c *Client) Export(ctx context.Context, w io.Writer) (string, error) {
+	resp, err := c.download(ctx, request{method: http.MethodGet, path: "/account/export"}, w)
+	if err != nil {
+		return "", err
+	}
+	_, params, err := mime.ParseMediaType(resp.Header.Get("Content-Disposition"))
+	if err != nil {
+		return "", nil
+	}
+	return params["filename"], nil
+}
+
+// ListAuditEntries devuelve los cambios hechos por el usuario. De filter se usan
+// Entity, EntityID, Action, From, To (días completos) y Limit.
+func (c *Client) ListAuditEntries(ctx context.Context, filter audit.Filter) ([]audit.EntryResponse, error) {
+	query := url.Values{}
+	if filter.Entity != "" {
+		query.Set("entity", filter.Entity)
+	}
+	if filter.EntityID > 0 {
+		query.Set("entity_id", strconv.Itoa(filter.EntityID))
+	}
+	if filter.Action != "" {
+		query.Set("action", filter.Action)
+	}
+	if !filter.From.IsZero() {
+		query.Set("from", filter.From.Format("2006-01-02"))
+	}
+	if !filter.To.IsZero() {
+		query.Set("to", filter.To.Format("2006-01-02"))
+	}
+	if filter.Limit > 0 {
+		query.Set("limit", strconv.Itoa(filter.Limit))
+	}
+
+	var response []audit.EntryResponse
+	if err := c.call(ctx, request{method: http.MethodGet, path: "/audit", query: query}, &response); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// ListSecurityEvents devuelve los sucesos de seguridad del usuario; limit 0 usa
+// el límite del servidor.
+func (c *Client) ListSecurityEvents(ctx context.Context, limit int) ([]security.EventResponse, error) {
+	query := url.Values{}
+	if limit > 0 {
+		query.Set("limit", strconv.Itoa(limit))
+	}
+
+	var response []security.EventResponse
+	if err := c.call(ctx, request{method: http.MethodGet, path: "/security/events", query: query}, &response); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
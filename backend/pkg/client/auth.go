@@ -0,0 +1,188 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+
+	"github.com/payvue/payvue-backend/pkg/domain/account"
+	"github.com/payvue/payvue-backend/pkg/domain/user"
+	"github.com/payvue/payvue-backend/pkg/rest/entities"
+)
+
+// LoginResult es la respuesta de un login: la sesión o, con la verificación en
+// dos pasos activa, el reto que se completa con LoginTwoFactor.
+type LoginResult struct {
+	Session   *entities.AuthResponse
+	Challenge *user.TwoFactorChallengeResponse
+}
+
+// Las llamadas de /auth no llevan clave de idempotencia: el servidor guardaría
+// la respuesta, que puede incluir un reto de login.
+
+func (c *Client) Register(ctx context.Context, req entities.RegisterRequest) (*entities.AuthResponse, error) {
+	r, err := jsonRequest(http.MethodPost, "/auth/register", req)
+	if err != nil {
+		return nil, err
+	}
+	var response entities.AuthResponse
+	if err := c.call(ctx, r, &response); err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+func (c *Client) Login(ctx context.Context, req entities.LoginRequest) (*LoginResult, error) {
+	r, err := jsonRequest(http.MethodPost, "/auth/login", req)
+	if err != nil {
+		return nil, err
+	}
+	return c.login(ctx, r)
+}
+
+// LoginTwoFactor completa el login con el reto de Login y un código TOTP o de
+// recuperación.
+func (c *Client) LoginTwoFactor(ctx context.Context, req entities.LoginTwoFactorRequest) (*entities.AuthResponse, error) {
+	r, err := jsonRequest(http.MethodPost, "/auth/login/2fa", req)
+	if err != nil {
+		return nil, err
+	}
+	var response entities.AuthResponse
+	if err := c.call(ctx, r, &response); err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+func (c *Client) login(ctx context.Context, r request) (*LoginResult, error) {
+	var raw json.RawMessage
+	if err := c.call(ctx, r, &raw); err != nil {
+		return nil, err
+	}
+
+	var probe struct {
+		TwoFactorRequired bool `json:"two_factor_required"`
+	}
+	if err := json.Unmarshal(raw, &probe); err != nil {
+		return nil, err
+	}
+	if probe.TwoFactorRequired {
+		var challenge user.TwoFactorChallengeResponse
+		if err := json.Unmarshal(raw, &challenge); err != nil {
+			return nil, err
+		}
+		return &LoginResult{Challenge: &challenge}, nil
+	}
+
+	var session entities.AuthResponse
+	if err := json.Unmarshal(raw, &session); err != nil {
+		return nil, err
+	}
+	return &LoginResult{Session: &session}, nil
+}
+
+func (c *Client) VerifyEmail(ctx context.Context, token string) error {
+	return c.call(ctx, request{
+		method: http.MethodGet,
+		path:   "/auth/verify",
+		query:  url.Values{"token": {token}},
+	}, nil)
+}
+
+func (c *Client) ResendVerification(ctx context.Context, email string) error {
+	r, err := jsonRequest(http.MethodPost, "/auth/verify/resend", entities.ResendVerificationRequest{Email: email})
+	if err != nil {
+		return err
+	}
+	return c.call(ctx, r, nil)
+}
+
+// EnrollTwoFactor pide la contraseña del usuario; las cuentas creadas con el
+// proveedor externo envían el ReauthenticationToken de un login externo
+// reciente. Como el resto de operaciones de 2FA, necesita un cliente con token
+// de acceso.
+func (c *Client) EnrollTwoFactor(ctx context.Context, req entities.EnrollTwoFactorRequest) (*user.TwoFactorEnrollment, error) {
+	r, err := jsonRequest(http.MethodPost, "/auth/2fa/enroll", req)
+	if err != nil {
+		return nil, err
+	}
+	var response user.TwoFactorEnrollment
+	if err := c.call(ctx, r, &response); err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+// EnableTwoFactor devuelve los códigos de recuperación; el servidor no los
+// vuelve a mostrar.
+func (c *Client) EnableTwoFactor(ctx context.Context, code string) ([]string, error) {
+	r, err := jsonRequest(http.MethodPost, "/auth/2fa/enable", entities.EnableTwoFactorRequest{Code: code})
+	if err != nil {
+		return nil, err
+	}
+	var response user.RecoveryCodesResponse
+	if err := c.call(ctx, r, &response); err != nil {
+		return nil, err
+	}
+	return response.RecoveryCodes, nil
+}
+
+func (c *Client) DisableTwoFactor(ctx context.Context, req entities.ReauthenticateRequest) error {
+	r, err := jsonRequest(http.MethodPost, "/auth/2fa/disable", req)
+	if err != nil {
+		return err
+	}
+	return c.call(ctx, r, nil)
+}
+
+func (c *Client) RegenerateRecoveryCodes(ctx context.Context, req entities.ReauthenticateRequest) ([]string, error) {
+	r, err := jsonRequest(http.MethodPost, "/auth/2fa/recovery-codes", req)
+	if err != nil {
+		return nil, err
+	}
+	var response user.RecoveryCodesResponse
+	if err := c.call(ctx, r, &response); err != nil {
+		return nil, err
+	}
+	return response.RecoveryCodes, nil
+}
+
+// StartExternalLogin devuelve la URL del proveedor OpenID Connect. Con un
+// usuario identificado la identidad externa se vincula a él.
+func (c *Client) StartExternalLogin(ctx context.Context) (string, error) {
+	var response user.ExternalLoginResponse
+	if err := c.call(ctx, request{method: http.MethodPost, path: "/auth/oidc/login"}, &response); err != nil {
+		return "", err
+	}
+	return response.AuthorizationURL, nil
+}
+
+func (c *Client) CompleteExternalLogin(ctx context.Context, req entities.ExternalLoginRequest) (*LoginResult, error) {
+	r, err := jsonRequest(http.MethodPost, "/auth/oidc/callback", req)
+	if err != nil {
+		return nil, err
+	}
+	return c.login(ctx, r)
+}
+
+// DeleteAccount programa el borrado de la cuenta del usuario del cliente.
+func (c *Client) DeleteAccount(ctx context.Context, req account.DeleteAccountRequest) (*account.DeletionResponse, error) {
+	r, err := jsonRequest(http.MethodDelete, "/auth/account", req)
+	if err != nil {
+		return nil, err
+	}
+	var response account.DeletionResponse
+	if err := c.call(ctx, r, &response); err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+func (c *Client) CancelAccountDeletion(ctx context.Context) error {
+	return c.call(ctx, request{method: http.MethodPost, path: "/auth/account/cancel-deletion"}, nil)
+}
+
+func (c *Client) Logout(ctx context.Context) error {
+	return c.call(ctx, request{method: http.MethodPost, path: "/auth/logout"}, nil)
+}
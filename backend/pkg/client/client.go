@@ -0,0 +1,273 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	mathrand "math/rand"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/payvue/payvue-backend/pkg/buildinfo"
+	"github.com/payvue/payvue-backend/pkg/tracing"
+)
+
+const (
+	DefaultMaxRetries   = 3
+	DefaultRetryBackoff = 250 * time.Millisecond
+
+	// maxRetryWait acota la espera entre reintentos aunque el servidor pida más
+	// con Retry-After.
+	maxRetryWait = 30 * time.Second
+)
+
+type Config struct {
+	// BaseURL es la URL del servidor unificado, p. ej. http://localhost:8080.
+	BaseURL string
+	// Token es un token de acceso personal; si está, se envía como
+	// Authorization: Bearer y UserID no se usa.
+	Token string
+	// UserID se envía en X-User-ID cuando no hay token, como hace el frontend.
+	UserID int
+	// MaxRetries es el número de reintentos de las peticiones que se pueden
+	// repetir sin efectos duplicados. 0 usa DefaultMaxRetries y un valor
+	// negativo los desactiva.
+	MaxRetries int
+	// RetryBackoff es la espera antes del primer reintento; se duplica en cada
+	// uno. 0 usa DefaultRetryBackoff.
+	RetryBackoff time.Duration
+	HTTPClient   *http.Client
+	// UserAgent identifica la herramienta que usa el cliente en los logs del
+	// servidor.
+	UserAgent string
+}
+
+// Client llama a la API de PayVue. Es seguro usarlo desde varias goroutines.
+type Client struct {
+	config  Config
+	baseURL *url.URL
+	client  *http.Client
+}
+
+func New(config Config) (*Client, error) {
+	baseURL, err := url.Parse(strings.TrimSuffix(config.BaseURL, "/"))
+	if err != nil || baseURL.Scheme == "" || baseURL.Host == "" {
+		return nil, fmt.Errorf("invalid base URL %q", config.BaseURL)
+	}
+
+	if config.MaxRetries == 0 {
+		config.MaxRetries = DefaultMaxRetries
+	}
+	if config.RetryBackoff <= 0 {
+		config.RetryBackoff = DefaultRetryBackoff
+	}
+	if config.UserAgent == "" {
+		config.UserAgent = "payvue-go/" + buildinfo.Get().Version
+	}
+
+	client := config.HTTPClient
+	if client == nil {
+		client = &http.Client{Timeout: 60 * time.Second}
+	}
+
+	return &Client{
+		config:  config,
+		baseURL: baseURL,
+		client:  client,
+	}, nil
+}
+
+// request describe una llamada. El cuerpo va ya serializado para poder
+// enviarlo otra vez en cada reintento.
+type request struct {
+	method      string
+	path        string
+	query       url.Values
+	header      http.Header
+	body        []byte
+	contentType string
+	// idempotencyKey añade una cabecera Idempotency-Key nueva, la misma en todos
+	// los reintentos, para que el servidor no repita el efecto de un POST o un
+	// PATCH que sí llegó a ejecutarse.
+	idempotencyKey bool
+}
+
+func jsonRequest(method, path string, body interface{}) (request, error) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return request{}, err
+	}
+	return request{method: method, path: path, body: data, contentType: "application/json"}, nil
+}
+
+// retryable indica si la petición se puede repetir: los GET, PUT y DELETE lo
+// son por definición y el resto solo con clave de idempotencia.
+func (r request) retryable() bool {
+	switch r.method {
+	case http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return r.header.Get("Idempotency-Key") != ""
+}
+
+// do envía la petición con reintentos y devuelve la respuesta si es 2xx o 3xx.
+// Con cualquier otro estado devuelve un *Error. El llamador cierra el cuerpo.
+func (c *Client) do(ctx context.Context, req request) (*http.Response, error) {
+	if req.header == nil {
+		req.header = make(http.Header)
+	}
+	if req.idempotencyKey && req.header.Get("Idempotency-Key") == "" {
+		req.header.Set("Idempotency-Key", newIdempotencyKey())
+	}
+
+	for attempt := 0; ; attempt++ {
+		resp, err := c.send(ctx, req)
+		canRetry := req.retryable() && attempt < c.config.MaxRetries
+
+		if err != nil {
+			if ctx.Err() != nil || !canRetry {
+				return nil, err
+			}
+			if err := c.wait(ctx, attempt, 0); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		if resp.StatusCode < http.StatusBadRequest {
+			return resp, nil
+		}
+
+		apiErr := decodeError(resp)
+		if !canRetry || !apiErr.temporary() {
+			return nil, apiErr
+		}
+		if err := c.wait(ctx, attempt, apiErr.RetryAfter); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (c *Client) send(ctx context.Context, req request) (*http.Response, error) {
+	target := *c.baseURL
+	target.Path = c.baseURL.Path + req.path
+	if len(req.query) > 0 {
+		target.RawQuery = req.query.Encode()
+	}
+
+	var body io.Reader
+	if req.body != nil {
+		body = bytes.NewReader(req.body)
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, req.method, target.String(), body)
+	if err != nil {
+		return nil, err
+	}
+
+	for name, values := range req.header {
+		httpReq.Header[name] = values
+	}
+	if req.contentType != "" {
+		httpReq.Header.Set("Content-Type", req.contentType)
+	}
+	httpReq.Header.Set("Accept", "application/json, application/problem+json")
+	httpReq.Header.Set("User-Agent", c.config.UserAgent)
+	if c.config.Token != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+c.config.Token)
+	} else if c.config.UserID > 0 {
+		httpReq.Header.Set("X-User-ID", strconv.Itoa(c.config.UserID))
+	}
+	tracing.Inject(ctx, httpReq.Header)
+
+	return c.client.Do(httpReq)
+}
+
+// wait espera antes del reintento attempt+1: backoff exponencial con jitter,
+// o lo que pida el servidor en Retry-After si es más.
+func (c *Client) wait(ctx context.Context, attempt int, retryAfter time.Duration) error {
+	delay := c.config.RetryBackoff << attempt
+	delay = delay/2 + time.Duration(mathrand.Int63n(int64(delay/2)+1))
+	if retryAfter > delay {
+		delay = retryAfter
+	}
+	if delay > maxRetryWait {
+		delay = maxRetryWait
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// call envía la petición y decodifica la respuesta JSON en out, si no es nil.
+func (c *Client) call(ctx context.Context, req request, out interface{}) error {
+	resp, err := c.do(ctx, req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if out == nil {
+		io.Copy(io.Discard, resp.Body)
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("decoding %s %s response: %w", req.method, req.path, err)
+	}
+	return nil
+}
+
+// download copia el cuerpo de la respuesta en w y devuelve la respuesta ya
+// cerrada para leer sus cabeceras.
+func (c *Client) download(ctx context.Context, req request, w io.Writer) (*http.Response, error) {
+	resp, err := c.do(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if _, err := io.Copy(w, resp.Body); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// ifMatch devuelve la cabecera If-Match para version; con 0 el servidor no
+// comprueba la versión.
+func ifMatch(version int) http.Header {
+	header := make(http.Header)
+	if version > 0 {
+		header.Set("If-Match", `"`+strconv.Itoa(version)+`"`)
+	}
+	return header
+}
+
+func newIdempotencyKey() string {
+	var key [16]byte
+	if _, err := rand.Read(key[:]); err != nil {
+		// Sin clave la petición sigue funcionando, solo que sin reintentos
+		return ""
+	}
+	return hex.EncodeToString(key[:])
+}
+
+// pathID construye rutas como /finances/debt/3/restore.
+func pathID(prefix string, id int, suffix ...string) string {
+	path := prefix + "/" + strconv.Itoa(id)
+	for _, s := range suffix {
+		path += "/" + s
+	}
+	return path
+}
@@ -0,0 +1,246 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/payvue/payvue-backend/pkg/rest/entities"
+)
+
+// recordingServer responde con las respuestas de responses en orden, la última
+// para el resto, y guarda las peticiones que recibe.
+type recordingServer struct {
+	mu        sync.Mutex
+	requests  []*http.Request
+	responses []func(w http.ResponseWriter)
+}
+
+func (s *recordingServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests = append(s.requests, r.Clone(context.Background()))
+	respond := s.responses[len(s.responses)-1]
+	if len(s.requests) <= len(s.responses) {
+		respond = s.responses[len(s.requests)-1]
+	}
+	respond(w)
+}
+
+func respondJSON(status int, body string) func(w http.ResponseWriter) {
+	return func(w http.ResponseWriter) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		w.Write([]byte(body))
+	}
+}
+
+func newTestClient(t *testing.T, config Config, responses ...func(w http.ResponseWriter)) (*Client, *recordingServer) {
+	t.Helper()
+	recorder := &recordingServer{responses: responses}
+	server := httptest.NewServer(recorder)
+	t.Cleanup(server.Close)
+
+	config.BaseURL = server.URL
+	config.RetryBackoff = time.Millisecond
+	c, err := New(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c, recorder
+}
+
+const debtJSON = `{"id":7,"name":"Coche","total_amount":1000,"remaining_amount":500,"version":3}`
+
+func TestDecodeProblem(t *testing.T) {
+	c, _ := newTestClient(t, Config{UserID: 1}, respondJSON(http.StatusBadRequest, `{
+		"type": "about:blank",
+		"title": "Bad Request",
+		"status": 400,
+		"error": "validation_error",
+		"message": "Datos inválidos",
+		"request_id": "req-1",
+		"errors": [{"field": "name", "rule": "required", "message": "Campo obligatorio"}]
+	}`))
+
+	_, err := c.CreateDebt(context.Background(), entities.CreateDebtRequest{})
+	var apiErr *Error
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("CreateDebt error = %v, want *Error", err)
+	}
+	if apiErr.StatusCode != http.StatusBadRequest || apiErr.Code != "validation_error" || apiErr.RequestID != "req-1" {
+		t.Errorf("error = %+v", apiErr)
+	}
+	// Sin detail se usa message
+	if apiErr.Detail != "Datos inválidos" {
+		t.Errorf("Detail = %q, want the message", apiErr.Detail)
+	}
+	want := entities.FieldError{Field: "name", Rule: "required", Message: "Campo obligatorio"}
+	if len(apiErr.Fields) != 1 || apiErr.Fields[0] != want {
+		t.Errorf("Fields = %+v, want [%+v]", apiErr.Fields, want)
+	}
+	if Code(err) != "validation_error" {
+		t.Errorf("Code = %q", Code(err))
+	}
+}
+
+func TestDecodeNotFound(t *testing.T) {
+	c, _ := newTestClient(t, Config{UserID: 1}, respondJSON(http.StatusNotFound,
+		`{"status":404,"error":"debt_not_found","detail":"Deuda no encontrada"}`))
+
+	_, err := c.GetDebt(context.Background(), 7)
+	if !IsNotFound(err) || Code(err) != "debt_not_found" {
+		t.Fatalf("GetDebt error = %v, want debt_not_found", err)
+	}
+	if err.Error() != "payvue: 404 debt_not_found: Deuda no encontrada" {
+		t.Errorf("Error() = %q", err.Error())
+	}
+}
+
+func TestDecodeNonProblemBody(t *testing.T) {
+	c, recorder := newTestClient(t, Config{UserID: 1, MaxRetries: -1}, func(w http.ResponseWriter) {
+		w.Header().Set("Retry-After", "2")
+		w.WriteHeader(http.StatusBadGateway)
+		w.Write([]byte("  upstream unavailable\n"))
+	})
+
+	_, err := c.GetDebt(context.Background(), 7)
+	var apiErr *Error
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("GetDebt error = %v, want *Error", err)
+	}
+	if apiErr.Code != "" || apiErr.Detail != "upstream unavailable" || apiErr.RetryAfter != 2*time.Second {
+		t.Errorf("error = %+v", apiErr)
+	}
+	if len(recorder.requests) != 1 {
+		t.Errorf("requests = %d, want 1 with retries disabled", len(recorder.requests))
+	}
+	if IsNotFound(err) || Code(errors.New("other")) != "" {
+		t.Error("IsNotFound or Code match errors they should not")
+	}
+}
+
+func TestIfMatch(t *testing.T) {
+	c, recorder := newTestClient(t, Config{UserID: 1}, respondJSON(http.StatusOK, debtJSON))
+	ctx := context.Background()
+
+	if _, err := c.UpdateDebt(ctx, 7, entities.UpdateDebtRequest{}, 3); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.PatchDebt(ctx, 7, map[string]interface{}{"paid": true}, 4); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.UpdateDebt(ctx, 7, entities.UpdateDebtRequest{}, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	put, patch, unconditional := recorder.requests[0], recorder.requests[1], recorder.requests[2]
+	if got := put.Header.Get("If-Match"); got != `"3"` {
+		t.Errorf("PUT If-Match = %q, want \"3\"", got)
+	}
+	if got := patch.Header.Get("If-Match"); got != `"4"` {
+		t.Errorf("PATCH If-Match = %q, want \"4\"", got)
+	}
+	if got := patch.Header.Get("Content-Type"); got != "application/merge-patch+json" {
+		t.Errorf("PATCH Content-Type = %q", got)
+	}
+	if _, ok := unconditional.Header["If-Match"]; ok {
+		t.Errorf("version 0 sent If-Match %q", unconditional.Header.Get("If-Match"))
+	}
+}
+
+func TestVersionConflict(t *testing.T) {
+	c, recorder := newTestClient(t, Config{UserID: 1}, respondJSON(http.StatusPreconditionFailed,
+		`{"status":412,"error":"version_conflict","detail":"La deuda ha cambiado"}`))
+
+	_, err := c.UpdateDebt(context.Background(), 7, entities.UpdateDebtRequest{}, 3)
+	if Code(err) != "version_conflict" {
+		t.Fatalf("UpdateDebt error = %v, want version_conflict", err)
+	}
+	if len(recorder.requests) != 1 {
+		t.Errorf("requests = %d, a conflict must not be retried", len(recorder.requests))
+	}
+}
+
+func TestIdempotencyKeyIsReusedOnRetry(t *testing.T) {
+	c, recorder := newTestClient(t, Config{Token: "pv_token"},
+		respondJSON(http.StatusServiceUnavailable, `{"status":503,"error":"unavailable"}`),
+		respondJSON(http.StatusConflict, `{"status":409,"error":"idempotency_key_in_progress"}`),
+		respondJSON(http.StatusCreated, debtJSON),
+	)
+
+	created, err := c.CreateDebt(context.Background(), entities.CreateDebtRequest{Name: "Coche"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if created.ID != 7 || created.Name != "Coche" {
+		t.Errorf("CreateDebt = %+v", created)
+	}
+
+	if len(recorder.requests) != 3 {
+		t.Fatalf("requests = %d, want 3", len(recorder.requests))
+	}
+	key := recorder.requests[0].Header.Get("Idempotency-Key")
+	if len(key) != 32 {
+		t.Fatalf("Idempotency-Key = %q, want 32 hex characters", key)
+	}
+	for i, req := range recorder.requests {
+		if got := req.Header.Get("Idempotency-Key"); got != key {
+			t.Errorf("attempt %d Idempotency-Key = %q, want %q", i+1, got, key)
+		}
+		if got := req.Header.Get("Authorization"); got != "Bearer pv_token" {
+			t.Errorf("attempt %d Authorization = %q", i+1, got)
+		}
+		if req.Header.Get("X-User-ID") != "" {
+			t.Errorf("attempt %d sent X-User-ID with a token", i+1)
+		}
+	}
+}
+
+func TestIdempotencyKeyIsNewPerCall(t *testing.T) {
+	c, recorder := newTestClient(t, Config{UserID: 1}, respondJSON(http.StatusCreated, debtJSON))
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		if _, err := c.CreateDebt(ctx, entities.CreateDebtRequest{Name: "Coche"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	first, second := recorder.requests[0].Header.Get("Idempotency-Key"), recorder.requests[1].Header.Get("Idempotency-Key")
+	if first == "" || first == second {
+		t.Errorf("Idempotency-Key = %q and %q, want two different keys", first, second)
+	}
+	if got := recorder.requests[0].Header.Get("X-User-ID"); got != "1" {
+		t.Errorf("X-User-ID = %q, want 1", got)
+	}
+}
+
+func TestPostWithoutKeyIsNotRetried(t *testing.T) {
+	c, recorder := newTestClient(t, Config{UserID: 1},
+		respondJSON(http.StatusServiceUnavailable, `{"status":503,"error":"unavailable"}`))
+
+	err := c.call(context.Background(), request{method: http.MethodPost, path: "/auth/logout"}, nil)
+	if Code(err) != "unavailable" {
+		t.Fatalf("call error = %v, want unavailable", err)
+	}
+	if len(recorder.requests) != 1 {
+		t.Errorf("requests = %d, a POST without Idempotency-Key must not be retried", len(recorder.requests))
+	}
+}
+
+func TestRetriesStopAtMaxRetries(t *testing.T) {
+	c, recorder := newTestClient(t, Config{UserID: 1, MaxRetries: 2},
+		respondJSON(http.StatusTooManyRequests, `{"status":429,"error":"rate_limited"}`))
+
+	_, err := c.ListDebts(context.Background())
+	if Code(err) != "rate_limited" {
+		t.Fatalf("ListDebts error = %v, want rate_limited", err)
+	}
+	if len(recorder.requests) != 3 {
+		t.Errorf("requests = %d, want the first attempt and 2 retries", len(recorder.requests))
+	}
+}
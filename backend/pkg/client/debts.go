@@ -0,0 +1,96 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/payvue/payvue-backend/pkg/domain/debt"
+	"github.com/payvue/payvue-backend/pkg/rest/entities"
+)
+
+const debtsPath = "/finances/debt"
+
+// ListDebts devuelve las deudas del usuario y las de sus hogares.
+func (c *Client) ListDebts(ctx context.Context) ([]debt.DebtResponse, error) {
+	var response []debt.DebtResponse
+	if err := c.call(ctx, request{method: http.MethodGet, path: debtsPath}, &response); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// ListDeletedDebts devuelve las deudas de la papelera.
+func (c *Client) ListDeletedDebts(ctx context.Context) ([]debt.DebtResponse, error) {
+	var response []debt.DebtResponse
+	if err := c.call(ctx, request{method: http.MethodGet, path: debtsPath + "/trash"}, &response); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (c *Client) GetDebt(ctx context.Context, id int) (*debt.DebtResponse, error) {
+	return c.debt(ctx, request{method: http.MethodGet, path: pathID(debtsPath, id)})
+}
+
+func (c *Client) CreateDebt(ctx context.Context, req entities.CreateDebtRequest) (*debt.DebtResponse, error) {
+	r, err := jsonRequest(http.MethodPost, debtsPath, req)
+	if err != nil {
+		return nil, err
+	}
+	r.idempotencyKey = true
+	return c.debt(ctx, r)
+}
+
+// UpdateDebt reemplaza la deuda. Con version distinto de 0 (el Version de la
+// última lectura) el servidor responde version_conflict si otro la cambió.
+func (c *Client) UpdateDebt(ctx context.Context, id int, req entities.UpdateDebtRequest, version int) (*debt.DebtResponse, error) {
+	r, err := jsonRequest(http.MethodPut, pathID(debtsPath, id), req)
+	if err != nil {
+		return nil, err
+	}
+	r.header = ifMatch(version)
+	return c.debt(ctx, r)
+}
+
+// PatchDebt aplica un JSON Merge Patch: patch solo lleva los campos que
+// cambian, p. ej. map[string]interface{}{"paid": true}.
+func (c *Client) PatchDebt(ctx context.Context, id int, patch interface{}, version int) (*debt.DebtResponse, error) {
+	r, err := mergePatchRequest(pathID(debtsPath, id), patch, version)
+	if err != nil {
+		return nil, err
+	}
+	return c.debt(ctx, r)
+}
+
+// DeleteDebt mueve la deuda a la papelera.
+func (c *Client) DeleteDebt(ctx context.Context, id int) error {
+	return c.call(ctx, request{method: http.MethodDelete, path: pathID(debtsPath, id), idempotencyKey: true}, nil)
+}
+
+func (c *Client) RestoreDebt(ctx context.Context, id int) (*debt.DebtResponse, error) {
+	return c.debt(ctx, request{method: http.MethodPost, path: pathID(debtsPath, id, "restore"), idempotencyKey: true})
+}
+
+func (c *Client) debt(ctx context.Context, r request) (*debt.DebtResponse, error) {
+	var response debt.DebtResponse
+	if err := c.call(ctx, r, &response); err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+func mergePatchRequest(path string, patch interface{}, version int) (request, error) {
+	data, err := json.Marshal(patch)
+	if err != nil {
+		return request{}, err
+	}
+	return request{
+		method:         http.MethodPatch,
+		path:           path,
+		header:         ifMatch(version),
+		body:           data,
+		contentType:    "application/merge-patch+json",
+		idempotencyKey: true,
+	}, nil
+}
@@ -0,0 +1,111 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/payvue/payvue-backend/pkg/rest/entities"
+)
+
+// Error es una respuesta de error de la API. Code es el código estable del
+// problema (debt_not_found, version_conflict...), el mismo que usa el frontend.
+type Error struct {
+	StatusCode int
+	Code       string
+	Detail     string
+	RequestID  string
+	// Fields es el detalle por campo de los errores de validación.
+	Fields []entities.FieldError
+	// RetryAfter es la espera que pide el servidor en los 429 y 503.
+	RetryAfter time.Duration
+}
+
+func (e *Error) Error() string {
+	message := fmt.Sprintf("payvue: %d", e.StatusCode)
+	if e.Code != "" {
+		message += " " + e.Code
+	}
+	if e.Detail != "" {
+		message += ": " + e.Detail
+	}
+	for _, field := range e.Fields {
+		message += fmt.Sprintf("; %s: %s", field.Field, field.Message)
+	}
+	return message
+}
+
+// temporary indica si merece la pena repetir la petición.
+func (e *Error) temporary() bool {
+	switch e.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	case http.StatusConflict:
+		// Otra petición con la misma clave sigue en curso; la respuesta llegará
+		return e.Code == "idempotency_key_in_progress"
+	}
+	return false
+}
+
+// Code devuelve el código del error de la API que haya en la cadena de err, o
+// "" si no es un error de la API.
+func Code(err error) string {
+	var apiErr *Error
+	if errors.As(err, &apiErr) {
+		return apiErr.Code
+	}
+	return ""
+}
+
+// IsNotFound indica si err es un 404 de la API.
+func IsNotFound(err error) bool {
+	var apiErr *Error
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound
+}
+
+// decodeError lee el problema de la respuesta. Si el cuerpo no es un problema
+// (un proxy, un 502) el detalle es el principio del cuerpo.
+func decodeError(resp *http.Response) *Error {
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+
+	apiErr := &Error{
+		StatusCode: resp.StatusCode,
+		RetryAfter: retryAfter(resp.Header.Get("Retry-After")),
+	}
+
+	var problem entities.ErrorResponse
+	if err := json.Unmarshal(body, &problem); err == nil && problem.Error != "" {
+		apiErr.Code = problem.Error
+		apiErr.Detail = problem.Detail
+		if apiErr.Detail == "" {
+			apiErr.Detail = problem.Message
+		}
+		apiErr.RequestID = problem.RequestID
+		apiErr.Fields = problem.Errors
+		return apiErr
+	}
+
+	apiErr.Detail = strings.TrimSpace(string(body))
+	if len(apiErr.Detail) > 200 {
+		apiErr.Detail = apiErr.Detail[:200]
+	}
+	if apiErr.Detail == "" {
+		apiErr.Detail = http.StatusText(resp.StatusCode)
+	}
+	return apiErr
+}
+
+// retryAfter solo entiende la forma en segundos, que es la que envía el servidor.
+func retryAfter(value string) time.Duration {
+	seconds, err := strconv.Atoi(value)
+	if err != nil || seconds < 0 {
+		return 0
+	}
+	return time.Duration(seconds) * time.Second
+}
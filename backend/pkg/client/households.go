@@ -0,0 +1,95 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/payvue/payvue-backend/pkg/domain/household"
+)
+
+const householdsPath = "/households"
+
+func (c *Client) ListHouseholds(ctx context.Context) ([]household.HouseholdResponse, error) {
+	var response []household.HouseholdResponse
+	if err := c.call(ctx, request{method: http.MethodGet, path: householdsPath}, &response); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// GetHousehold devuelve el hogar con sus miembros.
+func (c *Client) GetHousehold(ctx context.Context, id int) (*household.HouseholdResponse, error) {
+	return c.household(ctx, request{method: http.MethodGet, path: pathID(householdsPath, id)})
+}
+
+func (c *Client) CreateHousehold(ctx context.Context, req household.CreateHouseholdRequest) (*household.HouseholdResponse, error) {
+	r, err := jsonRequest(http.MethodPost, householdsPath, req)
+	if err != nil {
+		return nil, err
+	}
+	r.idempotencyKey = true
+	return c.household(ctx, r)
+}
+
+// ListInvitations devuelve las invitaciones pendientes; solo para owners.
+func (c *Client) ListInvitations(ctx context.Context, householdID int) ([]household.InvitationResponse, error) {
+	var response []household.InvitationResponse
+	if err := c.call(ctx, request{method: http.MethodGet, path: pathID(householdsPath, householdID, "invitations")}, &response); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// Invite crea una invitación. Su Token solo viene en esta respuesta, así que
+// tampoco lleva clave de idempotencia.
+func (c *Client) Invite(ctx context.Context, householdID int, req household.InviteRequest) (*household.InvitationResponse, error) {
+	r, err := jsonRequest(http.MethodPost, pathID(householdsPath, householdID, "invitations"), req)
+	if err != nil {
+		return nil, err
+	}
+	var response household.InvitationResponse
+	if err := c.call(ctx, r, &response); err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+func (c *Client) AcceptInvitation(ctx context.Context, token string) (*household.HouseholdResponse, error) {
+	r, err := jsonRequest(http.MethodPost, householdsPath+"/invitations/accept", household.AcceptInvitationRequest{Token: token})
+	if err != nil {
+		return nil, err
+	}
+	return c.household(ctx, r)
+}
+
+func (c *Client) RevokeInvitation(ctx context.Context, householdID, invitationID int) error {
+	return c.call(ctx, request{
+		method: http.MethodDelete,
+		path:   pathID(householdsPath, householdID, "invitations", strconv.Itoa(invitationID)),
+	}, nil)
+}
+
+func (c *Client) UpdateMember(ctx context.Context, householdID, userID int, role household.Role) error {
+	r, err := jsonRequest(http.MethodPut, pathID(householdsPath, householdID, "members", strconv.Itoa(userID)), household.UpdateMemberRequest{Role: role})
+	if err != nil {
+		return err
+	}
+	return c.call(ctx, r, nil)
+}
+
+// RemoveMember saca al usuario del hogar; con el propio usuario es abandonarlo.
+func (c *Client) RemoveMember(ctx context.Context, householdID, userID int) error {
+	return c.call(ctx, request{
+		method: http.MethodDelete,
+		path:   pathID(householdsPath, householdID, "members", strconv.Itoa(userID)),
+	}, nil)
+}
+
+func (c *Client) household(ctx context.Context, r request) (*household.HouseholdResponse, error) {
+	var response household.HouseholdResponse
+	if err := c.call(ctx, r, &response); err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
@@ -0,0 +1,80 @@
+package client
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/payvue/payvue-backend/pkg/domain/income"
+	"github.com/payvue/payvue-backend/pkg/rest/entities"
+)
+
+const incomesPath = "/finances/income"
+
+// ListIncomes devuelve los ingresos del usuario y los de sus hogares.
+func (c *Client) ListIncomes(ctx context.Context) ([]income.IncomeResponse, error) {
+	var response []income.IncomeResponse
+	if err := c.call(ctx, request{method: http.MethodGet, path: incomesPath}, &response); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// ListDeletedIncomes devuelve los ingresos de la papelera.
+func (c *Client) ListDeletedIncomes(ctx context.Context) ([]income.IncomeResponse, error) {
+	var response []income.IncomeResponse
+	if err := c.call(ctx, request{method: http.MethodGet, path: incomesPath + "/trash"}, &response); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (c *Client) GetIncome(ctx context.Context, id int) (*income.IncomeResponse, error) {
+	return c.income(ctx, request{method: http.MethodGet, path: pathID(incomesPath, id)})
+}
+
+func (c *Client) CreateIncome(ctx context.Context, req entities.CreateIncomeRequest) (*income.IncomeResponse, error) {
+	r, err := jsonRequest(http.MethodPost, incomesPath, req)
+	if err != nil {
+		return nil, err
+	}
+	r.idempotencyKey = true
+	return c.income(ctx, r)
+}
+
+// UpdateIncome reemplaza el ingreso. Con version distinto de 0 (el Version de la
+// última lectura) el servidor responde version_conflict si otro lo cambió.
+func (c *Client) UpdateIncome(ctx context.Context, id int, req entities.UpdateIncomeRequest, version int) (*income.IncomeResponse, error) {
+	r, err := jsonRequest(http.MethodPut, pathID(incomesPath, id), req)
+	if err != nil {
+		return nil, err
+	}
+	r.header = ifMatch(version)
+	return c.income(ctx, r)
+}
+
+// PatchIncome aplica un JSON Merge Patch: patch solo lleva los campos que
+// cambian, p. ej. map[string]interface{}{"amount": 1500}.
+func (c *Client) PatchIncome(ctx context.Context, id int, patch interface{}, version int) (*income.IncomeResponse, error) {
+	r, err := mergePatchRequest(pathID(incomesPath, id), patch, version)
+	if err != nil {
+		return nil, err
+	}
+	return c.income(ctx, r)
+}
+
+// DeleteIncome mueve el ingreso a la papelera.
+func (c *Client) DeleteIncome(ctx context.Context, id int) error {
+	return c.call(ctx, request{method: http.MethodDelete, path: pathID(incomesPath, id), idempotencyKey: true}, nil)
+}
+
+func (c *Client) RestoreIncome(ctx context.Context, id int) (*income.IncomeResponse, error) {
+	return c.income(ctx, request{method: http.MethodPost, path: pathID(incomesPath, id, "restore"), idempotencyKey: true})
+}
+
+func (c *Client) income(ctx context.Context, r request) (*income.IncomeResponse, error) {
+	var response income.IncomeResponse
+	if err := c.call(ctx, r, &response); err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
@@ -0,0 +1,118 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/payvue/payvue-backend/pkg/domain/payment"
+	"github.com/payvue/payvue-backend/pkg/utils/fileupload"
+)
+
+const paymentsPath = "/finances/payment"
+
+type CreatePaymentRequest struct {
+	Amount float64
+	DebtID int
+	// Date en formato YYYY-MM-DD; vacía usa la fecha del servidor.
+	Date string
+	// Receipt es el recibo opcional y ReceiptName su nombre, con la extensión
+	// (.pdf, .jpg, .png...), que el servidor usa para validar el tipo.
+	Receipt     io.Reader
+	ReceiptName string
+}
+
+func (c *Client) ListPayments(ctx context.Context) ([]payment.PaymentResponse, error) {
+	var response []payment.PaymentResponse
+	if err := c.call(ctx, request{method: http.MethodGet, path: paymentsPath}, &response); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (c *Client) ListDeletedPayments(ctx context.Context) ([]payment.PaymentResponse, error) {
+	var response []payment.PaymentResponse
+	if err := c.call(ctx, request{method: http.MethodGet, path: paymentsPath + "/trash"}, &response); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// CreatePayment registra el pago y sube el recibo si lo hay. El formulario se
+// construye en memoria para poder reintentarlo.
+func (c *Client) CreatePayment(ctx context.Context, req CreatePaymentRequest) (*payment.Payment, error) {
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	fields := map[string]string{
+		"amount":  strconv.FormatFloat(req.Amount, 'f', -1, 64),
+		"debt_id": strconv.Itoa(req.DebtID),
+	}
+	if req.Date != "" {
+		fields["date"] = req.Date
+	}
+	for name, value := range fields {
+		if err := form.WriteField(name, value); err != nil {
+			return nil, err
+		}
+	}
+
+	if req.Receipt != nil {
+		part, err := form.CreateFormFile("receipt", path.Base(req.ReceiptName))
+		if err != nil {
+			return nil, err
+		}
+		n, err := io.Copy(part, io.LimitReader(req.Receipt, fileupload.MaxFileSize+1))
+		if err != nil {
+			return nil, err
+		}
+		if n > fileupload.MaxFileSize {
+			return nil, fmt.Errorf("receipt is larger than %d MB", fileupload.MaxFileSize>>20)
+		}
+	}
+	if err := form.Close(); err != nil {
+		return nil, err
+	}
+
+	return c.payment(ctx, request{
+		method:         http.MethodPost,
+		path:           paymentsPath,
+		body:           body.Bytes(),
+		contentType:    form.FormDataContentType(),
+		idempotencyKey: true,
+	})
+}
+
+func (c *Client) DeletePayment(ctx context.Context, id int) error {
+	return c.call(ctx, request{method: http.MethodDelete, path: pathID(paymentsPath, id), idempotencyKey: true}, nil)
+}
+
+func (c *Client) RestorePayment(ctx context.Context, id int) (*payment.Payment, error) {
+	return c.payment(ctx, request{method: http.MethodPost, path: pathID(paymentsPath, id, "restore"), idempotencyKey: true})
+}
+
+// DownloadReceipt escribe en w el recibo de un pago. receipt puede ser el
+// nombre del fichero o el ReceiptURL de PaymentResponse. Devuelve el tipo de
+// contenido.
+func (c *Client) DownloadReceipt(ctx context.Context, receipt string, w io.Writer) (string, error) {
+	// El token de la URL firmada sobra: el cliente se identifica con sus cabeceras
+	receipt, _, _ = strings.Cut(receipt, "?")
+	resp, err := c.download(ctx, request{method: http.MethodGet, path: paymentsPath + "/receipt/" + path.Base(receipt)}, w)
+	if err != nil {
+		return "", err
+	}
+	return resp.Header.Get("Content-Type"), nil
+}
+
+func (c *Client) payment(ctx context.Context, r request) (*payment.Payment, error) {
+	var response payment.Payment
+	if err := c.call(ctx, r, &response); err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
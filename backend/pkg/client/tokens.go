@@ -0,0 +1,38 @@
+package client
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/payvue/payvue-backend/pkg/domain/accesstoken"
+)
+
+const tokensPath = "/tokens"
+
+// ListTokens devuelve los tokens de acceso del usuario, sin su valor.
+func (c *Client) ListTokens(ctx context.Context) ([]accesstoken.TokenResponse, error) {
+	var response accesstoken.TokenListResponse
+	if err := c.call(ctx, request{method: http.MethodGet, path: tokensPath}, &response); err != nil {
+		return nil, err
+	}
+	return response.Tokens, nil
+}
+
+// CreateToken crea un token de acceso personal. Su valor solo viene en esta
+// respuesta, en Token. No lleva clave de idempotencia para que el servidor no
+// guarde el token en claro.
+func (c *Client) CreateToken(ctx context.Context, req accesstoken.CreateTokenRequest) (*accesstoken.TokenResponse, error) {
+	r, err := jsonRequest(http.MethodPost, tokensPath, req)
+	if err != nil {
+		return nil, err
+	}
+	var response accesstoken.TokenResponse
+	if err := c.call(ctx, r, &response); err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+func (c *Client) RevokeToken(ctx context.Context, id int) error {
+	return c.call(ctx, request{method: http.MethodDelete, path: pathID(tokensPath, id)}, nil)
+}
@@ -0,0 +1,37 @@
+package accesstoken
+
+import (
+	"context"
+	"time"
+
+	"github.com/payvue/payvue-backend/pkg/domain/user"
+)
+
+type Container struct {
+	Repository
+	Users PasswordConfirmer
+	// ReadOnly no actualiza last_used_at al autenticar, porque la base de datos
+	// es de solo lectura (el reader); lo actualizan las peticiones a los binarios
+	// que escriben.
+	ReadOnly bool
+}
+
+type Repository interface {
+	CreateToken(ctx context.Context, token *AccessToken) (*AccessToken, error)
+	GetTokensByUserID(ctx context.Context, userID int) ([]AccessToken, error)
+	GetTokenByHash(ctx context.Context, tokenHash string) (*AccessToken, error)
+	// DeleteToken devuelve ErrTokenNotFound si el token no existe o es de otro usuario.
+	DeleteToken(ctx context.Context, userID, tokenID int) error
+	TouchToken(ctx context.Context, tokenID int, lastUsedAt time.Time) error
+}
+
+// PasswordConfirmer es lo que necesita el servicio de tokens del de usuarios.
+type PasswordConfirmer interface {
+	ConfirmPassword(ctx context.Context, userID int, credentials user.Credentials) (*user.User, error)
+}
+
+// Authenticator es lo que necesita el middleware HTTP para aceptar los tokens.
+type Authenticator interface {
+	// Authenticate devuelve ErrInvalidToken si el token no existe o ha caducado.
+	Authenticate(ctx context.Context, token string) (*AccessToken, error)
+}
@@ -0,0 +1,65 @@
+package accesstoken
+
+import (
+	"time"
+)
+
+type Scope string
+
+const (
+	ScopeRead      Scope = "read"
+	ScopeReadWrite Scope = "read_write"
+)
+
+func (s Scope) CanWrite() bool {
+	return s == ScopeReadWrite
+}
+
+// AccessToken es un token de acceso personal para scripts e integraciones. Solo
+// se guarda el hash; el token en claro se muestra una vez, al crearlo.
+type AccessToken struct {
+	ID        int    `json:"id"`
+	UserID    int    `json:"user_id"`
+	Name      string `json:"name"`
+	Scope     Scope  `json:"scope"`
+	TokenHash string `json:"-"`
+	// Hint son los primeros caracteres del token, para reconocerlo en el listado.
+	Hint string `json:"hint"`
+	// Token solo está relleno en la respuesta de CreateToken.
+	Token      string     `json:"-"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+func (t *AccessToken) Expired(now time.Time) bool {
+	return t.ExpiresAt != nil && !now.Before(*t.ExpiresAt)
+}
+
+// CreateTokenRequest lleva las mismas credenciales que el borrado de la cuenta:
+// un token da acceso sin la sesión, así que no basta con X-User-ID.
+type CreateTokenRequest struct {
+	Name  string `json:"name" validate:"required,max=100"`
+	Scope Scope  `json:"scope" validate:"required,oneof=read read_write"`
+	// ExpiresInDays 0 crea un token sin caducidad.
+	ExpiresInDays         int    `json:"expires_in_days" validate:"gte=0,lte=3650"`
+	Password              string `json:"password,omitempty"`
+	ReauthenticationToken string `json:"reauthentication_token,omitempty"`
+	// Code es un código TOTP o de recuperación; solo hace falta con 2FA activa.
+	Code string `json:"code,omitempty"`
+}
+
+type TokenListResponse struct {
+	Tokens []TokenResponse `json:"tokens"`
+}
+
+type TokenResponse struct {
+	ID         int    `json:"id"`
+	Name       string `json:"name"`
+	Scope      Scope  `json:"scope"`
+	Hint       string `json:"hint"`
+	Token      string `json:"token,omitempty"`
+	ExpiresAt  string `json:"expires_at,omitempty"`
+	LastUsedAt string `json:"last_used_at,omitempty"`
+	CreatedAt  string `json:"created_at"`
+}
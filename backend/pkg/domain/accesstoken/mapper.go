@@ -0,0 +1,46 @@
+package accesstoken
+
+import (
+	"time"
+
+	"github.com/payvue/payvue-backend/pkg/domain/user"
+)
+
+func ToTokenResponse(token *AccessToken) TokenResponse {
+	response := TokenResponse{
+		ID:        token.ID,
+		Name:      token.Name,
+		Scope:     token.Scope,
+		Hint:      token.Hint,
+		Token:     token.Token,
+		CreatedAt: token.CreatedAt.Format(time.RFC3339),
+	}
+
+	if token.ExpiresAt != nil {
+		response.ExpiresAt = token.ExpiresAt.Format(time.RFC3339)
+	}
+	if token.LastUsedAt != nil {
+		response.LastUsedAt = token.LastUsedAt.Format(time.RFC3339)
+	}
+
+	return response
+}
+
+func ToTokenListResponse(tokens []AccessToken) TokenListResponse {
+	responses := make([]TokenResponse, len(tokens))
+	for i, token := range tokens {
+		responses[i] = ToTokenResponse(&token)
+	}
+
+	return TokenListResponse{
+		Tokens: responses,
+	}
+}
+
+func (r CreateTokenRequest) Credentials() user.Credentials {
+	return user.Credentials{
+		Password:              r.Password,
+		ReauthenticationToken: r.ReauthenticationToken,
+		Code:                  r.Code,
+	}
+}
@@ -0,0 +1,134 @@
+package accesstoken
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/payvue/payvue-backend/pkg/tracing"
+)
+
+const (
+	// TokenPrefix distingue los tokens de PayVue, por ejemplo para los escáneres de secretos.
+	TokenPrefix = "pvt_"
+	hintLength  = len(TokenPrefix) + 6
+	// lastUsedResolution evita escribir en la base de datos en cada petición.
+	lastUsedResolution = time.Minute
+)
+
+var (
+	ErrTokenNotFound = errors.New("access token not found")
+	ErrInvalidToken  = errors.New("invalid or expired access token")
+	ErrDatabaseError = errors.New("database error")
+)
+
+type Service interface {
+	Authenticator
+	// CreateToken comprueba las credenciales de request y devuelve el token con el
+	// valor en claro en Token; no se puede recuperar después.
+	CreateToken(ctx context.Context, userID int, request CreateTokenRequest) (*AccessToken, error)
+	GetTokens(ctx context.Context, userID int) ([]AccessToken, error)
+	RevokeToken(ctx context.Context, userID, tokenID int) error
+}
+
+type service struct {
+	*Container
+}
+
+func New(container *Container) Service {
+	return &service{
+		Container: container,
+	}
+}
+
+func (s *service) CreateToken(ctx context.Context, userID int, request CreateTokenRequest) (*AccessToken, error) {
+	ctx, span := tracing.Start(ctx, "accesstoken.CreateToken")
+	defer span.End()
+
+	if _, err := s.Users.ConfirmPassword(ctx, userID, request.Credentials()); err != nil {
+		return nil, err
+	}
+
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return nil, err
+	}
+	value := TokenPrefix + base64.RawURLEncoding.EncodeToString(raw)
+
+	now := time.Now()
+	token := &AccessToken{
+		UserID:    userID,
+		Name:      strings.TrimSpace(request.Name),
+		Scope:     request.Scope,
+		TokenHash: hashToken(value),
+		Hint:      value[:hintLength],
+		CreatedAt: now,
+	}
+	if request.ExpiresInDays > 0 {
+		expiresAt := now.AddDate(0, 0, request.ExpiresInDays)
+		token.ExpiresAt = &expiresAt
+	}
+
+	created, err := s.Repository.CreateToken(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	created.Token = value
+	return created, nil
+}
+
+func (s *service) GetTokens(ctx context.Context, userID int) ([]AccessToken, error) {
+	ctx, span := tracing.Start(ctx, "accesstoken.GetTokens")
+	defer span.End()
+
+	return s.Repository.GetTokensByUserID(ctx, userID)
+}
+
+func (s *service) RevokeToken(ctx context.Context, userID, tokenID int) error {
+	ctx, span := tracing.Start(ctx, "accesstoken.RevokeToken")
+	defer span.End()
+
+	return s.Repository.DeleteToken(ctx, userID, tokenID)
+}
+
+func (s *service) Authenticate(ctx context.Context, value string) (*AccessToken, error) {
+	ctx, span := tracing.Start(ctx, "accesstoken.Authenticate")
+	defer span.End()
+
+	if !strings.HasPrefix(value, TokenPrefix) {
+		return nil, ErrInvalidToken
+	}
+
+	token, err := s.Repository.GetTokenByHash(ctx, hashToken(value))
+	if err != nil {
+		if err == ErrTokenNotFound {
+			return nil, ErrInvalidToken
+		}
+		return nil, err
+	}
+
+	now := time.Now()
+	if token.Expired(now) {
+		return nil, ErrInvalidToken
+	}
+
+	if !s.ReadOnly && (token.LastUsedAt == nil || now.Sub(*token.LastUsedAt) >= lastUsedResolution) {
+		if err := s.Repository.TouchToken(ctx, token.ID, now); err != nil {
+			return nil, err
+		}
+		token.LastUsedAt = &now
+	}
+
+	return token, nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
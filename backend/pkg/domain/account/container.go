@@ -0,0 +1,33 @@
+package account
+
+import (
+	"context"
+	"time"
+
+	"github.com/payvue/payvue-backend/pkg/domain/user"
+)
+
+type Container struct {
+	Repository
+	Users Authenticator
+	// GracePeriod es el margen entre la petición y el borrado; 0 borra en el acto.
+	GracePeriod time.Duration
+}
+
+// Authenticator es lo que necesita el servicio de cuentas del de usuarios.
+type Authenticator interface {
+	ConfirmPassword(ctx context.Context, userID int, credentials user.Credentials) (*user.User, error)
+}
+
+type Repository interface {
+	ScheduleDeletion(ctx context.Context, userID int, at time.Time) error
+	// CancelDeletion devuelve ErrDeletionNotScheduled si no había nada que cancelar.
+	CancelDeletion(ctx context.Context, userID int) error
+	// GetDueDeletions devuelve los usuarios cuyo periodo de gracia terminó antes de before.
+	GetDueDeletions(ctx context.Context, before time.Time) ([]int, error)
+	// DeleteAccount borra el usuario con sus datos personales en una transacción
+	// y devuelve los nombres de los recibos de los pagos borrados. Los registros
+	// de los hogares que siguen existiendo pasan a su owner.
+	DeleteAccount(ctx context.Context, userID int) ([]string, error)
+	GetExport(ctx context.Context, userID int) (*Export, error)
+}
@@ -0,0 +1,49 @@
+package account
+
+import (
+	"time"
+
+	"github.com/payvue/payvue-backend/pkg/domain/debt"
+	"github.com/payvue/payvue-backend/pkg/domain/household"
+	"github.com/payvue/payvue-backend/pkg/domain/income"
+	"github.com/payvue/payvue-backend/pkg/domain/payment"
+	"github.com/payvue/payvue-backend/pkg/domain/security"
+	"github.com/payvue/payvue-backend/pkg/domain/user"
+)
+
+// DeleteAccountRequest: las cuentas sin contraseña envían en su lugar el
+// reauthentication_token de un login externo reciente.
+type DeleteAccountRequest struct {
+	Password              string `json:"password"`
+	ReauthenticationToken string `json:"reauthentication_token"`
+	// Code es un código TOTP o de recuperación; solo hace falta con 2FA activa.
+	Code string `json:"code"`
+}
+
+// Deletion es el resultado de pedir el borrado de la cuenta.
+type Deletion struct {
+	ScheduledAt time.Time
+	// Completed es true si no hay periodo de gracia y la cuenta ya se borró.
+	Completed bool
+	// ReceiptFilenames son los recibos que quedan por borrar del disco si Completed.
+	ReceiptFilenames []string
+}
+
+// Export reúne todos los datos personales de un usuario, incluidos los que
+// están en la papelera.
+type Export struct {
+	GeneratedAt    time.Time             `json:"generated_at"`
+	User           user.User             `json:"user"`
+	Identities     []user.Identity       `json:"identities"`
+	Households     []household.Household `json:"households"`
+	Debts          []debt.Debt           `json:"debts"`
+	Incomes        []income.Income       `json:"incomes"`
+	Payments       []payment.Payment     `json:"payments"`
+	SecurityEvents []security.Event      `json:"security_events"`
+}
+
+type DeletionResponse struct {
+	Message     string `json:"message"`
+	ScheduledAt string `json:"scheduled_at"`
+	Completed   bool   `json:"completed"`
+}
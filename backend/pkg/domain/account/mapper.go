@@ -0,0 +1,28 @@
+package account
+
+import (
+	"time"
+
+	"github.com/payvue/payvue-backend/pkg/domain/user"
+)
+
+func ToDeletionResponse(deletion *Deletion) DeletionResponse {
+	message := "La cuenta se eliminará en la fecha indicada; puedes cancelarlo hasta entonces"
+	if deletion.Completed {
+		message = "Cuenta eliminada"
+	}
+
+	return DeletionResponse{
+		Message:     message,
+		ScheduledAt: deletion.ScheduledAt.Format(time.RFC3339),
+		Completed:   deletion.Completed,
+	}
+}
+
+func (r DeleteAccountRequest) Credentials() user.Credentials {
+	return user.Credentials{
+		Password:              r.Password,
+		ReauthenticationToken: r.ReauthenticationToken,
+		Code:                  r.Code,
+	}
+}
@@ -0,0 +1,113 @@
+package account
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/payvue/payvue-backend/pkg/tracing"
+)
+
+var (
+	ErrAccountNotFound      = errors.New("account not found")
+	ErrDeletionNotScheduled = errors.New("account deletion not scheduled")
+	ErrDatabaseError        = errors.New("database error")
+)
+
+type Service interface {
+	// RequestDeletion confirma las credenciales y programa el borrado de la cuenta al
+	// final del periodo de gracia, o la borra ya si no hay periodo de gracia. Pedirlo
+	// de nuevo mantiene la fecha ya programada.
+	RequestDeletion(ctx context.Context, userID int, request DeleteAccountRequest) (*Deletion, error)
+	CancelDeletion(ctx context.Context, userID int) error
+	Export(ctx context.Context, userID int) (*Export, error)
+	// PurgeDueDeletions borra las cuentas cuyo periodo de gracia ha terminado y
+	// devuelve cuántas borró y los recibos que quedan por borrar del disco.
+	PurgeDueDeletions(ctx context.Context, now time.Time) (int, []string, error)
+}
+
+type service struct {
+	*Container
+}
+
+func New(container *Container) Service {
+	return &service{
+		Container: container,
+	}
+}
+
+func (s *service) RequestDeletion(ctx context.Context, userID int, request DeleteAccountRequest) (*Deletion, error) {
+	ctx, span := tracing.Start(ctx, "account.RequestDeletion")
+	defer span.End()
+
+	user, err := s.Users.ConfirmPassword(ctx, userID, request.Credentials())
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if s.GracePeriod <= 0 {
+		filenames, err := s.Repository.DeleteAccount(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+		return &Deletion{ScheduledAt: now, Completed: true, ReceiptFilenames: filenames}, nil
+	}
+
+	if user.DeletionScheduledAt != nil {
+		return &Deletion{ScheduledAt: *user.DeletionScheduledAt}, nil
+	}
+
+	scheduledAt := now.Add(s.GracePeriod)
+	if err := s.Repository.ScheduleDeletion(ctx, userID, scheduledAt); err != nil {
+		return nil, err
+	}
+
+	return &Deletion{ScheduledAt: scheduledAt}, nil
+}
+
+func (s *service) CancelDeletion(ctx context.Context, userID int) error {
+	ctx, span := tracing.Start(ctx, "account.CancelDeletion")
+	defer span.End()
+
+	return s.Repository.CancelDeletion(ctx, userID)
+}
+
+func (s *service) Export(ctx context.Context, userID int) (*Export, error) {
+	ctx, span := tracing.Start(ctx, "account.Export")
+	defer span.End()
+
+	export, err := s.Repository.GetExport(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	export.GeneratedAt = time.Now()
+	return export, nil
+}
+
+func (s *service) PurgeDueDeletions(ctx context.Context, now time.Time) (int, []string, error) {
+	ctx, span := tracing.Start(ctx, "account.PurgeDueDeletions")
+	defer span.End()
+
+	userIDs, err := s.Repository.GetDueDeletions(ctx, now)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	purged := 0
+	var filenames []string
+	for _, userID := range userIDs {
+		deleted, err := s.Repository.DeleteAccount(ctx, userID)
+		if err != nil {
+			if err == ErrAccountNotFound {
+				continue
+			}
+			return purged, filenames, err
+		}
+		purged++
+		filenames = append(filenames, deleted...)
+	}
+
+	return purged, filenames, nil
+}
@@ -0,0 +1,26 @@
+package audit
+
+import (
+	"context"
+
+	"github.com/payvue/payvue-backend/pkg/domain/household"
+)
+
+type Container struct {
+	Repository
+	Households household.Scoper
+}
+
+type Repository interface {
+	// WithinTx ejecuta fn en una transacción compartida por todos los repositorios
+	// que reciban el contexto derivado.
+	WithinTx(ctx context.Context, fn func(ctx context.Context) error) error
+	CreateEntry(ctx context.Context, entry *Entry) error
+	GetEntries(ctx context.Context, filter Filter) ([]Entry, error)
+}
+
+// Recorder es lo que necesitan los demás servicios para registrar sus cambios.
+type Recorder interface {
+	WithinTx(ctx context.Context, fn func(ctx context.Context) error) error
+	Record(ctx context.Context, entity string, entityID int, action string, before, after interface{}) error
+}
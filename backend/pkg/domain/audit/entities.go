@@ -0,0 +1,63 @@
+package audit
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/payvue/payvue-backend/pkg/domain/household"
+)
+
+const (
+	EntityDebt    = "debt"
+	EntityIncome  = "income"
+	EntityPayment = "payment"
+)
+
+const (
+	ActionCreate  = "create"
+	ActionUpdate  = "update"
+	ActionDelete  = "delete"
+	ActionRestore = "restore"
+)
+
+type Entry struct {
+	ID        int             `json:"id"`
+	ActorID   int             `json:"actor_id"`
+	Entity    string          `json:"entity"`
+	EntityID  int             `json:"entity_id"`
+	Action    string          `json:"action"`
+	Before    json.RawMessage `json:"before"`
+	After     json.RawMessage `json:"after"`
+	RequestID string          `json:"request_id"`
+	IP        string          `json:"ip"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+type Filter struct {
+	Entity   string
+	EntityID int
+	Action   string
+	From     time.Time
+	To       time.Time
+	Limit    int
+	// Scope lo pone el servicio: limita el log a los cambios que hizo el usuario y
+	// a los de los registros que puede ver.
+	Scope household.Scope
+}
+
+type EntryListResponse struct {
+	Entries []EntryResponse `json:"entries"`
+}
+
+type EntryResponse struct {
+	ID        int             `json:"id"`
+	ActorID   int             `json:"actor_id"`
+	Entity    string          `json:"entity"`
+	EntityID  int             `json:"entity_id"`
+	Action    string          `json:"action"`
+	Before    json.RawMessage `json:"before"`
+	After     json.RawMessage `json:"after"`
+	RequestID string          `json:"request_id"`
+	IP        string          `json:"ip"`
+	CreatedAt string          `json:"created_at"`
+}
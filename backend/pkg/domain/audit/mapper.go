@@ -0,0 +1,29 @@
+package audit
+
+import "time"
+
+func ToEntryResponse(entry *Entry) EntryResponse {
+	return EntryResponse{
+		ID:        entry.ID,
+		ActorID:   entry.ActorID,
+		Entity:    entry.Entity,
+		EntityID:  entry.EntityID,
+		Action:    entry.Action,
+		Before:    entry.Before,
+		After:     entry.After,
+		RequestID: entry.RequestID,
+		IP:        entry.IP,
+		CreatedAt: entry.CreatedAt.Format(time.RFC3339),
+	}
+}
+
+func ToEntryListResponse(entries []Entry) EntryListResponse {
+	responses := make([]EntryResponse, len(entries))
+	for i, entry := range entries {
+		responses[i] = ToEntryResponse(&entry)
+	}
+
+	return EntryListResponse{
+		Entries: responses,
+	}
+}
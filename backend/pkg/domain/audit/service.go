@@ -0,0 +1,133 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/payvue/payvue-backend/pkg/tracing"
+	"github.com/payvue/payvue-backend/pkg/utils/actor"
+)
+
+const (
+	DefaultLimit = 100
+	MaxLimit     = 500
+)
+
+var (
+	validEntities = map[string]bool{EntityDebt: true, EntityIncome: true, EntityPayment: true}
+	validActions  = map[string]bool{ActionCreate: true, ActionUpdate: true, ActionDelete: true, ActionRestore: true}
+)
+
+var (
+	ErrInvalidFilter = errors.New("invalid audit filter")
+	ErrInvalidEntry  = errors.New("invalid audit entry")
+	ErrDatabaseError = errors.New("database error")
+)
+
+type Service interface {
+	Recorder
+	// GetEntries lista los cambios que hizo userID y los de los registros
+	// personales y de los hogares que puede ver.
+	GetEntries(ctx context.Context, userID int, filter Filter) ([]Entry, error)
+}
+
+type service struct {
+	*Container
+}
+
+func New(container *Container) Service {
+	return &service{
+		Container: container,
+	}
+}
+
+func (s *service) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return s.Repository.WithinTx(ctx, fn)
+}
+
+// Record guarda el estado anterior y posterior de una entidad junto con el
+// actor del contexto. Debe llamarse dentro de WithinTx para que la entrada se
+// confirme o descarte junto con el cambio.
+func (s *service) Record(ctx context.Context, entity string, entityID int, action string, before, after interface{}) error {
+	ctx, span := tracing.Start(ctx, "audit.Record")
+	defer span.End()
+
+	beforeData, err := marshalState(before)
+	if err != nil {
+		return err
+	}
+
+	afterData, err := marshalState(after)
+	if err != nil {
+		return err
+	}
+
+	a := actor.FromContext(ctx)
+	entry := &Entry{
+		ActorID:   a.UserID,
+		Entity:    entity,
+		EntityID:  entityID,
+		Action:    action,
+		Before:    beforeData,
+		After:     afterData,
+		RequestID: a.RequestID,
+		IP:        a.IP,
+		CreatedAt: time.Now(),
+	}
+
+	return s.Repository.CreateEntry(ctx, entry)
+}
+
+func (s *service) GetEntries(ctx context.Context, userID int, filter Filter) ([]Entry, error) {
+	ctx, span := tracing.Start(ctx, "audit.GetEntries")
+	defer span.End()
+
+	if filter.Entity != "" && !validEntities[filter.Entity] {
+		return nil, ErrInvalidFilter
+	}
+	if filter.Action != "" && !validActions[filter.Action] {
+		return nil, ErrInvalidFilter
+	}
+	if !filter.From.IsZero() && !filter.To.IsZero() && filter.To.Before(filter.From) {
+		return nil, ErrInvalidFilter
+	}
+
+	if filter.Limit <= 0 {
+		filter.Limit = DefaultLimit
+	}
+	if filter.Limit > MaxLimit {
+		filter.Limit = MaxLimit
+	}
+
+	// Sin usuario no hay nada que ver: actor_id 0 son los cambios anónimos
+	if userID <= 0 {
+		return []Entry{}, nil
+	}
+	scope, err := s.Households.Scope(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	filter.Scope = scope
+
+	entries, err := s.Repository.GetEntries(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+func marshalState(state interface{}) (json.RawMessage, error) {
+	if state == nil {
+		return nil, nil
+	}
+
+	data, err := json.Marshal(state)
+	if err != nil {
+		return nil, ErrInvalidEntry
+	}
+
+	return data, nil
+}
@@ -2,17 +2,29 @@ package debt
 
 import (
 	"context"
+	"time"
+
+	"github.com/payvue/payvue-backend/pkg/domain/audit"
+	"github.com/payvue/payvue-backend/pkg/domain/household"
 )
 
 type Container struct {
 	Repository
+	Audit      audit.Recorder
+	Households household.Scoper
 }
 
 type Repository interface {
 	CreateDebt(ctx context.Context, debt *Debt) (*Debt, error)
 	GetAllDebts(ctx context.Context) ([]Debt, error)
-	GetDebtsByUserID(ctx context.Context, userID int) ([]Debt, error)
+	// GetDebtsInScope lista las deudas personales del usuario y las de sus hogares.
+	GetDebtsInScope(ctx context.Context, scope household.Scope) ([]Debt, error)
 	GetDebtByID(ctx context.Context, id int) (*Debt, error)
 	UpdateDebt(ctx context.Context, debt *Debt) (*Debt, error)
+	// UpdateDebtColumns escribe solo las columnas indicadas, con la misma condición de versión que UpdateDebt.
+	UpdateDebtColumns(ctx context.Context, debt *Debt, columns []string) (*Debt, error)
 	DeleteDebt(ctx context.Context, id int) error
+	GetDeletedDebts(ctx context.Context, scope household.Scope) ([]Debt, error)
+	RestoreDebt(ctx context.Context, id int) error
+	PurgeDeletedDebts(ctx context.Context, before time.Time) (int, error)
 }
@@ -5,26 +5,31 @@ import (
 )
 
 type Debt struct {
-	ID                int       `json:"id"`
-	UserID            int       `json:"user_id"`
-	Name              string    `json:"name"`
-	TotalAmount       float64   `json:"total_amount"`
-	RemainingAmount   float64   `json:"remaining_amount"`
-	DueDate           time.Time `json:"due_date"`
-	InterestRate      float64   `json:"interest_rate"`
-	NumInstallments   int       `json:"num_installments"`
-	InstallmentAmount float64   `json:"installment_amount"`
-	PaymentDay        int       `json:"payment_day"`
-	Paid              bool      `json:"paid"`
-	CreatedAt         time.Time `json:"created_at"`
-	UpdatedAt         time.Time `json:"updated_at"`
+	ID                int        `json:"id"`
+	UserID            int        `json:"user_id"`
+	HouseholdID       int        `json:"household_id"`
+	Name              string     `json:"name"`
+	TotalAmount       float64    `json:"total_amount"`
+	RemainingAmount   float64    `json:"remaining_amount"`
+	DueDate           time.Time  `json:"due_date"`
+	InterestRate      float64    `json:"interest_rate"`
+	NumInstallments   int        `json:"num_installments"`
+	InstallmentAmount float64    `json:"installment_amount"`
+	PaymentDay        int        `json:"payment_day"`
+	Paid              bool       `json:"paid"`
+	Version           int        `json:"version"`
+	CreatedAt         time.Time  `json:"created_at"`
+	UpdatedAt         time.Time  `json:"updated_at"`
+	DeletedAt         *time.Time `json:"deleted_at,omitempty"`
 }
 
 type CreateDebtRequest struct {
-	UserID            int     `json:"user_id"`
+	UserID int `json:"user_id"`
+	// HouseholdID asigna la deuda a un hogar compartido; 0 la deja como personal.
+	HouseholdID       int     `json:"household_id" validate:"gte=0"`
 	Name              string  `json:"name" validate:"required"`
 	TotalAmount       float64 `json:"total_amount" validate:"required,gt=0"`
-	RemainingAmount   float64 `json:"remaining_amount" validate:"required,gte=0"`
+	RemainingAmount   float64 `json:"remaining_amount" validate:"gte=0"`
 	DueDate           string  `json:"due_date" validate:"required"`
 	InterestRate      float64 `json:"interest_rate" validate:"gte=0"`
 	NumInstallments   int     `json:"num_installments" validate:"required,gt=0"`
@@ -35,13 +40,22 @@ type CreateDebtRequest struct {
 type UpdateDebtRequest struct {
 	Name              string  `json:"name" validate:"required"`
 	TotalAmount       float64 `json:"total_amount" validate:"required,gt=0"`
-	RemainingAmount   float64 `json:"remaining_amount" validate:"required,gte=0"`
+	RemainingAmount   float64 `json:"remaining_amount" validate:"gte=0"`
 	DueDate           string  `json:"due_date" validate:"required"`
 	InterestRate      float64 `json:"interest_rate" validate:"gte=0"`
 	NumInstallments   int     `json:"num_installments" validate:"required,gt=0"`
 	InstallmentAmount float64 `json:"installment_amount" validate:"required,gt=0"`
 	PaymentDay        int     `json:"payment_day" validate:"required,min=1,max=31"`
 	Paid              bool    `json:"paid"`
+	// Version es la versión que el cliente espera modificar (If-Match); 0 omite la comprobación.
+	Version int `json:"-"`
+}
+
+// PatchDebtRequest es un documento JSON Merge Patch sobre los campos de UpdateDebtRequest.
+type PatchDebtRequest struct {
+	Patch []byte
+	// Version es la versión que el cliente espera modificar (If-Match); 0 omite la comprobación.
+	Version int
 }
 
 type DebtListResponse struct {
@@ -50,6 +64,7 @@ type DebtListResponse struct {
 
 type DebtResponse struct {
 	ID                int     `json:"id"`
+	HouseholdID       int     `json:"household_id,omitempty"`
 	Name              string  `json:"name"`
 	TotalAmount       float64 `json:"total_amount"`
 	RemainingAmount   float64 `json:"remaining_amount"`
@@ -60,4 +75,6 @@ type DebtResponse struct {
 	PaymentDay        int     `json:"payment_day"`
 	RemainingPayments int     `json:"remaining_payments"`
 	Paid              bool    `json:"paid"`
+	Version           int     `json:"version"`
+	DeletedAt         string  `json:"deleted_at,omitempty"`
 }
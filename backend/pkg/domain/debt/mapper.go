@@ -3,6 +3,11 @@ package debt
 import "math"
 
 func ToDebtResponse(debt *Debt) DebtResponse {
+	deletedAt := ""
+	if debt.DeletedAt != nil {
+		deletedAt = debt.DeletedAt.Format("2006-01-02")
+	}
+
 	remainingPayments := 0
 	if debt.InstallmentAmount > 0 {
 		remainingPayments = int(math.Floor(debt.RemainingAmount / debt.InstallmentAmount))
@@ -10,6 +15,7 @@ func ToDebtResponse(debt *Debt) DebtResponse {
 
 	return DebtResponse{
 		ID:                debt.ID,
+		HouseholdID:       debt.HouseholdID,
 		Name:              debt.Name,
 		TotalAmount:       debt.TotalAmount,
 		RemainingAmount:   debt.RemainingAmount,
@@ -20,6 +26,8 @@ func ToDebtResponse(debt *Debt) DebtResponse {
 		PaymentDay:        debt.PaymentDay,
 		RemainingPayments: remainingPayments,
 		Paid:              debt.Paid,
+		Version:           debt.Version,
+		DeletedAt:         deletedAt,
 	}
 }
 
@@ -1,24 +1,49 @@
 package debt
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"time"
+
+	"github.com/payvue/payvue-backend/pkg/domain/audit"
+	"github.com/payvue/payvue-backend/pkg/domain/household"
+	"github.com/payvue/payvue-backend/pkg/tracing"
+	"github.com/payvue/payvue-backend/pkg/utils/actor"
+	"github.com/payvue/payvue-backend/pkg/utils/mergepatch"
+	"github.com/payvue/payvue-backend/pkg/utils/validation"
 )
 
 var (
 	ErrDebtNotFound    = errors.New("debt not found")
 	ErrInvalidDebtData = errors.New("invalid debt data")
 	ErrDatabaseError   = errors.New("database error")
+	ErrVersionConflict = errors.New("debt version conflict")
+	ErrInvalidPatch    = errors.New("invalid debt patch")
+	ErrForbidden       = errors.New("debt is read-only for this user")
 )
 
+var validate = validation.New()
+
 type Service interface {
 	CreateDebt(ctx context.Context, request CreateDebtRequest) (*Debt, error)
+	// GetAllDebts lista las deudas visibles para el usuario de la petición; sin
+	// usuario identificado no devuelve ninguna.
 	GetAllDebts(ctx context.Context) ([]Debt, error)
+	// GetDebtsByUserID lista las deudas personales del usuario y las de sus hogares.
 	GetDebtsByUserID(ctx context.Context, userID int) ([]Debt, error)
 	GetDebtByID(ctx context.Context, id int) (*Debt, error)
 	UpdateDebt(ctx context.Context, id int, request UpdateDebtRequest) (*Debt, error)
+	// PatchDebt aplica un JSON Merge Patch sobre la deuda y escribe solo los campos que cambian.
+	PatchDebt(ctx context.Context, id int, request PatchDebtRequest) (*Debt, error)
 	DeleteDebt(ctx context.Context, id int) error
+	// GetDeletedDebts lista la papelera del usuario y de sus hogares; con userID 0
+	// devuelve la de todos los usuarios.
+	GetDeletedDebts(ctx context.Context, userID int) ([]Debt, error)
+	RestoreDebt(ctx context.Context, id int) (*Debt, error)
+	PurgeDeletedDebts(ctx context.Context, before time.Time) (int, error)
 }
 
 type service struct {
@@ -32,13 +57,30 @@ func New(container *Container) Service {
 }
 
 func (s *service) CreateDebt(ctx context.Context, request CreateDebtRequest) (*Debt, error) {
+	ctx, span := tracing.Start(ctx, "debt.CreateDebt")
+	defer span.End()
+
 	dueDate, err := time.Parse("2006-01-02", request.DueDate)
 	if err != nil {
 		return nil, ErrInvalidDebtData
 	}
 
+	if request.HouseholdID < 0 {
+		return nil, ErrInvalidDebtData
+	}
+	if request.HouseholdID > 0 {
+		scope, err := s.scope(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if !scope.CanWrite(request.UserID, request.HouseholdID) {
+			return nil, ErrForbidden
+		}
+	}
+
 	debt := &Debt{
 		UserID:            request.UserID,
+		HouseholdID:       request.HouseholdID,
 		Name:              request.Name,
 		TotalAmount:       request.TotalAmount,
 		RemainingAmount:   request.RemainingAmount,
@@ -48,11 +90,21 @@ func (s *service) CreateDebt(ctx context.Context, request CreateDebtRequest) (*D
 		InstallmentAmount: request.InstallmentAmount,
 		PaymentDay:        request.PaymentDay,
 		Paid:              false,
+		Version:           1,
 		CreatedAt:         time.Now(),
 		UpdatedAt:         time.Now(),
 	}
 
-	createdDebt, err := s.Repository.CreateDebt(ctx, debt)
+	var createdDebt *Debt
+	err = s.Audit.WithinTx(ctx, func(ctx context.Context) error {
+		created, err := s.Repository.CreateDebt(ctx, debt)
+		if err != nil {
+			return err
+		}
+		createdDebt = created
+
+		return s.Audit.Record(ctx, audit.EntityDebt, created.ID, audit.ActionCreate, nil, created)
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -61,16 +113,31 @@ func (s *service) CreateDebt(ctx context.Context, request CreateDebtRequest) (*D
 }
 
 func (s *service) GetAllDebts(ctx context.Context) ([]Debt, error) {
-	debts, err := s.Repository.GetAllDebts(ctx)
+	ctx, span := tracing.Start(ctx, "debt.GetAllDebts")
+	defer span.End()
+
+	scope, err := s.scope(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	return debts, nil
+	if scope.Unrestricted() {
+		return s.Repository.GetAllDebts(ctx)
+	}
+
+	return s.Repository.GetDebtsInScope(ctx, scope)
 }
 
 func (s *service) GetDebtsByUserID(ctx context.Context, userID int) ([]Debt, error) {
-	debts, err := s.Repository.GetDebtsByUserID(ctx, userID)
+	ctx, span := tracing.Start(ctx, "debt.GetDebtsByUserID")
+	defer span.End()
+
+	scope, err := s.Households.Scope(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	debts, err := s.Repository.GetDebtsInScope(ctx, scope)
 	if err != nil {
 		return nil, err
 	}
@@ -79,37 +146,63 @@ func (s *service) GetDebtsByUserID(ctx context.Context, userID int) ([]Debt, err
 }
 
 func (s *service) GetDebtByID(ctx context.Context, id int) (*Debt, error) {
+	ctx, span := tracing.Start(ctx, "debt.GetDebtByID")
+	defer span.End()
+
 	debt, err := s.Repository.GetDebtByID(ctx, id)
 	if err != nil {
 		return nil, err
 	}
 
+	if err := s.authorize(ctx, debt, false); err != nil {
+		return nil, err
+	}
+
 	return debt, nil
 }
 
 func (s *service) UpdateDebt(ctx context.Context, id int, request UpdateDebtRequest) (*Debt, error) {
-	existingDebt, err := s.Repository.GetDebtByID(ctx, id)
-	if err != nil {
-		return nil, err
-	}
+	ctx, span := tracing.Start(ctx, "debt.UpdateDebt")
+	defer span.End()
 
 	dueDate, err := time.Parse("2006-01-02", request.DueDate)
 	if err != nil {
 		return nil, ErrInvalidDebtData
 	}
 
-	existingDebt.Name = request.Name
-	existingDebt.TotalAmount = request.TotalAmount
-	existingDebt.RemainingAmount = request.RemainingAmount
-	existingDebt.DueDate = dueDate
-	existingDebt.InterestRate = request.InterestRate
-	existingDebt.NumInstallments = request.NumInstallments
-	existingDebt.InstallmentAmount = request.InstallmentAmount
-	existingDebt.PaymentDay = request.PaymentDay
-	existingDebt.Paid = request.Paid
-	existingDebt.UpdatedAt = time.Now()
+	var updatedDebt *Debt
+	err = s.Audit.WithinTx(ctx, func(ctx context.Context) error {
+		existingDebt, err := s.Repository.GetDebtByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		if err := s.authorize(ctx, existingDebt, true); err != nil {
+			return err
+		}
+		if request.Version > 0 && request.Version != existingDebt.Version {
+			return ErrVersionConflict
+		}
+		before := *existingDebt
+
+		existingDebt.Name = request.Name
+		existingDebt.TotalAmount = request.TotalAmount
+		existingDebt.RemainingAmount = request.RemainingAmount
+		existingDebt.DueDate = dueDate
+		existingDebt.InterestRate = request.InterestRate
+		existingDebt.NumInstallments = request.NumInstallments
+		existingDebt.InstallmentAmount = request.InstallmentAmount
+		existingDebt.PaymentDay = request.PaymentDay
+		existingDebt.Paid = request.Paid
+		existingDebt.UpdatedAt = time.Now()
 
-	updatedDebt, err := s.Repository.UpdateDebt(ctx, existingDebt)
+		updated, err := s.Repository.UpdateDebt(ctx, existingDebt)
+		if err != nil {
+			return err
+		}
+		updatedDebt = updated
+
+		return s.Audit.Record(ctx, audit.EntityDebt, id, audit.ActionUpdate, before, updated)
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -117,16 +210,216 @@ func (s *service) UpdateDebt(ctx context.Context, id int, request UpdateDebtRequ
 	return updatedDebt, nil
 }
 
+func (s *service) PatchDebt(ctx context.Context, id int, request PatchDebtRequest) (*Debt, error) {
+	ctx, span := tracing.Start(ctx, "debt.PatchDebt")
+	defer span.End()
+
+	var patchedDebt *Debt
+	err := s.Audit.WithinTx(ctx, func(ctx context.Context) error {
+		existingDebt, err := s.Repository.GetDebtByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		if err := s.authorize(ctx, existingDebt, true); err != nil {
+			return err
+		}
+		if request.Version > 0 && request.Version != existingDebt.Version {
+			return ErrVersionConflict
+		}
+		before := *existingDebt
+
+		merged, err := applyPatch(existingDebt, request.Patch)
+		if err != nil {
+			return err
+		}
+		dueDate, err := time.Parse("2006-01-02", merged.DueDate)
+		if err != nil {
+			return fmt.Errorf("%w: due_date must be YYYY-MM-DD", ErrInvalidPatch)
+		}
+
+		var columns []string
+		set := func(column string, changed bool) {
+			if changed {
+				columns = append(columns, column)
+			}
+		}
+		set("name", merged.Name != existingDebt.Name)
+		set("total_amount", merged.TotalAmount != existingDebt.TotalAmount)
+		set("remaining_amount", merged.RemainingAmount != existingDebt.RemainingAmount)
+		set("due_date", merged.DueDate != existingDebt.DueDate.Format("2006-01-02"))
+		set("interest_rate", merged.InterestRate != existingDebt.InterestRate)
+		set("num_installments", merged.NumInstallments != existingDebt.NumInstallments)
+		set("installment_amount", merged.InstallmentAmount != existingDebt.InstallmentAmount)
+		set("payment_day", merged.PaymentDay != existingDebt.PaymentDay)
+		set("paid", merged.Paid != existingDebt.Paid)
+
+		if len(columns) == 0 {
+			patchedDebt = existingDebt
+			return nil
+		}
+
+		existingDebt.Name = merged.Name
+		existingDebt.TotalAmount = merged.TotalAmount
+		existingDebt.RemainingAmount = merged.RemainingAmount
+		existingDebt.DueDate = dueDate
+		existingDebt.InterestRate = merged.InterestRate
+		existingDebt.NumInstallments = merged.NumInstallments
+		existingDebt.InstallmentAmount = merged.InstallmentAmount
+		existingDebt.PaymentDay = merged.PaymentDay
+		existingDebt.Paid = merged.Paid
+		existingDebt.UpdatedAt = time.Now()
+
+		updated, err := s.Repository.UpdateDebtColumns(ctx, existingDebt, columns)
+		if err != nil {
+			return err
+		}
+		patchedDebt = updated
+
+		return s.Audit.Record(ctx, audit.EntityDebt, id, audit.ActionUpdate, before, updated)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return patchedDebt, nil
+}
+
+// applyPatch fusiona el parche con los campos editables de la deuda y valida el
+// resultado con las mismas reglas que una actualización completa.
+func applyPatch(d *Debt, patch []byte) (*UpdateDebtRequest, error) {
+	current, err := json.Marshal(UpdateDebtRequest{
+		Name:              d.Name,
+		TotalAmount:       d.TotalAmount,
+		RemainingAmount:   d.RemainingAmount,
+		DueDate:           d.DueDate.Format("2006-01-02"),
+		InterestRate:      d.InterestRate,
+		NumInstallments:   d.NumInstallments,
+		InstallmentAmount: d.InstallmentAmount,
+		PaymentDay:        d.PaymentDay,
+		Paid:              d.Paid,
+	})
+	if err != nil {
+		return nil, ErrInvalidDebtData
+	}
+
+	doc, err := mergepatch.Apply(current, patch)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPatch, err)
+	}
+
+	var merged UpdateDebtRequest
+	decoder := json.NewDecoder(bytes.NewReader(doc))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&merged); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPatch, err)
+	}
+
+	if err := validate.Struct(merged); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidPatch, err)
+	}
+
+	return &merged, nil
+}
+
 func (s *service) DeleteDebt(ctx context.Context, id int) error {
-	_, err := s.Repository.GetDebtByID(ctx, id)
+	ctx, span := tracing.Start(ctx, "debt.DeleteDebt")
+	defer span.End()
+
+	return s.Audit.WithinTx(ctx, func(ctx context.Context) error {
+		existingDebt, err := s.Repository.GetDebtByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		if err := s.authorize(ctx, existingDebt, true); err != nil {
+			return err
+		}
+
+		err = s.Repository.DeleteDebt(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		return s.Audit.Record(ctx, audit.EntityDebt, id, audit.ActionDelete, existingDebt, nil)
+	})
+}
+
+func (s *service) GetDeletedDebts(ctx context.Context, userID int) ([]Debt, error) {
+	ctx, span := tracing.Start(ctx, "debt.GetDeletedDebts")
+	defer span.End()
+
+	scope, err := s.Households.Scope(ctx, userID)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	err = s.Repository.DeleteDebt(ctx, id)
+	debts, err := s.Repository.GetDeletedDebts(ctx, scope)
+	if err != nil {
+		return nil, err
+	}
+
+	return debts, nil
+}
+
+func (s *service) RestoreDebt(ctx context.Context, id int) (*Debt, error) {
+	ctx, span := tracing.Start(ctx, "debt.RestoreDebt")
+	defer span.End()
+
+	var restoredDebt *Debt
+	err := s.Audit.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.Repository.RestoreDebt(ctx, id); err != nil {
+			return err
+		}
+
+		restored, err := s.Repository.GetDebtByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		// Si el usuario no puede restaurarla, el error deshace la restauración
+		if err := s.authorize(ctx, restored, true); err != nil {
+			return err
+		}
+		restoredDebt = restored
+
+		return s.Audit.Record(ctx, audit.EntityDebt, id, audit.ActionRestore, nil, restored)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return restoredDebt, nil
+}
+
+// scope es el alcance del usuario que hace la petición.
+func (s *service) scope(ctx context.Context) (household.Scope, error) {
+	return s.Households.Scope(ctx, actor.FromContext(ctx).UserID)
+}
+
+// authorize devuelve ErrDebtNotFound si el usuario no puede ver la deuda, para no
+// revelar que existe, y ErrForbidden si puede verla pero no modificarla.
+func (s *service) authorize(ctx context.Context, d *Debt, write bool) error {
+	scope, err := s.scope(ctx)
 	if err != nil {
 		return err
 	}
 
+	if !scope.CanRead(d.UserID, d.HouseholdID) {
+		return ErrDebtNotFound
+	}
+	if write && !scope.CanWrite(d.UserID, d.HouseholdID) {
+		return ErrForbidden
+	}
+
 	return nil
 }
+
+func (s *service) PurgeDeletedDebts(ctx context.Context, before time.Time) (int, error) {
+	ctx, span := tracing.Start(ctx, "debt.PurgeDeletedDebts")
+	defer span.End()
+
+	purged, err := s.Repository.PurgeDeletedDebts(ctx, before)
+	if err != nil {
+		return 0, err
+	}
+
+	return purged, nil
+}
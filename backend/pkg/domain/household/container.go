@@ -0,0 +1,36 @@
+package household
+
+import (
+	"context"
+)
+
+type Container struct {
+	Repository
+}
+
+type Repository interface {
+	// CreateHousehold crea el hogar con el usuario creador como owner.
+	CreateHousehold(ctx context.Context, household *Household) (*Household, error)
+	GetHouseholdByID(ctx context.Context, id int) (*Household, error)
+	GetHouseholdsByUserID(ctx context.Context, userID int) ([]Household, error)
+	GetMembers(ctx context.Context, householdID int) ([]Member, error)
+	GetMember(ctx context.Context, householdID, userID int) (*Member, error)
+	// GetRoles devuelve el rol del usuario en cada uno de sus hogares.
+	GetRoles(ctx context.Context, userID int) (map[int]Role, error)
+	AddMember(ctx context.Context, member *Member) error
+	UpdateMemberRole(ctx context.Context, householdID, userID int, role Role) error
+	RemoveMember(ctx context.Context, householdID, userID int) error
+	CountOwners(ctx context.Context, householdID int) (int, error)
+	CreateInvitation(ctx context.Context, invitation *Invitation) (*Invitation, error)
+	GetInvitationByTokenHash(ctx context.Context, tokenHash string) (*Invitation, error)
+	GetPendingInvitations(ctx context.Context, householdID int) ([]Invitation, error)
+	// AcceptInvitation marca la invitación como usada y añade al miembro en una transacción.
+	AcceptInvitation(ctx context.Context, invitation *Invitation, member *Member) error
+	DeleteInvitation(ctx context.Context, householdID, invitationID int) error
+}
+
+// Scoper es lo que necesitan los servicios de finanzas para limitar sus consultas.
+type Scoper interface {
+	// Scope devuelve el alcance de userID; con userID 0 no da acceso a nada.
+	Scope(ctx context.Context, userID int) (Scope, error)
+}
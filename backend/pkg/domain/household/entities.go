@@ -0,0 +1,104 @@
+package household
+
+import (
+	"time"
+)
+
+type Role string
+
+const (
+	RoleOwner  Role = "owner"
+	RoleEditor Role = "editor"
+	RoleViewer Role = "viewer"
+)
+
+func (r Role) Valid() bool {
+	return r == RoleOwner || r == RoleEditor || r == RoleViewer
+}
+
+// CanWrite indica si el rol permite crear, modificar o borrar registros del hogar.
+func (r Role) CanWrite() bool {
+	return r == RoleOwner || r == RoleEditor
+}
+
+// Household agrupa a varios usuarios que gestionan las mismas finanzas.
+type Household struct {
+	ID        int       `json:"id"`
+	Name      string    `json:"name"`
+	CreatedBy int       `json:"created_by"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	// Role es el rol del usuario que consulta el hogar.
+	Role    Role     `json:"role,omitempty"`
+	Members []Member `json:"members,omitempty"`
+}
+
+type Member struct {
+	HouseholdID int       `json:"household_id"`
+	UserID      int       `json:"user_id"`
+	Email       string    `json:"email"`
+	Role        Role      `json:"role"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+type Invitation struct {
+	ID          int    `json:"id"`
+	HouseholdID int    `json:"household_id"`
+	TokenHash   string `json:"-"`
+	// Token solo está disponible al crear la invitación; en la base de datos se guarda el hash.
+	Token      string     `json:"-"`
+	Role       Role       `json:"role"`
+	Email      string     `json:"email"`
+	InvitedBy  int        `json:"invited_by"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	AcceptedBy int        `json:"accepted_by"`
+	AcceptedAt *time.Time `json:"accepted_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+type CreateHouseholdRequest struct {
+	Name string `json:"name" validate:"required,max=100"`
+}
+
+type InviteRequest struct {
+	Role Role `json:"role" validate:"required,oneof=owner editor viewer"`
+	// Email es informativo: la invitación la acepta quien tenga el token.
+	Email string `json:"email" validate:"omitempty,email"`
+}
+
+type AcceptInvitationRequest struct {
+	Token string `json:"token" validate:"required"`
+}
+
+type UpdateMemberRequest struct {
+	Role Role `json:"role" validate:"required,oneof=owner editor viewer"`
+}
+
+type HouseholdListResponse struct {
+	Households []HouseholdResponse `json:"households"`
+}
+
+type HouseholdResponse struct {
+	ID        int              `json:"id"`
+	Name      string           `json:"name"`
+	Role      Role             `json:"role"`
+	CreatedAt string           `json:"created_at"`
+	Members   []MemberResponse `json:"members,omitempty"`
+}
+
+type MemberResponse struct {
+	UserID   int    `json:"user_id"`
+	Email    string `json:"email"`
+	Role     Role   `json:"role"`
+	JoinedAt string `json:"joined_at"`
+}
+
+type InvitationResponse struct {
+	ID          int    `json:"id"`
+	HouseholdID int    `json:"household_id"`
+	Role        Role   `json:"role"`
+	Email       string `json:"email,omitempty"`
+	ExpiresAt   string `json:"expires_at"`
+	// Token solo se devuelve al crear la invitación; se guarda hasheado.
+	Token string `json:"token,omitempty"`
+}
@@ -0,0 +1,54 @@
+package household
+
+import "time"
+
+func ToHouseholdResponse(household *Household) HouseholdResponse {
+	response := HouseholdResponse{
+		ID:        household.ID,
+		Name:      household.Name,
+		Role:      household.Role,
+		CreatedAt: household.CreatedAt.Format(time.RFC3339),
+	}
+
+	for _, member := range household.Members {
+		response.Members = append(response.Members, MemberResponse{
+			UserID:   member.UserID,
+			Email:    member.Email,
+			Role:     member.Role,
+			JoinedAt: member.CreatedAt.Format(time.RFC3339),
+		})
+	}
+
+	return response
+}
+
+func ToHouseholdListResponse(households []Household) HouseholdListResponse {
+	responses := make([]HouseholdResponse, len(households))
+	for i, household := range households {
+		responses[i] = ToHouseholdResponse(&household)
+	}
+
+	return HouseholdListResponse{
+		Households: responses,
+	}
+}
+
+func ToInvitationResponse(invitation *Invitation) InvitationResponse {
+	return InvitationResponse{
+		ID:          invitation.ID,
+		HouseholdID: invitation.HouseholdID,
+		Role:        invitation.Role,
+		Email:       invitation.Email,
+		ExpiresAt:   invitation.ExpiresAt.Format(time.RFC3339),
+		Token:       invitation.Token,
+	}
+}
+
+func ToInvitationListResponse(invitations []Invitation) []InvitationResponse {
+	responses := make([]InvitationResponse, len(invitations))
+	for i, invitation := range invitations {
+		responses[i] = ToInvitationResponse(&invitation)
+	}
+
+	return responses
+}
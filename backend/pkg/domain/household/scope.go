@@ -0,0 +1,57 @@
+package household
+
+// Scope son los registros financieros a los que accede un usuario: los suyos
+// personales (household_id 0) y los de los hogares de los que es miembro. El
+// valor cero no da acceso a nada.
+type Scope struct {
+	UserID int
+	Roles  map[int]Role
+	all    bool
+}
+
+// InternalScope da acceso a todos los registros. Es solo para los jobs
+// internos; las peticiones siempre usan el alcance de un usuario.
+func InternalScope() Scope {
+	return Scope{all: true}
+}
+
+func (s Scope) Unrestricted() bool {
+	return s.all
+}
+
+func (s Scope) HouseholdIDs() []int {
+	ids := make([]int, 0, len(s.Roles))
+	for id := range s.Roles {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+// CanRead indica si el usuario puede ver un registro de ownerID y householdID.
+func (s Scope) CanRead(ownerID, householdID int) bool {
+	if s.Unrestricted() {
+		return true
+	}
+	if s.UserID <= 0 {
+		return false
+	}
+	if householdID == 0 {
+		return ownerID == s.UserID
+	}
+	_, ok := s.Roles[householdID]
+	return ok
+}
+
+// CanWrite es como CanRead, pero en un hogar exige rol de owner o editor.
+func (s Scope) CanWrite(ownerID, householdID int) bool {
+	if s.Unrestricted() {
+		return true
+	}
+	if s.UserID <= 0 {
+		return false
+	}
+	if householdID == 0 {
+		return ownerID == s.UserID
+	}
+	return s.Roles[householdID].CanWrite()
+}
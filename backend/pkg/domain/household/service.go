@@ -0,0 +1,331 @@
+package household
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/payvue/payvue-backend/pkg/tracing"
+)
+
+// InvitationTTL es el tiempo que una invitación puede aceptarse.
+const InvitationTTL = 7 * 24 * time.Hour
+
+var (
+	ErrHouseholdNotFound    = errors.New("household not found")
+	ErrMemberNotFound       = errors.New("member not found")
+	ErrAlreadyMember        = errors.New("user is already a member of the household")
+	ErrInvitationNotFound   = errors.New("invitation not found")
+	ErrInvalidInvitation    = errors.New("invalid or expired invitation")
+	ErrForbidden            = errors.New("operation not allowed for this role")
+	ErrLastOwner            = errors.New("household must keep at least one owner")
+	ErrInvalidHouseholdData = errors.New("invalid household data")
+	ErrDatabaseError        = errors.New("database error")
+)
+
+type Service interface {
+	Scoper
+	CreateHousehold(ctx context.Context, userID int, request CreateHouseholdRequest) (*Household, error)
+	// GetHouseholds lista los hogares del usuario con su rol en cada uno.
+	GetHouseholds(ctx context.Context, userID int) ([]Household, error)
+	// GetHousehold devuelve el hogar con sus miembros; solo para miembros.
+	GetHousehold(ctx context.Context, userID, householdID int) (*Household, error)
+	// Invite crea una invitación de un solo uso; solo los owners pueden invitar.
+	Invite(ctx context.Context, userID, householdID int, request InviteRequest) (*Invitation, error)
+	GetInvitations(ctx context.Context, userID, householdID int) ([]Invitation, error)
+	RevokeInvitation(ctx context.Context, userID, householdID, invitationID int) error
+	AcceptInvitation(ctx context.Context, userID int, token string) (*Household, error)
+	UpdateMemberRole(ctx context.Context, userID, householdID, memberID int, request UpdateMemberRequest) error
+	// RemoveMember expulsa a un miembro (owners) o abandona el hogar (memberID == userID).
+	RemoveMember(ctx context.Context, userID, householdID, memberID int) error
+}
+
+type service struct {
+	*Container
+}
+
+func New(container *Container) Service {
+	return &service{
+		Container: container,
+	}
+}
+
+func (s *service) Scope(ctx context.Context, userID int) (Scope, error) {
+	ctx, span := tracing.Start(ctx, "household.Scope")
+	defer span.End()
+
+	if userID <= 0 {
+		return Scope{}, nil
+	}
+
+	roles, err := s.Repository.GetRoles(ctx, userID)
+	if err != nil {
+		return Scope{}, err
+	}
+
+	return Scope{UserID: userID, Roles: roles}, nil
+}
+
+func (s *service) CreateHousehold(ctx context.Context, userID int, request CreateHouseholdRequest) (*Household, error) {
+	ctx, span := tracing.Start(ctx, "household.CreateHousehold")
+	defer span.End()
+
+	name := strings.TrimSpace(request.Name)
+	if name == "" {
+		return nil, ErrInvalidHouseholdData
+	}
+
+	now := time.Now()
+	household, err := s.Repository.CreateHousehold(ctx, &Household{
+		Name:      name,
+		CreatedBy: userID,
+		CreatedAt: now,
+		UpdatedAt: now,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	household.Role = RoleOwner
+	return household, nil
+}
+
+func (s *service) GetHouseholds(ctx context.Context, userID int) ([]Household, error) {
+	ctx, span := tracing.Start(ctx, "household.GetHouseholds")
+	defer span.End()
+
+	households, err := s.Repository.GetHouseholdsByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return households, nil
+}
+
+func (s *service) GetHousehold(ctx context.Context, userID, householdID int) (*Household, error) {
+	ctx, span := tracing.Start(ctx, "household.GetHousehold")
+	defer span.End()
+
+	member, err := s.member(ctx, householdID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	household, err := s.Repository.GetHouseholdByID(ctx, householdID)
+	if err != nil {
+		return nil, err
+	}
+
+	members, err := s.Repository.GetMembers(ctx, householdID)
+	if err != nil {
+		return nil, err
+	}
+
+	household.Role = member.Role
+	household.Members = members
+	return household, nil
+}
+
+func (s *service) Invite(ctx context.Context, userID, householdID int, request InviteRequest) (*Invitation, error) {
+	ctx, span := tracing.Start(ctx, "household.Invite")
+	defer span.End()
+
+	if !request.Role.Valid() {
+		return nil, ErrInvalidHouseholdData
+	}
+	if _, err := s.owner(ctx, householdID, userID); err != nil {
+		return nil, err
+	}
+
+	token, err := randomToken()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	invitation, err := s.Repository.CreateInvitation(ctx, &Invitation{
+		HouseholdID: householdID,
+		TokenHash:   hashToken(token),
+		Role:        request.Role,
+		Email:       request.Email,
+		InvitedBy:   userID,
+		ExpiresAt:   now.Add(InvitationTTL),
+		CreatedAt:   now,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	invitation.Token = token
+	return invitation, nil
+}
+
+func (s *service) GetInvitations(ctx context.Context, userID, householdID int) ([]Invitation, error) {
+	ctx, span := tracing.Start(ctx, "household.GetInvitations")
+	defer span.End()
+
+	if _, err := s.owner(ctx, householdID, userID); err != nil {
+		return nil, err
+	}
+
+	invitations, err := s.Repository.GetPendingInvitations(ctx, householdID)
+	if err != nil {
+		return nil, err
+	}
+
+	return invitations, nil
+}
+
+func (s *service) RevokeInvitation(ctx context.Context, userID, householdID, invitationID int) error {
+	ctx, span := tracing.Start(ctx, "household.RevokeInvitation")
+	defer span.End()
+
+	if _, err := s.owner(ctx, householdID, userID); err != nil {
+		return err
+	}
+
+	return s.Repository.DeleteInvitation(ctx, householdID, invitationID)
+}
+
+func (s *service) AcceptInvitation(ctx context.Context, userID int, token string) (*Household, error) {
+	ctx, span := tracing.Start(ctx, "household.AcceptInvitation")
+	defer span.End()
+
+	invitation, err := s.Repository.GetInvitationByTokenHash(ctx, hashToken(token))
+	if err != nil {
+		if err == ErrInvitationNotFound {
+			return nil, ErrInvalidInvitation
+		}
+		return nil, err
+	}
+	if invitation.AcceptedAt != nil || time.Now().After(invitation.ExpiresAt) {
+		return nil, ErrInvalidInvitation
+	}
+
+	if _, err := s.Repository.GetMember(ctx, invitation.HouseholdID, userID); err == nil {
+		return nil, ErrAlreadyMember
+	} else if err != ErrMemberNotFound {
+		return nil, err
+	}
+
+	now := time.Now()
+	invitation.AcceptedBy = userID
+	invitation.AcceptedAt = &now
+	member := &Member{
+		HouseholdID: invitation.HouseholdID,
+		UserID:      userID,
+		Role:        invitation.Role,
+		CreatedAt:   now,
+	}
+	if err := s.Repository.AcceptInvitation(ctx, invitation, member); err != nil {
+		return nil, err
+	}
+
+	return s.GetHousehold(ctx, userID, invitation.HouseholdID)
+}
+
+func (s *service) UpdateMemberRole(ctx context.Context, userID, householdID, memberID int, request UpdateMemberRequest) error {
+	ctx, span := tracing.Start(ctx, "household.UpdateMemberRole")
+	defer span.End()
+
+	if !request.Role.Valid() {
+		return ErrInvalidHouseholdData
+	}
+	if _, err := s.owner(ctx, householdID, userID); err != nil {
+		return err
+	}
+
+	member, err := s.Repository.GetMember(ctx, householdID, memberID)
+	if err != nil {
+		return err
+	}
+
+	if member.Role == RoleOwner && request.Role != RoleOwner {
+		if err := s.keepOwner(ctx, householdID); err != nil {
+			return err
+		}
+	}
+
+	return s.Repository.UpdateMemberRole(ctx, householdID, memberID, request.Role)
+}
+
+func (s *service) RemoveMember(ctx context.Context, userID, householdID, memberID int) error {
+	ctx, span := tracing.Start(ctx, "household.RemoveMember")
+	defer span.End()
+
+	if memberID != userID {
+		if _, err := s.owner(ctx, householdID, userID); err != nil {
+			return err
+		}
+	}
+
+	member, err := s.Repository.GetMember(ctx, householdID, memberID)
+	if err != nil {
+		return err
+	}
+
+	if member.Role == RoleOwner {
+		if err := s.keepOwner(ctx, householdID); err != nil {
+			return err
+		}
+	}
+
+	return s.Repository.RemoveMember(ctx, householdID, memberID)
+}
+
+// member devuelve ErrHouseholdNotFound si el usuario no pertenece al hogar, para
+// no revelar qué hogares existen.
+func (s *service) member(ctx context.Context, householdID, userID int) (*Member, error) {
+	member, err := s.Repository.GetMember(ctx, householdID, userID)
+	if err != nil {
+		if err == ErrMemberNotFound {
+			return nil, ErrHouseholdNotFound
+		}
+		return nil, err
+	}
+
+	return member, nil
+}
+
+func (s *service) owner(ctx context.Context, householdID, userID int) (*Member, error) {
+	member, err := s.member(ctx, householdID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if member.Role != RoleOwner {
+		return nil, ErrForbidden
+	}
+
+	return member, nil
+}
+
+// keepOwner impide quitar el último owner: el hogar se quedaría sin nadie que lo administre.
+func (s *service) keepOwner(ctx context.Context, householdID int) error {
+	owners, err := s.Repository.CountOwners(ctx, householdID)
+	if err != nil {
+		return err
+	}
+	if owners <= 1 {
+		return ErrLastOwner
+	}
+
+	return nil
+}
+
+func randomToken() (string, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(raw), nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,20 @@
+package idempotency
+
+import (
+	"context"
+	"time"
+)
+
+type Container struct {
+	Repository
+}
+
+type Repository interface {
+	// CreateRecord reserva la clave; devuelve ErrKeyExists si ya estaba registrada.
+	CreateRecord(ctx context.Context, record *Record) error
+	GetRecord(ctx context.Context, userID int, key string) (*Record, error)
+	CompleteRecord(ctx context.Context, record *Record) error
+	// DeleteRecord devuelve ErrNotFound si el registro ya no existe.
+	DeleteRecord(ctx context.Context, id int) error
+	PurgeRecords(ctx context.Context, before time.Time) (int, error)
+}
@@ -0,0 +1,25 @@
+package idempotency
+
+import (
+	"time"
+)
+
+// Record guarda el resultado de una petición identificada por Idempotency-Key.
+// Mientras StatusCode es 0 la petición original sigue en curso.
+type Record struct {
+	ID              int               `json:"id"`
+	UserID          int               `json:"user_id"`
+	Key             string            `json:"key"`
+	Method          string            `json:"method"`
+	Path            string            `json:"path"`
+	RequestHash     string            `json:"request_hash"`
+	StatusCode      int               `json:"status_code"`
+	ResponseHeaders map[string]string `json:"response_headers"`
+	ResponseBody    []byte            `json:"-"`
+	CreatedAt       time.Time         `json:"created_at"`
+	CompletedAt     *time.Time        `json:"completed_at,omitempty"`
+}
+
+func (r *Record) Completed() bool {
+	return r.StatusCode != 0
+}
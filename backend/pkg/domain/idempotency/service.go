@@ -0,0 +1,141 @@
+package idempotency
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/payvue/payvue-backend/pkg/tracing"
+)
+
+// TTL es el tiempo durante el que se puede repetir una petición con la misma clave.
+const TTL = 24 * time.Hour
+
+// LockTimeout es el tiempo tras el cual una petición que no terminó (por ejemplo,
+// porque el proceso se cayó) deja de bloquear su clave.
+const LockTimeout = 2 * time.Minute
+
+// MaxKeyLength limita el tamaño de la cabecera Idempotency-Key.
+const MaxKeyLength = 255
+
+var (
+	ErrInvalidKey    = errors.New("invalid idempotency key")
+	ErrKeyExists     = errors.New("idempotency key already exists")
+	ErrKeyInProgress = errors.New("a request with this idempotency key is in progress")
+	ErrKeyMismatch   = errors.New("idempotency key reused with a different request")
+	ErrNotFound      = errors.New("idempotency key not found")
+	ErrDatabaseError = errors.New("database error")
+)
+
+type Service interface {
+	// Begin reserva la clave para la petición descrita por record y devuelve nil. Si la
+	// clave ya se usó con la misma petición devuelve el registro guardado para repetir
+	// la respuesta.
+	Begin(ctx context.Context, record *Record) (*Record, error)
+	// Complete guarda la respuesta de una petición reservada con Begin.
+	Complete(ctx context.Context, record *Record) error
+	// Release libera la clave para que la petición se pueda reintentar.
+	Release(ctx context.Context, record *Record) error
+	PurgeExpired(ctx context.Context, before time.Time) (int, error)
+}
+
+type service struct {
+	*Container
+}
+
+func New(container *Container) Service {
+	return &service{
+		Container: container,
+	}
+}
+
+func (s *service) Begin(ctx context.Context, record *Record) (*Record, error) {
+	ctx, span := tracing.Start(ctx, "idempotency.Begin")
+	defer span.End()
+
+	if record.Key == "" || len(record.Key) > MaxKeyLength {
+		return nil, ErrInvalidKey
+	}
+
+	record.StatusCode = 0
+	record.CreatedAt = time.Now()
+
+	err := s.Repository.CreateRecord(ctx, record)
+	if err == nil {
+		return nil, nil
+	}
+	if err != ErrKeyExists {
+		return nil, err
+	}
+
+	existing, err := s.Repository.GetRecord(ctx, record.UserID, record.Key)
+	if err != nil {
+		if err == ErrNotFound {
+			// Se purgó entre medias: se reintenta la reserva una vez
+			if err := s.Repository.CreateRecord(ctx, record); err != nil {
+				if err == ErrKeyExists {
+					return nil, ErrKeyInProgress
+				}
+				return nil, err
+			}
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	expired := existing.CreatedAt.Before(record.CreatedAt.Add(-TTL))
+	abandoned := !existing.Completed() && existing.CreatedAt.Before(record.CreatedAt.Add(-LockTimeout))
+	if expired || abandoned {
+		// Si otra petición borró o reservó la clave antes, esta queda en espera
+		if err := s.Repository.DeleteRecord(ctx, existing.ID); err != nil {
+			if err == ErrNotFound {
+				return nil, ErrKeyInProgress
+			}
+			return nil, err
+		}
+		if err := s.Repository.CreateRecord(ctx, record); err != nil {
+			if err == ErrKeyExists {
+				return nil, ErrKeyInProgress
+			}
+			return nil, err
+		}
+		return nil, nil
+	}
+
+	if existing.Method != record.Method || existing.Path != record.Path || existing.RequestHash != record.RequestHash {
+		return nil, ErrKeyMismatch
+	}
+
+	if !existing.Completed() {
+		return nil, ErrKeyInProgress
+	}
+
+	return existing, nil
+}
+
+func (s *service) Complete(ctx context.Context, record *Record) error {
+	ctx, span := tracing.Start(ctx, "idempotency.Complete")
+	defer span.End()
+
+	now := time.Now()
+	record.CompletedAt = &now
+	return s.Repository.CompleteRecord(ctx, record)
+}
+
+func (s *service) Release(ctx context.Context, record *Record) error {
+	ctx, span := tracing.Start(ctx, "idempotency.Release")
+	defer span.End()
+
+	err := s.Repository.DeleteRecord(ctx, record.ID)
+	if err == ErrNotFound {
+		return nil
+	}
+	return err
+}
+
+func (s *service) PurgeExpired(ctx context.Context, before time.Time) (int, error) {
+	ctx, span := tracing.Start(ctx, "idempotency.PurgeExpired")
+	defer span.End()
+
+	return s.Repository.PurgeRecords(ctx, before)
+}
@@ -2,17 +2,29 @@ package income
 
 import (
 	"context"
+	"time"
+
+	"github.com/payvue/payvue-backend/pkg/domain/audit"
+	"github.com/payvue/payvue-backend/pkg/domain/household"
 )
 
 type Container struct {
 	Repository
+	Audit      audit.Recorder
+	Households household.Scoper
 }
 
 type Repository interface {
 	CreateIncome(ctx context.Context, income *Income) (*Income, error)
 	GetAllIncomes(ctx context.Context) ([]Income, error)
-	GetIncomesByUserID(ctx context.Context, userID int) ([]Income, error)
+	// GetIncomesInScope lista los ingresos personales del usuario y los de sus hogares.
+	GetIncomesInScope(ctx context.Context, scope household.Scope) ([]Income, error)
 	GetIncomeByID(ctx context.Context, id int) (*Income, error)
 	UpdateIncome(ctx context.Context, income *Income) (*Income, error)
+	// UpdateIncomeColumns escribe solo las columnas indicadas, con la misma condición de versión que UpdateIncome.
+	UpdateIncomeColumns(ctx context.Context, income *Income, columns []string) (*Income, error)
 	DeleteIncome(ctx context.Context, id int) error
+	GetDeletedIncomes(ctx context.Context, scope household.Scope) ([]Income, error)
+	RestoreIncome(ctx context.Context, id int) error
+	PurgeDeletedIncomes(ctx context.Context, before time.Time) (int, error)
 }
@@ -5,26 +5,40 @@ import (
 )
 
 type Income struct {
-	ID        int       `json:"id"`
-	UserID    int       `json:"user_id"`
-	Amount    float64   `json:"amount"`
-	Source    string    `json:"source"`
-	Date      time.Time `json:"date"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID          int        `json:"id"`
+	UserID      int        `json:"user_id"`
+	HouseholdID int        `json:"household_id"`
+	Amount      float64    `json:"amount"`
+	Source      string     `json:"source"`
+	Date        time.Time  `json:"date"`
+	Version     int        `json:"version"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+	DeletedAt   *time.Time `json:"deleted_at,omitempty"`
 }
 
 type CreateIncomeRequest struct {
-	UserID int     `json:"user_id"`
-	Amount float64 `json:"amount" validate:"required,gt=0"`
-	Source string  `json:"source" validate:"required"`
-	Date   string  `json:"date" validate:"required"`
+	UserID int `json:"user_id"`
+	// HouseholdID comparte el ingreso con un hogar; 0 lo deja como personal.
+	HouseholdID int     `json:"household_id" validate:"gte=0"`
+	Amount      float64 `json:"amount" validate:"required,gt=0"`
+	Source      string  `json:"source" validate:"required"`
+	Date        string  `json:"date" validate:"required"`
 }
 
 type UpdateIncomeRequest struct {
 	Amount float64 `json:"amount" validate:"required,gt=0"`
 	Source string  `json:"source" validate:"required"`
 	Date   string  `json:"date" validate:"required"`
+	// Version es la versión que el cliente espera modificar (If-Match); 0 omite la comprobación.
+	Version int `json:"-"`
+}
+
+// PatchIncomeRequest es un documento JSON Merge Patch sobre los campos de UpdateIncomeRequest.
+type PatchIncomeRequest struct {
+	Patch []byte
+	// Version es la versión que el cliente espera modificar (If-Match); 0 omite la comprobación.
+	Version int
 }
 
 type IncomeListResponse struct {
@@ -32,8 +46,11 @@ type IncomeListResponse struct {
 }
 
 type IncomeResponse struct {
-	ID     int     `json:"id"`
-	Amount float64 `json:"amount"`
-	Source string  `json:"source"`
-	Date   string  `json:"date"`
+	ID          int     `json:"id"`
+	HouseholdID int     `json:"household_id,omitempty"`
+	Amount      float64 `json:"amount"`
+	Source      string  `json:"source"`
+	Date        string  `json:"date"`
+	Version     int     `json:"version"`
+	DeletedAt   string  `json:"deleted_at,omitempty"`
 }
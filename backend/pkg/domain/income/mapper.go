@@ -1,11 +1,19 @@
 package income
 
 func ToIncomeResponse(income *Income) IncomeResponse {
+	deletedAt := ""
+	if income.DeletedAt != nil {
+		deletedAt = income.DeletedAt.Format("2006-01-02")
+	}
+
 	return IncomeResponse{
-		ID:     income.ID,
-		Amount: income.Amount,
-		Source: income.Source,
-		Date:   income.Date.Format("2006-01-02"),
+		ID:          income.ID,
+		HouseholdID: income.HouseholdID,
+		Amount:      income.Amount,
+		Source:      income.Source,
+		Date:        income.Date.Format("2006-01-02"),
+		Version:     income.Version,
+		DeletedAt:   deletedAt,
 	}
 }
 
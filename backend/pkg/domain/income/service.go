@@ -1,24 +1,49 @@
 package income
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"time"
+
+	"github.com/payvue/payvue-backend/pkg/domain/audit"
+	"github.com/payvue/payvue-backend/pkg/domain/household"
+	"github.com/payvue/payvue-backend/pkg/tracing"
+	"github.com/payvue/payvue-backend/pkg/utils/actor"
+	"github.com/payvue/payvue-backend/pkg/utils/mergepatch"
+	"github.com/payvue/payvue-backend/pkg/utils/validation"
 )
 
 var (
 	ErrIncomeNotFound    = errors.New("income not found")
 	ErrInvalidIncomeData = errors.New("invalid income data")
 	ErrDatabaseError     = errors.New("database error")
+	ErrVersionConflict   = errors.New("income version conflict")
+	ErrInvalidPatch      = errors.New("invalid income patch")
+	ErrForbidden         = errors.New("income is read-only for this user")
 )
 
+var validate = validation.New()
+
 type Service interface {
 	CreateIncome(ctx context.Context, request CreateIncomeRequest) (*Income, error)
+	// GetAllIncomes lista los ingresos visibles para el usuario de la petición; sin
+	// usuario identificado no devuelve ninguno.
 	GetAllIncomes(ctx context.Context) ([]Income, error)
+	// GetIncomesByUserID lista los ingresos personales del usuario y los de sus hogares.
 	GetIncomesByUserID(ctx context.Context, userID int) ([]Income, error)
 	GetIncomeByID(ctx context.Context, id int) (*Income, error)
 	UpdateIncome(ctx context.Context, id int, request UpdateIncomeRequest) (*Income, error)
+	// PatchIncome aplica un JSON Merge Patch sobre el ingreso y escribe solo los campos que cambian.
+	PatchIncome(ctx context.Context, id int, request PatchIncomeRequest) (*Income, error)
 	DeleteIncome(ctx context.Context, id int) error
+	// GetDeletedIncomes lista la papelera del usuario y de sus hogares; con userID 0
+	// devuelve la de todos los usuarios.
+	GetDeletedIncomes(ctx context.Context, userID int) ([]Income, error)
+	RestoreIncome(ctx context.Context, id int) (*Income, error)
+	PurgeDeletedIncomes(ctx context.Context, before time.Time) (int, error)
 }
 
 type service struct {
@@ -32,21 +57,48 @@ func New(container *Container) Service {
 }
 
 func (s *service) CreateIncome(ctx context.Context, request CreateIncomeRequest) (*Income, error) {
+	ctx, span := tracing.Start(ctx, "income.CreateIncome")
+	defer span.End()
+
 	date, err := time.Parse("2006-01-02", request.Date)
 	if err != nil {
 		return nil, ErrInvalidIncomeData
 	}
 
+	if request.HouseholdID < 0 {
+		return nil, ErrInvalidIncomeData
+	}
+	if request.HouseholdID > 0 {
+		scope, err := s.scope(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if !scope.CanWrite(request.UserID, request.HouseholdID) {
+			return nil, ErrForbidden
+		}
+	}
+
 	income := &Income{
-		UserID:    request.UserID,
-		Amount:    request.Amount,
-		Source:    request.Source,
-		Date:      date,
-		CreatedAt: time.Now(),
-		UpdatedAt: time.Now(),
+		UserID:      request.UserID,
+		HouseholdID: request.HouseholdID,
+		Amount:      request.Amount,
+		Source:      request.Source,
+		Date:        date,
+		Version:     1,
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
 	}
 
-	createdIncome, err := s.Repository.CreateIncome(ctx, income)
+	var createdIncome *Income
+	err = s.Audit.WithinTx(ctx, func(ctx context.Context) error {
+		created, err := s.Repository.CreateIncome(ctx, income)
+		if err != nil {
+			return err
+		}
+		createdIncome = created
+
+		return s.Audit.Record(ctx, audit.EntityIncome, created.ID, audit.ActionCreate, nil, created)
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -55,16 +107,31 @@ func (s *service) CreateIncome(ctx context.Context, request CreateIncomeRequest)
 }
 
 func (s *service) GetAllIncomes(ctx context.Context) ([]Income, error) {
-	incomes, err := s.Repository.GetAllIncomes(ctx)
+	ctx, span := tracing.Start(ctx, "income.GetAllIncomes")
+	defer span.End()
+
+	scope, err := s.scope(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	return incomes, nil
+	if scope.Unrestricted() {
+		return s.Repository.GetAllIncomes(ctx)
+	}
+
+	return s.Repository.GetIncomesInScope(ctx, scope)
 }
 
 func (s *service) GetIncomesByUserID(ctx context.Context, userID int) ([]Income, error) {
-	incomes, err := s.Repository.GetIncomesByUserID(ctx, userID)
+	ctx, span := tracing.Start(ctx, "income.GetIncomesByUserID")
+	defer span.End()
+
+	scope, err := s.Households.Scope(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	incomes, err := s.Repository.GetIncomesInScope(ctx, scope)
 	if err != nil {
 		return nil, err
 	}
@@ -73,31 +140,57 @@ func (s *service) GetIncomesByUserID(ctx context.Context, userID int) ([]Income,
 }
 
 func (s *service) GetIncomeByID(ctx context.Context, id int) (*Income, error) {
+	ctx, span := tracing.Start(ctx, "income.GetIncomeByID")
+	defer span.End()
+
 	income, err := s.Repository.GetIncomeByID(ctx, id)
 	if err != nil {
 		return nil, err
 	}
 
+	if err := s.authorize(ctx, income, false); err != nil {
+		return nil, err
+	}
+
 	return income, nil
 }
 
 func (s *service) UpdateIncome(ctx context.Context, id int, request UpdateIncomeRequest) (*Income, error) {
-	existingIncome, err := s.Repository.GetIncomeByID(ctx, id)
-	if err != nil {
-		return nil, err
-	}
+	ctx, span := tracing.Start(ctx, "income.UpdateIncome")
+	defer span.End()
 
 	date, err := time.Parse("2006-01-02", request.Date)
 	if err != nil {
 		return nil, ErrInvalidIncomeData
 	}
 
-	existingIncome.Amount = request.Amount
-	existingIncome.Source = request.Source
-	existingIncome.Date = date
-	existingIncome.UpdatedAt = time.Now()
+	var updatedIncome *Income
+	err = s.Audit.WithinTx(ctx, func(ctx context.Context) error {
+		existingIncome, err := s.Repository.GetIncomeByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		if err := s.authorize(ctx, existingIncome, true); err != nil {
+			return err
+		}
+		if request.Version > 0 && request.Version != existingIncome.Version {
+			return ErrVersionConflict
+		}
+		before := *existingIncome
+
+		existingIncome.Amount = request.Amount
+		existingIncome.Source = request.Source
+		existingIncome.Date = date
+		existingIncome.UpdatedAt = time.Now()
 
-	updatedIncome, err := s.Repository.UpdateIncome(ctx, existingIncome)
+		updated, err := s.Repository.UpdateIncome(ctx, existingIncome)
+		if err != nil {
+			return err
+		}
+		updatedIncome = updated
+
+		return s.Audit.Record(ctx, audit.EntityIncome, id, audit.ActionUpdate, before, updated)
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -105,16 +198,199 @@ func (s *service) UpdateIncome(ctx context.Context, id int, request UpdateIncome
 	return updatedIncome, nil
 }
 
+func (s *service) PatchIncome(ctx context.Context, id int, request PatchIncomeRequest) (*Income, error) {
+	ctx, span := tracing.Start(ctx, "income.PatchIncome")
+	defer span.End()
+
+	var patchedIncome *Income
+	err := s.Audit.WithinTx(ctx, func(ctx context.Context) error {
+		existingIncome, err := s.Repository.GetIncomeByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		if err := s.authorize(ctx, existingIncome, true); err != nil {
+			return err
+		}
+		if request.Version > 0 && request.Version != existingIncome.Version {
+			return ErrVersionConflict
+		}
+		before := *existingIncome
+
+		merged, err := applyPatch(existingIncome, request.Patch)
+		if err != nil {
+			return err
+		}
+		date, err := time.Parse("2006-01-02", merged.Date)
+		if err != nil {
+			return fmt.Errorf("%w: date must be YYYY-MM-DD", ErrInvalidPatch)
+		}
+
+		var columns []string
+		if merged.Amount != existingIncome.Amount {
+			columns = append(columns, "amount")
+		}
+		if merged.Source != existingIncome.Source {
+			columns = append(columns, "source")
+		}
+		if merged.Date != existingIncome.Date.Format("2006-01-02") {
+			columns = append(columns, "date")
+		}
+
+		if len(columns) == 0 {
+			patchedIncome = existingIncome
+			return nil
+		}
+
+		existingIncome.Amount = merged.Amount
+		existingIncome.Source = merged.Source
+		existingIncome.Date = date
+		existingIncome.UpdatedAt = time.Now()
+
+		updated, err := s.Repository.UpdateIncomeColumns(ctx, existingIncome, columns)
+		if err != nil {
+			return err
+		}
+		patchedIncome = updated
+
+		return s.Audit.Record(ctx, audit.EntityIncome, id, audit.ActionUpdate, before, updated)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return patchedIncome, nil
+}
+
+// applyPatch fusiona el parche con los campos editables del ingreso y valida el
+// resultado con las mismas reglas que una actualización completa.
+func applyPatch(i *Income, patch []byte) (*UpdateIncomeRequest, error) {
+	current, err := json.Marshal(UpdateIncomeRequest{
+		Amount: i.Amount,
+		Source: i.Source,
+		Date:   i.Date.Format("2006-01-02"),
+	})
+	if err != nil {
+		return nil, ErrInvalidIncomeData
+	}
+
+	doc, err := mergepatch.Apply(current, patch)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPatch, err)
+	}
+
+	var merged UpdateIncomeRequest
+	decoder := json.NewDecoder(bytes.NewReader(doc))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&merged); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPatch, err)
+	}
+
+	if err := validate.Struct(merged); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidPatch, err)
+	}
+
+	return &merged, nil
+}
+
 func (s *service) DeleteIncome(ctx context.Context, id int) error {
-	_, err := s.Repository.GetIncomeByID(ctx, id)
+	ctx, span := tracing.Start(ctx, "income.DeleteIncome")
+	defer span.End()
+
+	return s.Audit.WithinTx(ctx, func(ctx context.Context) error {
+		existingIncome, err := s.Repository.GetIncomeByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		if err := s.authorize(ctx, existingIncome, true); err != nil {
+			return err
+		}
+
+		err = s.Repository.DeleteIncome(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		return s.Audit.Record(ctx, audit.EntityIncome, id, audit.ActionDelete, existingIncome, nil)
+	})
+}
+
+func (s *service) GetDeletedIncomes(ctx context.Context, userID int) ([]Income, error) {
+	ctx, span := tracing.Start(ctx, "income.GetDeletedIncomes")
+	defer span.End()
+
+	scope, err := s.Households.Scope(ctx, userID)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	err = s.Repository.DeleteIncome(ctx, id)
+	incomes, err := s.Repository.GetDeletedIncomes(ctx, scope)
+	if err != nil {
+		return nil, err
+	}
+
+	return incomes, nil
+}
+
+func (s *service) RestoreIncome(ctx context.Context, id int) (*Income, error) {
+	ctx, span := tracing.Start(ctx, "income.RestoreIncome")
+	defer span.End()
+
+	var restoredIncome *Income
+	err := s.Audit.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.Repository.RestoreIncome(ctx, id); err != nil {
+			return err
+		}
+
+		restored, err := s.Repository.GetIncomeByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		// Si el usuario no puede restaurarlo, el error deshace la restauración
+		if err := s.authorize(ctx, restored, true); err != nil {
+			return err
+		}
+		restoredIncome = restored
+
+		return s.Audit.Record(ctx, audit.EntityIncome, id, audit.ActionRestore, nil, restored)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return restoredIncome, nil
+}
+
+// scope es el alcance del usuario que hace la petición.
+func (s *service) scope(ctx context.Context) (household.Scope, error) {
+	return s.Households.Scope(ctx, actor.FromContext(ctx).UserID)
+}
+
+// authorize devuelve ErrIncomeNotFound si el usuario no puede ver el ingreso, para
+// no revelar que existe, y ErrForbidden si puede verlo pero no modificarlo.
+func (s *service) authorize(ctx context.Context, i *Income, write bool) error {
+	scope, err := s.scope(ctx)
 	if err != nil {
 		return err
 	}
 
+	if !scope.CanRead(i.UserID, i.HouseholdID) {
+		return ErrIncomeNotFound
+	}
+	if write && !scope.CanWrite(i.UserID, i.HouseholdID) {
+		return ErrForbidden
+	}
+
 	return nil
 }
+
+func (s *service) PurgeDeletedIncomes(ctx context.Context, before time.Time) (int, error) {
+	ctx, span := tracing.Start(ctx, "income.PurgeDeletedIncomes")
+	defer span.End()
+
+	purged, err := s.Repository.PurgeDeletedIncomes(ctx, before)
+	if err != nil {
+		return 0, err
+	}
+
+	return purged, nil
+}
@@ -2,18 +2,35 @@ package payment
 
 import (
 	"context"
+	"time"
+
+	"github.com/payvue/payvue-backend/pkg/domain/audit"
+	"github.com/payvue/payvue-backend/pkg/domain/household"
+	"github.com/payvue/payvue-backend/pkg/utils/signedtoken"
 )
 
 type Container struct {
 	Repository
+	Audit      audit.Recorder
+	Households household.Scoper
+	// ReceiptSigner firma las URLs de los recibos, que el frontend carga con
+	// <img src> sin las cabeceras de autenticación.
+	ReceiptSigner *signedtoken.Signer
 }
 
 type Repository interface {
 	CreatePayment(ctx context.Context, payment *Payment) (*Payment, error)
 	GetAllPayments(ctx context.Context) ([]PaymentWithDebt, error)
-	GetPaymentsByUserID(ctx context.Context, userID int) ([]PaymentWithDebt, error)
+	// GetPaymentsInScope lista los pagos personales del usuario y los de las deudas de sus hogares.
+	GetPaymentsInScope(ctx context.Context, scope household.Scope) ([]PaymentWithDebt, error)
 	GetPaymentByID(ctx context.Context, id int) (*Payment, error)
+	// GetPaymentByReceipt devuelve ErrPaymentNotFound si ningún pago del ámbito tiene ese recibo.
+	GetPaymentByReceipt(ctx context.Context, filename string, scope household.Scope) (*Payment, error)
+	GetDebtBalance(ctx context.Context, debtID int) (*DebtBalance, error)
 	DeletePayment(ctx context.Context, id int) error
+	GetDeletedPayments(ctx context.Context, scope household.Scope) ([]PaymentWithDebt, error)
+	RestorePayment(ctx context.Context, id int) error
+	PurgeDeletedPayments(ctx context.Context, before time.Time) ([]string, error)
 }
 
 type PaymentWithDebt struct {
@@ -22,3 +39,12 @@ type PaymentWithDebt struct {
 	DebtRemainingAmount   float64
 	DebtInstallmentAmount float64
 }
+
+// DebtBalance es el estado de la deuda que modifica un pago.
+type DebtBalance struct {
+	DebtID          int     `json:"debt_id"`
+	UserID          int     `json:"-"`
+	HouseholdID     int     `json:"-"`
+	RemainingAmount float64 `json:"remaining_amount"`
+	Paid            bool    `json:"paid"`
+}
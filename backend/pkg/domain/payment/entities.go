@@ -5,14 +5,16 @@ import (
 )
 
 type Payment struct {
-	ID              int       `json:"id"`
-	UserID          int       `json:"user_id"`
-	Amount          float64   `json:"amount"`
-	DebtID          int       `json:"debt_id"`
-	ReceiptFilename string    `json:"receipt_filename"`
-	Date            time.Time `json:"date"`
-	CreatedAt       time.Time `json:"created_at"`
-	UpdatedAt       time.Time `json:"updated_at"`
+	ID              int        `json:"id"`
+	UserID          int        `json:"user_id"`
+	HouseholdID     int        `json:"household_id"`
+	Amount          float64    `json:"amount"`
+	DebtID          int        `json:"debt_id"`
+	ReceiptFilename string     `json:"receipt_filename"`
+	Date            time.Time  `json:"date"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+	DeletedAt       *time.Time `json:"deleted_at,omitempty"`
 }
 
 type CreatePaymentRequest struct {
@@ -29,6 +31,7 @@ type PaymentListResponse struct {
 type PaymentResponse struct {
 	ID                    int     `json:"id"`
 	DebtID                int     `json:"debt_id"`
+	HouseholdID           int     `json:"household_id,omitempty"`
 	Amount                float64 `json:"amount"`
 	Date                  string  `json:"date"`
 	CreatedAt             string  `json:"created_at"`
@@ -36,4 +39,5 @@ type PaymentResponse struct {
 	RemainingInstallments int     `json:"remaining_installments"`
 	RemainingAmount       float64 `json:"remaining_amount"`
 	ReceiptURL            string  `json:"receipt_url"`
+	DeletedAt             string  `json:"deleted_at,omitempty"`
 }
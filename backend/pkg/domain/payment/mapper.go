@@ -2,7 +2,15 @@ package payment
 
 import "math"
 
+// ReceiptPath es la ruta de descarga de los recibos, seguida del nombre del fichero.
+const ReceiptPath = "/finances/payment/receipt/"
+
 func ToPaymentResponse(pwd PaymentWithDebt) PaymentResponse {
+	deletedAt := ""
+	if pwd.DeletedAt != nil {
+		deletedAt = pwd.DeletedAt.Format("2006-01-02")
+	}
+
 	remainingInstallments := 0
 	if pwd.DebtInstallmentAmount > 0 {
 		remainingInstallments = int(math.Floor(pwd.DebtRemainingAmount / pwd.DebtInstallmentAmount))
@@ -10,12 +18,13 @@ func ToPaymentResponse(pwd PaymentWithDebt) PaymentResponse {
 
 	receiptURL := ""
 	if pwd.ReceiptFilename != "" {
-		receiptURL = "/finances/payment/receipt/" + pwd.ReceiptFilename
+		receiptURL = ReceiptPath + pwd.ReceiptFilename
 	}
 
 	return PaymentResponse{
 		ID:                    pwd.ID,
 		DebtID:                pwd.DebtID,
+		HouseholdID:           pwd.HouseholdID,
 		Amount:                pwd.Amount,
 		Date:                  pwd.Date.Format("2006-01-02"),
 		CreatedAt:             pwd.CreatedAt.Format("2006-01-02"),
@@ -23,6 +32,7 @@ func ToPaymentResponse(pwd PaymentWithDebt) PaymentResponse {
 		RemainingInstallments: remainingInstallments,
 		RemainingAmount:       pwd.DebtRemainingAmount,
 		ReceiptURL:            receiptURL,
+		DeletedAt:             deletedAt,
 	}
 }
 
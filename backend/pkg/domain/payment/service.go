@@ -3,7 +3,15 @@ package payment
 import (
 	"context"
 	"errors"
+	"net/url"
+	"strconv"
+	"strings"
 	"time"
+
+	"github.com/payvue/payvue-backend/pkg/domain/audit"
+	"github.com/payvue/payvue-backend/pkg/domain/household"
+	"github.com/payvue/payvue-backend/pkg/tracing"
+	"github.com/payvue/payvue-backend/pkg/utils/actor"
 )
 
 var (
@@ -11,14 +19,39 @@ var (
 	ErrInvalidPaymentData = errors.New("invalid payment data")
 	ErrDatabaseError      = errors.New("database error")
 	ErrDebtNotFound       = errors.New("debt not found")
+	ErrForbidden          = errors.New("payment is read-only for this user")
+	ErrInvalidReceiptLink = errors.New("invalid or expired receipt link")
+)
+
+const (
+	// ReceiptURLTTL es lo que vale la URL firmada de un recibo.
+	ReceiptURLTTL  = 15 * time.Minute
+	receiptPurpose = "receipt"
 )
 
 type Service interface {
 	CreatePayment(ctx context.Context, request CreatePaymentRequest, filename string) (*Payment, error)
+	// GetAllPayments lista los pagos visibles para el usuario de la petición; sin
+	// usuario identificado no devuelve ninguno.
 	GetAllPayments(ctx context.Context) ([]PaymentWithDebt, error)
+	// GetPaymentsByUserID lista los pagos personales del usuario y los de sus hogares.
 	GetPaymentsByUserID(ctx context.Context, userID int) ([]PaymentWithDebt, error)
 	GetPaymentByID(ctx context.Context, id int) (*Payment, error)
 	DeletePayment(ctx context.Context, id int) error
+	// GetDeletedPayments lista la papelera del usuario y de sus hogares; con userID 0
+	// devuelve la de todos los usuarios.
+	GetDeletedPayments(ctx context.Context, userID int) ([]PaymentWithDebt, error)
+	RestorePayment(ctx context.Context, id int) (*Payment, error)
+	// PurgeDeletedPayments devuelve los nombres de los recibos de los pagos purgados.
+	PurgeDeletedPayments(ctx context.Context, before time.Time) ([]string, error)
+	// GetReceipt devuelve el pago del recibo si está en el ámbito de userID.
+	GetReceipt(ctx context.Context, userID int, filename string) (*Payment, error)
+	// SignReceiptURLs añade a las URLs de los recibos un token que identifica a
+	// userID durante ReceiptURLTTL.
+	SignReceiptURLs(userID int, payments []PaymentResponse) error
+	// ReceiptLinkUser devuelve el usuario de una URL firmada por SignReceiptURLs, o
+	// ErrInvalidReceiptLink si no es de ese recibo o ha caducado.
+	ReceiptLinkUser(token, filename string) (int, error)
 }
 
 type service struct {
@@ -32,6 +65,9 @@ func New(container *Container) Service {
 }
 
 func (s *service) CreatePayment(ctx context.Context, request CreatePaymentRequest, filename string) (*Payment, error) {
+	ctx, span := tracing.Start(ctx, "payment.CreatePayment")
+	defer span.End()
+
 	var date time.Time
 	var err error
 
@@ -54,7 +90,44 @@ func (s *service) CreatePayment(ctx context.Context, request CreatePaymentReques
 		UpdatedAt:       time.Now(),
 	}
 
-	createdPayment, err := s.Repository.CreatePayment(ctx, payment)
+	var createdPayment *Payment
+	err = s.Audit.WithinTx(ctx, func(ctx context.Context) error {
+		balanceBefore, err := s.Repository.GetDebtBalance(ctx, payment.DebtID)
+		if err != nil {
+			return err
+		}
+
+		// El pago hereda el hogar de la deuda y exige poder modificarla
+		scope, err := s.scope(ctx)
+		if err != nil {
+			return err
+		}
+		if !scope.CanRead(balanceBefore.UserID, balanceBefore.HouseholdID) {
+			return ErrDebtNotFound
+		}
+		if !scope.CanWrite(balanceBefore.UserID, balanceBefore.HouseholdID) {
+			return ErrForbidden
+		}
+		payment.HouseholdID = balanceBefore.HouseholdID
+
+		created, err := s.Repository.CreatePayment(ctx, payment)
+		if err != nil {
+			return err
+		}
+		createdPayment = created
+
+		balanceAfter, err := s.Repository.GetDebtBalance(ctx, payment.DebtID)
+		if err != nil {
+			return err
+		}
+
+		if err := s.Audit.Record(ctx, audit.EntityPayment, created.ID, audit.ActionCreate, nil, created); err != nil {
+			return err
+		}
+
+		// El pago descuenta el saldo de la deuda, así que también queda registrado
+		return s.Audit.Record(ctx, audit.EntityDebt, payment.DebtID, audit.ActionUpdate, balanceBefore, balanceAfter)
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -63,16 +136,31 @@ func (s *service) CreatePayment(ctx context.Context, request CreatePaymentReques
 }
 
 func (s *service) GetAllPayments(ctx context.Context) ([]PaymentWithDebt, error) {
-	payments, err := s.Repository.GetAllPayments(ctx)
+	ctx, span := tracing.Start(ctx, "payment.GetAllPayments")
+	defer span.End()
+
+	scope, err := s.scope(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	return payments, nil
+	if scope.Unrestricted() {
+		return s.Repository.GetAllPayments(ctx)
+	}
+
+	return s.Repository.GetPaymentsInScope(ctx, scope)
 }
 
 func (s *service) GetPaymentsByUserID(ctx context.Context, userID int) ([]PaymentWithDebt, error) {
-	payments, err := s.Repository.GetPaymentsByUserID(ctx, userID)
+	ctx, span := tracing.Start(ctx, "payment.GetPaymentsByUserID")
+	defer span.End()
+
+	scope, err := s.Households.Scope(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	payments, err := s.Repository.GetPaymentsInScope(ctx, scope)
 	if err != nil {
 		return nil, err
 	}
@@ -81,24 +169,161 @@ func (s *service) GetPaymentsByUserID(ctx context.Context, userID int) ([]Paymen
 }
 
 func (s *service) GetPaymentByID(ctx context.Context, id int) (*Payment, error) {
+	ctx, span := tracing.Start(ctx, "payment.GetPaymentByID")
+	defer span.End()
+
 	payment, err := s.Repository.GetPaymentByID(ctx, id)
 	if err != nil {
 		return nil, err
 	}
 
+	if err := s.authorize(ctx, payment, false); err != nil {
+		return nil, err
+	}
+
 	return payment, nil
 }
 
 func (s *service) DeletePayment(ctx context.Context, id int) error {
-	_, err := s.Repository.GetPaymentByID(ctx, id)
+	ctx, span := tracing.Start(ctx, "payment.DeletePayment")
+	defer span.End()
+
+	return s.Audit.WithinTx(ctx, func(ctx context.Context) error {
+		existingPayment, err := s.Repository.GetPaymentByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		if err := s.authorize(ctx, existingPayment, true); err != nil {
+			return err
+		}
+
+		err = s.Repository.DeletePayment(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		return s.Audit.Record(ctx, audit.EntityPayment, id, audit.ActionDelete, existingPayment, nil)
+	})
+}
+
+func (s *service) GetDeletedPayments(ctx context.Context, userID int) ([]PaymentWithDebt, error) {
+	ctx, span := tracing.Start(ctx, "payment.GetDeletedPayments")
+	defer span.End()
+
+	scope, err := s.Households.Scope(ctx, userID)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	err = s.Repository.DeletePayment(ctx, id)
+	payments, err := s.Repository.GetDeletedPayments(ctx, scope)
+	if err != nil {
+		return nil, err
+	}
+
+	return payments, nil
+}
+
+func (s *service) RestorePayment(ctx context.Context, id int) (*Payment, error) {
+	ctx, span := tracing.Start(ctx, "payment.RestorePayment")
+	defer span.End()
+
+	var restoredPayment *Payment
+	err := s.Audit.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.Repository.RestorePayment(ctx, id); err != nil {
+			return err
+		}
+
+		restored, err := s.Repository.GetPaymentByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		// Si el usuario no puede restaurarlo, el error deshace la restauración
+		if err := s.authorize(ctx, restored, true); err != nil {
+			return err
+		}
+		restoredPayment = restored
+
+		return s.Audit.Record(ctx, audit.EntityPayment, id, audit.ActionRestore, nil, restored)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return restoredPayment, nil
+}
+
+// scope es el alcance del usuario que hace la petición.
+func (s *service) scope(ctx context.Context) (household.Scope, error) {
+	return s.Households.Scope(ctx, actor.FromContext(ctx).UserID)
+}
+
+// authorize devuelve ErrPaymentNotFound si el usuario no puede ver el pago, para
+// no revelar que existe, y ErrForbidden si puede verlo pero no modificarlo.
+func (s *service) authorize(ctx context.Context, p *Payment, write bool) error {
+	scope, err := s.scope(ctx)
 	if err != nil {
 		return err
 	}
 
+	if !scope.CanRead(p.UserID, p.HouseholdID) {
+		return ErrPaymentNotFound
+	}
+	if write && !scope.CanWrite(p.UserID, p.HouseholdID) {
+		return ErrForbidden
+	}
+
+	return nil
+}
+
+func (s *service) PurgeDeletedPayments(ctx context.Context, before time.Time) ([]string, error) {
+	ctx, span := tracing.Start(ctx, "payment.PurgeDeletedPayments")
+	defer span.End()
+
+	filenames, err := s.Repository.PurgeDeletedPayments(ctx, before)
+	if err != nil {
+		return nil, err
+	}
+
+	return filenames, nil
+}
+
+func (s *service) GetReceipt(ctx context.Context, userID int, filename string) (*Payment, error) {
+	ctx, span := tracing.Start(ctx, "payment.GetReceipt")
+	defer span.End()
+
+	scope, err := s.Households.Scope(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.Repository.GetPaymentByReceipt(ctx, filename, scope)
+}
+
+func (s *service) SignReceiptURLs(userID int, payments []PaymentResponse) error {
+	expiresAt := time.Now().Add(ReceiptURLTTL)
+	for i := range payments {
+		if payments[i].ReceiptURL == "" {
+			continue
+		}
+		filename := strings.TrimPrefix(payments[i].ReceiptURL, ReceiptPath)
+		token, err := s.ReceiptSigner.Sign(receiptPurpose, strconv.Itoa(userID)+"/"+filename, expiresAt)
+		if err != nil {
+			return err
+		}
+		payments[i].ReceiptURL += "?token=" + url.QueryEscape(token)
+	}
 	return nil
 }
+
+func (s *service) ReceiptLinkUser(token, filename string) (int, error) {
+	subject, err := s.ReceiptSigner.Verify(receiptPurpose, token, time.Now())
+	if err != nil {
+		return 0, ErrInvalidReceiptLink
+	}
+	id, signed, ok := strings.Cut(subject, "/")
+	userID, err := strconv.Atoi(id)
+	if !ok || err != nil || signed != filename {
+		return 0, ErrInvalidReceiptLink
+	}
+	return userID, nil
+}
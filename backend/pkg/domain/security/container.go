@@ -0,0 +1,32 @@
+package security
+
+import (
+	"context"
+	"time"
+)
+
+type Container struct {
+	Repository
+	Policy Policy
+}
+
+type Repository interface {
+	GetAttempt(ctx context.Context, scope, key string) (*Attempt, error)
+	// AddFailure suma un fallo y devuelve el contador resultante; si el último fallo
+	// es anterior a windowStart el contador vuelve a empezar en 1.
+	AddFailure(ctx context.Context, scope, key string, at, windowStart time.Time) (int, error)
+	LockAttempt(ctx context.Context, scope, key string, until time.Time) error
+	DeleteAttempt(ctx context.Context, scope, key string) error
+	PurgeAttempts(ctx context.Context, before time.Time) (int, error)
+	CreateEvent(ctx context.Context, event *Event) error
+	GetEventsByUserID(ctx context.Context, userID int, limit int) ([]Event, error)
+}
+
+// LoginGuard es lo que necesita el servicio de usuarios para proteger el login.
+type LoginGuard interface {
+	// CheckLogin devuelve un *LockoutError si el email o la IP están bloqueados.
+	CheckLogin(ctx context.Context, email, ip string) error
+	// LoginFailed registra un fallo; userID es 0 si el email no corresponde a ningún usuario.
+	LoginFailed(ctx context.Context, email, ip string, userID int) error
+	LoginSucceeded(ctx context.Context, email, ip string) error
+}
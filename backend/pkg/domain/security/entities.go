@@ -0,0 +1,62 @@
+package security
+
+import (
+	"encoding/json"
+	"time"
+)
+
+const (
+	ScopeEmail = "email"
+	ScopeIP    = "ip"
+)
+
+const (
+	EventAccountLocked = "account_locked"
+	EventIPLocked      = "ip_locked"
+)
+
+// Attempt acumula los fallos de login de un email o de una IP.
+type Attempt struct {
+	Scope         string     `json:"scope"`
+	Key           string     `json:"key"`
+	Failures      int        `json:"failures"`
+	LastFailureAt time.Time  `json:"last_failure_at"`
+	LockedUntil   *time.Time `json:"locked_until,omitempty"`
+}
+
+// Event es un suceso de seguridad visible para el usuario afectado.
+type Event struct {
+	ID        int             `json:"id"`
+	UserID    int             `json:"user_id"`
+	Type      string          `json:"type"`
+	IP        string          `json:"ip"`
+	Details   json.RawMessage `json:"details,omitempty"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+// Policy define cuántos fallos se toleran y cuánto duran los bloqueos.
+type Policy struct {
+	// MaxFailures es el número de fallos por email a partir del cual se bloquea la cuenta.
+	MaxFailures int
+	// IPMaxFailures es el equivalente por IP, más alto para no castigar redes compartidas.
+	IPMaxFailures int
+	// Backoff es la espera tras el primer fallo; se duplica con cada fallo siguiente.
+	Backoff time.Duration
+	// Lockout es la duración del primer bloqueo; se duplica con cada fallo posterior.
+	Lockout    time.Duration
+	MaxLockout time.Duration
+	// Window es el tiempo sin fallos tras el cual el contador vuelve a empezar.
+	Window time.Duration
+}
+
+type EventListResponse struct {
+	Events []EventResponse `json:"events"`
+}
+
+type EventResponse struct {
+	ID        int             `json:"id"`
+	Type      string          `json:"type"`
+	IP        string          `json:"ip"`
+	Details   json.RawMessage `json:"details,omitempty"`
+	CreatedAt string          `json:"created_at"`
+}
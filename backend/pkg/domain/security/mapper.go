@@ -0,0 +1,24 @@
+package security
+
+import "time"
+
+func ToEventResponse(event *Event) EventResponse {
+	return EventResponse{
+		ID:        event.ID,
+		Type:      event.Type,
+		IP:        event.IP,
+		Details:   event.Details,
+		CreatedAt: event.CreatedAt.Format(time.RFC3339),
+	}
+}
+
+func ToEventListResponse(events []Event) EventListResponse {
+	responses := make([]EventResponse, len(events))
+	for i, event := range events {
+		responses[i] = ToEventResponse(&event)
+	}
+
+	return EventListResponse{
+		Events: responses,
+	}
+}
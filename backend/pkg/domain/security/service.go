@@ -0,0 +1,211 @@
+package security
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/payvue/payvue-backend/pkg/tracing"
+)
+
+const (
+	DefaultEventLimit = 50
+	MaxEventLimit     = 200
+)
+
+var (
+	ErrTooManyAttempts = errors.New("too many login attempts")
+	ErrDatabaseError   = errors.New("database error")
+	ErrAttemptNotFound = errors.New("login attempt not found")
+)
+
+// LockoutError indica hasta cuándo está bloqueado el login. errors.Is(err, ErrTooManyAttempts) es true.
+type LockoutError struct {
+	Until time.Time
+}
+
+func (e *LockoutError) Error() string {
+	return fmt.Sprintf("%s: retry after %s", ErrTooManyAttempts, e.Until.Format(time.RFC3339))
+}
+
+func (e *LockoutError) Unwrap() error {
+	return ErrTooManyAttempts
+}
+
+// RetryAfter devuelve los segundos que faltan para poder reintentar, como mínimo 1.
+func (e *LockoutError) RetryAfter() int {
+	seconds := int(time.Until(e.Until).Seconds()) + 1
+	if seconds < 1 {
+		return 1
+	}
+	return seconds
+}
+
+type Service interface {
+	LoginGuard
+	GetEvents(ctx context.Context, userID int, limit int) ([]Event, error)
+	PurgeAttempts(ctx context.Context, before time.Time) (int, error)
+}
+
+type service struct {
+	*Container
+}
+
+func New(container *Container) Service {
+	return &service{
+		Container: container,
+	}
+}
+
+func DefaultPolicy() Policy {
+	return Policy{
+		MaxFailures:   5,
+		IPMaxFailures: 20,
+		Backoff:       time.Second,
+		Lockout:       15 * time.Minute,
+		MaxLockout:    24 * time.Hour,
+		Window:        24 * time.Hour,
+	}
+}
+
+func (s *service) CheckLogin(ctx context.Context, email, ip string) error {
+	ctx, span := tracing.Start(ctx, "security.CheckLogin")
+	defer span.End()
+
+	now := time.Now()
+	for _, target := range loginTargets(email, ip) {
+		attempt, err := s.Repository.GetAttempt(ctx, target.scope, target.key)
+		if err != nil {
+			if err == ErrAttemptNotFound {
+				continue
+			}
+			return err
+		}
+		if attempt.LockedUntil != nil && attempt.LockedUntil.After(now) {
+			return &LockoutError{Until: *attempt.LockedUntil}
+		}
+	}
+
+	return nil
+}
+
+func (s *service) LoginFailed(ctx context.Context, email, ip string, userID int) error {
+	ctx, span := tracing.Start(ctx, "security.LoginFailed")
+	defer span.End()
+
+	now := time.Now()
+	for _, target := range loginTargets(email, ip) {
+		failures, err := s.Repository.AddFailure(ctx, target.scope, target.key, now, now.Add(-s.Policy.Window))
+		if err != nil {
+			return err
+		}
+
+		maxFailures := s.Policy.MaxFailures
+		if target.scope == ScopeIP {
+			maxFailures = s.Policy.IPMaxFailures
+		}
+
+		// Por IP no hay backoff previo al bloqueo para no frenar a otros usuarios de una red compartida
+		if failures < maxFailures && target.scope == ScopeIP {
+			continue
+		}
+
+		delay := s.delay(failures, maxFailures)
+		if err := s.Repository.LockAttempt(ctx, target.scope, target.key, now.Add(delay)); err != nil {
+			return err
+		}
+
+		if failures < maxFailures {
+			continue
+		}
+
+		eventType := EventAccountLocked
+		if target.scope == ScopeIP {
+			eventType = EventIPLocked
+		}
+		details, _ := json.Marshal(map[string]interface{}{
+			"failures":     failures,
+			"locked_until": now.Add(delay).Format(time.RFC3339),
+		})
+		err = s.Repository.CreateEvent(ctx, &Event{
+			UserID:    userID,
+			Type:      eventType,
+			IP:        ip,
+			Details:   details,
+			CreatedAt: now,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *service) LoginSucceeded(ctx context.Context, email, ip string) error {
+	ctx, span := tracing.Start(ctx, "security.LoginSucceeded")
+	defer span.End()
+
+	// Solo se limpia el contador del email: el de la IP caduca con la ventana
+	return s.Repository.DeleteAttempt(ctx, ScopeEmail, normalizeEmail(email))
+}
+
+func (s *service) GetEvents(ctx context.Context, userID int, limit int) ([]Event, error) {
+	ctx, span := tracing.Start(ctx, "security.GetEvents")
+	defer span.End()
+
+	if limit <= 0 {
+		limit = DefaultEventLimit
+	}
+	if limit > MaxEventLimit {
+		limit = MaxEventLimit
+	}
+
+	return s.Repository.GetEventsByUserID(ctx, userID, limit)
+}
+
+func (s *service) PurgeAttempts(ctx context.Context, before time.Time) (int, error) {
+	ctx, span := tracing.Start(ctx, "security.PurgeAttempts")
+	defer span.End()
+
+	return s.Repository.PurgeAttempts(ctx, before)
+}
+
+// delay calcula la espera tras el fallo número failures: backoff exponencial
+// hasta maxFailures y, a partir de ahí, bloqueos que también se duplican.
+func (s *service) delay(failures, maxFailures int) time.Duration {
+	base, exponent := s.Policy.Backoff, failures-1
+	if failures >= maxFailures {
+		base, exponent = s.Policy.Lockout, failures-maxFailures
+	}
+
+	delay := base
+	for i := 0; i < exponent && delay < s.Policy.MaxLockout; i++ {
+		delay *= 2
+	}
+	if delay > s.Policy.MaxLockout {
+		delay = s.Policy.MaxLockout
+	}
+
+	return delay
+}
+
+type loginTarget struct {
+	scope string
+	key   string
+}
+
+func loginTargets(email, ip string) []loginTarget {
+	targets := []loginTarget{{scope: ScopeEmail, key: normalizeEmail(email)}}
+	if ip != "" {
+		targets = append(targets, loginTarget{scope: ScopeIP, key: ip})
+	}
+	return targets
+}
+
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
@@ -2,14 +2,72 @@ package user
 
 import (
 	"context"
+	"time"
+
+	"github.com/payvue/payvue-backend/pkg/domain/security"
+	"github.com/payvue/payvue-backend/pkg/utils/mailer"
+	"github.com/payvue/payvue-backend/pkg/utils/oidc"
+	"github.com/payvue/payvue-backend/pkg/utils/signedtoken"
 )
 
 type Container struct {
 	Repository
+	Guard security.LoginGuard
+	// IdentityProvider es nil si no hay un proveedor OpenID Connect configurado.
+	IdentityProvider  IdentityProvider
+	Mailer            mailer.Mailer
+	EmailVerification EmailVerification
+}
+
+// EmailVerification configura los correos de verificación que se envían al registrarse.
+type EmailVerification struct {
+	// Signer firma los enlaces de verificación y las pruebas de reautenticación
+	// de las cuentas sin contraseña; cada uso con su propósito.
+	Signer *signedtoken.Signer
+	// URL es el enlace del correo; el token se añade como parámetro "token".
+	URL string
+	TTL time.Duration
+	// Required impide iniciar sesión, y usar la API con una sesión o un token
+	// anteriores, hasta que el usuario verifique su correo.
+	Required bool
+}
+
+// VerificationChecker es lo que necesita el middleware HTTP para aplicar
+// EmailVerification.Required a todas las peticiones, no solo al login.
+type VerificationChecker interface {
+	// CheckEmailVerified devuelve ErrEmailNotVerified si la verificación es
+	// obligatoria y el usuario aún no ha verificado su correo.
+	CheckEmailVerified(ctx context.Context, userID int) error
+}
+
+type IdentityProvider interface {
+	Name() string
+	AuthCodeURL(ctx context.Context, state, nonce, codeVerifier string) (string, error)
+	// Exchange canjea el código y devuelve los claims del ID token ya verificados.
+	Exchange(ctx context.Context, code, codeVerifier, nonce string) (*oidc.Claims, error)
 }
 
 type Repository interface {
 	CreateUser(ctx context.Context, user *User) (*User, error)
 	GetUserByEmail(ctx context.Context, email string) (*User, error)
 	GetUserByID(ctx context.Context, id int) (*User, error)
+	MarkEmailVerified(ctx context.Context, userID int, verifiedAt time.Time) error
+	UpdateTwoFactor(ctx context.Context, user *User) error
+	// MarkTOTPStepUsed devuelve ErrInvalidTwoFactorCode si el periodo ya se usó.
+	MarkTOTPStepUsed(ctx context.Context, userID int, step int64) error
+	ReplaceRecoveryCodes(ctx context.Context, userID int, codeHashes []string) error
+	// UseRecoveryCode devuelve ErrInvalidTwoFactorCode si el código no existe o ya se usó.
+	UseRecoveryCode(ctx context.Context, userID int, codeHash string) error
+	DeleteRecoveryCodes(ctx context.Context, userID int) error
+	CreateChallenge(ctx context.Context, challenge *Challenge) error
+	GetChallenge(ctx context.Context, tokenHash string) (*Challenge, error)
+	IncrementChallengeAttempts(ctx context.Context, tokenHash string) error
+	DeleteChallenge(ctx context.Context, tokenHash string) error
+	GetIdentity(ctx context.Context, provider, subject string) (*Identity, error)
+	// CreateIdentity crea también el usuario si user.ID es 0, en la misma transacción.
+	CreateIdentity(ctx context.Context, user *User, identity *Identity) error
+	TouchIdentity(ctx context.Context, identityID int, lastLoginAt time.Time) error
+	CreateExternalLoginState(ctx context.Context, state *ExternalLoginState) error
+	// ConsumeExternalLoginState borra y devuelve el estado; solo se puede usar una vez.
+	ConsumeExternalLoginState(ctx context.Context, stateHash string) (*ExternalLoginState, error)
 }
@@ -5,11 +5,18 @@ import (
 )
 
 type User struct {
-	ID           int       `json:"id"`
-	Email        string    `json:"email"`
-	PasswordHash string    `json:"-"` // No exponer en JSON
-	CreatedAt    time.Time `json:"created_at"`
-	UpdatedAt    time.Time `json:"updated_at"`
+	ID               int    `json:"id"`
+	Email            string `json:"email"`
+	PasswordHash     string `json:"-"` // No exponer en JSON
+	TOTPSecret       string `json:"-"`
+	TwoFactorEnabled bool   `json:"two_factor_enabled"`
+	TOTPLastStep     int64  `json:"-"` // Último periodo TOTP aceptado, para no reutilizar códigos
+	// EmailVerifiedAt es nil mientras el usuario no confirme su correo.
+	EmailVerifiedAt *time.Time `json:"email_verified_at,omitempty"`
+	// DeletionScheduledAt es la fecha en la que se borrará la cuenta; nil si no se pidió.
+	DeletionScheduledAt *time.Time `json:"deletion_scheduled_at,omitempty"`
+	CreatedAt           time.Time  `json:"created_at"`
+	UpdatedAt           time.Time  `json:"updated_at"`
 }
 
 type RegisterRequest struct {
@@ -22,7 +29,102 @@ type LoginRequest struct {
 	Password string `json:"password" validate:"required"`
 }
 
+type ResendVerificationRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
 type UserResponse struct {
 	ID    int    `json:"id"`
 	Email string `json:"email"`
 }
+
+// Challenge es el paso intermedio de un login con verificación en dos pasos:
+// la contraseña ya se comprobó y falta el código.
+type Challenge struct {
+	TokenHash string    `json:"-"`
+	UserID    int       `json:"user_id"`
+	Attempts  int       `json:"attempts"`
+	ExpiresAt time.Time `json:"expires_at"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type LoginTwoFactorRequest struct {
+	ChallengeToken string `json:"challenge_token" validate:"required"`
+	// Code es un código TOTP de 6 dígitos o un código de recuperación.
+	Code string `json:"code" validate:"required"`
+}
+
+// ReauthenticateRequest pide de nuevo la contraseña y el segundo factor para
+// operaciones sensibles como desactivar 2FA. Las cuentas creadas con el
+// proveedor externo no tienen contraseña y solo dan el código.
+type ReauthenticateRequest struct {
+	Password string `json:"password"`
+	Code     string `json:"code" validate:"required"`
+}
+
+type TwoFactorEnrollment struct {
+	Secret string `json:"secret"`
+	URI    string `json:"otpauth_uri"`
+}
+
+type TwoFactorChallengeResponse struct {
+	Message           string `json:"message"`
+	TwoFactorRequired bool   `json:"two_factor_required"`
+	ChallengeToken    string `json:"challenge_token"`
+	ExpiresAt         string `json:"expires_at"`
+}
+
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+// Identity vincula un usuario con su cuenta en un proveedor OpenID Connect.
+type Identity struct {
+	ID          int       `json:"id"`
+	UserID      int       `json:"user_id"`
+	Provider    string    `json:"provider"`
+	Subject     string    `json:"subject"`
+	Email       string    `json:"email"`
+	CreatedAt   time.Time `json:"created_at"`
+	LastLoginAt time.Time `json:"last_login_at"`
+}
+
+// ExternalLoginState guarda entre la redirección al proveedor y el callback lo
+// necesario para completar el flujo: el code_verifier de PKCE y el nonce.
+type ExternalLoginState struct {
+	StateHash    string `json:"-"`
+	CodeVerifier string `json:"-"`
+	Nonce        string `json:"-"`
+	// LinkUserID es el usuario que pidió vincular la identidad; 0 en un login normal.
+	LinkUserID int       `json:"link_user_id"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type ExternalLoginRequest struct {
+	Code  string `json:"code" validate:"required"`
+	State string `json:"state" validate:"required"`
+}
+
+type ExternalLoginResponse struct {
+	AuthorizationURL string `json:"authorization_url"`
+}
+
+// ExternalLogin es el resultado de un login con el proveedor externo.
+type ExternalLogin struct {
+	User *User
+	// ReauthenticationToken solo se emite a las cuentas sin contraseña: prueba
+	// durante ReauthenticationTTL que el usuario acaba de entrar con el proveedor.
+	ReauthenticationToken string
+}
+
+// Credentials vuelven a probar la identidad del usuario antes de una operación
+// sensible. Las cuentas con contraseña la envían en Password; las creadas con el
+// proveedor externo, el ReauthenticationToken de un login externo reciente o,
+// si tienen 2FA activa, solo el código.
+type Credentials struct {
+	Password              string
+	ReauthenticationToken string
+	// Code es un código TOTP o de recuperación; solo hace falta con 2FA activa.
+	Code string
+}
@@ -2,24 +2,121 @@ package user
 
 import (
 	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/hex"
 	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
+	"github.com/payvue/payvue-backend/pkg/tracing"
+	"github.com/payvue/payvue-backend/pkg/utils/actor"
+	"github.com/payvue/payvue-backend/pkg/utils/mailer"
+	"github.com/payvue/payvue-backend/pkg/utils/oidc"
+	"github.com/payvue/payvue-backend/pkg/utils/totp"
 	"golang.org/x/crypto/bcrypt"
 )
 
+const (
+	// TOTPIssuer es el nombre que muestran las apps de autenticación.
+	TOTPIssuer           = "PayVue"
+	ChallengeTTL         = 5 * time.Minute
+	MaxChallengeAttempts = 5
+	RecoveryCodeCount    = 10
+	// ExternalLoginTTL es el tiempo que tiene el usuario para volver del proveedor.
+	ExternalLoginTTL = 10 * time.Minute
+	// emailVerificationPurpose separa estos tokens firmados de los de otros usos.
+	emailVerificationPurpose = "email_verification"
+	// ReauthenticationTTL es lo que vale la prueba de un login externo para
+	// confirmar una operación sensible.
+	ReauthenticationTTL     = 5 * time.Minute
+	reauthenticationPurpose = "reauthentication"
+)
+
 var (
 	ErrUserNotFound       = errors.New("user not found")
 	ErrInvalidCredentials = errors.New("invalid credentials")
 	ErrEmailAlreadyExists = errors.New("email already exists")
 	ErrDatabaseError      = errors.New("database error")
 	ErrHashingPassword    = errors.New("error hashing password")
+
+	ErrTwoFactorRequired       = errors.New("two-factor authentication required")
+	ErrTwoFactorAlreadyEnabled = errors.New("two-factor authentication already enabled")
+	ErrTwoFactorNotEnabled     = errors.New("two-factor authentication not enabled")
+	ErrTwoFactorNotEnrolled    = errors.New("two-factor enrollment not started")
+	ErrInvalidTwoFactorCode    = errors.New("invalid two-factor code")
+	ErrInvalidChallenge        = errors.New("invalid or expired login challenge")
+
+	ErrExternalLoginDisabled     = errors.New("external login not configured")
+	ErrInvalidExternalLoginState = errors.New("invalid or expired external login state")
+	ErrExternalLoginFailed       = errors.New("external login failed")
+	ErrIdentityNotFound          = errors.New("identity not found")
+	ErrIdentityAlreadyLinked     = errors.New("identity already linked to another user")
+	ErrUnverifiedExternalEmail   = errors.New("external account email not verified")
+
+	ErrEmailNotVerified         = errors.New("email not verified")
+	ErrReauthenticationRequired = errors.New("reauthentication required")
+	ErrInvalidVerificationToken = errors.New("invalid or expired verification token")
+	ErrVerificationEmailNotSent = errors.New("verification email could not be sent")
 )
 
+// TwoFactorRequiredError lo devuelve Login cuando la contraseña es correcta pero
+// falta el segundo factor. errors.Is(err, ErrTwoFactorRequired) es true.
+type TwoFactorRequiredError struct {
+	ChallengeToken string
+	ExpiresAt      time.Time
+}
+
+func (e *TwoFactorRequiredError) Error() string {
+	return ErrTwoFactorRequired.Error()
+}
+
+func (e *TwoFactorRequiredError) Unwrap() error {
+	return ErrTwoFactorRequired
+}
+
 type Service interface {
+	VerificationChecker
+	// Register crea el usuario y le envía el correo de verificación. Si el usuario
+	// se crea pero el correo falla devuelve el usuario junto con un error
+	// ErrVerificationEmailNotSent; puede pedirse otro con ResendVerification.
 	Register(ctx context.Context, request RegisterRequest) (*User, error)
 	Login(ctx context.Context, request LoginRequest) (*User, error)
+	// LoginTwoFactor completa un login iniciado con Login usando un código TOTP o de recuperación.
+	LoginTwoFactor(ctx context.Context, request LoginTwoFactorRequest) (*User, error)
 	GetUserByEmail(ctx context.Context, email string) (*User, error)
+
+	// VerifyEmail marca el correo como verificado a partir del token del enlace.
+	VerifyEmail(ctx context.Context, token string) (*User, error)
+	// ResendVerification envía otro correo de verificación. No indica si el correo
+	// existe o ya estaba verificado, para no revelar qué cuentas hay.
+	ResendVerification(ctx context.Context, request ResendVerificationRequest) error
+
+	// EnrollTwoFactor comprueba de nuevo las credenciales y genera un secreto TOTP
+	// nuevo que no se activa hasta EnableTwoFactor.
+	EnrollTwoFactor(ctx context.Context, userID int, credentials Credentials) (*TwoFactorEnrollment, error)
+	// EnableTwoFactor confirma el alta con un código y devuelve los códigos de recuperación.
+	EnableTwoFactor(ctx context.Context, userID int, code string) ([]string, error)
+	DisableTwoFactor(ctx context.Context, userID int, request ReauthenticateRequest) error
+	RegenerateRecoveryCodes(ctx context.Context, userID int, request ReauthenticateRequest) ([]string, error)
+	// ConfirmPassword comprueba de nuevo las credenciales y, si el usuario tiene
+	// 2FA activa, también el código. Es para operaciones irreversibles como borrar
+	// la cuenta. Las cuentas sin contraseña devuelven ErrReauthenticationRequired
+	// si no traen una prueba de un login externo reciente ni tienen 2FA.
+	ConfirmPassword(ctx context.Context, userID int, credentials Credentials) (*User, error)
+
+	// StartExternalLogin prepara el login con el proveedor OpenID Connect y devuelve
+	// la URL a la que redirigir. Con linkUserID > 0 la identidad se vincula a ese usuario.
+	StartExternalLogin(ctx context.Context, linkUserID int) (*ExternalLoginResponse, error)
+	// CompleteExternalLogin procesa el callback del proveedor. Si el usuario tiene
+	// 2FA activa devuelve un *TwoFactorRequiredError como Login.
+	CompleteExternalLogin(ctx context.Context, request ExternalLoginRequest) (*ExternalLogin, error)
 }
 
 type service struct {
@@ -33,6 +130,9 @@ func New(container *Container) Service {
 }
 
 func (s *service) Register(ctx context.Context, request RegisterRequest) (*User, error) {
+	ctx, span := tracing.Start(ctx, "user.Register")
+	defer span.End()
+
 	// Verificar si el email ya existe
 	existingUser, err := s.Repository.GetUserByEmail(ctx, request.Email)
 	if err != nil && err != ErrUserNotFound {
@@ -60,26 +160,653 @@ func (s *service) Register(ctx context.Context, request RegisterRequest) (*User,
 		return nil, err
 	}
 
+	if err := s.sendVerification(ctx, createdUser); err != nil {
+		return createdUser, fmt.Errorf("%w: %v", ErrVerificationEmailNotSent, err)
+	}
+
 	return createdUser, nil
 }
 
+// dummyPasswordHash es el hash con el que se compara la contraseña cuando no hay
+// usuario. Se calcula una vez, con el mismo coste que los de verdad.
+var dummyPasswordHash = sync.OnceValue(func() []byte {
+	hash, err := bcrypt.GenerateFromPassword([]byte("payvue-dummy-password"), bcrypt.DefaultCost)
+	if err != nil {
+		panic(err)
+	}
+	return hash
+})
+
+// Login devuelve un *security.LockoutError si el email o la IP están bloqueados
+// por demasiados intentos fallidos.
 func (s *service) Login(ctx context.Context, request LoginRequest) (*User, error) {
-	// Buscar usuario por email
+	ctx, span := tracing.Start(ctx, "user.Login")
+	defer span.End()
+
+	ip := actor.FromContext(ctx).IP
+
+	// Comprobar el bloqueo antes de gastar un bcrypt
+	if err := s.Guard.CheckLogin(ctx, request.Email, ip); err != nil {
+		return nil, err
+	}
+
+	// Solo cuentan como fallo un email desconocido y una contraseña incorrecta;
+	// un error de la base de datos no debe acabar bloqueando la cuenta
 	user, err := s.Repository.GetUserByEmail(ctx, request.Email)
-	if err != nil {
+	if err != nil && err != ErrUserNotFound {
+		return nil, err
+	}
+
+	// Con un email desconocido o sin contraseña (solo OIDC) también se gasta un
+	// bcrypt, para que el tiempo de respuesta no revele qué cuentas existen
+	hash, userID := dummyPasswordHash(), 0
+	if user != nil {
+		userID = user.ID
+		if user.PasswordHash != "" {
+			hash = []byte(user.PasswordHash)
+		}
+	}
+	err = bcrypt.CompareHashAndPassword(hash, []byte(request.Password))
+	if err != nil || user == nil || user.PasswordHash == "" {
+		if err := s.Guard.LoginFailed(ctx, request.Email, ip, userID); err != nil {
+			return nil, err
+		}
 		return nil, ErrInvalidCredentials
 	}
 
-	// Verificar contraseña
-	err = bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(request.Password))
+	if s.EmailVerification.Required && user.EmailVerifiedAt == nil {
+		return nil, ErrEmailNotVerified
+	}
+
+	if user.TwoFactorEnabled {
+		return nil, s.startChallenge(ctx, user)
+	}
+
+	if err := s.Guard.LoginSucceeded(ctx, request.Email, ip); err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+func (s *service) LoginTwoFactor(ctx context.Context, request LoginTwoFactorRequest) (*User, error) {
+	ctx, span := tracing.Start(ctx, "user.LoginTwoFactor")
+	defer span.End()
+
+	tokenHash := hashSecret(request.ChallengeToken)
+	challenge, err := s.Repository.GetChallenge(ctx, tokenHash)
 	if err != nil {
-		return nil, ErrInvalidCredentials
+		return nil, err
+	}
+	if time.Now().After(challenge.ExpiresAt) || challenge.Attempts >= MaxChallengeAttempts {
+		return nil, ErrInvalidChallenge
+	}
+
+	user, err := s.Repository.GetUserByID(ctx, challenge.UserID)
+	if err != nil {
+		return nil, ErrInvalidChallenge
+	}
+
+	ip := actor.FromContext(ctx).IP
+	if err := s.Guard.CheckLogin(ctx, user.Email, ip); err != nil {
+		return nil, err
+	}
+
+	if err := s.verifySecondFactor(ctx, user, request.Code); err != nil {
+		if err != ErrInvalidTwoFactorCode {
+			return nil, err
+		}
+		// Los códigos erróneos cuentan como fallos de login para el bloqueo
+		if err := s.Repository.IncrementChallengeAttempts(ctx, tokenHash); err != nil {
+			return nil, err
+		}
+		if err := s.Guard.LoginFailed(ctx, user.Email, ip, user.ID); err != nil {
+			return nil, err
+		}
+		return nil, ErrInvalidTwoFactorCode
+	}
+
+	if err := s.Repository.DeleteChallenge(ctx, tokenHash); err != nil {
+		return nil, err
+	}
+	if err := s.Guard.LoginSucceeded(ctx, user.Email, ip); err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+func (s *service) EnrollTwoFactor(ctx context.Context, userID int, credentials Credentials) (*TwoFactorEnrollment, error) {
+	ctx, span := tracing.Start(ctx, "user.EnrollTwoFactor")
+	defer span.End()
+
+	user, err := s.Repository.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.TwoFactorEnabled {
+		return nil, ErrTwoFactorAlreadyEnabled
+	}
+	if err := s.checkCredentials(user, credentials); err != nil {
+		return nil, err
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	user.TOTPSecret = secret
+	user.TOTPLastStep = 0
+	user.UpdatedAt = time.Now()
+	if err := s.Repository.UpdateTwoFactor(ctx, user); err != nil {
+		return nil, err
+	}
+
+	return &TwoFactorEnrollment{
+		Secret: secret,
+		URI:    totp.URI(TOTPIssuer, user.Email, secret),
+	}, nil
+}
+
+func (s *service) EnableTwoFactor(ctx context.Context, userID int, code string) ([]string, error) {
+	ctx, span := tracing.Start(ctx, "user.EnableTwoFactor")
+	defer span.End()
+
+	user, err := s.Repository.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.TwoFactorEnabled {
+		return nil, ErrTwoFactorAlreadyEnabled
+	}
+	if user.TOTPSecret == "" {
+		return nil, ErrTwoFactorNotEnrolled
+	}
+
+	if err := s.verifyTOTP(ctx, user, code); err != nil {
+		return nil, err
+	}
+
+	user.TwoFactorEnabled = true
+	user.UpdatedAt = time.Now()
+	if err := s.Repository.UpdateTwoFactor(ctx, user); err != nil {
+		return nil, err
+	}
+
+	return s.issueRecoveryCodes(ctx, user.ID)
+}
+
+func (s *service) DisableTwoFactor(ctx context.Context, userID int, request ReauthenticateRequest) error {
+	ctx, span := tracing.Start(ctx, "user.DisableTwoFactor")
+	defer span.End()
+
+	user, err := s.reauthenticate(ctx, userID, request)
+	if err != nil {
+		return err
+	}
+
+	user.TOTPSecret = ""
+	user.TwoFactorEnabled = false
+	user.TOTPLastStep = 0
+	user.UpdatedAt = time.Now()
+	if err := s.Repository.UpdateTwoFactor(ctx, user); err != nil {
+		return err
+	}
+
+	return s.Repository.DeleteRecoveryCodes(ctx, user.ID)
+}
+
+func (s *service) RegenerateRecoveryCodes(ctx context.Context, userID int, request ReauthenticateRequest) ([]string, error) {
+	ctx, span := tracing.Start(ctx, "user.RegenerateRecoveryCodes")
+	defer span.End()
+
+	user, err := s.reauthenticate(ctx, userID, request)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.issueRecoveryCodes(ctx, user.ID)
+}
+
+func (s *service) StartExternalLogin(ctx context.Context, linkUserID int) (*ExternalLoginResponse, error) {
+	ctx, span := tracing.Start(ctx, "user.StartExternalLogin")
+	defer span.End()
+
+	if s.IdentityProvider == nil {
+		return nil, ErrExternalLoginDisabled
+	}
+
+	if linkUserID > 0 {
+		if _, err := s.Repository.GetUserByID(ctx, linkUserID); err != nil {
+			return nil, err
+		}
+	}
+
+	state, err := randomToken()
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := randomToken()
+	if err != nil {
+		return nil, err
+	}
+	codeVerifier, err := oidc.NewCodeVerifier()
+	if err != nil {
+		return nil, err
+	}
+
+	authorizationURL, err := s.IdentityProvider.AuthCodeURL(ctx, state, nonce, codeVerifier)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrExternalLoginFailed, err)
+	}
+
+	now := time.Now()
+	err = s.Repository.CreateExternalLoginState(ctx, &ExternalLoginState{
+		StateHash:    hashSecret(state),
+		CodeVerifier: codeVerifier,
+		Nonce:        nonce,
+		LinkUserID:   linkUserID,
+		ExpiresAt:    now.Add(ExternalLoginTTL),
+		CreatedAt:    now,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &ExternalLoginResponse{AuthorizationURL: authorizationURL}, nil
+}
+
+func (s *service) CompleteExternalLogin(ctx context.Context, request ExternalLoginRequest) (*ExternalLogin, error) {
+	ctx, span := tracing.Start(ctx, "user.CompleteExternalLogin")
+	defer span.End()
+
+	if s.IdentityProvider == nil {
+		return nil, ErrExternalLoginDisabled
+	}
+
+	state, err := s.Repository.ConsumeExternalLoginState(ctx, hashSecret(request.State))
+	if err != nil {
+		return nil, err
+	}
+	if time.Now().After(state.ExpiresAt) {
+		return nil, ErrInvalidExternalLoginState
+	}
+
+	claims, err := s.IdentityProvider.Exchange(ctx, request.Code, state.CodeVerifier, state.Nonce)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrExternalLoginFailed, err)
+	}
+
+	user, err := s.resolveIdentity(ctx, claims, state.LinkUserID)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.EmailVerification.Required && user.EmailVerifiedAt == nil && state.LinkUserID == 0 {
+		return nil, ErrEmailNotVerified
+	}
+
+	// La verificación en dos pasos también se exige al entrar con el proveedor
+	if user.TwoFactorEnabled && state.LinkUserID == 0 {
+		return nil, s.startChallenge(ctx, user)
+	}
+
+	result := &ExternalLogin{User: user}
+	if user.PasswordHash == "" {
+		result.ReauthenticationToken, err = s.EmailVerification.Signer.Sign(reauthenticationPurpose,
+			strconv.Itoa(user.ID), time.Now().Add(ReauthenticationTTL))
+		if err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// resolveIdentity devuelve el usuario de la identidad externa, vinculándola o
+// creando la cuenta si es la primera vez que se usa.
+func (s *service) resolveIdentity(ctx context.Context, claims *oidc.Claims, linkUserID int) (*User, error) {
+	provider := s.IdentityProvider.Name()
+	now := time.Now()
+
+	identity, err := s.Repository.GetIdentity(ctx, provider, claims.Subject)
+	if err == nil {
+		if linkUserID > 0 && identity.UserID != linkUserID {
+			return nil, ErrIdentityAlreadyLinked
+		}
+		if err := s.Repository.TouchIdentity(ctx, identity.ID, now); err != nil {
+			return nil, err
+		}
+		user, err := s.Repository.GetUserByID(ctx, identity.UserID)
+		if err != nil {
+			return nil, err
+		}
+		return user, s.trustProviderEmail(ctx, user, claims)
+	}
+	if err != ErrIdentityNotFound {
+		return nil, err
+	}
+
+	identity = &Identity{
+		Provider:    provider,
+		Subject:     claims.Subject,
+		Email:       claims.Email,
+		CreatedAt:   now,
+		LastLoginAt: now,
+	}
+
+	var user *User
+	switch {
+	case linkUserID > 0:
+		if user, err = s.Repository.GetUserByID(ctx, linkUserID); err != nil {
+			return nil, err
+		}
+	case claims.Email == "":
+		return nil, fmt.Errorf("%w: provider did not return an email", ErrExternalLoginFailed)
+	default:
+		user, err = s.Repository.GetUserByEmail(ctx, claims.Email)
+		if err != nil && err != ErrUserNotFound {
+			return nil, err
+		}
+		// Solo se vincula a una cuenta existente si el proveedor garantiza el email
+		if user != nil && !claims.EmailVerified {
+			return nil, ErrUnverifiedExternalEmail
+		}
+		if user == nil {
+			// Sin contraseña: la cuenta solo puede entrar a través del proveedor
+			user = &User{
+				Email:     claims.Email,
+				CreatedAt: now,
+				UpdatedAt: now,
+			}
+			if claims.EmailVerified {
+				user.EmailVerifiedAt = &now
+			}
+		}
+	}
+
+	if err := s.Repository.CreateIdentity(ctx, user, identity); err != nil {
+		return nil, err
+	}
+
+	return user, s.trustProviderEmail(ctx, user, claims)
+}
+
+// trustProviderEmail da por verificado el correo del usuario si el proveedor
+// garantiza que es suyo.
+func (s *service) trustProviderEmail(ctx context.Context, user *User, claims *oidc.Claims) error {
+	if user.EmailVerifiedAt != nil || !bool(claims.EmailVerified) || !strings.EqualFold(user.Email, claims.Email) {
+		return nil
+	}
+
+	now := time.Now()
+	if err := s.Repository.MarkEmailVerified(ctx, user.ID, now); err != nil {
+		return err
+	}
+	user.EmailVerifiedAt = &now
+	return nil
+}
+
+// CheckEmailVerified no consulta la base de datos si la verificación no es
+// obligatoria. Un usuario que no existe no es asunto suyo: lo rechazará el
+// servicio que atienda la petición.
+func (s *service) CheckEmailVerified(ctx context.Context, userID int) error {
+	if !s.EmailVerification.Required {
+		return nil
+	}
+
+	user, err := s.Repository.GetUserByID(ctx, userID)
+	if err != nil {
+		if err == ErrUserNotFound {
+			return nil
+		}
+		return err
+	}
+	if user.EmailVerifiedAt == nil {
+		return ErrEmailNotVerified
+	}
+	return nil
+}
+
+func (s *service) VerifyEmail(ctx context.Context, token string) (*User, error) {
+	ctx, span := tracing.Start(ctx, "user.VerifyEmail")
+	defer span.End()
+
+	subject, err := s.EmailVerification.Signer.Verify(emailVerificationPurpose, token, time.Now())
+	if err != nil {
+		return nil, ErrInvalidVerificationToken
+	}
+
+	// El sujeto incluye el correo para que el enlace deje de valer si cambia
+	id, email, ok := strings.Cut(subject, ":")
+	userID, err := strconv.Atoi(id)
+	if !ok || err != nil {
+		return nil, ErrInvalidVerificationToken
+	}
+
+	user, err := s.Repository.GetUserByID(ctx, userID)
+	if err != nil {
+		if err == ErrUserNotFound {
+			return nil, ErrInvalidVerificationToken
+		}
+		return nil, err
+	}
+	if !strings.EqualFold(user.Email, email) {
+		return nil, ErrInvalidVerificationToken
+	}
+
+	// Repetir la verificación no es un error: el enlace puede abrirse dos veces
+	if user.EmailVerifiedAt != nil {
+		return user, nil
+	}
+
+	now := time.Now()
+	if err := s.Repository.MarkEmailVerified(ctx, user.ID, now); err != nil {
+		return nil, err
+	}
+	user.EmailVerifiedAt = &now
+
+	return user, nil
+}
+
+func (s *service) ResendVerification(ctx context.Context, request ResendVerificationRequest) error {
+	ctx, span := tracing.Start(ctx, "user.ResendVerification")
+	defer span.End()
+
+	user, err := s.Repository.GetUserByEmail(ctx, request.Email)
+	if err != nil {
+		if err == ErrUserNotFound {
+			return nil
+		}
+		return err
+	}
+	if user.EmailVerifiedAt != nil {
+		return nil
+	}
+
+	return s.sendVerification(ctx, user)
+}
+
+func (s *service) sendVerification(ctx context.Context, user *User) error {
+	subject := strconv.Itoa(user.ID) + ":" + strings.ToLower(user.Email)
+	token, err := s.EmailVerification.Signer.Sign(emailVerificationPurpose, subject, time.Now().Add(s.EmailVerification.TTL))
+	if err != nil {
+		return err
+	}
+
+	link := s.EmailVerification.URL
+	if strings.Contains(link, "?") {
+		link += "&token=" + url.QueryEscape(token)
+	} else {
+		link += "?token=" + url.QueryEscape(token)
+	}
+
+	return s.Mailer.Send(ctx, mailer.Message{
+		To:      user.Email,
+		Subject: "Confirma tu correo en PayVue",
+		Body: "Hola,\n\nPara activar tu cuenta de PayVue confirma tu correo abriendo este enlace:\n\n" +
+			link + "\n\nEl enlace caduca en " + strconv.Itoa(int(s.EmailVerification.TTL.Hours())) +
+			" horas. Si no has creado una cuenta, ignora este mensaje.\n",
+	})
+}
+
+func (s *service) ConfirmPassword(ctx context.Context, userID int, credentials Credentials) (*User, error) {
+	ctx, span := tracing.Start(ctx, "user.ConfirmPassword")
+	defer span.End()
+
+	user, err := s.Repository.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.checkCredentials(user, credentials); err != nil {
+		return nil, err
+	}
+
+	if user.TwoFactorEnabled {
+		if credentials.Code == "" {
+			return nil, ErrInvalidTwoFactorCode
+		}
+		if err := s.verifySecondFactor(ctx, user, credentials.Code); err != nil {
+			return nil, err
+		}
 	}
 
 	return user, nil
 }
 
+// checkCredentials comprueba la contraseña o, en las cuentas sin ella, la prueba
+// de un login externo reciente. Una cuenta sin contraseña con 2FA activa pasa:
+// el llamador exige después el código, que ya es un factor que no se puede
+// conseguir con solo conocer el ID del usuario.
+func (s *service) checkCredentials(user *User, credentials Credentials) error {
+	if user.PasswordHash != "" {
+		if bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(credentials.Password)) != nil {
+			return ErrInvalidCredentials
+		}
+		return nil
+	}
+	if user.TwoFactorEnabled {
+		return nil
+	}
+	if credentials.ReauthenticationToken == "" {
+		return ErrReauthenticationRequired
+	}
+	subject, err := s.EmailVerification.Signer.Verify(reauthenticationPurpose, credentials.ReauthenticationToken, time.Now())
+	if err != nil || subject != strconv.Itoa(user.ID) {
+		return ErrReauthenticationRequired
+	}
+	return nil
+}
+
+// reauthenticate exige contraseña y segundo factor de un usuario con 2FA activo.
+func (s *service) reauthenticate(ctx context.Context, userID int, request ReauthenticateRequest) (*User, error) {
+	user, err := s.Repository.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !user.TwoFactorEnabled {
+		return nil, ErrTwoFactorNotEnabled
+	}
+
+	if err := s.checkCredentials(user, Credentials{Password: request.Password}); err != nil {
+		return nil, err
+	}
+
+	if err := s.verifySecondFactor(ctx, user, request.Code); err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+func (s *service) startChallenge(ctx context.Context, user *User) error {
+	token, err := randomToken()
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	challenge := &Challenge{
+		TokenHash: hashSecret(token),
+		UserID:    user.ID,
+		ExpiresAt: now.Add(ChallengeTTL),
+		CreatedAt: now,
+	}
+	if err := s.Repository.CreateChallenge(ctx, challenge); err != nil {
+		return err
+	}
+
+	return &TwoFactorRequiredError{
+		ChallengeToken: token,
+		ExpiresAt:      challenge.ExpiresAt,
+	}
+}
+
+// verifySecondFactor acepta un código TOTP de 6 dígitos o un código de recuperación.
+func (s *service) verifySecondFactor(ctx context.Context, user *User, code string) error {
+	code = strings.TrimSpace(code)
+	if len(code) == totp.Digits {
+		return s.verifyTOTP(ctx, user, code)
+	}
+
+	return s.Repository.UseRecoveryCode(ctx, user.ID, hashSecret(normalizeRecoveryCode(code)))
+}
+
+func (s *service) verifyTOTP(ctx context.Context, user *User, code string) error {
+	step, ok := totp.Validate(user.TOTPSecret, code, time.Now())
+	if !ok {
+		return ErrInvalidTwoFactorCode
+	}
+
+	return s.Repository.MarkTOTPStepUsed(ctx, user.ID, step)
+}
+
+func (s *service) issueRecoveryCodes(ctx context.Context, userID int) ([]string, error) {
+	codes := make([]string, RecoveryCodeCount)
+	hashes := make([]string, RecoveryCodeCount)
+	for i := range codes {
+		raw := make([]byte, 5)
+		if _, err := rand.Read(raw); err != nil {
+			return nil, err
+		}
+		code := strings.ToLower(base32.StdEncoding.EncodeToString(raw))
+		codes[i] = code[:4] + "-" + code[4:]
+		hashes[i] = hashSecret(code)
+	}
+
+	if err := s.Repository.ReplaceRecoveryCodes(ctx, userID, hashes); err != nil {
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToLower(code)
+	code = strings.ReplaceAll(code, "-", "")
+	return strings.ReplaceAll(code, " ", "")
+}
+
+func randomToken() (string, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(raw), nil
+}
+
+// hashSecret sirve para tokens y códigos aleatorios de alta entropía, que no
+// necesitan un hash lento como bcrypt.
+func hashSecret(value string) string {
+	sum := sha256.Sum256([]byte(value))
+	return hex.EncodeToString(sum[:])
+}
+
 func (s *service) GetUserByEmail(ctx context.Context, email string) (*User, error) {
+	ctx, span := tracing.Start(ctx, "user.GetUserByEmail")
+	defer span.End()
+
 	user, err := s.Repository.GetUserByEmail(ctx, email)
 	if err != nil {
 		return nil, err
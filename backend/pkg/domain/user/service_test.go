@@ -0,0 +1,115 @@
+package user
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/payvue/payvue-backend/pkg/utils/signedtoken"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// fakeRepository solo implementa lo que usan estas pruebas; el resto de métodos
+// del interfaz entra en pánico si se llama.
+type fakeRepository struct {
+	Repository
+	users map[int]*User
+}
+
+func (r *fakeRepository) GetUserByID(ctx context.Context, id int) (*User, error) {
+	user, ok := r.users[id]
+	if !ok {
+		return nil, ErrUserNotFound
+	}
+	copied := *user
+	return &copied, nil
+}
+
+func (r *fakeRepository) UpdateTwoFactor(ctx context.Context, user *User) error {
+	copied := *user
+	r.users[user.ID] = &copied
+	return nil
+}
+
+func newTestService(t *testing.T, users ...*User) (*service, *signedtoken.Signer) {
+	t.Helper()
+	repository := &fakeRepository{users: make(map[int]*User)}
+	for _, user := range users {
+		repository.users[user.ID] = user
+	}
+	signer := signedtoken.New([]byte("test-key"))
+	return &service{Container: &Container{
+		Repository:        repository,
+		EmailVerification: EmailVerification{Signer: signer},
+	}}, signer
+}
+
+func TestConfirmPassword(t *testing.T) {
+	hash, err := bcrypt.GenerateFromPassword([]byte("secreta"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	withPassword := &User{ID: 1, Email: "a@example.com", PasswordHash: string(hash)}
+	external := &User{ID: 2, Email: "b@example.com"}
+	s, signer := newTestService(t, withPassword, external)
+
+	sign := func(purpose string, userID int, expiresAt time.Time) string {
+		token, err := signer.Sign(purpose, strconv.Itoa(userID), expiresAt)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return token
+	}
+	fresh := time.Now().Add(ReauthenticationTTL)
+
+	tests := []struct {
+		name        string
+		userID      int
+		credentials Credentials
+		want        error
+	}{
+		{"password", 1, Credentials{Password: "secreta"}, nil},
+		{"wrong password", 1, Credentials{Password: "otra"}, ErrInvalidCredentials},
+		{"password account ignores the token", 1, Credentials{ReauthenticationToken: sign(reauthenticationPurpose, 1, fresh)}, ErrInvalidCredentials},
+		{"external account without proof", 2, Credentials{}, ErrReauthenticationRequired},
+		{"external account with an empty password", 2, Credentials{Password: ""}, ErrReauthenticationRequired},
+		{"external account with a recent login", 2, Credentials{ReauthenticationToken: sign(reauthenticationPurpose, 2, fresh)}, nil},
+		{"token of another user", 2, Credentials{ReauthenticationToken: sign(reauthenticationPurpose, 1, fresh)}, ErrReauthenticationRequired},
+		{"expired token", 2, Credentials{ReauthenticationToken: sign(reauthenticationPurpose, 2, time.Now().Add(-time.Second))}, ErrReauthenticationRequired},
+		{"token for another purpose", 2, Credentials{ReauthenticationToken: sign(emailVerificationPurpose, 2, fresh)}, ErrReauthenticationRequired},
+		{"unknown user", 3, Credentials{Password: "secreta"}, ErrUserNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			user, err := s.ConfirmPassword(context.Background(), tt.userID, tt.credentials)
+			if err != tt.want {
+				t.Fatalf("ConfirmPassword error = %v, want %v", err, tt.want)
+			}
+			if err == nil && user.ID != tt.userID {
+				t.Errorf("ConfirmPassword user = %d, want %d", user.ID, tt.userID)
+			}
+		})
+	}
+}
+
+func TestEnrollTwoFactorWithoutPassword(t *testing.T) {
+	s, signer := newTestService(t, &User{ID: 2, Email: "b@example.com"})
+	ctx := context.Background()
+
+	if _, err := s.EnrollTwoFactor(ctx, 2, Credentials{}); err != ErrReauthenticationRequired {
+		t.Fatalf("EnrollTwoFactor without proof error = %v, want %v", err, ErrReauthenticationRequired)
+	}
+
+	token, err := signer.Sign(reauthenticationPurpose, "2", time.Now().Add(ReauthenticationTTL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	enrollment, err := s.EnrollTwoFactor(ctx, 2, Credentials{ReauthenticationToken: token})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if enrollment.Secret == "" {
+		t.Error("EnrollTwoFactor returned an empty secret")
+	}
+}
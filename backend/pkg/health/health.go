@@ -0,0 +1,159 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/payvue/payvue-backend/pkg/buildinfo"
+)
+
+// Check comprueba una dependencia. Run devuelve un error si la dependencia no
+// está disponible y, opcionalmente, detalles para la respuesta.
+type Check struct {
+	Name string
+	Run  func(ctx context.Context) (details interface{}, err error)
+}
+
+// Checker responde a las sondas de liveness y readiness.
+type Checker struct {
+	service string
+	started time.Time
+	timeout time.Duration
+	checks  []Check
+}
+
+// New crea el checker del binario service. Cada check tiene timeout para
+// terminar; pasado ese tiempo cuenta como fallido.
+func New(service string, timeout time.Duration, checks ...Check) *Checker {
+	return &Checker{
+		service: service,
+		started: time.Now(),
+		timeout: timeout,
+		checks:  checks,
+	}
+}
+
+const (
+	StatusOK   = "ok"
+	StatusFail = "fail"
+)
+
+type Report struct {
+	Status  string `json:"status"`
+	Service string `json:"service"`
+	buildinfo.Info
+	StartedAt     time.Time              `json:"started_at"`
+	UptimeSeconds int64                  `json:"uptime_seconds"`
+	Checks        map[string]CheckResult `json:"checks,omitempty"`
+}
+
+type CheckResult struct {
+	Status     string      `json:"status"`
+	DurationMS float64     `json:"duration_ms"`
+	Error      string      `json:"error,omitempty"`
+	Details    interface{} `json:"details,omitempty"`
+}
+
+// Live informa de que el proceso responde. No comprueba dependencias: si la
+// base de datos cae, reiniciar el proceso no lo arregla.
+func (c *Checker) Live() Report {
+	return Report{
+		Status:        StatusOK,
+		Service:       c.service,
+		Info:          buildinfo.Get(),
+		StartedAt:     c.started,
+		UptimeSeconds: int64(time.Since(c.started).Seconds()),
+	}
+}
+
+// Ready ejecuta todos los checks en paralelo. El estado es "fail" si falla
+// cualquiera de ellos.
+func (c *Checker) Ready(ctx context.Context) Report {
+	report := c.Live()
+	report.Checks = make(map[string]CheckResult, len(c.checks))
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, check := range c.checks {
+		wg.Add(1)
+		go func(check Check) {
+			defer wg.Done()
+			result := c.run(ctx, check)
+
+			mu.Lock()
+			defer mu.Unlock()
+			report.Checks[check.Name] = result
+			if result.Status != StatusOK {
+				report.Status = StatusFail
+			}
+		}(check)
+	}
+	wg.Wait()
+
+	return report
+}
+
+// run no espera al check más allá del timeout: algunas dependencias no
+// atienden la cancelación (SQLite sigue esperando un bloqueo hasta su
+// busy_timeout) y la sonda tiene que responder a tiempo.
+func (c *Checker) run(ctx context.Context, check Check) CheckResult {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	type outcome struct {
+		details interface{}
+		err     error
+	}
+	done := make(chan outcome, 1)
+	start := time.Now()
+	go func() {
+		details, err := check.Run(ctx)
+		done <- outcome{details, err}
+	}()
+
+	var result outcome
+	select {
+	case result = <-done:
+	case <-ctx.Done():
+		result.err = fmt.Errorf("no answer within %s: %w", c.timeout, ctx.Err())
+	}
+
+	checkResult := CheckResult{
+		Status:     StatusOK,
+		DurationMS: float64(time.Since(start).Microseconds()) / 1000,
+		Details:    result.details,
+	}
+	if result.err != nil {
+		checkResult.Status = StatusFail
+		checkResult.Error = result.err.Error()
+	}
+	return checkResult
+}
+
+// LiveHandler sirve Live; siempre responde 200.
+func (c *Checker) LiveHandler(w http.ResponseWriter, r *http.Request) {
+	respond(w, c.Live())
+}
+
+// ReadyHandler sirve Ready con 200 si todo está bien y 503 si algo falla, que
+// es lo que miran los healthchecks de Docker y las sondas de Kubernetes.
+func (c *Checker) ReadyHandler(w http.ResponseWriter, r *http.Request) {
+	respond(w, c.Ready(r.Context()))
+}
+
+func respond(w http.ResponseWriter, report Report) {
+	status := http.StatusOK
+	if report.Status != StatusOK {
+		status = http.StatusServiceUnavailable
+	}
+
+	response, _ := json.Marshal(report)
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	w.Write(response)
+}
@@ -0,0 +1,41 @@
+package jobs
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/payvue/payvue-backend/pkg/domain/account"
+	"github.com/payvue/payvue-backend/pkg/scheduler"
+	"github.com/payvue/payvue-backend/pkg/utils/fileupload"
+	"github.com/payvue/payvue-backend/pkg/utils/logger"
+)
+
+// AccountDeletion borra cada hora las cuentas cuyo periodo de gracia ha
+// terminado, junto con sus recibos.
+func AccountDeletion(accountService account.Service) scheduler.Job {
+	return scheduler.Job{
+		Name:     "account_deletion",
+		Interval: time.Hour,
+		Run: func(ctx context.Context) error {
+			purged, filenames, err := accountService.PurgeDueDeletions(ctx, time.Now())
+			deleteReceipts(ctx, filenames)
+			if err != nil {
+				return err
+			}
+			if purged > 0 {
+				logger.FromContext(ctx).Info("deleted accounts", slog.Int("accounts", purged), slog.Int("receipts", len(filenames)))
+			}
+			return nil
+		},
+	}
+}
+
+// deleteReceipts borra del disco los recibos de registros ya eliminados de la base de datos.
+func deleteReceipts(ctx context.Context, filenames []string) {
+	for _, filename := range filenames {
+		if err := fileupload.DeleteFile(filename); err != nil {
+			logger.FromContext(ctx).Warn("could not delete receipt", slog.String("file", filename), slog.Any("error", err))
+		}
+	}
+}
@@ -0,0 +1,29 @@
+package jobs
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/payvue/payvue-backend/pkg/domain/idempotency"
+	"github.com/payvue/payvue-backend/pkg/scheduler"
+	"github.com/payvue/payvue-backend/pkg/utils/logger"
+)
+
+// IdempotencyKeys borra cada hora las claves de idempotencia que ya no se pueden repetir.
+func IdempotencyKeys(idempotencyService idempotency.Service) scheduler.Job {
+	return scheduler.Job{
+		Name:     "idempotency_keys",
+		Interval: time.Hour,
+		Run: func(ctx context.Context) error {
+			purged, err := idempotencyService.PurgeExpired(ctx, time.Now().Add(-idempotency.TTL))
+			if err != nil {
+				return err
+			}
+			if purged > 0 {
+				logger.FromContext(ctx).Info("purged expired idempotency keys", slog.Int("keys", purged))
+			}
+			return nil
+		},
+	}
+}
@@ -0,0 +1,47 @@
+package jobs
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/payvue/payvue-backend/pkg/domain/debt"
+	"github.com/payvue/payvue-backend/pkg/domain/income"
+	"github.com/payvue/payvue-backend/pkg/domain/payment"
+	"github.com/payvue/payvue-backend/pkg/scheduler"
+	"github.com/payvue/payvue-backend/pkg/utils/logger"
+)
+
+// TrashRetention purga una vez al día los registros que llevan más de
+// retentionDays días en la papelera, junto con sus recibos.
+func TrashRetention(debtService debt.Service, incomeService income.Service, paymentService payment.Service, retentionDays int) scheduler.Job {
+	return scheduler.Job{
+		Name:     "trash_retention",
+		Interval: 24 * time.Hour,
+		Run: func(ctx context.Context) error {
+			before := time.Now().AddDate(0, 0, -retentionDays)
+
+			// Los pagos van primero para recoger también los de las deudas que se purgan
+			filenames, err := paymentService.PurgeDeletedPayments(ctx, before)
+			if err != nil {
+				return err
+			}
+			deleteReceipts(ctx, filenames)
+
+			debts, err := debtService.PurgeDeletedDebts(ctx, before)
+			if err != nil {
+				return err
+			}
+
+			incomes, err := incomeService.PurgeDeletedIncomes(ctx, before)
+			if err != nil {
+				return err
+			}
+
+			if debts+incomes+len(filenames) > 0 {
+				logger.FromContext(ctx).Info("purged trash", slog.Int("debts", debts), slog.Int("incomes", incomes), slog.Int("receipts", len(filenames)))
+			}
+			return nil
+		},
+	}
+}
@@ -0,0 +1,29 @@
+package jobs
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/payvue/payvue-backend/pkg/domain/security"
+	"github.com/payvue/payvue-backend/pkg/scheduler"
+	"github.com/payvue/payvue-backend/pkg/utils/logger"
+)
+
+// LoginAttempts borra una vez al día los contadores de login que ya no bloquean nada.
+func LoginAttempts(securityService security.Service, window time.Duration) scheduler.Job {
+	return scheduler.Job{
+		Name:     "login_attempts",
+		Interval: 24 * time.Hour,
+		Run: func(ctx context.Context) error {
+			purged, err := securityService.PurgeAttempts(ctx, time.Now().Add(-window))
+			if err != nil {
+				return err
+			}
+			if purged > 0 {
+				logger.FromContext(ctx).Info("purged stale login attempts", slog.Int("counters", purged))
+			}
+			return nil
+		},
+	}
+}
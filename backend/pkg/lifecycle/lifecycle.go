@@ -0,0 +1,159 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// Component es una pieza del proceso con arranque y parada. Start no debe
+// bloquear; lo que tenga que quedarse corriendo lo lanza en segundo plano y, si
+// falla, lo notifica con Manager.Fail. Stop debe respetar el plazo del contexto.
+type Component struct {
+	Name  string
+	Start func(ctx context.Context) error
+	Stop  func(ctx context.Context) error
+}
+
+// Manager arranca los componentes en orden y los para en orden inverso cuando
+// llega SIGINT o SIGTERM, cuando uno falla o cuando se cancela el contexto de
+// Run. Con SIGHUP llama a los reloaders sin parar nada.
+type Manager struct {
+	logger          *slog.Logger
+	shutdownTimeout time.Duration
+	components      []Component
+	reloaders       []func(ctx context.Context) error
+	failures        chan error
+}
+
+func New(logger *slog.Logger, shutdownTimeout time.Duration) *Manager {
+	return &Manager{
+		logger:          logger,
+		shutdownTimeout: shutdownTimeout,
+		failures:        make(chan error, 1),
+	}
+}
+
+func (m *Manager) Add(component Component) {
+	m.components = append(m.components, component)
+}
+
+// OnReload registra una función a la que se llama con cada SIGHUP. Un error se
+// registra en el log y deja la configuración anterior.
+func (m *Manager) OnReload(reload func(ctx context.Context) error) {
+	m.reloaders = append(m.reloaders, reload)
+}
+
+// Fail pide la parada del proceso por un error de un componente.
+func (m *Manager) Fail(err error) {
+	select {
+	case m.failures <- err:
+	default:
+	}
+}
+
+// AddHTTPServer añade un servidor HTTP. El puerto se abre en Start, así que un
+// puerto ocupado impide arrancar; al parar deja de aceptar conexiones y espera a
+// que terminen las peticiones en curso.
+func (m *Manager) AddHTTPServer(name string, server *http.Server) {
+	m.Add(Component{
+		Name: name,
+		Start: func(ctx context.Context) error {
+			listener, err := net.Listen("tcp", server.Addr)
+			if err != nil {
+				return err
+			}
+			go func() {
+				if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+					m.Fail(fmt.Errorf("%s: %w", name, err))
+				}
+			}()
+			return nil
+		},
+		Stop: server.Shutdown,
+	})
+}
+
+// Run arranca todo y bloquea hasta la parada. Devuelve el error que la provocó,
+// o nil si fue una señal, junto con los errores de parada.
+func (m *Manager) Run(ctx context.Context) error {
+	started := 0
+	var runErr error
+	for _, component := range m.components {
+		if component.Start != nil {
+			if err := component.Start(ctx); err != nil {
+				runErr = fmt.Errorf("starting %s: %w", component.Name, err)
+				break
+			}
+		}
+		m.logger.Debug("component started", slog.String("component", component.Name))
+		started++
+	}
+
+	if runErr == nil {
+		runErr = m.wait(ctx)
+	}
+
+	return errors.Join(runErr, m.stop(m.components[:started]))
+}
+
+func (m *Manager) wait(ctx context.Context) error {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP)
+	defer signal.Stop(signals)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case err := <-m.failures:
+			return err
+		case sig := <-signals:
+			if sig == syscall.SIGHUP {
+				m.reload(ctx)
+				continue
+			}
+			m.logger.Info("shutting down", slog.String("signal", sig.String()), slog.Duration("timeout", m.shutdownTimeout))
+			return nil
+		}
+	}
+}
+
+func (m *Manager) reload(ctx context.Context) {
+	m.logger.Info("reloading configuration")
+	for _, reload := range m.reloaders {
+		if err := reload(ctx); err != nil {
+			m.logger.Error("reload failed, keeping the previous configuration", slog.Any("error", err))
+			return
+		}
+	}
+}
+
+// stop para los componentes en orden inverso con un plazo común. Si el plazo
+// vence se sigue con los demás para cerrar al menos lo que se pueda.
+func (m *Manager) stop(components []Component) error {
+	ctx, cancel := context.WithTimeout(context.Background(), m.shutdownTimeout)
+	defer cancel()
+
+	var errs []error
+	for i := len(components) - 1; i >= 0; i-- {
+		component := components[i]
+		if component.Stop == nil {
+			continue
+		}
+		if err := component.Stop(ctx); err != nil {
+			m.logger.Error("component did not stop cleanly", slog.String("component", component.Name), slog.Any("error", err))
+			errs = append(errs, fmt.Errorf("stopping %s: %w", component.Name, err))
+			continue
+		}
+		m.logger.Debug("component stopped", slog.String("component", component.Name))
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,280 @@
+package metrics
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Buckets por defecto de los histogramas de latencia, en segundos.
+var DefaultBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// Registry guarda las métricas y las escribe en el formato de texto de
+// Prometheus (versión 0.0.4). Registrar dos veces el mismo nombre es un error de
+// programación y provoca un panic, igual que en el cliente oficial.
+type Registry struct {
+	mu      sync.Mutex
+	metrics map[string]metric
+}
+
+type metric interface {
+	write(ctx context.Context, w *bufio.Writer)
+}
+
+func NewRegistry() *Registry {
+	return &Registry{metrics: make(map[string]metric)}
+}
+
+// Default es el registro que usan los paquetes instrumentados y el que sirve /metrics.
+var Default = NewRegistry()
+
+func (r *Registry) register(name string, m metric) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, exists := r.metrics[name]; exists {
+		panic("metrics: duplicate metric " + name)
+	}
+	r.metrics[name] = m
+}
+
+// Write escribe todas las métricas ordenadas por nombre.
+func (r *Registry) Write(ctx context.Context, w io.Writer) error {
+	r.mu.Lock()
+	names := make([]string, 0, len(r.metrics))
+	for name := range r.metrics {
+		names = append(names, name)
+	}
+	metrics := make([]metric, len(names))
+	sort.Strings(names)
+	for i, name := range names {
+		metrics[i] = r.metrics[name]
+	}
+	r.mu.Unlock()
+
+	bw := bufio.NewWriter(w)
+	for _, m := range metrics {
+		m.write(ctx, bw)
+	}
+	return bw.Flush()
+}
+
+// Handler sirve las métricas del registro.
+func (r *Registry) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		r.Write(req.Context(), w)
+	})
+}
+
+type Counter struct {
+	vec *vec
+}
+
+// NewCounter registra un contador con las etiquetas indicadas.
+func (r *Registry) NewCounter(name, help string, labels ...string) *Counter {
+	c := &Counter{vec: newVec(name, help, "counter", labels)}
+	r.register(name, c)
+	return c
+}
+
+// Add suma value a la serie de labelValues, en el orden de las etiquetas.
+func (c *Counter) Add(value float64, labelValues ...string) {
+	s := c.vec.seriesFor(labelValues)
+	c.vec.mu.Lock()
+	s.value += value
+	c.vec.mu.Unlock()
+}
+
+func (c *Counter) Inc(labelValues ...string) {
+	c.Add(1, labelValues...)
+}
+
+func (c *Counter) write(ctx context.Context, w *bufio.Writer) {
+	c.vec.writeHeader(w)
+	c.vec.each(func(labels string, s *series) {
+		writeSample(w, c.vec.name, labels, s.value)
+	})
+}
+
+type Histogram struct {
+	vec     *vec
+	buckets []float64
+}
+
+// NewHistogram registra un histograma; buckets son los límites superiores en
+// orden creciente, sin +Inf.
+func (r *Registry) NewHistogram(name, help string, buckets []float64, labels ...string) *Histogram {
+	h := &Histogram{vec: newVec(name, help, "histogram", labels), buckets: buckets}
+	r.register(name, h)
+	return h
+}
+
+func (h *Histogram) Observe(value float64, labelValues ...string) {
+	s := h.vec.seriesFor(labelValues)
+	h.vec.mu.Lock()
+	defer h.vec.mu.Unlock()
+	if s.counts == nil {
+		s.counts = make([]uint64, len(h.buckets))
+	}
+	for i, bound := range h.buckets {
+		if value <= bound {
+			s.counts[i]++
+		}
+	}
+	s.value += value
+	s.count++
+}
+
+func (h *Histogram) write(ctx context.Context, w *bufio.Writer) {
+	h.vec.writeHeader(w)
+	h.vec.each(func(labels string, s *series) {
+		for i, bound := range h.buckets {
+			var count uint64
+			if s.counts != nil {
+				count = s.counts[i]
+			}
+			writeSample(w, h.vec.name+"_bucket", joinLabels(labels, `le="`+formatFloat(bound)+`"`), float64(count))
+		}
+		writeSample(w, h.vec.name+"_bucket", joinLabels(labels, `le="+Inf"`), float64(s.count))
+		writeSample(w, h.vec.name+"_sum", labels, s.value)
+		writeSample(w, h.vec.name+"_count", labels, float64(s.count))
+	})
+}
+
+type gaugeFunc struct {
+	name, help string
+	fn         func(ctx context.Context) (float64, error)
+}
+
+// NewGaugeFunc registra un gauge que se calcula en cada lectura. Si fn falla la
+// métrica se omite en esa lectura.
+func (r *Registry) NewGaugeFunc(name, help string, fn func(ctx context.Context) (float64, error)) {
+	r.register(name, &gaugeFunc{name: name, help: help, fn: fn})
+}
+
+func (g *gaugeFunc) write(ctx context.Context, w *bufio.Writer) {
+	value, err := g.fn(ctx)
+	if err != nil {
+		return
+	}
+	writeHeader(w, g.name, g.help, "gauge")
+	writeSample(w, g.name, "", value)
+}
+
+// vec agrupa las series de una métrica por los valores de sus etiquetas.
+type vec struct {
+	name, help, kind string
+	labels           []string
+
+	mu     sync.Mutex
+	series map[string]*series
+}
+
+// series es una combinación de valores de etiquetas. En los histogramas value es
+// la suma de las observaciones y counts, el acumulado de cada bucket.
+type series struct {
+	labels string
+	value  float64
+	count  uint64
+	counts []uint64
+}
+
+func newVec(name, help, kind string, labels []string) *vec {
+	return &vec{name: name, help: help, kind: kind, labels: labels, series: make(map[string]*series)}
+}
+
+func (v *vec) seriesFor(labelValues []string) *series {
+	if len(labelValues) != len(v.labels) {
+		panic(fmt.Sprintf("metrics: %s expects %d label values, got %d", v.name, len(v.labels), len(labelValues)))
+	}
+	key := strings.Join(labelValues, "\xff")
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	s, ok := v.series[key]
+	if !ok {
+		pairs := make([]string, len(v.labels))
+		for i, label := range v.labels {
+			pairs[i] = label + `="` + escapeLabel(labelValues[i]) + `"`
+		}
+		s = &series{labels: strings.Join(pairs, ",")}
+		v.series[key] = s
+	}
+	return s
+}
+
+func (v *vec) writeHeader(w *bufio.Writer) {
+	writeHeader(w, v.name, v.help, v.kind)
+}
+
+// each recorre las series ordenadas por etiquetas con el mutex tomado.
+func (v *vec) each(fn func(labels string, s *series)) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	all := make([]*series, 0, len(v.series))
+	for _, s := range v.series {
+		all = append(all, s)
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].labels < all[j].labels })
+	for _, s := range all {
+		fn(s.labels, s)
+	}
+}
+
+func writeHeader(w *bufio.Writer, name, help, kind string) {
+	help = strings.NewReplacer(`\`, `\\`, "\n", `\n`).Replace(help)
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+}
+
+func writeSample(w *bufio.Writer, name, labels string, value float64) {
+	w.WriteString(name)
+	if labels != "" {
+		w.WriteString("{" + labels + "}")
+	}
+	w.WriteString(" " + formatFloat(value) + "\n")
+}
+
+func joinLabels(labels, extra string) string {
+	if labels == "" {
+		return extra
+	}
+	return labels + "," + extra
+}
+
+func escapeLabel(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value)
+}
+
+func formatFloat(value float64) string {
+	switch {
+	case math.IsInf(value, 1):
+		return "+Inf"
+	case math.IsInf(value, -1):
+		return "-Inf"
+	}
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
+
+// RegisterRuntime añade las métricas básicas del proceso Go.
+func RegisterRuntime(r *Registry) {
+	start := float64(time.Now().Unix())
+	r.NewGaugeFunc("process_start_time_seconds", "Hora de arranque del proceso en segundos desde epoch.",
+		func(ctx context.Context) (float64, error) { return start, nil })
+	r.NewGaugeFunc("go_goroutines", "Goroutines en ejecución.",
+		func(ctx context.Context) (float64, error) { return float64(runtime.NumGoroutine()), nil })
+	r.NewGaugeFunc("go_memstats_heap_alloc_bytes", "Bytes reservados en el heap y en uso.",
+		func(ctx context.Context) (float64, error) {
+			var stats runtime.MemStats
+			runtime.ReadMemStats(&stats)
+			return float64(stats.HeapAlloc), nil
+		})
+}
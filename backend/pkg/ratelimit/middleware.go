@@ -0,0 +1,77 @@
+package ratelimit
+
+import (
+	"log/slog"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/payvue/payvue-backend/pkg/rest"
+	"github.com/payvue/payvue-backend/pkg/utils/actor"
+	"github.com/payvue/payvue-backend/pkg/utils/logger"
+)
+
+// Limiter permite Limit peticiones por Window a cada clave devuelta por Key.
+type Limiter struct {
+	Name   string
+	Store  Store
+	Limit  int
+	Window time.Duration
+	// Key identifica al cliente; por defecto, su IP.
+	Key func(r *http.Request) string
+}
+
+// Middleware responde 429 con Retry-After cuando se supera el límite. Si el
+// almacén falla deja pasar la petición para no tumbar el servicio.
+func Middleware(l Limiter) func(http.Handler) http.Handler {
+	keyFunc := l.Key
+	if keyFunc == nil {
+		keyFunc = ByIP
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if l.Limit <= 0 || r.Method == http.MethodOptions {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			hits, resetAt, err := l.Store.Hit(r.Context(), l.Name+":"+keyFunc(r), l.Window)
+			if err != nil {
+				logger.FromContext(r.Context()).Error("rate limit store error", slog.String("limiter", l.Name), slog.Any("error", err))
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			remaining := l.Limit - hits
+			if remaining < 0 {
+				remaining = 0
+			}
+			w.Header().Set("X-RateLimit-Limit", strconv.Itoa(l.Limit))
+			w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(remaining))
+			w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(resetAt.Unix(), 10))
+
+			if hits > l.Limit {
+				retryAfter := int(time.Until(resetAt).Seconds()) + 1
+				w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+				rest.RespondProblem(w, r, http.StatusTooManyRequests, "rate_limited", "Demasiadas peticiones, inténtalo más tarde")
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// ByIP usa la IP que ActorContext dejó en el contexto o, si no está, la de la conexión.
+func ByIP(r *http.Request) string {
+	if ip := actor.FromContext(r.Context()).IP; ip != "" {
+		return ip
+	}
+	ip, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return ip
+}
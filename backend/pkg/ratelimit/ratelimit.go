@@ -0,0 +1,61 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+var ErrStore = errors.New("rate limit store error")
+
+// Store cuenta las peticiones de cada clave en ventanas fijas.
+type Store interface {
+	// Hit suma una petición a la ventana actual de key y devuelve el total y cuándo
+	// empieza la siguiente ventana.
+	Hit(ctx context.Context, key string, window time.Duration) (hits int, resetAt time.Time, err error)
+}
+
+type memoryStore struct {
+	mu        sync.Mutex
+	buckets   map[string]bucket
+	lastSweep time.Time
+}
+
+type bucket struct {
+	hits    int
+	resetAt time.Time
+}
+
+// NewMemoryStore guarda los contadores en memoria; solo sirve con una instancia.
+func NewMemoryStore() Store {
+	return &memoryStore{
+		buckets: map[string]bucket{},
+	}
+}
+
+func (s *memoryStore) Hit(ctx context.Context, key string, window time.Duration) (int, time.Time, error) {
+	now := time.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Limpieza perezosa de las ventanas vencidas
+	if now.Sub(s.lastSweep) > time.Minute {
+		for k, b := range s.buckets {
+			if !b.resetAt.After(now) {
+				delete(s.buckets, k)
+			}
+		}
+		s.lastSweep = now
+	}
+
+	b, ok := s.buckets[key]
+	if !ok || !b.resetAt.After(now) {
+		b = bucket{resetAt: now.Add(window)}
+	}
+	b.hits++
+	s.buckets[key] = b
+
+	return b.hits, b.resetAt, nil
+}
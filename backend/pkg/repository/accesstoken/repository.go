@@ -0,0 +1,122 @@
+package accesstoken
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/payvue/payvue-backend/pkg/domain/accesstoken"
+	"github.com/payvue/payvue-backend/pkg/repository/database"
+)
+
+const tokenColumns = `id, user_id, name, scope, token_hash, hint, expires_at, last_used_at, created_at`
+
+type repository struct {
+	db *sql.DB
+}
+
+func NewRepository(db *sql.DB) accesstoken.Repository {
+	return &repository{
+		db: db,
+	}
+}
+
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanToken(row scanner, t *accesstoken.AccessToken) error {
+	return row.Scan(&t.ID, &t.UserID, &t.Name, &t.Scope, &t.TokenHash, &t.Hint, &t.ExpiresAt, &t.LastUsedAt, &t.CreatedAt)
+}
+
+func (r *repository) CreateToken(ctx context.Context, t *accesstoken.AccessToken) (*accesstoken.AccessToken, error) {
+	query := `
+		INSERT INTO access_tokens (user_id, name, scope, token_hash, hint, expires_at, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		RETURNING id
+	`
+
+	err := r.db.QueryRowContext(ctx, query,
+		t.UserID, t.Name, t.Scope, t.TokenHash, t.Hint, t.ExpiresAt, t.CreatedAt,
+	).Scan(&t.ID)
+	if err != nil {
+		return nil, database.Wrap(accesstoken.ErrDatabaseError, err)
+	}
+
+	return t, nil
+}
+
+func (r *repository) GetTokensByUserID(ctx context.Context, userID int) ([]accesstoken.AccessToken, error) {
+	query := `
+		SELECT ` + tokenColumns + `
+		FROM access_tokens
+		WHERE user_id = ?
+		ORDER BY id DESC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, database.Wrap(accesstoken.ErrDatabaseError, err)
+	}
+	defer rows.Close()
+
+	tokens := []accesstoken.AccessToken{}
+	for rows.Next() {
+		var t accesstoken.AccessToken
+		if err := scanToken(rows, &t); err != nil {
+			return nil, database.Wrap(accesstoken.ErrDatabaseError, err)
+		}
+		tokens = append(tokens, t)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, database.Wrap(accesstoken.ErrDatabaseError, err)
+	}
+
+	return tokens, nil
+}
+
+func (r *repository) GetTokenByHash(ctx context.Context, tokenHash string) (*accesstoken.AccessToken, error) {
+	query := `
+		SELECT ` + tokenColumns + `
+		FROM access_tokens
+		WHERE token_hash = ?
+	`
+
+	var t accesstoken.AccessToken
+	if err := scanToken(r.db.QueryRowContext(ctx, query, tokenHash), &t); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, accesstoken.ErrTokenNotFound
+		}
+		return nil, database.Wrap(accesstoken.ErrDatabaseError, err)
+	}
+
+	return &t, nil
+}
+
+func (r *repository) DeleteToken(ctx context.Context, userID, tokenID int) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM access_tokens WHERE id = ? AND user_id = ?`, tokenID, userID)
+	if err != nil {
+		return database.Wrap(accesstoken.ErrDatabaseError, err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return database.Wrap(accesstoken.ErrDatabaseError, err)
+	}
+
+	if rowsAffected == 0 {
+		return accesstoken.ErrTokenNotFound
+	}
+
+	return nil
+}
+
+func (r *repository) TouchToken(ctx context.Context, tokenID int, lastUsedAt time.Time) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE access_tokens SET last_used_at = ? WHERE id = ?`, lastUsedAt, tokenID)
+	if err != nil {
+		return database.Wrap(accesstoken.ErrDatabaseError, err)
+	}
+	return nil
+}
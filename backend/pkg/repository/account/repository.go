@@ -0,0 +1,414 @@
+package account
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/payvue/payvue-backend/pkg/domain/account"
+	"github.com/payvue/payvue-backend/pkg/domain/debt"
+	"github.com/payvue/payvue-backend/pkg/domain/household"
+	"github.com/payvue/payvue-backend/pkg/domain/income"
+	"github.com/payvue/payvue-backend/pkg/domain/payment"
+	"github.com/payvue/payvue-backend/pkg/domain/security"
+	"github.com/payvue/payvue-backend/pkg/domain/user"
+	"github.com/payvue/payvue-backend/pkg/repository/database"
+)
+
+type repository struct {
+	db *sql.DB
+}
+
+func NewRepository(db *sql.DB) account.Repository {
+	return &repository{
+		db: db,
+	}
+}
+
+func (r *repository) ScheduleDeletion(ctx context.Context, userID int, at time.Time) error {
+	result, err := r.db.ExecContext(ctx,
+		`UPDATE users SET deletion_scheduled_at = ?, updated_at = ? WHERE id = ?`,
+		at, time.Now(), userID,
+	)
+	if err != nil {
+		return database.Wrap(account.ErrDatabaseError, err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return database.Wrap(account.ErrDatabaseError, err)
+	}
+
+	if rowsAffected == 0 {
+		return account.ErrAccountNotFound
+	}
+
+	return nil
+}
+
+func (r *repository) CancelDeletion(ctx context.Context, userID int) error {
+	result, err := r.db.ExecContext(ctx,
+		`UPDATE users SET deletion_scheduled_at = NULL, updated_at = ? WHERE id = ? AND deletion_scheduled_at IS NOT NULL`,
+		time.Now(), userID,
+	)
+	if err != nil {
+		return database.Wrap(account.ErrDatabaseError, err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return database.Wrap(account.ErrDatabaseError, err)
+	}
+
+	if rowsAffected == 0 {
+		return account.ErrDeletionNotScheduled
+	}
+
+	return nil
+}
+
+func (r *repository) GetDueDeletions(ctx context.Context, before time.Time) ([]int, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT id FROM users WHERE deletion_scheduled_at IS NOT NULL AND deletion_scheduled_at <= ? ORDER BY id`,
+		before,
+	)
+	if err != nil {
+		return nil, database.Wrap(account.ErrDatabaseError, err)
+	}
+	defer rows.Close()
+
+	var userIDs []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, database.Wrap(account.ErrDatabaseError, err)
+		}
+		userIDs = append(userIDs, id)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, database.Wrap(account.ErrDatabaseError, err)
+	}
+
+	return userIDs, nil
+}
+
+// DeleteAccount borra explícitamente las tablas financieras: en las bases de datos
+// anteriores a los usuarios, user_id se añadió sin clave foránea y el CASCADE no
+// llega a ellas. El resto (2FA, identidades, membresías) cae por las claves foráneas.
+// Solo borra los registros personales: los de un hogar que sigue existiendo pasan
+// a su owner y los pagos del usuario sobre deudas ajenas, al dueño de la deuda.
+func (r *repository) DeleteAccount(ctx context.Context, userID int) ([]string, error) {
+	var filenames []string
+
+	err := database.WithinTx(ctx, r.db, func(ctx context.Context) error {
+		conn := database.Conn(ctx, r.db)
+
+		var email string
+		err := conn.QueryRowContext(ctx, `SELECT email FROM users WHERE id = ?`, userID).Scan(&email)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return account.ErrAccountNotFound
+			}
+			return err
+		}
+
+		if err := r.leaveHouseholds(ctx, conn, userID); err != nil {
+			return err
+		}
+		if err := r.transferHouseholdRows(ctx, conn, userID); err != nil {
+			return err
+		}
+
+		// Lo que queda del usuario es personal o de un hogar que ya no existe; los
+		// pagos de otros sobre esas deudas se van con ellas
+		rows, err := conn.QueryContext(ctx, `
+			SELECT receipt_filename FROM payments
+			WHERE (user_id = ? OR debt_id IN (SELECT id FROM debts WHERE user_id = ?))
+			  AND COALESCE(receipt_filename, '') != ''
+		`, userID, userID)
+		if err != nil {
+			return err
+		}
+		for rows.Next() {
+			var filename string
+			if err := rows.Scan(&filename); err != nil {
+				rows.Close()
+				return err
+			}
+			filenames = append(filenames, filename)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+
+		statements := []struct {
+			query string
+			args  []interface{}
+		}{
+			{`DELETE FROM payments WHERE user_id = ? OR debt_id IN (SELECT id FROM debts WHERE user_id = ?)`, []interface{}{userID, userID}},
+			{`DELETE FROM debts WHERE user_id = ?`, []interface{}{userID}},
+			{`DELETE FROM incomes WHERE user_id = ?`, []interface{}{userID}},
+			{`DELETE FROM security_events WHERE user_id = ?`, []interface{}{userID}},
+			{`DELETE FROM idempotency_keys WHERE user_id = ?`, []interface{}{userID}},
+			{`DELETE FROM external_login_states WHERE link_user_id = ?`, []interface{}{userID}},
+			{`DELETE FROM login_attempts WHERE scope = ? AND attempt_key = LOWER(TRIM(?))`, []interface{}{security.ScopeEmail, email}},
+			{`DELETE FROM users WHERE id = ?`, []interface{}{userID}},
+		}
+		for _, statement := range statements {
+			if _, err := conn.ExecContext(ctx, statement.query, statement.args...); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		if err == account.ErrAccountNotFound {
+			return nil, err
+		}
+		return nil, database.Wrap(account.ErrDatabaseError, err)
+	}
+
+	return filenames, nil
+}
+
+// transferHouseholdRows da las deudas e ingresos del usuario en hogares que siguen
+// existiendo al owner más antiguo del hogar, y sus pagos sobre deudas que no son
+// suyas al dueño de la deuda. Se llama después de leaveHouseholds, que ya ha
+// ascendido a otro owner donde hacía falta.
+func (r *repository) transferHouseholdRows(ctx context.Context, conn database.Executor, userID int) error {
+	for _, table := range []string{"debts", "incomes"} {
+		_, err := conn.ExecContext(ctx, `
+			UPDATE `+table+` SET version = version + 1, user_id = (
+				SELECT m.user_id FROM household_members m
+				WHERE m.household_id = `+table+`.household_id AND m.role = ?
+				ORDER BY m.created_at, m.user_id
+				LIMIT 1
+			)
+			WHERE user_id = ? AND household_id != 0
+			  AND EXISTS (
+				SELECT 1 FROM household_members m
+				WHERE m.household_id = `+table+`.household_id AND m.role = ?
+			  )
+		`, household.RoleOwner, userID, household.RoleOwner)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err := conn.ExecContext(ctx, `
+		UPDATE payments SET user_id = (SELECT d.user_id FROM debts d WHERE d.id = payments.debt_id)
+		WHERE user_id = ?
+		  AND debt_id IN (SELECT id FROM debts WHERE user_id != ?)
+	`, userID, userID)
+	return err
+}
+
+// leaveHouseholds saca al usuario de sus hogares sin dejarlos huérfanos: si era el
+// único owner asciende al miembro más antiguo y si era el único miembro borra el hogar.
+func (r *repository) leaveHouseholds(ctx context.Context, conn database.Executor, userID int) error {
+	_, err := conn.ExecContext(ctx, `
+		UPDATE household_members SET role = ?
+		WHERE (household_id, user_id) IN (
+			SELECT m.household_id, (
+				SELECT other.user_id FROM household_members other
+				WHERE other.household_id = m.household_id AND other.user_id != m.user_id
+				ORDER BY other.created_at, other.user_id
+				LIMIT 1
+			)
+			FROM household_members m
+			WHERE m.user_id = ? AND m.role = ?
+			  AND NOT EXISTS (
+				SELECT 1 FROM household_members o
+				WHERE o.household_id = m.household_id AND o.user_id != m.user_id AND o.role = ?
+			  )
+		)
+	`, household.RoleOwner, userID, household.RoleOwner, household.RoleOwner)
+	if err != nil {
+		return err
+	}
+
+	_, err = conn.ExecContext(ctx, `
+		DELETE FROM households
+		WHERE id IN (SELECT household_id FROM household_members WHERE user_id = ?)
+		  AND NOT EXISTS (
+			SELECT 1 FROM household_members o
+			WHERE o.household_id = households.id AND o.user_id != ?
+		  )
+	`, userID, userID)
+	if err != nil {
+		return err
+	}
+
+	_, err = conn.ExecContext(ctx, `DELETE FROM household_members WHERE user_id = ?`, userID)
+	return err
+}
+
+func (r *repository) GetExport(ctx context.Context, userID int) (*account.Export, error) {
+	export := &account.Export{
+		Identities:     []user.Identity{},
+		Households:     []household.Household{},
+		Debts:          []debt.Debt{},
+		Incomes:        []income.Income{},
+		Payments:       []payment.Payment{},
+		SecurityEvents: []security.Event{},
+	}
+
+	u := &export.User
+	err := r.db.QueryRowContext(ctx, `
+		SELECT id, email, totp_enabled, email_verified_at, deletion_scheduled_at, created_at, updated_at
+		FROM users
+		WHERE id = ?
+	`, userID).Scan(
+		&u.ID, &u.Email, &u.TwoFactorEnabled, &u.EmailVerifiedAt, &u.DeletionScheduledAt, &u.CreatedAt, &u.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, account.ErrAccountNotFound
+		}
+		return nil, database.Wrap(account.ErrDatabaseError, err)
+	}
+
+	err = r.queryRows(ctx, `
+		SELECT id, user_id, provider, subject, COALESCE(email, ''), created_at, last_login_at
+		FROM user_identities
+		WHERE user_id = ?
+		ORDER BY id
+	`, userID, func(rows *sql.Rows) error {
+		var i user.Identity
+		if err := rows.Scan(&i.ID, &i.UserID, &i.Provider, &i.Subject, &i.Email, &i.CreatedAt, &i.LastLoginAt); err != nil {
+			return err
+		}
+		export.Identities = append(export.Identities, i)
+		return nil
+	})
+	if err != nil {
+		return nil, database.Wrap(account.ErrDatabaseError, err)
+	}
+
+	err = r.queryRows(ctx, `
+		SELECT h.id, h.name, h.created_by, h.created_at, h.updated_at, m.role
+		FROM household_members m
+		JOIN households h ON h.id = m.household_id
+		WHERE m.user_id = ?
+		ORDER BY h.id
+	`, userID, func(rows *sql.Rows) error {
+		var h household.Household
+		if err := rows.Scan(&h.ID, &h.Name, &h.CreatedBy, &h.CreatedAt, &h.UpdatedAt, &h.Role); err != nil {
+			return err
+		}
+		export.Households = append(export.Households, h)
+		return nil
+	})
+	if err != nil {
+		return nil, database.Wrap(account.ErrDatabaseError, err)
+	}
+
+	err = r.queryRows(ctx, `
+		SELECT id, user_id, household_id, name, total_amount, remaining_amount, due_date, interest_rate,
+		       num_installments, installment_amount, payment_day, paid, version, created_at, updated_at, deleted_at
+		FROM debts
+		WHERE user_id = ?
+		ORDER BY id
+	`, userID, func(rows *sql.Rows) error {
+		var d debt.Debt
+		err := rows.Scan(
+			&d.ID, &d.UserID, &d.HouseholdID, &d.Name, &d.TotalAmount, &d.RemainingAmount, &d.DueDate,
+			&d.InterestRate, &d.NumInstallments, &d.InstallmentAmount,
+			&d.PaymentDay, &d.Paid, &d.Version, &d.CreatedAt, &d.UpdatedAt, &d.DeletedAt,
+		)
+		if err != nil {
+			return err
+		}
+		export.Debts = append(export.Debts, d)
+		return nil
+	})
+	if err != nil {
+		return nil, database.Wrap(account.ErrDatabaseError, err)
+	}
+
+	err = r.queryRows(ctx, `
+		SELECT id, user_id, household_id, amount, source, date, version, created_at, updated_at, deleted_at
+		FROM incomes
+		WHERE user_id = ?
+		ORDER BY id
+	`, userID, func(rows *sql.Rows) error {
+		var i income.Income
+		err := rows.Scan(
+			&i.ID, &i.UserID, &i.HouseholdID, &i.Amount, &i.Source, &i.Date, &i.Version,
+			&i.CreatedAt, &i.UpdatedAt, &i.DeletedAt,
+		)
+		if err != nil {
+			return err
+		}
+		export.Incomes = append(export.Incomes, i)
+		return nil
+	})
+	if err != nil {
+		return nil, database.Wrap(account.ErrDatabaseError, err)
+	}
+
+	err = r.queryRows(ctx, `
+		SELECT id, user_id, household_id, amount, debt_id, COALESCE(receipt_filename, ''), date, created_at, updated_at, deleted_at
+		FROM payments
+		WHERE user_id = ?
+		ORDER BY id
+	`, userID, func(rows *sql.Rows) error {
+		var p payment.Payment
+		err := rows.Scan(
+			&p.ID, &p.UserID, &p.HouseholdID, &p.Amount, &p.DebtID, &p.ReceiptFilename, &p.Date,
+			&p.CreatedAt, &p.UpdatedAt, &p.DeletedAt,
+		)
+		if err != nil {
+			return err
+		}
+		export.Payments = append(export.Payments, p)
+		return nil
+	})
+	if err != nil {
+		return nil, database.Wrap(account.ErrDatabaseError, err)
+	}
+
+	err = r.queryRows(ctx, `
+		SELECT id, user_id, event_type, COALESCE(ip_address, ''), details, created_at
+		FROM security_events
+		WHERE user_id = ?
+		ORDER BY id
+	`, userID, func(rows *sql.Rows) error {
+		var e security.Event
+		var details sql.NullString
+		if err := rows.Scan(&e.ID, &e.UserID, &e.Type, &e.IP, &details, &e.CreatedAt); err != nil {
+			return err
+		}
+		if details.Valid {
+			e.Details = []byte(details.String)
+		}
+		export.SecurityEvents = append(export.SecurityEvents, e)
+		return nil
+	})
+	if err != nil {
+		return nil, database.Wrap(account.ErrDatabaseError, err)
+	}
+
+	return export, nil
+}
+
+func (r *repository) queryRows(ctx context.Context, query string, userID int, scan func(rows *sql.Rows) error) error {
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		if err := scan(rows); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
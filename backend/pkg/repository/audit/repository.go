@@ -0,0 +1,158 @@
+package audit
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+
+	"github.com/payvue/payvue-backend/pkg/domain/audit"
+	"github.com/payvue/payvue-backend/pkg/repository/database"
+)
+
+type repository struct {
+	db *sql.DB
+}
+
+func NewRepository(db *sql.DB) audit.Repository {
+	return &repository{
+		db: db,
+	}
+}
+
+// fnError distingue los errores devueltos por fn de los fallos propios de la transacción.
+type fnError struct {
+	err error
+}
+
+func (e fnError) Error() string {
+	return e.err.Error()
+}
+
+func (r *repository) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	err := database.WithinTx(ctx, r.db, func(ctx context.Context) error {
+		if err := fn(ctx); err != nil {
+			return fnError{err: err}
+		}
+		return nil
+	})
+
+	if err != nil {
+		var fe fnError
+		if errors.As(err, &fe) {
+			return fe.err
+		}
+		return database.Wrap(audit.ErrDatabaseError, err)
+	}
+
+	return nil
+}
+
+func (r *repository) CreateEntry(ctx context.Context, e *audit.Entry) error {
+	query := `
+		INSERT INTO audit_log (actor_id, entity, entity_id, action, before_data, after_data,
+		                       request_id, ip_address, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+		RETURNING id
+	`
+
+	err := database.Conn(ctx, r.db).QueryRowContext(ctx, query,
+		e.ActorID, e.Entity, e.EntityID, e.Action, nullableJSON(e.Before), nullableJSON(e.After),
+		e.RequestID, e.IP, e.CreatedAt,
+	).Scan(&e.ID)
+
+	if err != nil {
+		return database.Wrap(audit.ErrDatabaseError, err)
+	}
+
+	return nil
+}
+
+func (r *repository) GetEntries(ctx context.Context, filter audit.Filter) ([]audit.Entry, error) {
+	// Los cambios del usuario y los de las deudas, ingresos y pagos de su alcance,
+	// aunque los hiciera otro miembro del hogar
+	scope, scopeArgs := database.ScopeCondition("", filter.Scope)
+	visible := []string{"actor_id = ?"}
+	args := []interface{}{filter.Scope.UserID}
+	for _, e := range []struct{ entity, table string }{
+		{audit.EntityDebt, "debts"},
+		{audit.EntityIncome, "incomes"},
+		{audit.EntityPayment, "payments"},
+	} {
+		visible = append(visible, "(entity = ? AND entity_id IN (SELECT id FROM "+e.table+" WHERE "+scope+"))")
+		args = append(args, e.entity)
+		args = append(args, scopeArgs...)
+	}
+	conditions := []string{"(" + strings.Join(visible, " OR ") + ")"}
+	if filter.Entity != "" {
+		conditions = append(conditions, "entity = ?")
+		args = append(args, filter.Entity)
+	}
+	if filter.EntityID > 0 {
+		conditions = append(conditions, "entity_id = ?")
+		args = append(args, filter.EntityID)
+	}
+	if filter.Action != "" {
+		conditions = append(conditions, "action = ?")
+		args = append(args, filter.Action)
+	}
+	if !filter.From.IsZero() {
+		conditions = append(conditions, "created_at >= ?")
+		args = append(args, filter.From)
+	}
+	if !filter.To.IsZero() {
+		conditions = append(conditions, "created_at < ?")
+		args = append(args, filter.To)
+	}
+
+	query := `
+		SELECT id, actor_id, entity, entity_id, action, before_data, after_data,
+		       COALESCE(request_id, ''), COALESCE(ip_address, ''), created_at
+		FROM audit_log
+		WHERE ` + strings.Join(conditions, " AND ") + `
+		ORDER BY id DESC LIMIT ?`
+	args = append(args, filter.Limit)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, database.Wrap(audit.ErrDatabaseError, err)
+	}
+	defer rows.Close()
+
+	var entries []audit.Entry
+	for rows.Next() {
+		var e audit.Entry
+		var before, after sql.NullString
+		err := rows.Scan(
+			&e.ID, &e.ActorID, &e.Entity, &e.EntityID, &e.Action, &before, &after,
+			&e.RequestID, &e.IP, &e.CreatedAt,
+		)
+		if err != nil {
+			return nil, database.Wrap(audit.ErrDatabaseError, err)
+		}
+		if before.Valid {
+			e.Before = []byte(before.String)
+		}
+		if after.Valid {
+			e.After = []byte(after.String)
+		}
+		entries = append(entries, e)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, database.Wrap(audit.ErrDatabaseError, err)
+	}
+
+	if entries == nil {
+		entries = []audit.Entry{}
+	}
+
+	return entries, nil
+}
+
+func nullableJSON(data []byte) interface{} {
+	if len(data) == 0 {
+		return nil
+	}
+	return string(data)
+}
@@ -2,40 +2,157 @@ package database
 
 import (
 	"database/sql"
+	"errors"
 	"fmt"
-	"log"
-
-	_ "github.com/mattn/go-sqlite3"
+	"log/slog"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
 )
 
-func InitDB(dbPath string) (*sql.DB, error) {
-	db, err := sql.Open("sqlite3", dbPath)
+// Config elige el motor, dónde está la base de datos y cómo se abre.
+type Config struct {
+	Engine Engine
+	// Path es el fichero de SQLite.
+	Path string
+	// URL es la cadena de conexión de PostgreSQL (postgres://...).
+	URL string
+	// ReadOnly abre la base de datos en solo lectura y no crea tablas ni aplica
+	// migraciones: de eso se encargan los binarios que escriben.
+	ReadOnly bool
+	// JournalMode y Synchronous son los pragmas de SQLite del mismo nombre; vacíos,
+	// se quedan los de SQLite. BusyTimeout es lo que espera una consulta a que
+	// otro proceso suelte el bloqueo antes de fallar con SQLITE_BUSY, y
+	// BusyRetries las veces que se repite después.
+	JournalMode string
+	Synchronous string
+	BusyTimeout time.Duration
+	BusyRetries int
+	// MaxOpenConns y MaxIdleConns dimensionan el pool; con 0 se quedan los
+	// valores de database/sql.
+	MaxOpenConns int
+	MaxIdleConns int
+}
+
+func InitDB(cfg Config) (*sql.DB, error) {
+	db, err := open(cfg)
 	if err != nil {
 		return nil, fmt.Errorf("error opening database: %w", err)
 	}
 
+	db.SetMaxOpenConns(cfg.MaxOpenConns)
+	if cfg.MaxIdleConns > 0 {
+		db.SetMaxIdleConns(cfg.MaxIdleConns)
+	}
+
 	if err := db.Ping(); err != nil {
+		db.Close()
+		if cfg.ReadOnly {
+			return nil, fmt.Errorf("error connecting to read-only database (the writer creates it): %w", err)
+		}
 		return nil, fmt.Errorf("error connecting to database: %w", err)
 	}
 
+	if cfg.ReadOnly {
+		return db, nil
+	}
+
 	if err := createTables(db); err != nil {
+		db.Close()
 		return nil, fmt.Errorf("error creating tables: %w", err)
 	}
 
-	// Ejecutar migración para añadir user_id si no existe
-	if err := migrateUserID(db); err != nil {
-		log.Printf("Migration warning: %v", err)
+	if err := runMigrations(db); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("error running migrations: %w", err)
 	}
 
 	return db, nil
 }
 
-func createTables(db *sql.DB) error {
-	schema := `
+func open(cfg Config) (*sql.DB, error) {
+	switch cfg.Engine {
+	case SQLite, "":
+		return sql.OpenDB(&connector{driver: sqliteDriver, dsn: sqliteDSN(cfg), busyRetries: cfg.BusyRetries}), nil
+	case Postgres:
+		if postgresDriver == nil {
+			return nil, errors.New("built without PostgreSQL support: rebuild with -tags postgres")
+		}
+		dsn, err := postgresDSN(cfg)
+		if err != nil {
+			return nil, err
+		}
+		return sql.OpenDB(&connector{driver: postgresDriver, dsn: dsn}), nil
+	default:
+		return nil, fmt.Errorf("unknown database engine %q", cfg.Engine)
+	}
+}
+
+// sqliteDSN añade a la ruta los parámetros de go-sqlite3, que los aplica a cada
+// conexión del pool.
+func sqliteDSN(cfg Config) string {
+	params := url.Values{}
+	// SQLite no aplica las claves foráneas (ni sus ON DELETE CASCADE) salvo que se
+	// active en cada conexión.
+	params.Set("_foreign_keys", "on")
+	params.Set("_busy_timeout", strconv.FormatInt(cfg.BusyTimeout.Milliseconds(), 10))
+	if cfg.Synchronous != "" {
+		params.Set("_synchronous", strings.ToUpper(cfg.Synchronous))
+	}
+
+	dsn := cfg.Path
+	if cfg.ReadOnly {
+		// mode=ro solo lo entiende SQLite en una URI file:. El modo del journal lo
+		// fija quien escribe; cambiarlo es una escritura.
+		if !strings.HasPrefix(dsn, "file:") {
+			dsn = "file:" + dsn
+		}
+		params.Set("mode", "ro")
+	} else {
+		if cfg.JournalMode != "" {
+			params.Set("_journal_mode", strings.ToUpper(cfg.JournalMode))
+		}
+		// Las transacciones toman el bloqueo de escritura en el BEGIN, donde se
+		// aplica el busy_timeout, y no a mitad, donde SQLite devuelve SQLITE_BUSY
+		// sin esperar para evitar un interbloqueo.
+		params.Set("_txlock", "immediate")
+	}
+
+	if strings.Contains(dsn, "?") {
+		return dsn + "&" + params.Encode()
+	}
+	return dsn + "?" + params.Encode()
+}
+
+// postgresDSN marca las sesiones como de solo lectura con ReadOnly; pgx pasa los
+// parámetros que no conoce al servidor.
+func postgresDSN(cfg Config) (string, error) {
+	if !cfg.ReadOnly {
+		return cfg.URL, nil
+	}
+	// El error de url.Parse lleva la URL, con la contraseña
+	u, err := url.Parse(cfg.URL)
+	if err != nil {
+		return "", errors.New("invalid database URL")
+	}
+	query := u.Query()
+	query.Set("default_transaction_read_only", "on")
+	u.RawQuery = query.Encode()
+	return u.String(), nil
+}
+
+// schema está escrito para SQLite; Engine.ddl lo traduce para PostgreSQL.
+const schema = `
 	CREATE TABLE IF NOT EXISTS users (
 		id INTEGER PRIMARY KEY AUTOINCREMENT,
 		email TEXT UNIQUE NOT NULL,
 		password_hash TEXT NOT NULL,
+		totp_secret TEXT,
+		totp_enabled BOOLEAN NOT NULL DEFAULT 0,
+		totp_last_step INTEGER NOT NULL DEFAULT 0,
+		email_verified_at DATETIME,
+		deletion_scheduled_at DATETIME,
 		created_at DATETIME NOT NULL,
 		updated_at DATETIME NOT NULL
 	);
@@ -43,6 +160,7 @@ func createTables(db *sql.DB) error {
 	CREATE TABLE IF NOT EXISTS debts (
 		id INTEGER PRIMARY KEY AUTOINCREMENT,
 		user_id INTEGER NOT NULL DEFAULT 0,
+		household_id INTEGER NOT NULL DEFAULT 0,
 		name TEXT NOT NULL,
 		total_amount REAL NOT NULL,
 		remaining_amount REAL NOT NULL,
@@ -52,54 +170,243 @@ func createTables(db *sql.DB) error {
 		installment_amount REAL NOT NULL,
 		payment_day INTEGER NOT NULL,
 		paid BOOLEAN DEFAULT 0,
+		version INTEGER NOT NULL DEFAULT 1,
 		created_at DATETIME NOT NULL,
 		updated_at DATETIME NOT NULL,
+		deleted_at DATETIME,
 		FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
 	);
 
 	CREATE TABLE IF NOT EXISTS incomes (
 		id INTEGER PRIMARY KEY AUTOINCREMENT,
 		user_id INTEGER NOT NULL DEFAULT 0,
+		household_id INTEGER NOT NULL DEFAULT 0,
 		amount REAL NOT NULL,
 		source TEXT NOT NULL,
 		date DATETIME NOT NULL,
+		version INTEGER NOT NULL DEFAULT 1,
 		created_at DATETIME NOT NULL,
 		updated_at DATETIME NOT NULL,
+		deleted_at DATETIME,
 		FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
 	);
 
 	CREATE TABLE IF NOT EXISTS payments (
 		id INTEGER PRIMARY KEY AUTOINCREMENT,
 		user_id INTEGER NOT NULL DEFAULT 0,
+		household_id INTEGER NOT NULL DEFAULT 0,
 		amount REAL NOT NULL,
 		debt_id INTEGER NOT NULL,
 		receipt_filename TEXT,
 		date DATETIME NOT NULL,
 		created_at DATETIME NOT NULL,
 		updated_at DATETIME NOT NULL,
+		deleted_at DATETIME,
 		FOREIGN KEY (debt_id) REFERENCES debts(id) ON DELETE CASCADE,
 		FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
 	);
 
+	CREATE TABLE IF NOT EXISTS audit_log (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		actor_id INTEGER NOT NULL DEFAULT 0,
+		entity TEXT NOT NULL,
+		entity_id INTEGER NOT NULL,
+		action TEXT NOT NULL,
+		before_data TEXT,
+		after_data TEXT,
+		request_id TEXT,
+		ip_address TEXT,
+		created_at DATETIME NOT NULL
+	);
+
+	CREATE TABLE IF NOT EXISTS idempotency_keys (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		user_id INTEGER NOT NULL DEFAULT 0,
+		idempotency_key TEXT NOT NULL,
+		method TEXT NOT NULL,
+		path TEXT NOT NULL,
+		request_hash TEXT NOT NULL,
+		status_code INTEGER NOT NULL DEFAULT 0,
+		response_headers TEXT,
+		response_body BLOB,
+		created_at DATETIME NOT NULL,
+		completed_at DATETIME,
+		UNIQUE (user_id, idempotency_key)
+	);
+
+	CREATE TABLE IF NOT EXISTS recovery_codes (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		user_id INTEGER NOT NULL,
+		code_hash TEXT NOT NULL,
+		used_at DATETIME,
+		created_at DATETIME NOT NULL,
+		FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+	);
+
+	CREATE TABLE IF NOT EXISTS two_factor_challenges (
+		token_hash TEXT PRIMARY KEY,
+		user_id INTEGER NOT NULL,
+		attempts INTEGER NOT NULL DEFAULT 0,
+		expires_at DATETIME NOT NULL,
+		created_at DATETIME NOT NULL,
+		FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+	);
+
+	CREATE TABLE IF NOT EXISTS login_attempts (
+		scope TEXT NOT NULL,
+		attempt_key TEXT NOT NULL,
+		failures INTEGER NOT NULL DEFAULT 0,
+		last_failure_at DATETIME NOT NULL,
+		locked_until DATETIME,
+		PRIMARY KEY (scope, attempt_key)
+	);
+
+	CREATE TABLE IF NOT EXISTS security_events (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		user_id INTEGER NOT NULL DEFAULT 0,
+		event_type TEXT NOT NULL,
+		ip_address TEXT,
+		details TEXT,
+		created_at DATETIME NOT NULL
+	);
+
+	CREATE TABLE IF NOT EXISTS rate_limits (
+		bucket_key TEXT PRIMARY KEY,
+		hits INTEGER NOT NULL,
+		reset_at INTEGER NOT NULL
+	);
+
+	CREATE TABLE IF NOT EXISTS user_identities (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		user_id INTEGER NOT NULL,
+		provider TEXT NOT NULL,
+		subject TEXT NOT NULL,
+		email TEXT,
+		created_at DATETIME NOT NULL,
+		last_login_at DATETIME NOT NULL,
+		UNIQUE (provider, subject),
+		FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+	);
+
+	CREATE TABLE IF NOT EXISTS households (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		name TEXT NOT NULL,
+		created_by INTEGER NOT NULL,
+		created_at DATETIME NOT NULL,
+		updated_at DATETIME NOT NULL
+	);
+
+	CREATE TABLE IF NOT EXISTS household_members (
+		household_id INTEGER NOT NULL,
+		user_id INTEGER NOT NULL,
+		role TEXT NOT NULL,
+		created_at DATETIME NOT NULL,
+		PRIMARY KEY (household_id, user_id),
+		FOREIGN KEY (household_id) REFERENCES households(id) ON DELETE CASCADE,
+		FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+	);
+
+	CREATE TABLE IF NOT EXISTS household_invitations (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		household_id INTEGER NOT NULL,
+		token_hash TEXT UNIQUE NOT NULL,
+		role TEXT NOT NULL,
+		email TEXT,
+		invited_by INTEGER NOT NULL,
+		expires_at DATETIME NOT NULL,
+		accepted_by INTEGER,
+		accepted_at DATETIME,
+		created_at DATETIME NOT NULL,
+		FOREIGN KEY (household_id) REFERENCES households(id) ON DELETE CASCADE
+	);
+
+	CREATE TABLE IF NOT EXISTS external_login_states (
+		state_hash TEXT PRIMARY KEY,
+		code_verifier TEXT NOT NULL,
+		nonce TEXT NOT NULL,
+		link_user_id INTEGER NOT NULL DEFAULT 0,
+		expires_at DATETIME NOT NULL,
+		created_at DATETIME NOT NULL
+	);
+
+	CREATE TABLE IF NOT EXISTS access_tokens (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		user_id INTEGER NOT NULL,
+		name TEXT NOT NULL,
+		scope TEXT NOT NULL,
+		token_hash TEXT UNIQUE NOT NULL,
+		hint TEXT NOT NULL,
+		expires_at DATETIME,
+		last_used_at DATETIME,
+		created_at DATETIME NOT NULL,
+		FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+	);
+
 	CREATE INDEX IF NOT EXISTS idx_debts_user_id ON debts(user_id);
 	CREATE INDEX IF NOT EXISTS idx_incomes_user_id ON incomes(user_id);
 	CREATE INDEX IF NOT EXISTS idx_payments_user_id ON payments(user_id);
-	`
+	CREATE INDEX IF NOT EXISTS idx_payments_debt_id ON payments(debt_id);
+	CREATE INDEX IF NOT EXISTS idx_audit_log_entity ON audit_log(entity, entity_id);
+	CREATE INDEX IF NOT EXISTS idx_audit_log_actor_id ON audit_log(actor_id);
+	CREATE INDEX IF NOT EXISTS idx_idempotency_keys_created_at ON idempotency_keys(created_at);
+	CREATE INDEX IF NOT EXISTS idx_security_events_user_id ON security_events(user_id);
+	CREATE INDEX IF NOT EXISTS idx_recovery_codes_user_id ON recovery_codes(user_id);
+	CREATE INDEX IF NOT EXISTS idx_user_identities_user_id ON user_identities(user_id);
+	CREATE INDEX IF NOT EXISTS idx_household_members_user_id ON household_members(user_id);
+	CREATE INDEX IF NOT EXISTS idx_household_invitations_household_id ON household_invitations(household_id);
+	CREATE INDEX IF NOT EXISTS idx_access_tokens_user_id ON access_tokens(user_id);
+`
+
+// auditTriggers hacen que el log de auditoría sea de solo inserción. Los
+// triggers no tienen una sintaxis común, así que hay una versión por motor.
+var auditTriggers = map[Engine]string{
+	SQLite: `
+	CREATE TRIGGER IF NOT EXISTS audit_log_no_update BEFORE UPDATE ON audit_log
+	BEGIN
+		SELECT RAISE(ABORT, 'audit_log is append-only');
+	END;
+
+	CREATE TRIGGER IF NOT EXISTS audit_log_no_delete BEFORE DELETE ON audit_log
+	BEGIN
+		SELECT RAISE(ABORT, 'audit_log is append-only');
+	END;
+	`,
+	Postgres: `
+	CREATE OR REPLACE FUNCTION audit_log_append_only() RETURNS trigger AS $$
+	BEGIN
+		RAISE EXCEPTION 'audit_log is append-only';
+	END;
+	$$ LANGUAGE plpgsql;
+
+	DROP TRIGGER IF EXISTS audit_log_no_update ON audit_log;
+	CREATE TRIGGER audit_log_no_update BEFORE UPDATE ON audit_log
+		FOR EACH ROW EXECUTE FUNCTION audit_log_append_only();
+
+	DROP TRIGGER IF EXISTS audit_log_no_delete ON audit_log;
+	CREATE TRIGGER audit_log_no_delete BEFORE DELETE ON audit_log
+		FOR EACH ROW EXECUTE FUNCTION audit_log_append_only();
+	`,
+}
 
-	_, err := db.Exec(schema)
+func createTables(db *sql.DB) error {
+	engine := EngineOf(db)
+	if _, err := db.Exec(engine.ddl(schema)); err != nil {
+		return err
+	}
+
+	_, err := db.Exec(auditTriggers[engine])
 	return err
 }
 
 // Migración para añadir user_id a tablas existentes
 func migrateUserID(db *sql.DB) error {
 	// Verificar si la columna user_id existe en debts
-	var count int
-	err := db.QueryRow("SELECT COUNT(*) FROM pragma_table_info('debts') WHERE name='user_id'").Scan(&count)
+	exists, err := hasColumn(db, "debts", "user_id")
 	if err != nil {
 		return err
 	}
 
-	if count == 0 {
+	if !exists {
 		// Añadir columna user_id a tablas existentes
 		migrations := []string{
 			"ALTER TABLE debts ADD COLUMN user_id INTEGER NOT NULL DEFAULT 0",
@@ -107,12 +414,112 @@ func migrateUserID(db *sql.DB) error {
 			"ALTER TABLE payments ADD COLUMN user_id INTEGER NOT NULL DEFAULT 0",
 		}
 		for _, m := range migrations {
-			_, err := db.Exec(m)
+			_, err := db.Exec(EngineOf(db).ddl(m))
 			if err != nil {
-				log.Printf("Migration step skipped (may already exist): %v", err)
+				slog.Debug("migration step skipped (may already exist)", slog.Any("error", err))
 			}
 		}
 	}
 
 	return nil
 }
+
+// Migración para la papelera: añade deleted_at a las tablas financieras
+func migrateDeletedAt(db *sql.DB) error {
+	for _, table := range []string{"debts", "incomes", "payments"} {
+		if err := addColumnIfMissing(db, table, "deleted_at", "DATETIME"); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Migración para el control de concurrencia optimista en deudas e ingresos
+func migrateVersion(db *sql.DB) error {
+	for _, table := range []string{"debts", "incomes"} {
+		if err := addColumnIfMissing(db, table, "version", "INTEGER NOT NULL DEFAULT 1"); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Migración para la verificación en dos pasos (TOTP)
+func migrateTwoFactor(db *sql.DB) error {
+	columns := []struct{ name, definition string }{
+		{"totp_secret", "TEXT"},
+		{"totp_enabled", "BOOLEAN NOT NULL DEFAULT 0"},
+		{"totp_last_step", "INTEGER NOT NULL DEFAULT 0"},
+	}
+	for _, c := range columns {
+		if err := addColumnIfMissing(db, "users", c.name, c.definition); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Migración para los hogares compartidos: household_id 0 es un registro personal
+func migrateHouseholds(db *sql.DB) error {
+	for _, table := range []string{"debts", "incomes", "payments"} {
+		if err := addColumnIfMissing(db, table, "household_id", "INTEGER NOT NULL DEFAULT 0"); err != nil {
+			return err
+		}
+
+		index := fmt.Sprintf("CREATE INDEX IF NOT EXISTS idx_%s_household_id ON %s(household_id)", table, table)
+		if _, err := db.Exec(index); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Migración para la verificación del correo; las cuentas existentes quedan sin verificar
+func migrateEmailVerification(db *sql.DB) error {
+	return addColumnIfMissing(db, "users", "email_verified_at", "DATETIME")
+}
+
+// Migración para el borrado de cuentas. Con las claves foráneas activas, los
+// registros sin usuario (user_id 0) necesitan una fila en users a la que apuntar;
+// la fila 0 no tiene contraseña y su email no es válido, así que nadie puede usarla.
+func migrateAccountDeletion(db *sql.DB) error {
+	if err := addColumnIfMissing(db, "users", "deletion_scheduled_at", "DATETIME"); err != nil {
+		return err
+	}
+
+	_, err := db.Exec(`
+		INSERT INTO users (id, email, password_hash, email_verified_at, created_at, updated_at)
+		VALUES (0, 'anonymous', '', NULL, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
+		ON CONFLICT DO NOTHING
+	`)
+	return err
+}
+
+func addColumnIfMissing(db *sql.DB, table, column, definition string) error {
+	exists, err := hasColumn(db, table, column)
+	if err != nil || exists {
+		return err
+	}
+
+	_, err = db.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, column, EngineOf(db).ddl(definition)))
+	return err
+}
+
+func hasColumn(db *sql.DB, table, column string) (bool, error) {
+	var count int
+	var err error
+	if EngineOf(db) == Postgres {
+		err = db.QueryRow(`
+			SELECT COUNT(*) FROM information_schema.columns
+			WHERE table_schema = current_schema() AND table_name = ? AND column_name = ?
+		`, table, column).Scan(&count)
+	} else {
+		query := fmt.Sprintf("SELECT COUNT(*) FROM pragma_table_info('%s') WHERE name = ?", table)
+		err = db.QueryRow(query, column).Scan(&count)
+	}
+	return count > 0, err
+}
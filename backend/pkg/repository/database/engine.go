@@ -0,0 +1,85 @@
+package database
+
+import (
+	"database/sql"
+	"strconv"
+	"strings"
+)
+
+// Engine es el motor de base de datos. Los repositorios escriben un único SQL,
+// con marcadores "?" y el esquema en tipos de SQLite; lo que cambia con
+// PostgreSQL (marcadores $1, tipos, catálogo) se resuelve en este paquete.
+type Engine string
+
+const (
+	SQLite   Engine = "sqlite"
+	Postgres Engine = "postgres"
+)
+
+// EngineOf devuelve el motor de una conexión abierta con InitDB.
+func EngineOf(db *sql.DB) Engine {
+	if d, ok := db.Driver().(*instrumentedDriver); ok {
+		return d.engine
+	}
+	return SQLite
+}
+
+// system es el nombre del motor en los spans (db.system).
+func (e Engine) system() string {
+	if e == Postgres {
+		return "postgresql"
+	}
+	return "sqlite"
+}
+
+// postgresTypes traduce los tipos del esquema. Los enteros pasan a BIGINT
+// porque en SQLite INTEGER es de 64 bits; REAL a DOUBLE PRECISION por lo mismo.
+// El orden importa: se prueban primero las formas más largas.
+var postgresTypes = strings.NewReplacer(
+	"INTEGER PRIMARY KEY AUTOINCREMENT", "BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY",
+	"INTEGER", "BIGINT",
+	"DATETIME", "TIMESTAMPTZ",
+	"REAL", "DOUBLE PRECISION",
+	"BLOB", "BYTEA",
+	"BOOLEAN NOT NULL DEFAULT 0", "BOOLEAN NOT NULL DEFAULT FALSE",
+	"BOOLEAN DEFAULT 0", "BOOLEAN DEFAULT FALSE",
+)
+
+// ddl adapta al motor una sentencia del esquema escrita para SQLite.
+func (e Engine) ddl(statement string) string {
+	if e == Postgres {
+		return postgresTypes.Replace(statement)
+	}
+	return statement
+}
+
+// rebind cambia los marcadores "?" por $1, $2... para PostgreSQL. Respeta los
+// literales entre comillas simples y los identificadores entre comillas dobles.
+func rebind(query string) string {
+	if !strings.Contains(query, "?") {
+		return query
+	}
+
+	var b strings.Builder
+	b.Grow(len(query) + 16)
+	n := 0
+	var quote byte
+	for i := 0; i < len(query); i++ {
+		c := query[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		case c == '?':
+			n++
+			b.WriteByte('$')
+			b.WriteString(strconv.Itoa(n))
+			continue
+		}
+		b.WriteByte(c)
+	}
+	return b.String()
+}
@@ -0,0 +1,20 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Wrap devuelve domainErr con la causa original envuelta: el servicio y la capa
+// REST siguen comparando con errors.Is y el log conserva el error de SQLite.
+// Si cause ya lleva domainErr (p. ej. viene de dentro de una transacción) se
+// devuelve tal cual para no repetir el prefijo.
+// Cada error nuevo cuenta en payvue_db_errors_total.
+func Wrap(domainErr, cause error) error {
+	if errors.Is(cause, domainErr) {
+		return cause
+	}
+	repository, method := caller()
+	queryErrors.Inc(repository, method)
+	return fmt.Errorf("%w: %w", domainErr, cause)
+}
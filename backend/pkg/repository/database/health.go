@@ -0,0 +1,46 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+)
+
+// Ping comprueba que la base de datos responde a una consulta que lee una
+// tabla, no solo que el pool tiene una conexión abierta.
+func Ping(ctx context.Context, db *sql.DB) error {
+	if err := db.PingContext(ctx); err != nil {
+		return err
+	}
+	var applied int
+	return db.QueryRowContext(ctx, "SELECT COUNT(*) FROM schema_migrations").Scan(&applied)
+}
+
+// CheckWritable comprueba que se puede escribir sin escribir nada. En SQLite
+// toma y suelta el bloqueo de escritura: falla si el fichero es de solo lectura
+// o si otro proceso lo tiene bloqueado durante más tiempo del que da ctx. En
+// PostgreSQL falla si la sesión es de solo lectura, como en una réplica.
+func CheckWritable(ctx context.Context, db *sql.DB) error {
+	if EngineOf(db) == Postgres {
+		var readOnly string
+		if err := db.QueryRowContext(ctx, "SHOW transaction_read_only").Scan(&readOnly); err != nil {
+			return err
+		}
+		if readOnly == "on" {
+			return errors.New("database is read-only")
+		}
+		return nil
+	}
+
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, "BEGIN IMMEDIATE"); err != nil {
+		return err
+	}
+	_, err = conn.ExecContext(context.Background(), "ROLLBACK")
+	return err
+}
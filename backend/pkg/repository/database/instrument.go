@@ -0,0 +1,333 @@
+package database
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"log/slog"
+	mathrand "math/rand"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/mattn/go-sqlite3"
+	"github.com/payvue/payvue-backend/pkg/metrics"
+	"github.com/payvue/payvue-backend/pkg/tracing"
+)
+
+var (
+	queryDuration = metrics.Default.NewHistogram("payvue_db_query_duration_seconds",
+		"Duración de las consultas a la base de datos por repositorio y método.",
+		[]float64{0.0005, 0.001, 0.0025, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1},
+		"repository", "method")
+	queryErrors = metrics.Default.NewCounter("payvue_db_errors_total",
+		"Errores de base de datos devueltos por los repositorios, por repositorio y método.",
+		"repository", "method")
+	queryBusyRetries = metrics.Default.NewCounter("payvue_db_busy_retries_total",
+		"Consultas repetidas porque SQLite estaba bloqueado (SQLITE_BUSY), por repositorio y método.",
+		"repository", "method")
+)
+
+// Los drivers añaden métricas y trazas: cada consulta se mide y se atribuye al
+// método del repositorio que la hace, sin tener que decorar cada repositorio.
+var sqliteDriver = &instrumentedDriver{driver: &sqlite3.SQLiteDriver{}, engine: SQLite}
+
+// postgresDriver lo asigna postgres.go, que solo se compila con -tags postgres
+// para no obligar a todas las builds a llevar el driver.
+var postgresDriver *instrumentedDriver
+
+func registerPostgres(d driver.Driver) {
+	postgresDriver = &instrumentedDriver{driver: d, engine: Postgres}
+}
+
+type instrumentedDriver struct {
+	driver driver.Driver
+	engine Engine
+}
+
+// driverConn son las interfaces de driver.Conn que implementan go-sqlite3 y
+// pgx y de las que depende database/sql para no caer en los caminos lentos.
+type driverConn interface {
+	driver.Conn
+	driver.ConnBeginTx
+	driver.ConnPrepareContext
+	driver.ExecerContext
+	driver.QueryerContext
+	driver.Pinger
+}
+
+func (d *instrumentedDriver) Open(name string) (driver.Conn, error) {
+	return d.open(name)
+}
+
+func (d *instrumentedDriver) open(name string) (*instrumentedConn, error) {
+	conn, err := d.driver.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	dc, ok := conn.(driverConn)
+	if !ok {
+		conn.Close()
+		return nil, fmt.Errorf("unexpected %s connection type %T", d.engine, conn)
+	}
+	return &instrumentedConn{driverConn: dc, engine: d.engine}, nil
+}
+
+// connector abre las conexiones de un pool con la configuración de InitDB.
+type connector struct {
+	driver *instrumentedDriver
+	dsn    string
+	// busyRetries es cuántas veces se repite una consulta que falla con
+	// SQLITE_BUSY después de agotar el busy_timeout.
+	busyRetries int
+}
+
+func (c *connector) Connect(context.Context) (driver.Conn, error) {
+	conn, err := c.driver.open(c.dsn)
+	if err != nil {
+		return nil, err
+	}
+	conn.busyRetries = c.busyRetries
+	return conn, nil
+}
+
+func (c *connector) Driver() driver.Driver {
+	return c.driver
+}
+
+type instrumentedConn struct {
+	driverConn
+	engine      Engine
+	busyRetries int
+	// inTx marca la transacción abierta con BeginTx; database/sql no usa una
+	// conexión desde dos goroutines a la vez, así que no necesita mutex.
+	inTx bool
+}
+
+// bind adapta los marcadores de la consulta al motor.
+func (c *instrumentedConn) bind(query string) string {
+	if c.engine == Postgres {
+		return rebind(query)
+	}
+	return query
+}
+
+func (c *instrumentedConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	return c.driverConn.PrepareContext(ctx, c.bind(query))
+}
+
+func (c *instrumentedConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	var tx driver.Tx
+	err := c.retryBusy(ctx, func() (err error) {
+		tx, err = c.driverConn.BeginTx(ctx, opts)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	c.inTx = true
+	return &instrumentedTx{Tx: tx, conn: c}, nil
+}
+
+func (c *instrumentedConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	done := observe(ctx, c.engine, query)
+	var result driver.Result
+	err := c.retryBusy(ctx, func() (err error) {
+		result, err = c.driverConn.ExecContext(ctx, c.bind(query), args)
+		return err
+	})
+	done(err)
+	return result, err
+}
+
+func (c *instrumentedConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	done := observe(ctx, c.engine, query)
+	var rows driver.Rows
+	err := c.retryBusy(ctx, func() (err error) {
+		rows, err = c.driverConn.QueryContext(ctx, c.bind(query), args)
+		if err != nil || !c.retries() {
+			return err
+		}
+		rows, err = prefetch(rows)
+		return err
+	})
+	done(err)
+	return rows, err
+}
+
+type instrumentedTx struct {
+	driver.Tx
+	conn *instrumentedConn
+}
+
+func (t *instrumentedTx) Commit() error {
+	t.conn.inTx = false
+	return t.Tx.Commit()
+}
+
+func (t *instrumentedTx) Rollback() error {
+	t.conn.inTx = false
+	return t.Tx.Rollback()
+}
+
+// retries indica si las consultas de la conexión se repiten con SQLITE_BUSY.
+// Dentro de una transacción no: el bloqueo de escritura se toma en el BEGIN
+// (_txlock=immediate), que sí se repite, y repetir una sola sentencia de una
+// transacción a medias no es seguro.
+func (c *instrumentedConn) retries() bool {
+	return c.engine == SQLite && c.busyRetries > 0 && !c.inTx
+}
+
+// retryBusy ejecuta op y la repite, con backoff exponencial y jitter, mientras
+// falle con SQLITE_BUSY, hasta busyRetries veces o hasta que venza ctx. Cada
+// intento ya ha esperado el busy_timeout de SQLite.
+func (c *instrumentedConn) retryBusy(ctx context.Context, op func() error) error {
+	err := op()
+	for attempt := 0; attempt < c.busyRetries && c.retries() && isBusy(err); attempt++ {
+		queryBusyRetries.Inc(caller())
+
+		delay := busyBackoff << attempt
+		delay = delay/2 + time.Duration(mathrand.Int63n(int64(delay/2)+1))
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		}
+		err = op()
+	}
+	return err
+}
+
+const busyBackoff = 50 * time.Millisecond
+
+func isBusy(err error) bool {
+	var sqliteErr sqlite3.Error
+	return errors.As(err, &sqliteErr) && sqliteErr.Code == sqlite3.ErrBusy
+}
+
+// prefetch lee la primera fila. go-sqlite3 no ejecuta la sentencia hasta el
+// primer Next, así que es ahí donde llega SQLITE_BUSY en los SELECT y en los
+// INSERT ... RETURNING; leyéndola aquí, la consulta entera se puede repetir.
+func prefetch(rows driver.Rows) (driver.Rows, error) {
+	first := make([]driver.Value, len(rows.Columns()))
+	err := rows.Next(first)
+	if isBusy(err) {
+		rows.Close()
+		return nil, err
+	}
+	return &prefetchedRows{Rows: rows, first: first, firstErr: err}, nil
+}
+
+type prefetchedRows struct {
+	driver.Rows
+	first    []driver.Value
+	firstErr error
+	read     bool
+}
+
+func (r *prefetchedRows) Next(dest []driver.Value) error {
+	if r.read {
+		return r.Rows.Next(dest)
+	}
+	r.read = true
+	if r.firstErr != nil {
+		return r.firstErr
+	}
+	copy(dest, r.first)
+	return nil
+}
+
+// ResetSession, IsValid y CheckNamedValue se reenvían si el driver los
+// implementa (pgx lo hace): el pool descarta así las conexiones rotas y los
+// argumentos se convierten como espera el driver.
+func (c *instrumentedConn) ResetSession(ctx context.Context) error {
+	if r, ok := c.driverConn.(driver.SessionResetter); ok {
+		return r.ResetSession(ctx)
+	}
+	return nil
+}
+
+func (c *instrumentedConn) IsValid() bool {
+	if v, ok := c.driverConn.(driver.Validator); ok {
+		return v.IsValid()
+	}
+	return true
+}
+
+func (c *instrumentedConn) CheckNamedValue(nv *driver.NamedValue) error {
+	if checker, ok := c.driverConn.(driver.NamedValueChecker); ok {
+		return checker.CheckNamedValue(nv)
+	}
+	return driver.ErrSkip
+}
+
+// observe empieza a medir una consulta; la función que devuelve la termina. Solo
+// abre un span si la consulta forma parte de una traza (una petición o un job),
+// para que las migraciones no generen trazas sueltas. En QueryContext se mide
+// hasta tener las filas (en SQLite, la primera, por prefetch), no su lectura.
+func observe(ctx context.Context, engine Engine, query string) func(err error) {
+	start := time.Now()
+	repository, method := caller()
+
+	var span *tracing.Span
+	if tracing.SpanFromContext(ctx) != nil {
+		_, span = tracing.Default().Start(ctx, repository+"."+method, tracing.KindClient,
+			slog.String("db.system", engine.system()),
+			slog.String("db.query.text", strings.Join(strings.Fields(query), " ")),
+		)
+	}
+
+	return func(err error) {
+		queryDuration.Observe(time.Since(start).Seconds(), repository, method)
+		if span != nil {
+			span.RecordError(err)
+			span.End()
+		}
+	}
+}
+
+const repositoryPrefix = "github.com/payvue/payvue-backend/pkg/repository/"
+
+// caller busca en la pila el método del repositorio que originó la consulta. Se
+// queda con el más externo del mismo paquete, así que las consultas de helpers
+// (queryDebts) y de closures dentro de WithinTx cuentan para el método público.
+// Las consultas propias de este paquete (migraciones) se atribuyen a "database".
+func caller() (repository, method string) {
+	pcs := make([]uintptr, 32)
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(3, pcs)])
+	repository, method = "unknown", "unknown"
+	found := false
+	for {
+		frame, more := frames.Next()
+		pkg, fn, ok := splitRepositoryFunction(frame.Function)
+		switch {
+		case ok && pkg != "database" && (!found || pkg == repository):
+			repository, method, found = pkg, fn, true
+		case found && !(ok && pkg == "database"):
+			return repository, method
+		case ok && !found && fn != "instrumentedConn":
+			repository, method = pkg, fn
+		}
+		if !more {
+			return repository, method
+		}
+	}
+}
+
+// splitRepositoryFunction parte ".../pkg/repository/debt.(*repository).CreateDebt.func1"
+// en "debt" y "CreateDebt".
+func splitRepositoryFunction(function string) (pkg, method string, ok bool) {
+	rest, ok := strings.CutPrefix(function, repositoryPrefix)
+	if !ok {
+		return "", "", false
+	}
+	pkg, rest, _ = strings.Cut(rest, ".")
+	if strings.HasPrefix(rest, "(") {
+		_, rest, _ = strings.Cut(rest, ").")
+	}
+	method, _, _ = strings.Cut(rest, ".")
+	return pkg, method, true
+}
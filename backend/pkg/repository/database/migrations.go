@@ -0,0 +1,95 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+)
+
+// migration es un cambio de esquema sobre las tablas de createTables. Las
+// migraciones son idempotentes, pero una vez aplicadas se anotan en
+// schema_migrations y no se vuelven a ejecutar. El nombre no debe cambiar.
+type migration struct {
+	name string
+	run  func(db *sql.DB) error
+}
+
+// migrations se aplican en este orden; las nuevas van al final.
+var migrations = []migration{
+	{"0001_user_id", migrateUserID},
+	{"0002_deleted_at", migrateDeletedAt},
+	{"0003_version", migrateVersion},
+	{"0004_two_factor", migrateTwoFactor},
+	{"0005_households", migrateHouseholds},
+	{"0006_email_verification", migrateEmailVerification},
+	{"0007_account_deletion", migrateAccountDeletion},
+}
+
+// runMigrations aplica las migraciones pendientes en orden. Se detiene en la
+// primera que falla, porque las siguientes pueden depender de ella, y devuelve
+// el error para que el binario no arranque con un esquema a medias.
+func runMigrations(db *sql.DB) error {
+	_, err := db.Exec(EngineOf(db).ddl(`
+	CREATE TABLE IF NOT EXISTS schema_migrations (
+		name TEXT PRIMARY KEY,
+		applied_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+	)`))
+	if err != nil {
+		return err
+	}
+
+	applied, err := appliedMigrations(context.Background(), db)
+	if err != nil {
+		return err
+	}
+
+	for _, m := range migrations {
+		if applied[m.name] {
+			continue
+		}
+		if err := m.run(db); err != nil {
+			return fmt.Errorf("migration %s: %w", m.name, err)
+		}
+		if _, err := db.Exec("INSERT INTO schema_migrations (name) VALUES (?)", m.name); err != nil {
+			return fmt.Errorf("recording migration %s: %w", m.name, err)
+		}
+		slog.Info("migration applied", slog.String("migration", m.name))
+	}
+
+	return nil
+}
+
+// PendingMigrations devuelve las migraciones que aún no se han aplicado.
+func PendingMigrations(ctx context.Context, db *sql.DB) ([]string, error) {
+	applied, err := appliedMigrations(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+
+	pending := []string{}
+	for _, m := range migrations {
+		if !applied[m.name] {
+			pending = append(pending, m.name)
+		}
+	}
+	return pending, nil
+}
+
+func appliedMigrations(ctx context.Context, db *sql.DB) (map[string]bool, error) {
+	rows, err := db.QueryContext(ctx, "SELECT name FROM schema_migrations")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	applied := make(map[string]bool)
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		applied[name] = true
+	}
+	return applied, rows.Err()
+}
@@ -0,0 +1,48 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestRunMigrationsStopsAtTheFirstFailure(t *testing.T) {
+	db, err := InitDB(Config{Engine: SQLite, Path: filepath.Join(t.TempDir(), "payvue.db")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	var ran []string
+	record := func(name string, err error) migration {
+		return migration{name, func(db *sql.DB) error {
+			ran = append(ran, name)
+			return err
+		}}
+	}
+	original := migrations
+	migrations = append(migrations[:len(migrations):len(migrations)],
+		record("9001_ok", nil),
+		record("9002_broken", errors.New("broken")),
+		record("9003_after", nil),
+	)
+	t.Cleanup(func() { migrations = original })
+
+	if err := runMigrations(db); err == nil {
+		t.Fatal("runMigrations ignored a failed migration")
+	}
+	if want := []string{"9001_ok", "9002_broken"}; !reflect.DeepEqual(ran, want) {
+		t.Errorf("ran %v, want %v", ran, want)
+	}
+
+	pending, err := PendingMigrations(context.Background(), db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"9002_broken", "9003_after"}; !reflect.DeepEqual(pending, want) {
+		t.Errorf("PendingMigrations = %v, want %v", pending, want)
+	}
+}
@@ -0,0 +1,10 @@
+//go:build postgres
+
+package database
+
+import "github.com/jackc/pgx/v5/stdlib"
+
+// Con -tags postgres se registra pgx.
+func init() {
+	registerPostgres(stdlib.GetDefaultDriver())
+}
@@ -0,0 +1,34 @@
+package database
+
+import (
+	"strings"
+
+	"github.com/payvue/payvue-backend/pkg/domain/household"
+)
+
+// ScopeCondition devuelve la condición SQL que limita una consulta a los registros
+// personales del usuario y a los de sus hogares. alias es el prefijo de la tabla
+// ("p." en una consulta con joins, "" si no hay). Sin usuario no coincide nada.
+func ScopeCondition(alias string, scope household.Scope) (string, []interface{}) {
+	if scope.Unrestricted() {
+		return "1 = 1", nil
+	}
+	if scope.UserID <= 0 {
+		return "1 = 0", nil
+	}
+
+	condition := "(" + alias + "household_id = 0 AND " + alias + "user_id = ?)"
+	args := []interface{}{scope.UserID}
+
+	ids := scope.HouseholdIDs()
+	if len(ids) == 0 {
+		return condition, args
+	}
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(ids)), ", ")
+	for _, id := range ids {
+		args = append(args, id)
+	}
+
+	return "(" + condition + " OR " + alias + "household_id IN (" + placeholders + "))", args
+}
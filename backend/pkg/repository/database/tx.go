@@ -0,0 +1,52 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/payvue/payvue-backend/pkg/tracing"
+)
+
+// Executor es el subconjunto común de *sql.DB y *sql.Tx que usan los repositorios.
+type Executor interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+type txKey struct{}
+
+// Conn devuelve la transacción activa del contexto o, si no hay ninguna, la conexión.
+func Conn(ctx context.Context, db *sql.DB) Executor {
+	if tx, ok := ctx.Value(txKey{}).(*sql.Tx); ok {
+		return tx
+	}
+	return db
+}
+
+// WithinTx ejecuta fn dentro de una transacción. Si el contexto ya tiene una
+// transacción activa, fn se une a ella y el commit queda a cargo del llamador.
+func WithinTx(ctx context.Context, db *sql.DB, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(txKey{}).(*sql.Tx); ok {
+		return fn(ctx)
+	}
+
+	ctx, span := tracing.Start(ctx, "db.transaction")
+	defer span.End()
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		span.RecordError(err)
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := fn(context.WithValue(ctx, txKey{}, tx)); err != nil {
+		span.RecordError(err)
+		return err
+	}
+
+	err = tx.Commit()
+	span.RecordError(err)
+	return err
+}
@@ -4,10 +4,19 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"strings"
+	"time"
 
 	"github.com/payvue/payvue-backend/pkg/domain/debt"
+	"github.com/payvue/payvue-backend/pkg/domain/household"
+	"github.com/payvue/payvue-backend/pkg/repository/database"
 )
 
+const debtColumns = `
+	id, COALESCE(user_id, 0), household_id, name, total_amount, remaining_amount, due_date, interest_rate,
+	num_installments, installment_amount, payment_day, paid, version, created_at, updated_at, deleted_at
+`
+
 type repository struct {
 	db *sql.DB
 }
@@ -18,102 +27,92 @@ func NewRepository(db *sql.DB) debt.Repository {
 	}
 }
 
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanDebt(row scanner, d *debt.Debt) error {
+	return row.Scan(
+		&d.ID, &d.UserID, &d.HouseholdID, &d.Name, &d.TotalAmount, &d.RemainingAmount, &d.DueDate,
+		&d.InterestRate, &d.NumInstallments, &d.InstallmentAmount,
+		&d.PaymentDay, &d.Paid, &d.Version, &d.CreatedAt, &d.UpdatedAt, &d.DeletedAt,
+	)
+}
+
 func (r *repository) CreateDebt(ctx context.Context, d *debt.Debt) (*debt.Debt, error) {
 	query := `
-		INSERT INTO debts (user_id, name, total_amount, remaining_amount, due_date, interest_rate, 
-		                   num_installments, installment_amount, payment_day, paid, created_at, updated_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		INSERT INTO debts (user_id, household_id, name, total_amount, remaining_amount, due_date, interest_rate, 
+		                   num_installments, installment_amount, payment_day, paid, version, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		RETURNING id
 	`
 
-	result, err := r.db.ExecContext(ctx, query,
-		d.UserID, d.Name, d.TotalAmount, d.RemainingAmount, d.DueDate,
+	err := database.Conn(ctx, r.db).QueryRowContext(ctx, query,
+		d.UserID, d.HouseholdID, d.Name, d.TotalAmount, d.RemainingAmount, d.DueDate,
 		d.InterestRate, d.NumInstallments, d.InstallmentAmount,
-		d.PaymentDay, d.Paid, d.CreatedAt, d.UpdatedAt,
-	)
-
-	if err != nil {
-		return nil, debt.ErrDatabaseError
-	}
+		d.PaymentDay, d.Paid, d.Version, d.CreatedAt, d.UpdatedAt,
+	).Scan(&d.ID)
 
-	id, err := result.LastInsertId()
 	if err != nil {
-		return nil, debt.ErrDatabaseError
+		return nil, database.Wrap(debt.ErrDatabaseError, err)
 	}
 
-	d.ID = int(id)
 	return d, nil
 }
 
 func (r *repository) GetAllDebts(ctx context.Context) ([]debt.Debt, error) {
 	query := `
-		SELECT id, COALESCE(user_id, 0), name, total_amount, remaining_amount, due_date, interest_rate,
-		       num_installments, installment_amount, payment_day, paid, created_at, updated_at
+		SELECT ` + debtColumns + `
 		FROM debts
+		WHERE deleted_at IS NULL
 		ORDER BY created_at DESC
 	`
 
-	rows, err := r.db.QueryContext(ctx, query)
-	if err != nil {
-		return nil, debt.ErrDatabaseError
-	}
-	defer rows.Close()
-
-	var debts []debt.Debt
-	for rows.Next() {
-		var d debt.Debt
-		err := rows.Scan(
-			&d.ID, &d.UserID, &d.Name, &d.TotalAmount, &d.RemainingAmount, &d.DueDate,
-			&d.InterestRate, &d.NumInstallments, &d.InstallmentAmount,
-			&d.PaymentDay, &d.Paid, &d.CreatedAt, &d.UpdatedAt,
-		)
-		if err != nil {
-			return nil, debt.ErrDatabaseError
-		}
-		debts = append(debts, d)
-	}
-
-	if err = rows.Err(); err != nil {
-		return nil, debt.ErrDatabaseError
-	}
+	return r.queryDebts(ctx, query)
+}
 
-	if debts == nil {
-		debts = []debt.Debt{}
-	}
+func (r *repository) GetDebtsInScope(ctx context.Context, scope household.Scope) ([]debt.Debt, error) {
+	condition, args := database.ScopeCondition("", scope)
+	query := `
+		SELECT ` + debtColumns + `
+		FROM debts
+		WHERE ` + condition + ` AND deleted_at IS NULL
+		ORDER BY created_at DESC
+	`
 
-	return debts, nil
+	return r.queryDebts(ctx, query, args...)
 }
 
-func (r *repository) GetDebtsByUserID(ctx context.Context, userID int) ([]debt.Debt, error) {
+func (r *repository) GetDeletedDebts(ctx context.Context, scope household.Scope) ([]debt.Debt, error) {
+	condition, args := database.ScopeCondition("", scope)
 	query := `
-		SELECT id, COALESCE(user_id, 0), name, total_amount, remaining_amount, due_date, interest_rate,
-		       num_installments, installment_amount, payment_day, paid, created_at, updated_at
+		SELECT ` + debtColumns + `
 		FROM debts
-		WHERE user_id = ?
-		ORDER BY created_at DESC
+		WHERE deleted_at IS NOT NULL AND ` + condition + `
+		ORDER BY deleted_at DESC
 	`
 
-	rows, err := r.db.QueryContext(ctx, query, userID)
+	return r.queryDebts(ctx, query, args...)
+}
+
+func (r *repository) queryDebts(ctx context.Context, query string, args ...interface{}) ([]debt.Debt, error) {
+	rows, err := database.Conn(ctx, r.db).QueryContext(ctx, query, args...)
 	if err != nil {
-		return nil, debt.ErrDatabaseError
+		return nil, database.Wrap(debt.ErrDatabaseError, err)
 	}
 	defer rows.Close()
 
 	var debts []debt.Debt
 	for rows.Next() {
 		var d debt.Debt
-		err := rows.Scan(
-			&d.ID, &d.UserID, &d.Name, &d.TotalAmount, &d.RemainingAmount, &d.DueDate,
-			&d.InterestRate, &d.NumInstallments, &d.InstallmentAmount,
-			&d.PaymentDay, &d.Paid, &d.CreatedAt, &d.UpdatedAt,
-		)
-		if err != nil {
-			return nil, debt.ErrDatabaseError
+		if err := scanDebt(rows, &d); err != nil {
+			return nil, database.Wrap(debt.ErrDatabaseError, err)
 		}
 		debts = append(debts, d)
 	}
 
 	if err = rows.Err(); err != nil {
-		return nil, debt.ErrDatabaseError
+		return nil, database.Wrap(debt.ErrDatabaseError, err)
 	}
 
 	if debts == nil {
@@ -125,62 +124,156 @@ func (r *repository) GetDebtsByUserID(ctx context.Context, userID int) ([]debt.D
 
 func (r *repository) GetDebtByID(ctx context.Context, id int) (*debt.Debt, error) {
 	query := `
-		SELECT id, COALESCE(user_id, 0), name, total_amount, remaining_amount, due_date, interest_rate,
-		       num_installments, installment_amount, payment_day, paid, created_at, updated_at
+		SELECT ` + debtColumns + `
 		FROM debts
-		WHERE id = ?
+		WHERE id = ? AND deleted_at IS NULL
 	`
 
 	var d debt.Debt
-	err := r.db.QueryRowContext(ctx, query, id).Scan(
-		&d.ID, &d.UserID, &d.Name, &d.TotalAmount, &d.RemainingAmount, &d.DueDate,
-		&d.InterestRate, &d.NumInstallments, &d.InstallmentAmount,
-		&d.PaymentDay, &d.Paid, &d.CreatedAt, &d.UpdatedAt,
-	)
+	err := scanDebt(database.Conn(ctx, r.db).QueryRowContext(ctx, query, id), &d)
 
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, debt.ErrDebtNotFound
 		}
-		return nil, debt.ErrDatabaseError
+		return nil, database.Wrap(debt.ErrDatabaseError, err)
 	}
 
 	return &d, nil
 }
 
+// UpdateDebt solo escribe si la fila sigue en la versión leída (d.Version);
+// si otra petición la modificó antes devuelve ErrVersionConflict.
 func (r *repository) UpdateDebt(ctx context.Context, d *debt.Debt) (*debt.Debt, error) {
 	query := `
 		UPDATE debts 
 		SET name = ?, total_amount = ?, remaining_amount = ?, due_date = ?,
 		    interest_rate = ?, num_installments = ?, installment_amount = ?,
-		    payment_day = ?, paid = ?, updated_at = ?
-		WHERE id = ?
+		    payment_day = ?, paid = ?, updated_at = ?, version = version + 1
+		WHERE id = ? AND version = ? AND deleted_at IS NULL
 	`
 
-	_, err := r.db.ExecContext(ctx, query,
+	result, err := database.Conn(ctx, r.db).ExecContext(ctx, query,
 		d.Name, d.TotalAmount, d.RemainingAmount, d.DueDate,
 		d.InterestRate, d.NumInstallments, d.InstallmentAmount,
-		d.PaymentDay, d.Paid, d.UpdatedAt, d.ID,
+		d.PaymentDay, d.Paid, d.UpdatedAt, d.ID, d.Version,
 	)
 
 	if err != nil {
-		return nil, debt.ErrDatabaseError
+		return nil, database.Wrap(debt.ErrDatabaseError, err)
 	}
 
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return nil, database.Wrap(debt.ErrDatabaseError, err)
+	}
+
+	if rowsAffected == 0 {
+		return nil, debt.ErrVersionConflict
+	}
+
+	d.Version++
 	return d, nil
 }
 
+// UpdateDebtColumns escribe solo las columnas indicadas (además de updated_at y version).
+func (r *repository) UpdateDebtColumns(ctx context.Context, d *debt.Debt, columns []string) (*debt.Debt, error) {
+	var assignments []string
+	var args []interface{}
+	for _, column := range columns {
+		var value interface{}
+		switch column {
+		case "name":
+			value = d.Name
+		case "total_amount":
+			value = d.TotalAmount
+		case "remaining_amount":
+			value = d.RemainingAmount
+		case "due_date":
+			value = d.DueDate
+		case "interest_rate":
+			value = d.InterestRate
+		case "num_installments":
+			value = d.NumInstallments
+		case "installment_amount":
+			value = d.InstallmentAmount
+		case "payment_day":
+			value = d.PaymentDay
+		case "paid":
+			value = d.Paid
+		default:
+			return nil, debt.ErrInvalidDebtData
+		}
+		assignments = append(assignments, column+" = ?")
+		args = append(args, value)
+	}
+	args = append(args, d.UpdatedAt, d.ID, d.Version)
+
+	query := "UPDATE debts SET " + strings.Join(assignments, ", ") +
+		", updated_at = ?, version = version + 1 WHERE id = ? AND version = ? AND deleted_at IS NULL"
+
+	result, err := database.Conn(ctx, r.db).ExecContext(ctx, query, args...)
+	if err != nil {
+		return nil, database.Wrap(debt.ErrDatabaseError, err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return nil, database.Wrap(debt.ErrDatabaseError, err)
+	}
+
+	if rowsAffected == 0 {
+		return nil, debt.ErrVersionConflict
+	}
+
+	d.Version++
+	return d, nil
+}
+
+// DeleteDebt envía la deuda a la papelera. Sus pagos dejan de listarse
+// mientras la deuda esté eliminada y vuelven con ella al restaurarla.
 func (r *repository) DeleteDebt(ctx context.Context, id int) error {
-	query := `DELETE FROM debts WHERE id = ?`
+	query := `UPDATE debts SET deleted_at = ? WHERE id = ? AND deleted_at IS NULL`
+
+	return r.execOne(ctx, query, time.Now(), id)
+}
+
+func (r *repository) RestoreDebt(ctx context.Context, id int) error {
+	query := `
+		UPDATE debts SET deleted_at = NULL, updated_at = ?, version = version + 1
+		WHERE id = ? AND deleted_at IS NOT NULL
+	`
+
+	return r.execOne(ctx, query, time.Now(), id)
+}
+
+// PurgeDeletedDebts borra definitivamente las deudas que llevan en la papelera
+// desde antes de la fecha indicada.
+func (r *repository) PurgeDeletedDebts(ctx context.Context, before time.Time) (int, error) {
+	query := `DELETE FROM debts WHERE deleted_at IS NOT NULL AND deleted_at < ?`
+
+	result, err := database.Conn(ctx, r.db).ExecContext(ctx, query, before)
+	if err != nil {
+		return 0, database.Wrap(debt.ErrDatabaseError, err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, database.Wrap(debt.ErrDatabaseError, err)
+	}
+
+	return int(rowsAffected), nil
+}
 
-	result, err := r.db.ExecContext(ctx, query, id)
+func (r *repository) execOne(ctx context.Context, query string, args ...interface{}) error {
+	result, err := database.Conn(ctx, r.db).ExecContext(ctx, query, args...)
 	if err != nil {
-		return debt.ErrDatabaseError
+		return database.Wrap(debt.ErrDatabaseError, err)
 	}
 
 	rowsAffected, err := result.RowsAffected()
 	if err != nil {
-		return debt.ErrDatabaseError
+		return database.Wrap(debt.ErrDatabaseError, err)
 	}
 
 	if rowsAffected == 0 {
@@ -0,0 +1,380 @@
+package household
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/payvue/payvue-backend/pkg/domain/household"
+	"github.com/payvue/payvue-backend/pkg/repository/database"
+)
+
+type repository struct {
+	db *sql.DB
+}
+
+func NewRepository(db *sql.DB) household.Repository {
+	return &repository{
+		db: db,
+	}
+}
+
+func (r *repository) CreateHousehold(ctx context.Context, h *household.Household) (*household.Household, error) {
+	err := database.WithinTx(ctx, r.db, func(ctx context.Context) error {
+		conn := database.Conn(ctx, r.db)
+
+		err := conn.QueryRowContext(ctx,
+			`INSERT INTO households (name, created_by, created_at, updated_at) VALUES (?, ?, ?, ?) RETURNING id`,
+			h.Name, h.CreatedBy, h.CreatedAt, h.UpdatedAt,
+		).Scan(&h.ID)
+		if err != nil {
+			return err
+		}
+
+		_, err = conn.ExecContext(ctx,
+			`INSERT INTO household_members (household_id, user_id, role, created_at) VALUES (?, ?, ?, ?)`,
+			h.ID, h.CreatedBy, household.RoleOwner, h.CreatedAt,
+		)
+		return err
+	})
+
+	if err != nil {
+		return nil, database.Wrap(household.ErrDatabaseError, err)
+	}
+
+	return h, nil
+}
+
+func (r *repository) GetHouseholdByID(ctx context.Context, id int) (*household.Household, error) {
+	query := `
+		SELECT id, name, created_by, created_at, updated_at
+		FROM households
+		WHERE id = ?
+	`
+
+	var h household.Household
+	err := r.db.QueryRowContext(ctx, query, id).Scan(
+		&h.ID, &h.Name, &h.CreatedBy, &h.CreatedAt, &h.UpdatedAt,
+	)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, household.ErrHouseholdNotFound
+		}
+		return nil, database.Wrap(household.ErrDatabaseError, err)
+	}
+
+	return &h, nil
+}
+
+func (r *repository) GetHouseholdsByUserID(ctx context.Context, userID int) ([]household.Household, error) {
+	query := `
+		SELECT h.id, h.name, h.created_by, h.created_at, h.updated_at, m.role
+		FROM households h
+		INNER JOIN household_members m ON m.household_id = h.id
+		WHERE m.user_id = ?
+		ORDER BY h.name
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, database.Wrap(household.ErrDatabaseError, err)
+	}
+	defer rows.Close()
+
+	households := []household.Household{}
+	for rows.Next() {
+		var h household.Household
+		if err := rows.Scan(&h.ID, &h.Name, &h.CreatedBy, &h.CreatedAt, &h.UpdatedAt, &h.Role); err != nil {
+			return nil, database.Wrap(household.ErrDatabaseError, err)
+		}
+		households = append(households, h)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, database.Wrap(household.ErrDatabaseError, err)
+	}
+
+	return households, nil
+}
+
+func (r *repository) GetMembers(ctx context.Context, householdID int) ([]household.Member, error) {
+	query := `
+		SELECT m.household_id, m.user_id, COALESCE(u.email, ''), m.role, m.created_at
+		FROM household_members m
+		LEFT JOIN users u ON u.id = m.user_id
+		WHERE m.household_id = ?
+		ORDER BY m.created_at
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, householdID)
+	if err != nil {
+		return nil, database.Wrap(household.ErrDatabaseError, err)
+	}
+	defer rows.Close()
+
+	members := []household.Member{}
+	for rows.Next() {
+		var m household.Member
+		if err := rows.Scan(&m.HouseholdID, &m.UserID, &m.Email, &m.Role, &m.CreatedAt); err != nil {
+			return nil, database.Wrap(household.ErrDatabaseError, err)
+		}
+		members = append(members, m)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, database.Wrap(household.ErrDatabaseError, err)
+	}
+
+	return members, nil
+}
+
+func (r *repository) GetMember(ctx context.Context, householdID, userID int) (*household.Member, error) {
+	query := `
+		SELECT m.household_id, m.user_id, COALESCE(u.email, ''), m.role, m.created_at
+		FROM household_members m
+		LEFT JOIN users u ON u.id = m.user_id
+		WHERE m.household_id = ? AND m.user_id = ?
+	`
+
+	var m household.Member
+	err := r.db.QueryRowContext(ctx, query, householdID, userID).Scan(
+		&m.HouseholdID, &m.UserID, &m.Email, &m.Role, &m.CreatedAt,
+	)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, household.ErrMemberNotFound
+		}
+		return nil, database.Wrap(household.ErrDatabaseError, err)
+	}
+
+	return &m, nil
+}
+
+func (r *repository) GetRoles(ctx context.Context, userID int) (map[int]household.Role, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT household_id, role FROM household_members WHERE user_id = ?`, userID)
+	if err != nil {
+		return nil, database.Wrap(household.ErrDatabaseError, err)
+	}
+	defer rows.Close()
+
+	roles := map[int]household.Role{}
+	for rows.Next() {
+		var id int
+		var role household.Role
+		if err := rows.Scan(&id, &role); err != nil {
+			return nil, database.Wrap(household.ErrDatabaseError, err)
+		}
+		roles[id] = role
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, database.Wrap(household.ErrDatabaseError, err)
+	}
+
+	return roles, nil
+}
+
+func (r *repository) AddMember(ctx context.Context, m *household.Member) error {
+	_, err := database.Conn(ctx, r.db).ExecContext(ctx,
+		`INSERT INTO household_members (household_id, user_id, role, created_at) VALUES (?, ?, ?, ?)`,
+		m.HouseholdID, m.UserID, m.Role, m.CreatedAt,
+	)
+	if err != nil {
+		return database.Wrap(household.ErrDatabaseError, err)
+	}
+
+	return nil
+}
+
+func (r *repository) UpdateMemberRole(ctx context.Context, householdID, userID int, role household.Role) error {
+	result, err := r.db.ExecContext(ctx,
+		`UPDATE household_members SET role = ? WHERE household_id = ? AND user_id = ?`,
+		role, householdID, userID,
+	)
+	if err != nil {
+		return database.Wrap(household.ErrDatabaseError, err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return database.Wrap(household.ErrDatabaseError, err)
+	}
+
+	if rowsAffected == 0 {
+		return household.ErrMemberNotFound
+	}
+
+	return nil
+}
+
+func (r *repository) RemoveMember(ctx context.Context, householdID, userID int) error {
+	result, err := r.db.ExecContext(ctx,
+		`DELETE FROM household_members WHERE household_id = ? AND user_id = ?`,
+		householdID, userID,
+	)
+	if err != nil {
+		return database.Wrap(household.ErrDatabaseError, err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return database.Wrap(household.ErrDatabaseError, err)
+	}
+
+	if rowsAffected == 0 {
+		return household.ErrMemberNotFound
+	}
+
+	return nil
+}
+
+func (r *repository) CountOwners(ctx context.Context, householdID int) (int, error) {
+	var count int
+	err := r.db.QueryRowContext(ctx,
+		`SELECT COUNT(*) FROM household_members WHERE household_id = ? AND role = ?`,
+		householdID, household.RoleOwner,
+	).Scan(&count)
+	if err != nil {
+		return 0, database.Wrap(household.ErrDatabaseError, err)
+	}
+
+	return count, nil
+}
+
+func (r *repository) CreateInvitation(ctx context.Context, i *household.Invitation) (*household.Invitation, error) {
+	query := `
+		INSERT INTO household_invitations (household_id, token_hash, role, email, invited_by, expires_at, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		RETURNING id
+	`
+
+	err := r.db.QueryRowContext(ctx, query,
+		i.HouseholdID, i.TokenHash, i.Role, i.Email, i.InvitedBy, i.ExpiresAt, i.CreatedAt,
+	).Scan(&i.ID)
+	if err != nil {
+		return nil, database.Wrap(household.ErrDatabaseError, err)
+	}
+
+	return i, nil
+}
+
+const invitationColumns = `id, household_id, token_hash, role, COALESCE(email, ''), invited_by, expires_at, COALESCE(accepted_by, 0), accepted_at, created_at`
+
+func scanInvitation(scanner interface{ Scan(...interface{}) error }) (*household.Invitation, error) {
+	var i household.Invitation
+	var acceptedAt sql.NullTime
+	err := scanner.Scan(
+		&i.ID, &i.HouseholdID, &i.TokenHash, &i.Role, &i.Email, &i.InvitedBy, &i.ExpiresAt,
+		&i.AcceptedBy, &acceptedAt, &i.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if acceptedAt.Valid {
+		i.AcceptedAt = &acceptedAt.Time
+	}
+
+	return &i, nil
+}
+
+func (r *repository) GetInvitationByTokenHash(ctx context.Context, tokenHash string) (*household.Invitation, error) {
+	query := `SELECT ` + invitationColumns + ` FROM household_invitations WHERE token_hash = ?`
+
+	i, err := scanInvitation(r.db.QueryRowContext(ctx, query, tokenHash))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, household.ErrInvitationNotFound
+		}
+		return nil, database.Wrap(household.ErrDatabaseError, err)
+	}
+
+	return i, nil
+}
+
+func (r *repository) GetPendingInvitations(ctx context.Context, householdID int) ([]household.Invitation, error) {
+	query := `
+		SELECT ` + invitationColumns + `
+		FROM household_invitations
+		WHERE household_id = ? AND accepted_at IS NULL
+		ORDER BY created_at DESC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, householdID)
+	if err != nil {
+		return nil, database.Wrap(household.ErrDatabaseError, err)
+	}
+	defer rows.Close()
+
+	invitations := []household.Invitation{}
+	for rows.Next() {
+		i, err := scanInvitation(rows)
+		if err != nil {
+			return nil, database.Wrap(household.ErrDatabaseError, err)
+		}
+		invitations = append(invitations, *i)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, database.Wrap(household.ErrDatabaseError, err)
+	}
+
+	return invitations, nil
+}
+
+func (r *repository) AcceptInvitation(ctx context.Context, i *household.Invitation, m *household.Member) error {
+	err := database.WithinTx(ctx, r.db, func(ctx context.Context) error {
+		conn := database.Conn(ctx, r.db)
+
+		// La condición sobre accepted_at evita que dos peticiones usen la misma invitación
+		result, err := conn.ExecContext(ctx,
+			`UPDATE household_invitations SET accepted_by = ?, accepted_at = ? WHERE id = ? AND accepted_at IS NULL`,
+			i.AcceptedBy, i.AcceptedAt, i.ID,
+		)
+		if err != nil {
+			return err
+		}
+
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if rowsAffected == 0 {
+			return household.ErrInvalidInvitation
+		}
+
+		return r.AddMember(ctx, m)
+	})
+
+	if err != nil {
+		if err == household.ErrInvalidInvitation {
+			return err
+		}
+		return database.Wrap(household.ErrDatabaseError, err)
+	}
+
+	return nil
+}
+
+func (r *repository) DeleteInvitation(ctx context.Context, householdID, invitationID int) error {
+	result, err := r.db.ExecContext(ctx,
+		`DELETE FROM household_invitations WHERE id = ? AND household_id = ? AND accepted_at IS NULL`,
+		invitationID, householdID,
+	)
+	if err != nil {
+		return database.Wrap(household.ErrDatabaseError, err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return database.Wrap(household.ErrDatabaseError, err)
+	}
+
+	if rowsAffected == 0 {
+		return household.ErrInvitationNotFound
+	}
+
+	return nil
+}
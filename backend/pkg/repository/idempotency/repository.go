@@ -0,0 +1,138 @@
+package idempotency
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/payvue/payvue-backend/pkg/domain/idempotency"
+	"github.com/payvue/payvue-backend/pkg/repository/database"
+)
+
+type repository struct {
+	db *sql.DB
+}
+
+func NewRepository(db *sql.DB) idempotency.Repository {
+	return &repository{
+		db: db,
+	}
+}
+
+func (r *repository) CreateRecord(ctx context.Context, rec *idempotency.Record) error {
+	query := `
+		INSERT INTO idempotency_keys (user_id, idempotency_key, method, path, request_hash, status_code, created_at)
+		VALUES (?, ?, ?, ?, ?, 0, ?)
+		ON CONFLICT (user_id, idempotency_key) DO NOTHING
+		RETURNING id
+	`
+
+	err := r.db.QueryRowContext(ctx, query,
+		rec.UserID, rec.Key, rec.Method, rec.Path, rec.RequestHash, rec.CreatedAt,
+	).Scan(&rec.ID)
+	// Sin fila devuelta, el INSERT no hizo nada: la clave ya existía
+	if errors.Is(err, sql.ErrNoRows) {
+		return idempotency.ErrKeyExists
+	}
+	if err != nil {
+		return database.Wrap(idempotency.ErrDatabaseError, err)
+	}
+
+	return nil
+}
+
+func (r *repository) GetRecord(ctx context.Context, userID int, key string) (*idempotency.Record, error) {
+	query := `
+		SELECT id, user_id, idempotency_key, method, path, request_hash, status_code,
+		       response_headers, response_body, created_at, completed_at
+		FROM idempotency_keys
+		WHERE user_id = ? AND idempotency_key = ?
+	`
+
+	var rec idempotency.Record
+	var headers sql.NullString
+	err := r.db.QueryRowContext(ctx, query, userID, key).Scan(
+		&rec.ID, &rec.UserID, &rec.Key, &rec.Method, &rec.Path, &rec.RequestHash, &rec.StatusCode,
+		&headers, &rec.ResponseBody, &rec.CreatedAt, &rec.CompletedAt,
+	)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, idempotency.ErrNotFound
+		}
+		return nil, database.Wrap(idempotency.ErrDatabaseError, err)
+	}
+
+	if headers.Valid && headers.String != "" {
+		if err := json.Unmarshal([]byte(headers.String), &rec.ResponseHeaders); err != nil {
+			return nil, database.Wrap(idempotency.ErrDatabaseError, err)
+		}
+	}
+
+	return &rec, nil
+}
+
+func (r *repository) CompleteRecord(ctx context.Context, rec *idempotency.Record) error {
+	headers, err := json.Marshal(rec.ResponseHeaders)
+	if err != nil {
+		return database.Wrap(idempotency.ErrDatabaseError, err)
+	}
+
+	query := `
+		UPDATE idempotency_keys
+		SET status_code = ?, response_headers = ?, response_body = ?, completed_at = ?
+		WHERE id = ?
+	`
+
+	result, err := r.db.ExecContext(ctx, query,
+		rec.StatusCode, string(headers), rec.ResponseBody, rec.CompletedAt, rec.ID,
+	)
+	if err != nil {
+		return database.Wrap(idempotency.ErrDatabaseError, err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return database.Wrap(idempotency.ErrDatabaseError, err)
+	}
+
+	if rowsAffected == 0 {
+		return idempotency.ErrNotFound
+	}
+
+	return nil
+}
+
+func (r *repository) DeleteRecord(ctx context.Context, id int) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM idempotency_keys WHERE id = ?`, id)
+	if err != nil {
+		return database.Wrap(idempotency.ErrDatabaseError, err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return database.Wrap(idempotency.ErrDatabaseError, err)
+	}
+
+	if rowsAffected == 0 {
+		return idempotency.ErrNotFound
+	}
+
+	return nil
+}
+
+func (r *repository) PurgeRecords(ctx context.Context, before time.Time) (int, error) {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM idempotency_keys WHERE created_at < ?`, before)
+	if err != nil {
+		return 0, database.Wrap(idempotency.ErrDatabaseError, err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, database.Wrap(idempotency.ErrDatabaseError, err)
+	}
+
+	return int(rowsAffected), nil
+}
@@ -4,10 +4,16 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"strings"
+	"time"
 
+	"github.com/payvue/payvue-backend/pkg/domain/household"
 	"github.com/payvue/payvue-backend/pkg/domain/income"
+	"github.com/payvue/payvue-backend/pkg/repository/database"
 )
 
+const incomeColumns = `id, COALESCE(user_id, 0), household_id, amount, source, date, version, created_at, updated_at, deleted_at`
+
 type repository struct {
 	db *sql.DB
 }
@@ -18,93 +24,87 @@ func NewRepository(db *sql.DB) income.Repository {
 	}
 }
 
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanIncome(row scanner, i *income.Income) error {
+	return row.Scan(
+		&i.ID, &i.UserID, &i.HouseholdID, &i.Amount, &i.Source, &i.Date, &i.Version, &i.CreatedAt, &i.UpdatedAt, &i.DeletedAt,
+	)
+}
+
 func (r *repository) CreateIncome(ctx context.Context, i *income.Income) (*income.Income, error) {
 	query := `
-		INSERT INTO incomes (user_id, amount, source, date, created_at, updated_at)
-		VALUES (?, ?, ?, ?, ?, ?)
+		INSERT INTO incomes (user_id, household_id, amount, source, date, version, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		RETURNING id
 	`
 
-	result, err := r.db.ExecContext(ctx, query,
-		i.UserID, i.Amount, i.Source, i.Date, i.CreatedAt, i.UpdatedAt,
-	)
-
-	if err != nil {
-		return nil, income.ErrDatabaseError
-	}
+	err := database.Conn(ctx, r.db).QueryRowContext(ctx, query,
+		i.UserID, i.HouseholdID, i.Amount, i.Source, i.Date, i.Version, i.CreatedAt, i.UpdatedAt,
+	).Scan(&i.ID)
 
-	id, err := result.LastInsertId()
 	if err != nil {
-		return nil, income.ErrDatabaseError
+		return nil, database.Wrap(income.ErrDatabaseError, err)
 	}
 
-	i.ID = int(id)
 	return i, nil
 }
 
 func (r *repository) GetAllIncomes(ctx context.Context) ([]income.Income, error) {
 	query := `
-		SELECT id, COALESCE(user_id, 0), amount, source, date, created_at, updated_at
+		SELECT ` + incomeColumns + `
 		FROM incomes
+		WHERE deleted_at IS NULL
 		ORDER BY date DESC
 	`
 
-	rows, err := r.db.QueryContext(ctx, query)
-	if err != nil {
-		return nil, income.ErrDatabaseError
-	}
-	defer rows.Close()
-
-	var incomes []income.Income
-	for rows.Next() {
-		var i income.Income
-		err := rows.Scan(
-			&i.ID, &i.UserID, &i.Amount, &i.Source, &i.Date, &i.CreatedAt, &i.UpdatedAt,
-		)
-		if err != nil {
-			return nil, income.ErrDatabaseError
-		}
-		incomes = append(incomes, i)
-	}
-
-	if err = rows.Err(); err != nil {
-		return nil, income.ErrDatabaseError
-	}
+	return r.queryIncomes(ctx, query)
+}
 
-	if incomes == nil {
-		incomes = []income.Income{}
-	}
+func (r *repository) GetIncomesInScope(ctx context.Context, scope household.Scope) ([]income.Income, error) {
+	condition, args := database.ScopeCondition("", scope)
+	query := `
+		SELECT ` + incomeColumns + `
+		FROM incomes
+		WHERE ` + condition + ` AND deleted_at IS NULL
+		ORDER BY date DESC
+	`
 
-	return incomes, nil
+	return r.queryIncomes(ctx, query, args...)
 }
 
-func (r *repository) GetIncomesByUserID(ctx context.Context, userID int) ([]income.Income, error) {
+func (r *repository) GetDeletedIncomes(ctx context.Context, scope household.Scope) ([]income.Income, error) {
+	condition, args := database.ScopeCondition("", scope)
 	query := `
-		SELECT id, COALESCE(user_id, 0), amount, source, date, created_at, updated_at
+		SELECT ` + incomeColumns + `
 		FROM incomes
-		WHERE user_id = ?
-		ORDER BY date DESC
+		WHERE deleted_at IS NOT NULL AND ` + condition + `
+		ORDER BY deleted_at DESC
 	`
 
-	rows, err := r.db.QueryContext(ctx, query, userID)
+	return r.queryIncomes(ctx, query, args...)
+}
+
+func (r *repository) queryIncomes(ctx context.Context, query string, args ...interface{}) ([]income.Income, error) {
+	rows, err := database.Conn(ctx, r.db).QueryContext(ctx, query, args...)
 	if err != nil {
-		return nil, income.ErrDatabaseError
+		return nil, database.Wrap(income.ErrDatabaseError, err)
 	}
 	defer rows.Close()
 
 	var incomes []income.Income
 	for rows.Next() {
 		var i income.Income
-		err := rows.Scan(
-			&i.ID, &i.UserID, &i.Amount, &i.Source, &i.Date, &i.CreatedAt, &i.UpdatedAt,
-		)
-		if err != nil {
-			return nil, income.ErrDatabaseError
+		if err := scanIncome(rows, &i); err != nil {
+			return nil, database.Wrap(income.ErrDatabaseError, err)
 		}
 		incomes = append(incomes, i)
 	}
 
 	if err = rows.Err(); err != nil {
-		return nil, income.ErrDatabaseError
+		return nil, database.Wrap(income.ErrDatabaseError, err)
 	}
 
 	if incomes == nil {
@@ -116,55 +116,136 @@ func (r *repository) GetIncomesByUserID(ctx context.Context, userID int) ([]inco
 
 func (r *repository) GetIncomeByID(ctx context.Context, id int) (*income.Income, error) {
 	query := `
-		SELECT id, COALESCE(user_id, 0), amount, source, date, created_at, updated_at
+		SELECT ` + incomeColumns + `
 		FROM incomes
-		WHERE id = ?
+		WHERE id = ? AND deleted_at IS NULL
 	`
 
 	var i income.Income
-	err := r.db.QueryRowContext(ctx, query, id).Scan(
-		&i.ID, &i.UserID, &i.Amount, &i.Source, &i.Date, &i.CreatedAt, &i.UpdatedAt,
-	)
+	err := scanIncome(database.Conn(ctx, r.db).QueryRowContext(ctx, query, id), &i)
 
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, income.ErrIncomeNotFound
 		}
-		return nil, income.ErrDatabaseError
+		return nil, database.Wrap(income.ErrDatabaseError, err)
 	}
 
 	return &i, nil
 }
 
+// UpdateIncome solo escribe si la fila sigue en la versión leída (i.Version);
+// si otra petición la modificó antes devuelve ErrVersionConflict.
 func (r *repository) UpdateIncome(ctx context.Context, i *income.Income) (*income.Income, error) {
 	query := `
 		UPDATE incomes 
-		SET amount = ?, source = ?, date = ?, updated_at = ?
-		WHERE id = ?
+		SET amount = ?, source = ?, date = ?, updated_at = ?, version = version + 1
+		WHERE id = ? AND version = ? AND deleted_at IS NULL
 	`
 
-	_, err := r.db.ExecContext(ctx, query,
-		i.Amount, i.Source, i.Date, i.UpdatedAt, i.ID,
+	result, err := database.Conn(ctx, r.db).ExecContext(ctx, query,
+		i.Amount, i.Source, i.Date, i.UpdatedAt, i.ID, i.Version,
 	)
 
 	if err != nil {
-		return nil, income.ErrDatabaseError
+		return nil, database.Wrap(income.ErrDatabaseError, err)
 	}
 
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return nil, database.Wrap(income.ErrDatabaseError, err)
+	}
+
+	if rowsAffected == 0 {
+		return nil, income.ErrVersionConflict
+	}
+
+	i.Version++
+	return i, nil
+}
+
+// UpdateIncomeColumns escribe solo las columnas indicadas (además de updated_at y version).
+func (r *repository) UpdateIncomeColumns(ctx context.Context, i *income.Income, columns []string) (*income.Income, error) {
+	var assignments []string
+	var args []interface{}
+	for _, column := range columns {
+		var value interface{}
+		switch column {
+		case "amount":
+			value = i.Amount
+		case "source":
+			value = i.Source
+		case "date":
+			value = i.Date
+		default:
+			return nil, income.ErrInvalidIncomeData
+		}
+		assignments = append(assignments, column+" = ?")
+		args = append(args, value)
+	}
+	args = append(args, i.UpdatedAt, i.ID, i.Version)
+
+	query := "UPDATE incomes SET " + strings.Join(assignments, ", ") +
+		", updated_at = ?, version = version + 1 WHERE id = ? AND version = ? AND deleted_at IS NULL"
+
+	result, err := database.Conn(ctx, r.db).ExecContext(ctx, query, args...)
+	if err != nil {
+		return nil, database.Wrap(income.ErrDatabaseError, err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return nil, database.Wrap(income.ErrDatabaseError, err)
+	}
+
+	if rowsAffected == 0 {
+		return nil, income.ErrVersionConflict
+	}
+
+	i.Version++
 	return i, nil
 }
 
 func (r *repository) DeleteIncome(ctx context.Context, id int) error {
-	query := `DELETE FROM incomes WHERE id = ?`
+	query := `UPDATE incomes SET deleted_at = ? WHERE id = ? AND deleted_at IS NULL`
+
+	return r.execOne(ctx, query, time.Now(), id)
+}
+
+func (r *repository) RestoreIncome(ctx context.Context, id int) error {
+	query := `
+		UPDATE incomes SET deleted_at = NULL, updated_at = ?, version = version + 1
+		WHERE id = ? AND deleted_at IS NOT NULL
+	`
+
+	return r.execOne(ctx, query, time.Now(), id)
+}
+
+func (r *repository) PurgeDeletedIncomes(ctx context.Context, before time.Time) (int, error) {
+	query := `DELETE FROM incomes WHERE deleted_at IS NOT NULL AND deleted_at < ?`
+
+	result, err := database.Conn(ctx, r.db).ExecContext(ctx, query, before)
+	if err != nil {
+		return 0, database.Wrap(income.ErrDatabaseError, err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, database.Wrap(income.ErrDatabaseError, err)
+	}
+
+	return int(rowsAffected), nil
+}
 
-	result, err := r.db.ExecContext(ctx, query, id)
+func (r *repository) execOne(ctx context.Context, query string, args ...interface{}) error {
+	result, err := database.Conn(ctx, r.db).ExecContext(ctx, query, args...)
 	if err != nil {
-		return income.ErrDatabaseError
+		return database.Wrap(income.ErrDatabaseError, err)
 	}
 
 	rowsAffected, err := result.RowsAffected()
 	if err != nil {
-		return income.ErrDatabaseError
+		return database.Wrap(income.ErrDatabaseError, err)
 	}
 
 	if rowsAffected == 0 {
@@ -0,0 +1,13 @@
+//go:build !postgres
+
+package repository_test
+
+import (
+	"database/sql"
+	"testing"
+)
+
+func openPostgres(t *testing.T) *sql.DB {
+	t.Skip("built without -tags postgres")
+	return nil
+}
@@ -4,10 +4,18 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"time"
 
+	"github.com/payvue/payvue-backend/pkg/domain/household"
 	"github.com/payvue/payvue-backend/pkg/domain/payment"
+	"github.com/payvue/payvue-backend/pkg/repository/database"
 )
 
+const paymentWithDebtColumns = `
+	p.id, COALESCE(p.user_id, 0), p.household_id, p.amount, p.debt_id, p.receipt_filename, p.date, p.created_at, p.updated_at,
+	p.deleted_at, d.name, d.remaining_amount, d.installment_amount
+`
+
 type repository struct {
 	db *sql.DB
 }
@@ -19,73 +27,114 @@ func NewRepository(db *sql.DB) payment.Repository {
 }
 
 func (r *repository) CreatePayment(ctx context.Context, p *payment.Payment) (*payment.Payment, error) {
-	tx, err := r.db.BeginTx(ctx, nil)
+	err := database.WithinTx(ctx, r.db, func(ctx context.Context) error {
+		tx := database.Conn(ctx, r.db)
+
+		// Insertar el pago
+		query := `
+			INSERT INTO payments (user_id, household_id, amount, debt_id, receipt_filename, date, created_at, updated_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+			RETURNING id
+		`
+
+		err := tx.QueryRowContext(ctx, query,
+			p.UserID, p.HouseholdID, p.Amount, p.DebtID, p.ReceiptFilename, p.Date, p.CreatedAt, p.UpdatedAt,
+		).Scan(&p.ID)
+		if err != nil {
+			return err
+		}
+
+		// Actualizar la deuda
+		updateQuery := `
+			UPDATE debts 
+			SET remaining_amount = CASE 
+				WHEN remaining_amount - ? < 0 THEN 0 
+				ELSE remaining_amount - ? 
+			END,
+			paid = CASE 
+				WHEN remaining_amount - ? <= 0 THEN TRUE 
+				ELSE paid 
+			END,
+			updated_at = ?,
+			version = version + 1
+			WHERE id = ?
+		`
+
+		_, err = tx.ExecContext(ctx, updateQuery, p.Amount, p.Amount, p.Amount, p.UpdatedAt, p.DebtID)
+		return err
+	})
+
 	if err != nil {
-		return nil, payment.ErrDatabaseError
+		return nil, database.Wrap(payment.ErrDatabaseError, err)
 	}
-	defer tx.Rollback()
 
-	// Insertar el pago
+	return p, nil
+}
+
+func (r *repository) GetDebtBalance(ctx context.Context, debtID int) (*payment.DebtBalance, error) {
 	query := `
-		INSERT INTO payments (user_id, amount, debt_id, receipt_filename, date, created_at, updated_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?)
+		SELECT id, COALESCE(user_id, 0), household_id, remaining_amount, paid
+		FROM debts
+		WHERE id = ? AND deleted_at IS NULL
 	`
 
-	result, err := tx.ExecContext(ctx, query,
-		p.UserID, p.Amount, p.DebtID, p.ReceiptFilename, p.Date, p.CreatedAt, p.UpdatedAt,
+	var b payment.DebtBalance
+	err := database.Conn(ctx, r.db).QueryRowContext(ctx, query, debtID).Scan(
+		&b.DebtID, &b.UserID, &b.HouseholdID, &b.RemainingAmount, &b.Paid,
 	)
 
 	if err != nil {
-		return nil, payment.ErrDatabaseError
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, payment.ErrDebtNotFound
+		}
+		return nil, database.Wrap(payment.ErrDatabaseError, err)
 	}
 
-	id, err := result.LastInsertId()
-	if err != nil {
-		return nil, payment.ErrDatabaseError
-	}
+	return &b, nil
+}
 
-	p.ID = int(id)
-
-	// Actualizar la deuda
-	updateQuery := `
-		UPDATE debts 
-		SET remaining_amount = CASE 
-			WHEN remaining_amount - ? < 0 THEN 0 
-			ELSE remaining_amount - ? 
-		END,
-		paid = CASE 
-			WHEN remaining_amount - ? <= 0 THEN 1 
-			ELSE paid 
-		END,
-		updated_at = ?
-		WHERE id = ?
+func (r *repository) GetAllPayments(ctx context.Context) ([]payment.PaymentWithDebt, error) {
+	query := `
+		SELECT ` + paymentWithDebtColumns + `
+		FROM payments p
+		INNER JOIN debts d ON p.debt_id = d.id
+		WHERE p.deleted_at IS NULL AND d.deleted_at IS NULL
+		ORDER BY p.date DESC
 	`
 
-	_, err = tx.ExecContext(ctx, updateQuery, p.Amount, p.Amount, p.Amount, p.UpdatedAt, p.DebtID)
-	if err != nil {
-		return nil, payment.ErrDatabaseError
-	}
+	return r.queryPayments(ctx, query)
+}
 
-	if err := tx.Commit(); err != nil {
-		return nil, payment.ErrDatabaseError
-	}
+func (r *repository) GetPaymentsInScope(ctx context.Context, scope household.Scope) ([]payment.PaymentWithDebt, error) {
+	condition, args := database.ScopeCondition("p.", scope)
+	query := `
+		SELECT ` + paymentWithDebtColumns + `
+		FROM payments p
+		INNER JOIN debts d ON p.debt_id = d.id
+		WHERE ` + condition + ` AND p.deleted_at IS NULL AND d.deleted_at IS NULL
+		ORDER BY p.date DESC
+	`
 
-	return p, nil
+	return r.queryPayments(ctx, query, args...)
 }
 
-func (r *repository) GetAllPayments(ctx context.Context) ([]payment.PaymentWithDebt, error) {
+func (r *repository) GetDeletedPayments(ctx context.Context, scope household.Scope) ([]payment.PaymentWithDebt, error) {
+	condition, args := database.ScopeCondition("p.", scope)
 	query := `
-		SELECT 
-			p.id, COALESCE(p.user_id, 0), p.amount, p.debt_id, p.receipt_filename, p.date, p.created_at, p.updated_at,
-			d.name, d.remaining_amount, d.installment_amount
+		SELECT ` + paymentWithDebtColumns + `
 		FROM payments p
 		INNER JOIN debts d ON p.debt_id = d.id
-		ORDER BY p.date DESC
+		WHERE p.deleted_at IS NOT NULL AND ` + condition + `
+		ORDER BY p.deleted_at DESC
 	`
 
-	rows, err := r.db.QueryContext(ctx, query)
+	return r.queryPayments(ctx, query, args...)
+}
+
+func (r *repository) queryPayments(ctx context.Context, query string, args ...interface{}) ([]payment.PaymentWithDebt, error) {
+	rows, err := database.Conn(ctx, r.db).QueryContext(ctx, query, args...)
 	if err != nil {
-		return nil, payment.ErrDatabaseError
+		return nil, database.Wrap(payment.ErrDatabaseError, err)
 	}
 	defer rows.Close()
 
@@ -93,18 +142,18 @@ func (r *repository) GetAllPayments(ctx context.Context) ([]payment.PaymentWithD
 	for rows.Next() {
 		var pwd payment.PaymentWithDebt
 		err := rows.Scan(
-			&pwd.ID, &pwd.UserID, &pwd.Amount, &pwd.DebtID, &pwd.ReceiptFilename, &pwd.Date,
-			&pwd.CreatedAt, &pwd.UpdatedAt,
+			&pwd.ID, &pwd.UserID, &pwd.HouseholdID, &pwd.Amount, &pwd.DebtID, &pwd.ReceiptFilename, &pwd.Date,
+			&pwd.CreatedAt, &pwd.UpdatedAt, &pwd.DeletedAt,
 			&pwd.DebtName, &pwd.DebtRemainingAmount, &pwd.DebtInstallmentAmount,
 		)
 		if err != nil {
-			return nil, payment.ErrDatabaseError
+			return nil, database.Wrap(payment.ErrDatabaseError, err)
 		}
 		payments = append(payments, pwd)
 	}
 
 	if err = rows.Err(); err != nil {
-		return nil, payment.ErrDatabaseError
+		return nil, database.Wrap(payment.ErrDatabaseError, err)
 	}
 
 	if payments == nil {
@@ -114,81 +163,125 @@ func (r *repository) GetAllPayments(ctx context.Context) ([]payment.PaymentWithD
 	return payments, nil
 }
 
-func (r *repository) GetPaymentsByUserID(ctx context.Context, userID int) ([]payment.PaymentWithDebt, error) {
+func (r *repository) GetPaymentByID(ctx context.Context, id int) (*payment.Payment, error) {
 	query := `
-		SELECT 
-			p.id, COALESCE(p.user_id, 0), p.amount, p.debt_id, p.receipt_filename, p.date, p.created_at, p.updated_at,
-			d.name, d.remaining_amount, d.installment_amount
-		FROM payments p
-		INNER JOIN debts d ON p.debt_id = d.id
-		WHERE p.user_id = ?
-		ORDER BY p.date DESC
+		SELECT id, COALESCE(user_id, 0), household_id, amount, debt_id, receipt_filename, date, created_at, updated_at, deleted_at
+		FROM payments
+		WHERE id = ? AND deleted_at IS NULL
 	`
 
-	rows, err := r.db.QueryContext(ctx, query, userID)
-	if err != nil {
-		return nil, payment.ErrDatabaseError
-	}
-	defer rows.Close()
+	var p payment.Payment
+	err := database.Conn(ctx, r.db).QueryRowContext(ctx, query, id).Scan(
+		&p.ID, &p.UserID, &p.HouseholdID, &p.Amount, &p.DebtID, &p.ReceiptFilename, &p.Date, &p.CreatedAt, &p.UpdatedAt,
+		&p.DeletedAt,
+	)
 
-	var payments []payment.PaymentWithDebt
-	for rows.Next() {
-		var pwd payment.PaymentWithDebt
-		err := rows.Scan(
-			&pwd.ID, &pwd.UserID, &pwd.Amount, &pwd.DebtID, &pwd.ReceiptFilename, &pwd.Date,
-			&pwd.CreatedAt, &pwd.UpdatedAt,
-			&pwd.DebtName, &pwd.DebtRemainingAmount, &pwd.DebtInstallmentAmount,
-		)
-		if err != nil {
-			return nil, payment.ErrDatabaseError
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, payment.ErrPaymentNotFound
 		}
-		payments = append(payments, pwd)
+		return nil, database.Wrap(payment.ErrDatabaseError, err)
 	}
 
-	if err = rows.Err(); err != nil {
-		return nil, payment.ErrDatabaseError
-	}
-
-	if payments == nil {
-		payments = []payment.PaymentWithDebt{}
-	}
-
-	return payments, nil
+	return &p, nil
 }
 
-func (r *repository) GetPaymentByID(ctx context.Context, id int) (*payment.Payment, error) {
+// GetPaymentByReceipt incluye los pagos de la papelera, que también muestran su recibo.
+func (r *repository) GetPaymentByReceipt(ctx context.Context, filename string, scope household.Scope) (*payment.Payment, error) {
+	condition, args := database.ScopeCondition("", scope)
 	query := `
-		SELECT id, COALESCE(user_id, 0), amount, debt_id, receipt_filename, date, created_at, updated_at
+		SELECT id, COALESCE(user_id, 0), household_id, amount, debt_id, receipt_filename, date, created_at, updated_at, deleted_at
 		FROM payments
-		WHERE id = ?
+		WHERE receipt_filename = ? AND ` + condition + `
+		LIMIT 1
 	`
 
 	var p payment.Payment
-	err := r.db.QueryRowContext(ctx, query, id).Scan(
-		&p.ID, &p.UserID, &p.Amount, &p.DebtID, &p.ReceiptFilename, &p.Date, &p.CreatedAt, &p.UpdatedAt,
+	err := database.Conn(ctx, r.db).QueryRowContext(ctx, query, append([]interface{}{filename}, args...)...).Scan(
+		&p.ID, &p.UserID, &p.HouseholdID, &p.Amount, &p.DebtID, &p.ReceiptFilename, &p.Date, &p.CreatedAt, &p.UpdatedAt,
+		&p.DeletedAt,
 	)
 
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, payment.ErrPaymentNotFound
 		}
-		return nil, payment.ErrDatabaseError
+		return nil, database.Wrap(payment.ErrDatabaseError, err)
 	}
 
 	return &p, nil
 }
 
 func (r *repository) DeletePayment(ctx context.Context, id int) error {
-	query := `DELETE FROM payments WHERE id = ?`
+	query := `UPDATE payments SET deleted_at = ? WHERE id = ? AND deleted_at IS NULL`
+
+	return r.execOne(ctx, query, time.Now(), id)
+}
+
+// RestorePayment solo restaura pagos cuya deuda sigue activa; los pagos de una
+// deuda en la papelera se recuperan restaurando la deuda.
+func (r *repository) RestorePayment(ctx context.Context, id int) error {
+	query := `
+		UPDATE payments SET deleted_at = NULL, updated_at = ?
+		WHERE id = ? AND deleted_at IS NOT NULL
+		  AND debt_id IN (SELECT id FROM debts WHERE deleted_at IS NULL)
+	`
+
+	return r.execOne(ctx, query, time.Now(), id)
+}
+
+// PurgeDeletedPayments borra los pagos eliminados antes de la fecha indicada y
+// los de las deudas que se purgan con el mismo corte. Devuelve los recibos
+// asociados para que puedan borrarse del disco.
+func (r *repository) PurgeDeletedPayments(ctx context.Context, before time.Time) ([]string, error) {
+	condition := `
+		(deleted_at IS NOT NULL AND deleted_at < ?)
+		OR debt_id IN (SELECT id FROM debts WHERE deleted_at IS NOT NULL AND deleted_at < ?)
+	`
+
+	var filenames []string
+	err := database.WithinTx(ctx, r.db, func(ctx context.Context) error {
+		tx := database.Conn(ctx, r.db)
+
+		rows, err := tx.QueryContext(ctx, `SELECT COALESCE(receipt_filename, '') FROM payments WHERE `+condition, before, before)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var filename string
+			if err := rows.Scan(&filename); err != nil {
+				return err
+			}
+			if filename != "" {
+				filenames = append(filenames, filename)
+			}
+		}
+		if err := rows.Err(); err != nil {
+			return err
+		}
+
+		_, err = tx.ExecContext(ctx, `DELETE FROM payments WHERE `+condition, before, before)
+		return err
+	})
+
+	if err != nil {
+		return nil, database.Wrap(payment.ErrDatabaseError, err)
+	}
+
+	return filenames, nil
+}
 
-	result, err := r.db.ExecContext(ctx, query, id)
+func (r *repository) execOne(ctx context.Context, query string, args ...interface{}) error {
+	result, err := database.Conn(ctx, r.db).ExecContext(ctx, query, args...)
 	if err != nil {
-		return payment.ErrDatabaseError
+		return database.Wrap(payment.ErrDatabaseError, err)
 	}
 
 	rowsAffected, err := result.RowsAffected()
 	if err != nil {
-		return payment.ErrDatabaseError
+		return database.Wrap(payment.ErrDatabaseError, err)
 	}
 
 	if rowsAffected == 0 {
@@ -0,0 +1,57 @@
+//go:build postgres
+
+package repository_test
+
+import (
+	"database/sql"
+	"fmt"
+	"net/url"
+	"os"
+	"testing"
+	"time"
+
+	_ "github.com/jackc/pgx/v5/stdlib"
+	"github.com/payvue/payvue-backend/pkg/repository/database"
+)
+
+// openPostgres crea un esquema vacío para cada prueba en la base de datos de
+// PAYVUE_TEST_POSTGRES_URL y lo borra al terminar.
+func openPostgres(t *testing.T) *sql.DB {
+	t.Helper()
+	rawURL := os.Getenv("PAYVUE_TEST_POSTGRES_URL")
+	if rawURL == "" {
+		t.Skip("PAYVUE_TEST_POSTGRES_URL is not set")
+	}
+
+	admin, err := sql.Open("pgx", rawURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { admin.Close() })
+
+	schema := fmt.Sprintf("payvue_test_%d", time.Now().UnixNano())
+	if _, err := admin.Exec("CREATE SCHEMA " + schema); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if _, err := admin.Exec("DROP SCHEMA " + schema + " CASCADE"); err != nil {
+			t.Errorf("dropping schema %s: %v", schema, err)
+		}
+	})
+
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	query := u.Query()
+	query.Set("search_path", schema)
+	u.RawQuery = query.Encode()
+
+	db, err := database.InitDB(database.Config{Engine: database.Postgres, URL: u.String()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Se cierra antes del DROP SCHEMA: las Cleanup van en orden inverso
+	t.Cleanup(func() { db.Close() })
+	return db
+}
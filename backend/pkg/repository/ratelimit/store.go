@@ -0,0 +1,65 @@
+package ratelimit
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/payvue/payvue-backend/pkg/ratelimit"
+	"github.com/payvue/payvue-backend/pkg/repository/database"
+	"github.com/payvue/payvue-backend/pkg/utils/logger"
+)
+
+type store struct {
+	db        *sql.DB
+	mu        sync.Mutex
+	lastSweep time.Time
+}
+
+// NewStore guarda los contadores en la base de datos para que los compartan
+// todas las instancias (reader, writer y server).
+func NewStore(db *sql.DB) ratelimit.Store {
+	return &store{
+		db: db,
+	}
+}
+
+func (s *store) Hit(ctx context.Context, key string, window time.Duration) (int, time.Time, error) {
+	now := time.Now()
+	s.sweep(ctx, now)
+
+	query := `
+		INSERT INTO rate_limits (bucket_key, hits, reset_at)
+		VALUES (?, 1, ?)
+		ON CONFLICT (bucket_key) DO UPDATE SET
+			hits = CASE WHEN rate_limits.reset_at <= ? THEN 1 ELSE rate_limits.hits + 1 END,
+			reset_at = CASE WHEN rate_limits.reset_at <= ? THEN excluded.reset_at ELSE rate_limits.reset_at END
+		RETURNING hits, reset_at
+	`
+
+	var hits int
+	var resetAt int64
+	err := s.db.QueryRowContext(ctx, query, key, now.Add(window).Unix(), now.Unix(), now.Unix()).Scan(&hits, &resetAt)
+	if err != nil {
+		return 0, time.Time{}, database.Wrap(ratelimit.ErrStore, err)
+	}
+
+	return hits, time.Unix(resetAt, 0), nil
+}
+
+// sweep borra como mucho una vez por minuto las ventanas vencidas.
+func (s *store) sweep(ctx context.Context, now time.Time) {
+	s.mu.Lock()
+	if now.Sub(s.lastSweep) < time.Minute {
+		s.mu.Unlock()
+		return
+	}
+	s.lastSweep = now
+	s.mu.Unlock()
+
+	if _, err := s.db.ExecContext(ctx, `DELETE FROM rate_limits WHERE reset_at <= ?`, now.Unix()); err != nil {
+		logger.FromContext(ctx).Warn("could not sweep rate limit buckets", slog.Any("error", err))
+	}
+}
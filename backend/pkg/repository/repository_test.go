@@ -0,0 +1,504 @@
+package repository_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/payvue/payvue-backend/pkg/domain/debt"
+	"github.com/payvue/payvue-backend/pkg/domain/household"
+	"github.com/payvue/payvue-backend/pkg/domain/income"
+	"github.com/payvue/payvue-backend/pkg/domain/payment"
+	"github.com/payvue/payvue-backend/pkg/domain/user"
+	accountrepository "github.com/payvue/payvue-backend/pkg/repository/account"
+	"github.com/payvue/payvue-backend/pkg/repository/database"
+	debtrepository "github.com/payvue/payvue-backend/pkg/repository/debt"
+	householdrepository "github.com/payvue/payvue-backend/pkg/repository/household"
+	incomerepository "github.com/payvue/payvue-backend/pkg/repository/income"
+	paymentrepository "github.com/payvue/payvue-backend/pkg/repository/payment"
+	userrepository "github.com/payvue/payvue-backend/pkg/repository/user"
+)
+
+// Estas pruebas recorren los repositorios contra cada motor con el mismo
+// código: SQLite siempre y PostgreSQL con -tags postgres y
+// PAYVUE_TEST_POSTGRES_URL (ver postgres_test.go).
+func forEachEngine(t *testing.T, run func(t *testing.T, db *sql.DB)) {
+	t.Run("sqlite", func(t *testing.T) {
+		db, err := database.InitDB(database.Config{
+			Engine:      database.SQLite,
+			Path:        filepath.Join(t.TempDir(), "payvue.db"),
+			BusyTimeout: 5 * time.Second,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { db.Close() })
+		run(t, db)
+	})
+	t.Run("postgres", func(t *testing.T) {
+		run(t, openPostgres(t))
+	})
+}
+
+// now va en segundos enteros: PostgreSQL guarda microsegundos y SQLite el texto
+// que le da el driver, así que las fechas se comparan con Equal.
+func now() time.Time {
+	return time.Now().UTC().Truncate(time.Second)
+}
+
+func createUser(t *testing.T, db *sql.DB, email string) *user.User {
+	t.Helper()
+	u, err := userrepository.NewRepository(db).CreateUser(context.Background(), &user.User{
+		Email:        email,
+		PasswordHash: "hash",
+		CreatedAt:    now(),
+		UpdatedAt:    now(),
+	})
+	if err != nil {
+		t.Fatalf("CreateUser(%s): %v", email, err)
+	}
+	return u
+}
+
+func createDebt(t *testing.T, repo debt.Repository, userID, householdID int, name string) *debt.Debt {
+	t.Helper()
+	d, err := repo.CreateDebt(context.Background(), &debt.Debt{
+		UserID:            userID,
+		HouseholdID:       householdID,
+		Name:              name,
+		TotalAmount:       1000,
+		RemainingAmount:   1000,
+		DueDate:           now().AddDate(1, 0, 0),
+		InterestRate:      3.5,
+		NumInstallments:   10,
+		InstallmentAmount: 100,
+		PaymentDay:        5,
+		Version:           1,
+		CreatedAt:         now(),
+		UpdatedAt:         now(),
+	})
+	if err != nil {
+		t.Fatalf("CreateDebt(%s): %v", name, err)
+	}
+	return d
+}
+
+func debtNames(debts []debt.Debt) []string {
+	names := []string{}
+	for _, d := range debts {
+		names = append(names, d.Name)
+	}
+	return names
+}
+
+func TestUserRepository(t *testing.T) {
+	forEachEngine(t, func(t *testing.T, db *sql.DB) {
+		ctx := context.Background()
+		repo := userrepository.NewRepository(db)
+
+		created := createUser(t, db, "ana@example.com")
+		if created.ID == 0 {
+			t.Fatal("CreateUser did not set the ID")
+		}
+
+		got, err := repo.GetUserByEmail(ctx, "ana@example.com")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.ID != created.ID || got.TwoFactorEnabled || got.EmailVerifiedAt != nil {
+			t.Errorf("GetUserByEmail = %+v", got)
+		}
+
+		if _, err := repo.GetUserByEmail(ctx, "nadie@example.com"); !errors.Is(err, user.ErrUserNotFound) {
+			t.Errorf("GetUserByEmail(unknown) error = %v, want ErrUserNotFound", err)
+		}
+		if _, err := repo.CreateUser(ctx, &user.User{Email: "ana@example.com", PasswordHash: "x", CreatedAt: now(), UpdatedAt: now()}); err == nil {
+			t.Error("CreateUser with a duplicate email succeeded")
+		}
+
+		verifiedAt := now()
+		if err := repo.MarkEmailVerified(ctx, created.ID, verifiedAt); err != nil {
+			t.Fatal(err)
+		}
+		created.TOTPSecret = "SECRET"
+		created.TwoFactorEnabled = true
+		if err := repo.UpdateTwoFactor(ctx, created); err != nil {
+			t.Fatal(err)
+		}
+
+		got, err = repo.GetUserByID(ctx, created.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !got.TwoFactorEnabled || got.TOTPSecret != "SECRET" {
+			t.Errorf("two-factor fields = %v %q", got.TwoFactorEnabled, got.TOTPSecret)
+		}
+		if got.EmailVerifiedAt == nil || !got.EmailVerifiedAt.Equal(verifiedAt) {
+			t.Errorf("EmailVerifiedAt = %v, want %v", got.EmailVerifiedAt, verifiedAt)
+		}
+
+		if err := repo.MarkTOTPStepUsed(ctx, created.ID, 10); err != nil {
+			t.Fatal(err)
+		}
+		if err := repo.MarkTOTPStepUsed(ctx, created.ID, 10); !errors.Is(err, user.ErrInvalidTwoFactorCode) {
+			t.Errorf("reusing a TOTP step error = %v, want ErrInvalidTwoFactorCode", err)
+		}
+	})
+}
+
+func TestDebtRepository(t *testing.T) {
+	forEachEngine(t, func(t *testing.T, db *sql.DB) {
+		ctx := context.Background()
+		repo := debtrepository.NewRepository(db)
+		owner := createUser(t, db, "owner@example.com")
+
+		d := createDebt(t, repo, owner.ID, 0, "Coche")
+		got, err := repo.GetDebtByID(ctx, d.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.Name != "Coche" || got.RemainingAmount != 1000 || got.Paid || got.Version != 1 || !got.DueDate.Equal(d.DueDate) {
+			t.Errorf("GetDebtByID = %+v", got)
+		}
+
+		got.RemainingAmount = 0
+		got.Paid = true
+		got.UpdatedAt = now()
+		if _, err := repo.UpdateDebtColumns(ctx, got, []string{"remaining_amount", "paid"}); err != nil {
+			t.Fatal(err)
+		}
+		stale := *d
+		stale.Name = "Moto"
+		if _, err := repo.UpdateDebt(ctx, &stale); !errors.Is(err, debt.ErrVersionConflict) {
+			t.Errorf("UpdateDebt with a stale version error = %v, want ErrVersionConflict", err)
+		}
+
+		got, err = repo.GetDebtByID(ctx, d.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !got.Paid || got.RemainingAmount != 0 || got.Version != 2 || got.Name != "Coche" {
+			t.Errorf("after UpdateDebtColumns = %+v", got)
+		}
+
+		if err := repo.DeleteDebt(ctx, d.ID); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := repo.GetDebtByID(ctx, d.ID); !errors.Is(err, debt.ErrDebtNotFound) {
+			t.Errorf("GetDebtByID(deleted) error = %v, want ErrDebtNotFound", err)
+		}
+		deleted, err := repo.GetDeletedDebts(ctx, household.Scope{UserID: owner.ID})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(deleted) != 1 || deleted[0].DeletedAt == nil {
+			t.Errorf("GetDeletedDebts = %+v", deleted)
+		}
+		if err := repo.RestoreDebt(ctx, d.ID); err != nil {
+			t.Fatal(err)
+		}
+		if err := repo.RestoreDebt(ctx, d.ID); !errors.Is(err, debt.ErrDebtNotFound) {
+			t.Errorf("RestoreDebt(active) error = %v, want ErrDebtNotFound", err)
+		}
+
+		if err := repo.DeleteDebt(ctx, d.ID); err != nil {
+			t.Fatal(err)
+		}
+		purged, err := repo.PurgeDeletedDebts(ctx, time.Now().Add(time.Minute))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if purged != 1 {
+			t.Errorf("PurgeDeletedDebts = %d, want 1", purged)
+		}
+	})
+}
+
+func TestDebtRepositoryScope(t *testing.T) {
+	forEachEngine(t, func(t *testing.T, db *sql.DB) {
+		ctx := context.Background()
+		repo := debtrepository.NewRepository(db)
+		households := householdrepository.NewRepository(db)
+
+		owner := createUser(t, db, "owner@example.com")
+		member := createUser(t, db, "member@example.com")
+		stranger := createUser(t, db, "stranger@example.com")
+
+		home, err := households.CreateHousehold(ctx, &household.Household{Name: "Casa", CreatedBy: owner.ID, CreatedAt: now(), UpdatedAt: now()})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := households.AddMember(ctx, &household.Member{HouseholdID: home.ID, UserID: member.ID, Role: household.RoleViewer, CreatedAt: now()}); err != nil {
+			t.Fatal(err)
+		}
+
+		createDebt(t, repo, owner.ID, 0, "Personal")
+		createDebt(t, repo, owner.ID, home.ID, "Hipoteca")
+		createDebt(t, repo, stranger.ID, 0, "Ajena")
+
+		for _, tc := range []struct {
+			name   string
+			userID int
+			want   int
+		}{
+			{"owner", owner.ID, 2},
+			{"member", member.ID, 1},
+			{"stranger", stranger.ID, 1},
+			{"anonymous", 0, 0},
+		} {
+			roles := map[int]household.Role{}
+			if tc.userID > 0 {
+				if roles, err = households.GetRoles(ctx, tc.userID); err != nil {
+					t.Fatal(err)
+				}
+			}
+			debts, err := repo.GetDebtsInScope(ctx, household.Scope{UserID: tc.userID, Roles: roles})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(debts) != tc.want {
+				t.Errorf("%s sees %v, want %d debts", tc.name, debtNames(debts), tc.want)
+			}
+		}
+
+		all, err := repo.GetDebtsInScope(ctx, household.InternalScope())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(all) != 3 {
+			t.Errorf("InternalScope sees %v, want 3 debts", debtNames(all))
+		}
+	})
+}
+
+func TestIncomeRepository(t *testing.T) {
+	forEachEngine(t, func(t *testing.T, db *sql.DB) {
+		ctx := context.Background()
+		repo := incomerepository.NewRepository(db)
+		owner := createUser(t, db, "owner@example.com")
+		other := createUser(t, db, "other@example.com")
+
+		i, err := repo.CreateIncome(ctx, &income.Income{
+			UserID: owner.ID, Amount: 1500.5, Source: "Nómina", Date: now(), Version: 1, CreatedAt: now(), UpdatedAt: now(),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		i.Source = "Nómina marzo"
+		i.UpdatedAt = now()
+		if _, err := repo.UpdateIncomeColumns(ctx, i, []string{"source"}); err != nil {
+			t.Fatal(err)
+		}
+		got, err := repo.GetIncomeByID(ctx, i.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.Source != "Nómina marzo" || got.Amount != 1500.5 || got.Version != 2 || !got.Date.Equal(i.Date) {
+			t.Errorf("GetIncomeByID = %+v", got)
+		}
+
+		stale := *got
+		stale.Version = 1
+		if _, err := repo.UpdateIncome(ctx, &stale); !errors.Is(err, income.ErrVersionConflict) {
+			t.Errorf("UpdateIncome with a stale version error = %v, want ErrVersionConflict", err)
+		}
+
+		for _, tc := range []struct {
+			userID int
+			want   int
+		}{{owner.ID, 1}, {other.ID, 0}} {
+			incomes, err := repo.GetIncomesInScope(ctx, household.Scope{UserID: tc.userID})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(incomes) != tc.want {
+				t.Errorf("user %d sees %d incomes, want %d", tc.userID, len(incomes), tc.want)
+			}
+		}
+
+		if err := repo.DeleteIncome(ctx, i.ID); err != nil {
+			t.Fatal(err)
+		}
+		purged, err := repo.PurgeDeletedIncomes(ctx, time.Now().Add(time.Minute))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if purged != 1 {
+			t.Errorf("PurgeDeletedIncomes = %d, want 1", purged)
+		}
+	})
+}
+
+func TestPaymentRepository(t *testing.T) {
+	forEachEngine(t, func(t *testing.T, db *sql.DB) {
+		ctx := context.Background()
+		debts := debtrepository.NewRepository(db)
+		repo := paymentrepository.NewRepository(db)
+		owner := createUser(t, db, "owner@example.com")
+		d := createDebt(t, debts, owner.ID, 0, "Préstamo")
+
+		var ids []int
+		for n, amount := range []float64{400, 700} {
+			p, err := repo.CreatePayment(ctx, &payment.Payment{
+				UserID: owner.ID, Amount: amount, DebtID: d.ID, ReceiptFilename: fmt.Sprintf("recibo-%d.pdf", n),
+				Date: now(), CreatedAt: now(), UpdatedAt: now(),
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			ids = append(ids, p.ID)
+		}
+
+		// El segundo pago supera lo que quedaba: la deuda queda a 0 y pagada
+		balance, err := repo.GetDebtBalance(ctx, d.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if balance.RemainingAmount != 0 || !balance.Paid {
+			t.Errorf("GetDebtBalance = %+v, want remaining 0 and paid", balance)
+		}
+
+		payments, err := repo.GetPaymentsInScope(ctx, household.Scope{UserID: owner.ID})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(payments) != 2 || payments[0].DebtName != "Préstamo" {
+			t.Errorf("GetPaymentsInScope = %+v", payments)
+		}
+
+		stranger := createUser(t, db, "stranger@example.com")
+		if p, err := repo.GetPaymentByReceipt(ctx, "recibo-1.pdf", household.Scope{UserID: owner.ID}); err != nil || p.ID != ids[1] {
+			t.Errorf("GetPaymentByReceipt(owner) = %+v, %v, want payment %d", p, err, ids[1])
+		}
+		if _, err := repo.GetPaymentByReceipt(ctx, "recibo-1.pdf", household.Scope{UserID: stranger.ID}); !errors.Is(err, payment.ErrPaymentNotFound) {
+			t.Errorf("GetPaymentByReceipt(stranger) error = %v, want ErrPaymentNotFound", err)
+		}
+
+		if err := repo.DeletePayment(ctx, ids[0]); err != nil {
+			t.Fatal(err)
+		}
+		if err := repo.DeletePayment(ctx, ids[0]); !errors.Is(err, payment.ErrPaymentNotFound) {
+			t.Errorf("DeletePayment(deleted) error = %v, want ErrPaymentNotFound", err)
+		}
+		deleted, err := repo.GetDeletedPayments(ctx, household.Scope{UserID: owner.ID})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(deleted) != 1 || deleted[0].ID != ids[0] {
+			t.Errorf("GetDeletedPayments = %+v", deleted)
+		}
+
+		// Los pagos de una deuda purgada se purgan con ella
+		if err := debts.DeleteDebt(ctx, d.ID); err != nil {
+			t.Fatal(err)
+		}
+		filenames, err := repo.PurgeDeletedPayments(ctx, time.Now().Add(time.Minute))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(filenames) != 2 {
+			t.Errorf("PurgeDeletedPayments = %v, want both receipts", filenames)
+		}
+	})
+}
+
+func TestAccountDeletion(t *testing.T) {
+	forEachEngine(t, func(t *testing.T, db *sql.DB) {
+		ctx := context.Background()
+		debts := debtrepository.NewRepository(db)
+		incomes := incomerepository.NewRepository(db)
+		payments := paymentrepository.NewRepository(db)
+		households := householdrepository.NewRepository(db)
+
+		leaving := createUser(t, db, "leaving@example.com")
+		member := createUser(t, db, "member@example.com")
+
+		home, err := households.CreateHousehold(ctx, &household.Household{Name: "Casa", CreatedBy: leaving.ID, CreatedAt: now(), UpdatedAt: now()})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := households.AddMember(ctx, &household.Member{HouseholdID: home.ID, UserID: member.ID, Role: household.RoleEditor, CreatedAt: now()}); err != nil {
+			t.Fatal(err)
+		}
+		alone, err := households.CreateHousehold(ctx, &household.Household{Name: "Solo", CreatedBy: leaving.ID, CreatedAt: now(), UpdatedAt: now()})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		personal := createDebt(t, debts, leaving.ID, 0, "Personal")
+		mortgage := createDebt(t, debts, leaving.ID, home.ID, "Hipoteca")
+		power := createDebt(t, debts, member.ID, home.ID, "Luz")
+		createDebt(t, debts, leaving.ID, alone.ID, "Solo")
+		if _, err := incomes.CreateIncome(ctx, &income.Income{
+			UserID: leaving.ID, HouseholdID: home.ID, Amount: 900, Source: "Alquiler", Date: now(), Version: 1, CreatedAt: now(), UpdatedAt: now(),
+		}); err != nil {
+			t.Fatal(err)
+		}
+		pay := func(userID int, d *debt.Debt, receipt string) *payment.Payment {
+			p, err := payments.CreatePayment(ctx, &payment.Payment{
+				UserID: userID, HouseholdID: d.HouseholdID, Amount: 100, DebtID: d.ID, ReceiptFilename: receipt,
+				Date: now(), CreatedAt: now(), UpdatedAt: now(),
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			return p
+		}
+		pay(leaving.ID, personal, "personal.pdf")
+		ownPayment := pay(leaving.ID, mortgage, "hipoteca.pdf")
+		pay(member.ID, mortgage, "")
+		powerPayment := pay(leaving.ID, power, "luz.pdf")
+
+		filenames, err := accountrepository.NewRepository(db).DeleteAccount(ctx, leaving.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(filenames) != 1 || filenames[0] != "personal.pdf" {
+			t.Errorf("DeleteAccount receipts = %v, want only the personal one", filenames)
+		}
+
+		remaining, err := debts.GetDebtsInScope(ctx, household.InternalScope())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if names := debtNames(remaining); len(names) != 2 || names[0] == "Personal" || names[1] == "Personal" {
+			t.Errorf("debts left = %v, want the household ones", names)
+		}
+		for _, d := range remaining {
+			if d.UserID != member.ID {
+				t.Errorf("debt %s belongs to %d, want the new owner %d", d.Name, d.UserID, member.ID)
+			}
+		}
+
+		left, err := incomes.GetIncomesInScope(ctx, household.InternalScope())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(left) != 1 || left[0].UserID != member.ID {
+			t.Errorf("incomes left = %+v, want the household one owned by %d", left, member.ID)
+		}
+
+		for _, p := range []*payment.Payment{ownPayment, powerPayment} {
+			got, err := payments.GetPaymentByID(ctx, p.ID)
+			if err != nil {
+				t.Fatalf("payment %s: %v", p.ReceiptFilename, err)
+			}
+			if got.UserID != member.ID {
+				t.Errorf("payment %s belongs to %d, want %d", p.ReceiptFilename, got.UserID, member.ID)
+			}
+		}
+
+		roles, err := households.GetRoles(ctx, member.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if roles[home.ID] != household.RoleOwner {
+			t.Errorf("member roles = %v, want owner of %d", roles, home.ID)
+		}
+	})
+}
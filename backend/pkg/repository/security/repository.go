@@ -0,0 +1,158 @@
+package security
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/payvue/payvue-backend/pkg/domain/security"
+	"github.com/payvue/payvue-backend/pkg/repository/database"
+)
+
+type repository struct {
+	db *sql.DB
+}
+
+func NewRepository(db *sql.DB) security.Repository {
+	return &repository{
+		db: db,
+	}
+}
+
+func (r *repository) GetAttempt(ctx context.Context, scope, key string) (*security.Attempt, error) {
+	query := `
+		SELECT scope, attempt_key, failures, last_failure_at, locked_until
+		FROM login_attempts
+		WHERE scope = ? AND attempt_key = ?
+	`
+
+	var a security.Attempt
+	err := r.db.QueryRowContext(ctx, query, scope, key).Scan(
+		&a.Scope, &a.Key, &a.Failures, &a.LastFailureAt, &a.LockedUntil,
+	)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, security.ErrAttemptNotFound
+		}
+		return nil, database.Wrap(security.ErrDatabaseError, err)
+	}
+
+	return &a, nil
+}
+
+func (r *repository) AddFailure(ctx context.Context, scope, key string, at, windowStart time.Time) (int, error) {
+	query := `
+		INSERT INTO login_attempts (scope, attempt_key, failures, last_failure_at)
+		VALUES (?, ?, 1, ?)
+		ON CONFLICT (scope, attempt_key) DO UPDATE SET
+			failures = CASE WHEN login_attempts.last_failure_at < ? THEN 1 ELSE login_attempts.failures + 1 END,
+			last_failure_at = excluded.last_failure_at
+		RETURNING failures
+	`
+
+	var failures int
+	err := r.db.QueryRowContext(ctx, query, scope, key, at, windowStart).Scan(&failures)
+	if err != nil {
+		return 0, database.Wrap(security.ErrDatabaseError, err)
+	}
+
+	return failures, nil
+}
+
+func (r *repository) LockAttempt(ctx context.Context, scope, key string, until time.Time) error {
+	query := `UPDATE login_attempts SET locked_until = ? WHERE scope = ? AND attempt_key = ?`
+
+	_, err := r.db.ExecContext(ctx, query, until, scope, key)
+	if err != nil {
+		return database.Wrap(security.ErrDatabaseError, err)
+	}
+
+	return nil
+}
+
+func (r *repository) DeleteAttempt(ctx context.Context, scope, key string) error {
+	query := `DELETE FROM login_attempts WHERE scope = ? AND attempt_key = ?`
+
+	_, err := r.db.ExecContext(ctx, query, scope, key)
+	if err != nil {
+		return database.Wrap(security.ErrDatabaseError, err)
+	}
+
+	return nil
+}
+
+func (r *repository) PurgeAttempts(ctx context.Context, before time.Time) (int, error) {
+	query := `
+		DELETE FROM login_attempts
+		WHERE last_failure_at < ? AND (locked_until IS NULL OR locked_until < ?)
+	`
+
+	result, err := r.db.ExecContext(ctx, query, before, before)
+	if err != nil {
+		return 0, database.Wrap(security.ErrDatabaseError, err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, database.Wrap(security.ErrDatabaseError, err)
+	}
+
+	return int(rowsAffected), nil
+}
+
+func (r *repository) CreateEvent(ctx context.Context, e *security.Event) error {
+	query := `
+		INSERT INTO security_events (user_id, event_type, ip_address, details, created_at)
+		VALUES (?, ?, ?, ?, ?)
+		RETURNING id
+	`
+
+	var details interface{}
+	if len(e.Details) > 0 {
+		details = string(e.Details)
+	}
+
+	err := r.db.QueryRowContext(ctx, query, e.UserID, e.Type, e.IP, details, e.CreatedAt).Scan(&e.ID)
+	if err != nil {
+		return database.Wrap(security.ErrDatabaseError, err)
+	}
+
+	return nil
+}
+
+func (r *repository) GetEventsByUserID(ctx context.Context, userID int, limit int) ([]security.Event, error) {
+	query := `
+		SELECT id, user_id, event_type, COALESCE(ip_address, ''), details, created_at
+		FROM security_events
+		WHERE user_id = ?
+		ORDER BY id DESC
+		LIMIT ?
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID, limit)
+	if err != nil {
+		return nil, database.Wrap(security.ErrDatabaseError, err)
+	}
+	defer rows.Close()
+
+	events := []security.Event{}
+	for rows.Next() {
+		var e security.Event
+		var details sql.NullString
+		if err := rows.Scan(&e.ID, &e.UserID, &e.Type, &e.IP, &details, &e.CreatedAt); err != nil {
+			return nil, database.Wrap(security.ErrDatabaseError, err)
+		}
+		if details.Valid {
+			e.Details = []byte(details.String)
+		}
+		events = append(events, e)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, database.Wrap(security.ErrDatabaseError, err)
+	}
+
+	return events, nil
+}
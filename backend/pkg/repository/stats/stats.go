@@ -0,0 +1,39 @@
+package stats
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/payvue/payvue-backend/pkg/metrics"
+)
+
+// Register añade al registro los gauges de negocio. Se calculan con una consulta
+// en cada lectura de /metrics, así que reflejan la base de datos compartida y no
+// solo lo que ha hecho esta instancia.
+func Register(registry *metrics.Registry, db *sql.DB) {
+	gauges := []struct {
+		name, help, query string
+	}{
+		{"payvue_debts_active", "Deudas sin pagar y fuera de la papelera.",
+			`SELECT COUNT(*) FROM debts WHERE deleted_at IS NULL AND NOT paid`},
+		{"payvue_debts_remaining_amount", "Importe pendiente de las deudas activas.",
+			`SELECT COALESCE(SUM(remaining_amount), 0) FROM debts WHERE deleted_at IS NULL AND NOT paid`},
+		{"payvue_payments", "Pagos registrados fuera de la papelera.",
+			`SELECT COUNT(*) FROM payments WHERE deleted_at IS NULL`},
+		{"payvue_incomes", "Ingresos registrados fuera de la papelera.",
+			`SELECT COUNT(*) FROM incomes WHERE deleted_at IS NULL`},
+		{"payvue_users", "Usuarios registrados.",
+			`SELECT COUNT(*) FROM users`},
+		{"payvue_households", "Hogares compartidos.",
+			`SELECT COUNT(*) FROM households`},
+	}
+
+	for _, gauge := range gauges {
+		query := gauge.query
+		registry.NewGaugeFunc(gauge.name, gauge.help, func(ctx context.Context) (float64, error) {
+			var value float64
+			err := db.QueryRowContext(ctx, query).Scan(&value)
+			return value, err
+		})
+	}
+}
@@ -4,10 +4,14 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"time"
 
 	"github.com/payvue/payvue-backend/pkg/domain/user"
+	"github.com/payvue/payvue-backend/pkg/repository/database"
 )
 
+const userColumns = `id, email, password_hash, COALESCE(totp_secret, ''), totp_enabled, totp_last_step, email_verified_at, deletion_scheduled_at, created_at, updated_at`
+
 type repository struct {
 	db *sql.DB
 }
@@ -20,44 +24,40 @@ func NewRepository(db *sql.DB) user.Repository {
 
 func (r *repository) CreateUser(ctx context.Context, u *user.User) (*user.User, error) {
 	query := `
-		INSERT INTO users (email, password_hash, created_at, updated_at)
-		VALUES (?, ?, ?, ?)
+		INSERT INTO users (email, password_hash, email_verified_at, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?)
+		RETURNING id
 	`
 
-	result, err := r.db.ExecContext(ctx, query,
-		u.Email, u.PasswordHash, u.CreatedAt, u.UpdatedAt,
-	)
-
-	if err != nil {
-		return nil, user.ErrDatabaseError
-	}
+	err := r.db.QueryRowContext(ctx, query,
+		u.Email, u.PasswordHash, u.EmailVerifiedAt, u.CreatedAt, u.UpdatedAt,
+	).Scan(&u.ID)
 
-	id, err := result.LastInsertId()
 	if err != nil {
-		return nil, user.ErrDatabaseError
+		return nil, database.Wrap(user.ErrDatabaseError, err)
 	}
 
-	u.ID = int(id)
 	return u, nil
 }
 
 func (r *repository) GetUserByEmail(ctx context.Context, email string) (*user.User, error) {
 	query := `
-		SELECT id, email, password_hash, created_at, updated_at
+		SELECT ` + userColumns + `
 		FROM users
 		WHERE email = ?
 	`
 
 	var u user.User
 	err := r.db.QueryRowContext(ctx, query, email).Scan(
-		&u.ID, &u.Email, &u.PasswordHash, &u.CreatedAt, &u.UpdatedAt,
+		&u.ID, &u.Email, &u.PasswordHash, &u.TOTPSecret, &u.TwoFactorEnabled, &u.TOTPLastStep,
+		&u.EmailVerifiedAt, &u.DeletionScheduledAt, &u.CreatedAt, &u.UpdatedAt,
 	)
 
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, user.ErrUserNotFound
 		}
-		return nil, user.ErrDatabaseError
+		return nil, database.Wrap(user.ErrDatabaseError, err)
 	}
 
 	return &u, nil
@@ -65,22 +65,320 @@ func (r *repository) GetUserByEmail(ctx context.Context, email string) (*user.Us
 
 func (r *repository) GetUserByID(ctx context.Context, id int) (*user.User, error) {
 	query := `
-		SELECT id, email, password_hash, created_at, updated_at
+		SELECT ` + userColumns + `
 		FROM users
 		WHERE id = ?
 	`
 
 	var u user.User
 	err := r.db.QueryRowContext(ctx, query, id).Scan(
-		&u.ID, &u.Email, &u.PasswordHash, &u.CreatedAt, &u.UpdatedAt,
+		&u.ID, &u.Email, &u.PasswordHash, &u.TOTPSecret, &u.TwoFactorEnabled, &u.TOTPLastStep,
+		&u.EmailVerifiedAt, &u.DeletionScheduledAt, &u.CreatedAt, &u.UpdatedAt,
 	)
 
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, user.ErrUserNotFound
 		}
-		return nil, user.ErrDatabaseError
+		return nil, database.Wrap(user.ErrDatabaseError, err)
 	}
 
 	return &u, nil
 }
+
+func (r *repository) MarkEmailVerified(ctx context.Context, userID int, verifiedAt time.Time) error {
+	query := `UPDATE users SET email_verified_at = ?, updated_at = ? WHERE id = ?`
+
+	result, err := r.db.ExecContext(ctx, query, verifiedAt, verifiedAt, userID)
+	if err != nil {
+		return database.Wrap(user.ErrDatabaseError, err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return database.Wrap(user.ErrDatabaseError, err)
+	}
+
+	if rowsAffected == 0 {
+		return user.ErrUserNotFound
+	}
+
+	return nil
+}
+
+func (r *repository) UpdateTwoFactor(ctx context.Context, u *user.User) error {
+	query := `
+		UPDATE users
+		SET totp_secret = ?, totp_enabled = ?, totp_last_step = ?, updated_at = ?
+		WHERE id = ?
+	`
+
+	var secret interface{}
+	if u.TOTPSecret != "" {
+		secret = u.TOTPSecret
+	}
+
+	result, err := r.db.ExecContext(ctx, query, secret, u.TwoFactorEnabled, u.TOTPLastStep, u.UpdatedAt, u.ID)
+	if err != nil {
+		return database.Wrap(user.ErrDatabaseError, err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return database.Wrap(user.ErrDatabaseError, err)
+	}
+
+	if rowsAffected == 0 {
+		return user.ErrUserNotFound
+	}
+
+	return nil
+}
+
+func (r *repository) MarkTOTPStepUsed(ctx context.Context, userID int, step int64) error {
+	query := `UPDATE users SET totp_last_step = ? WHERE id = ? AND totp_last_step < ?`
+
+	result, err := r.db.ExecContext(ctx, query, step, userID, step)
+	if err != nil {
+		return database.Wrap(user.ErrDatabaseError, err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return database.Wrap(user.ErrDatabaseError, err)
+	}
+
+	if rowsAffected == 0 {
+		return user.ErrInvalidTwoFactorCode
+	}
+
+	return nil
+}
+
+func (r *repository) ReplaceRecoveryCodes(ctx context.Context, userID int, codeHashes []string) error {
+	err := database.WithinTx(ctx, r.db, func(ctx context.Context) error {
+		conn := database.Conn(ctx, r.db)
+		if _, err := conn.ExecContext(ctx, `DELETE FROM recovery_codes WHERE user_id = ?`, userID); err != nil {
+			return err
+		}
+
+		now := time.Now()
+		for _, hash := range codeHashes {
+			_, err := conn.ExecContext(ctx,
+				`INSERT INTO recovery_codes (user_id, code_hash, created_at) VALUES (?, ?, ?)`,
+				userID, hash, now,
+			)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+
+	if err != nil {
+		return database.Wrap(user.ErrDatabaseError, err)
+	}
+
+	return nil
+}
+
+func (r *repository) UseRecoveryCode(ctx context.Context, userID int, codeHash string) error {
+	query := `
+		UPDATE recovery_codes SET used_at = ?
+		WHERE user_id = ? AND code_hash = ? AND used_at IS NULL
+	`
+
+	result, err := r.db.ExecContext(ctx, query, time.Now(), userID, codeHash)
+	if err != nil {
+		return database.Wrap(user.ErrDatabaseError, err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return database.Wrap(user.ErrDatabaseError, err)
+	}
+
+	if rowsAffected == 0 {
+		return user.ErrInvalidTwoFactorCode
+	}
+
+	return nil
+}
+
+func (r *repository) DeleteRecoveryCodes(ctx context.Context, userID int) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM recovery_codes WHERE user_id = ?`, userID)
+	if err != nil {
+		return database.Wrap(user.ErrDatabaseError, err)
+	}
+
+	return nil
+}
+
+func (r *repository) CreateChallenge(ctx context.Context, c *user.Challenge) error {
+	query := `
+		INSERT INTO two_factor_challenges (token_hash, user_id, attempts, expires_at, created_at)
+		VALUES (?, ?, ?, ?, ?)
+	`
+
+	_, err := r.db.ExecContext(ctx, query, c.TokenHash, c.UserID, c.Attempts, c.ExpiresAt, c.CreatedAt)
+	if err != nil {
+		return database.Wrap(user.ErrDatabaseError, err)
+	}
+
+	// Los retos caducados no sirven para nada: se limpian al crear uno nuevo
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM two_factor_challenges WHERE expires_at < ?`, c.CreatedAt); err != nil {
+		return database.Wrap(user.ErrDatabaseError, err)
+	}
+
+	return nil
+}
+
+func (r *repository) GetChallenge(ctx context.Context, tokenHash string) (*user.Challenge, error) {
+	query := `
+		SELECT token_hash, user_id, attempts, expires_at, created_at
+		FROM two_factor_challenges
+		WHERE token_hash = ?
+	`
+
+	var c user.Challenge
+	err := r.db.QueryRowContext(ctx, query, tokenHash).Scan(
+		&c.TokenHash, &c.UserID, &c.Attempts, &c.ExpiresAt, &c.CreatedAt,
+	)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, user.ErrInvalidChallenge
+		}
+		return nil, database.Wrap(user.ErrDatabaseError, err)
+	}
+
+	return &c, nil
+}
+
+func (r *repository) IncrementChallengeAttempts(ctx context.Context, tokenHash string) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE two_factor_challenges SET attempts = attempts + 1 WHERE token_hash = ?`, tokenHash)
+	if err != nil {
+		return database.Wrap(user.ErrDatabaseError, err)
+	}
+
+	return nil
+}
+
+func (r *repository) DeleteChallenge(ctx context.Context, tokenHash string) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM two_factor_challenges WHERE token_hash = ?`, tokenHash)
+	if err != nil {
+		return database.Wrap(user.ErrDatabaseError, err)
+	}
+
+	return nil
+}
+
+func (r *repository) GetIdentity(ctx context.Context, provider, subject string) (*user.Identity, error) {
+	query := `
+		SELECT id, user_id, provider, subject, COALESCE(email, ''), created_at, last_login_at
+		FROM user_identities
+		WHERE provider = ? AND subject = ?
+	`
+
+	var i user.Identity
+	err := r.db.QueryRowContext(ctx, query, provider, subject).Scan(
+		&i.ID, &i.UserID, &i.Provider, &i.Subject, &i.Email, &i.CreatedAt, &i.LastLoginAt,
+	)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, user.ErrIdentityNotFound
+		}
+		return nil, database.Wrap(user.ErrDatabaseError, err)
+	}
+
+	return &i, nil
+}
+
+func (r *repository) CreateIdentity(ctx context.Context, u *user.User, i *user.Identity) error {
+	err := database.WithinTx(ctx, r.db, func(ctx context.Context) error {
+		conn := database.Conn(ctx, r.db)
+
+		if u.ID == 0 {
+			err := conn.QueryRowContext(ctx,
+				`INSERT INTO users (email, password_hash, email_verified_at, created_at, updated_at) VALUES (?, ?, ?, ?, ?) RETURNING id`,
+				u.Email, u.PasswordHash, u.EmailVerifiedAt, u.CreatedAt, u.UpdatedAt,
+			).Scan(&u.ID)
+			if err != nil {
+				return err
+			}
+		}
+
+		i.UserID = u.ID
+		err := conn.QueryRowContext(ctx, `
+			INSERT INTO user_identities (user_id, provider, subject, email, created_at, last_login_at)
+			VALUES (?, ?, ?, ?, ?, ?)
+			ON CONFLICT (provider, subject) DO NOTHING
+			RETURNING id
+		`, i.UserID, i.Provider, i.Subject, i.Email, i.CreatedAt, i.LastLoginAt).Scan(&i.ID)
+		// Otra petición vinculó la misma identidad a la vez
+		if errors.Is(err, sql.ErrNoRows) {
+			return user.ErrIdentityAlreadyLinked
+		}
+		return err
+	})
+
+	if err != nil {
+		if err == user.ErrIdentityAlreadyLinked {
+			return err
+		}
+		return database.Wrap(user.ErrDatabaseError, err)
+	}
+
+	return nil
+}
+
+func (r *repository) TouchIdentity(ctx context.Context, identityID int, lastLoginAt time.Time) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE user_identities SET last_login_at = ? WHERE id = ?`, lastLoginAt, identityID)
+	if err != nil {
+		return database.Wrap(user.ErrDatabaseError, err)
+	}
+
+	return nil
+}
+
+func (r *repository) CreateExternalLoginState(ctx context.Context, s *user.ExternalLoginState) error {
+	query := `
+		INSERT INTO external_login_states (state_hash, code_verifier, nonce, link_user_id, expires_at, created_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`
+
+	_, err := r.db.ExecContext(ctx, query, s.StateHash, s.CodeVerifier, s.Nonce, s.LinkUserID, s.ExpiresAt, s.CreatedAt)
+	if err != nil {
+		return database.Wrap(user.ErrDatabaseError, err)
+	}
+
+	// Los estados de logins abandonados se limpian al crear uno nuevo
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM external_login_states WHERE expires_at < ?`, s.CreatedAt); err != nil {
+		return database.Wrap(user.ErrDatabaseError, err)
+	}
+
+	return nil
+}
+
+func (r *repository) ConsumeExternalLoginState(ctx context.Context, stateHash string) (*user.ExternalLoginState, error) {
+	query := `
+		DELETE FROM external_login_states
+		WHERE state_hash = ?
+		RETURNING state_hash, code_verifier, nonce, link_user_id, expires_at, created_at
+	`
+
+	var s user.ExternalLoginState
+	err := r.db.QueryRowContext(ctx, query, stateHash).Scan(
+		&s.StateHash, &s.CodeVerifier, &s.Nonce, &s.LinkUserID, &s.ExpiresAt, &s.CreatedAt,
+	)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, user.ErrInvalidExternalLoginState
+		}
+		return nil, database.Wrap(user.ErrDatabaseError, err)
+	}
+
+	return &s, nil
+}
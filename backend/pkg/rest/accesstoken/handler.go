@@ -0,0 +1,16 @@
+package accesstoken
+
+import (
+	"github.com/payvue/payvue-backend/pkg/domain/accesstoken"
+	"github.com/payvue/payvue-backend/pkg/rest"
+)
+
+type handler struct {
+	tokenService accesstoken.Service
+}
+
+func NewHandler(tokenService accesstoken.Service) rest.Handler {
+	return &handler{
+		tokenService: tokenService,
+	}
+}
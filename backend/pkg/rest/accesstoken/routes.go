@@ -0,0 +1,18 @@
+package accesstoken
+
+import (
+	"github.com/go-chi/chi/v5"
+	"github.com/payvue/payvue-backend/pkg/rest"
+)
+
+func (h *handler) RouteURLs(router *chi.Mux, mode rest.Mode) {
+	router.Route("/tokens", func(r chi.Router) {
+		if mode.Reads() {
+			r.Get("/", h.GetTokens)
+		}
+		if mode.Writes() {
+			r.Post("/", h.CreateToken)
+			r.Delete("/{id}", h.RevokeToken)
+		}
+	})
+}
@@ -0,0 +1,102 @@
+package accesstoken
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/payvue/payvue-backend/pkg/domain/accesstoken"
+	"github.com/payvue/payvue-backend/pkg/rest"
+	"github.com/payvue/payvue-backend/pkg/rest/entities"
+	"github.com/payvue/payvue-backend/pkg/utils/validation"
+)
+
+var validate = validation.New()
+
+// GetTokens lista los tokens de acceso del usuario, sin su valor.
+func (h *handler) GetTokens(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID := rest.UserIDFromRequest(r)
+	if userID <= 0 {
+		rest.RespondProblem(w, r, http.StatusUnauthorized, "unauthorized", "Usuario no identificado")
+		return
+	}
+
+	tokens, err := h.tokenService.GetTokens(ctx, userID)
+	if err != nil {
+		rest.RespondError(w, r, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, accesstoken.ToTokenListResponse(tokens))
+}
+
+// CreateToken crea un token de acceso; el valor solo se devuelve en esta respuesta.
+// Pide la contraseña (y el código de 2FA) como el borrado de la cuenta. Un token
+// no puede crear otros tokens.
+func (h *handler) CreateToken(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID := rest.UserIDFromRequest(r)
+	if userID <= 0 {
+		rest.RespondProblem(w, r, http.StatusUnauthorized, "unauthorized", "Usuario no identificado")
+		return
+	}
+	if rest.AccessTokenFromContext(ctx) != nil {
+		rest.RespondProblem(w, r, http.StatusForbidden, "token_not_allowed", "Los tokens de acceso no pueden crear otros tokens")
+		return
+	}
+
+	var request accesstoken.CreateTokenRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		rest.RespondDecodeError(w, r, err)
+		return
+	}
+
+	if err := validate.Struct(request); err != nil {
+		rest.RespondError(w, r, err)
+		return
+	}
+
+	token, err := h.tokenService.CreateToken(ctx, userID, request)
+	if err != nil {
+		rest.RespondError(w, r, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, accesstoken.ToTokenResponse(token))
+}
+
+func (h *handler) RevokeToken(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID := rest.UserIDFromRequest(r)
+	if userID <= 0 {
+		rest.RespondProblem(w, r, http.StatusUnauthorized, "unauthorized", "Usuario no identificado")
+		return
+	}
+
+	tokenID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		rest.RespondProblem(w, r, http.StatusBadRequest, "invalid_id", "ID must be a number")
+		return
+	}
+
+	if err := h.tokenService.RevokeToken(ctx, userID, tokenID); err != nil {
+		rest.RespondError(w, r, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, entities.MessageResponse{
+		Message: "Token revocado exitosamente",
+	})
+}
+
+func respondWithJSON(w http.ResponseWriter, code int, payload interface{}) {
+	response, _ := json.Marshal(payload)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	w.Write(response)
+}
@@ -0,0 +1,122 @@
+package account
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/payvue/payvue-backend/pkg/domain/account"
+	"github.com/payvue/payvue-backend/pkg/domain/household"
+	"github.com/payvue/payvue-backend/pkg/domain/user"
+	"github.com/payvue/payvue-backend/pkg/rest"
+	"github.com/payvue/payvue-backend/pkg/utils/fileupload"
+)
+
+// Export descarga un zip con todos los datos del usuario: un JSON por tipo de
+// dato y los recibos de sus pagos en receipts/.
+func (h *handler) Export(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID := rest.UserIDFromRequest(r)
+	if userID <= 0 {
+		rest.RespondProblem(w, r, http.StatusUnauthorized, "unauthorized", "Usuario no identificado")
+		return
+	}
+
+	export, err := h.accountService.Export(ctx, userID)
+	if err != nil {
+		rest.RespondError(w, r, err)
+		return
+	}
+
+	// Se genera entero en memoria para poder responder con un error si algo falla
+	var archive bytes.Buffer
+	if err := writeArchive(&archive, export); err != nil {
+		rest.RespondError(w, r, err)
+		return
+	}
+
+	filename := fmt.Sprintf("payvue-export-%d-%s.zip", userID, export.GeneratedAt.Format("20060102"))
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+	w.Header().Set("Content-Length", fmt.Sprint(archive.Len()))
+	w.WriteHeader(http.StatusOK)
+	w.Write(archive.Bytes())
+}
+
+func writeArchive(w io.Writer, export *account.Export) error {
+	archive := zip.NewWriter(w)
+
+	files := []struct {
+		name string
+		data interface{}
+	}{
+		{"account.json", struct {
+			GeneratedAt time.Time             `json:"generated_at"`
+			User        user.User             `json:"user"`
+			Identities  []user.Identity       `json:"identities"`
+			Households  []household.Household `json:"households"`
+		}{export.GeneratedAt, export.User, export.Identities, export.Households}},
+		{"debts.json", export.Debts},
+		{"incomes.json", export.Incomes},
+		{"payments.json", export.Payments},
+		{"security_events.json", export.SecurityEvents},
+	}
+	for _, file := range files {
+		data, err := json.MarshalIndent(file.data, "", "  ")
+		if err != nil {
+			return err
+		}
+		f, err := archive.Create(file.name)
+		if err != nil {
+			return err
+		}
+		if _, err := f.Write(data); err != nil {
+			return err
+		}
+	}
+
+	for _, payment := range export.Payments {
+		if payment.ReceiptFilename == "" {
+			continue
+		}
+		if err := addReceipt(archive, payment.ReceiptFilename); err != nil {
+			return err
+		}
+	}
+
+	return archive.Close()
+}
+
+// addReceipt copia un recibo al zip; los que ya no están en disco se omiten.
+func addReceipt(archive *zip.Writer, filename string) error {
+	name := filepath.Base(filename)
+	src, err := os.Open(fileupload.GetFilePath(name))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	defer src.Close()
+
+	dst, err := archive.Create("receipts/" + name)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(dst, src)
+	return err
+}
+
+func respondWithJSON(w http.ResponseWriter, code int, payload interface{}) {
+	response, _ := json.Marshal(payload)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	w.Write(response)
+}
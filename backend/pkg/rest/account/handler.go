@@ -0,0 +1,16 @@
+package account
+
+import (
+	"github.com/payvue/payvue-backend/pkg/domain/account"
+	"github.com/payvue/payvue-backend/pkg/rest"
+)
+
+type handler struct {
+	accountService account.Service
+}
+
+func NewHandler(accountService account.Service) rest.Handler {
+	return &handler{
+		accountService: accountService,
+	}
+}
@@ -0,0 +1,15 @@
+package account
+
+import (
+	"github.com/go-chi/chi/v5"
+	"github.com/payvue/payvue-backend/pkg/rest"
+)
+
+func (h *handler) RouteURLs(router *chi.Mux, mode rest.Mode) {
+	if !mode.Reads() {
+		return
+	}
+	router.Route("/account", func(r chi.Router) {
+		r.Get("/export", h.Export)
+	})
+}
@@ -0,0 +1,86 @@
+package audit
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/payvue/payvue-backend/pkg/domain/audit"
+	"github.com/payvue/payvue-backend/pkg/rest"
+)
+
+func (h *handler) GetEntries(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID := rest.UserIDFromRequest(r)
+	if userID <= 0 {
+		rest.RespondProblem(w, r, http.StatusUnauthorized, "unauthorized", "Usuario no identificado")
+		return
+	}
+
+	filter, err := ParseFilter(r.URL.Query())
+	if err != nil {
+		rest.RespondProblem(w, r, http.StatusBadRequest, "invalid_filter", err.Error())
+		return
+	}
+
+	entries, err := h.auditService.GetEntries(ctx, userID, filter)
+	if err != nil {
+		rest.RespondError(w, r, err)
+		return
+	}
+
+	response := audit.ToEntryListResponse(entries)
+	respondWithJSON(w, http.StatusOK, response.Entries)
+}
+
+// ParseFilter interpreta los parámetros entity, entity_id, action, from, to
+// (YYYY-MM-DD, ambos inclusive) y limit. El usuario no se elige: lo fija el
+// servicio con el de la petición.
+func ParseFilter(query url.Values) (audit.Filter, error) {
+	filter := audit.Filter{
+		Entity: query.Get("entity"),
+		Action: query.Get("action"),
+	}
+
+	var err error
+	if v := query.Get("entity_id"); v != "" {
+		if filter.EntityID, err = strconv.Atoi(v); err != nil {
+			return filter, errInvalidParam("entity_id")
+		}
+	}
+	if v := query.Get("limit"); v != "" {
+		if filter.Limit, err = strconv.Atoi(v); err != nil {
+			return filter, errInvalidParam("limit")
+		}
+	}
+	if v := query.Get("from"); v != "" {
+		if filter.From, err = time.Parse("2006-01-02", v); err != nil {
+			return filter, errInvalidParam("from")
+		}
+	}
+	if v := query.Get("to"); v != "" {
+		to, err := time.Parse("2006-01-02", v)
+		if err != nil {
+			return filter, errInvalidParam("to")
+		}
+		filter.To = to.AddDate(0, 0, 1)
+	}
+
+	return filter, nil
+}
+
+type errInvalidParam string
+
+func (e errInvalidParam) Error() string {
+	return "invalid value for " + string(e)
+}
+
+func respondWithJSON(w http.ResponseWriter, code int, payload interface{}) {
+	response, _ := json.Marshal(payload)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	w.Write(response)
+}
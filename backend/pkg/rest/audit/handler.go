@@ -0,0 +1,16 @@
+package audit
+
+import (
+	"github.com/payvue/payvue-backend/pkg/domain/audit"
+	"github.com/payvue/payvue-backend/pkg/rest"
+)
+
+type handler struct {
+	auditService audit.Service
+}
+
+func NewHandler(auditService audit.Service) rest.Handler {
+	return &handler{
+		auditService: auditService,
+	}
+}
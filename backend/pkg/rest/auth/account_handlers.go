@@ -0,0 +1,73 @@
+package auth
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"github.com/payvue/payvue-backend/pkg/domain/account"
+	"github.com/payvue/payvue-backend/pkg/rest"
+	"github.com/payvue/payvue-backend/pkg/rest/entities"
+	"github.com/payvue/payvue-backend/pkg/utils/fileupload"
+	"github.com/payvue/payvue-backend/pkg/utils/logger"
+)
+
+// DeleteAccount pide las credenciales y programa el borrado de la cuenta. Si no hay
+// periodo de gracia la borra en el acto, recibos incluidos.
+func (h *handler) DeleteAccount(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID := rest.UserIDFromRequest(r)
+	if userID <= 0 {
+		rest.RespondProblem(w, r, http.StatusUnauthorized, "unauthorized", "Usuario no identificado")
+		return
+	}
+
+	var request account.DeleteAccountRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		rest.RespondDecodeError(w, r, err)
+		return
+	}
+
+	if err := validate.Struct(request); err != nil {
+		rest.RespondError(w, r, err)
+		return
+	}
+
+	deletion, err := h.accountService.RequestDeletion(ctx, userID, request)
+	if err != nil {
+		rest.RespondError(w, r, err)
+		return
+	}
+
+	if !deletion.Completed {
+		respondWithJSON(w, http.StatusAccepted, account.ToDeletionResponse(deletion))
+		return
+	}
+
+	for _, filename := range deletion.ReceiptFilenames {
+		if err := fileupload.DeleteFile(filename); err != nil {
+			logger.FromContext(r.Context()).Warn("could not delete receipt", slog.String("file", filename), slog.Any("error", err))
+		}
+	}
+	respondWithJSON(w, http.StatusOK, account.ToDeletionResponse(deletion))
+}
+
+func (h *handler) CancelAccountDeletion(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID := rest.UserIDFromRequest(r)
+	if userID <= 0 {
+		rest.RespondProblem(w, r, http.StatusUnauthorized, "unauthorized", "Usuario no identificado")
+		return
+	}
+
+	if err := h.accountService.CancelDeletion(ctx, userID); err != nil {
+		rest.RespondError(w, r, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, entities.MessageResponse{
+		Message: "Borrado de la cuenta cancelado",
+	})
+}
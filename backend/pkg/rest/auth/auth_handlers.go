@@ -0,0 +1,349 @@
+package auth
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/payvue/payvue-backend/pkg/domain/user"
+	"github.com/payvue/payvue-backend/pkg/rest"
+	"github.com/payvue/payvue-backend/pkg/rest/entities"
+	"github.com/payvue/payvue-backend/pkg/utils/validation"
+)
+
+var validate = validation.New()
+
+func (h *handler) Register(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var request entities.RegisterRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		rest.RespondDecodeError(w, r, err)
+		return
+	}
+
+	if err := validate.Struct(request); err != nil {
+		rest.RespondError(w, r, err)
+		return
+	}
+
+	registered, err := h.userService.Register(ctx, request.ToDomain())
+	if err != nil {
+		if errors.Is(err, user.ErrVerificationEmailNotSent) {
+			respondWithJSON(w, http.StatusCreated, entities.AuthResponse{
+				Message: "Usuario registrado, pero no se pudo enviar el correo de verificación; solicita otro",
+				UserID:  registered.ID,
+				Email:   registered.Email,
+			})
+			return
+		}
+		rest.RespondError(w, r, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, entities.AuthResponse{
+		Message: "Usuario registrado exitosamente; revisa tu correo para verificar la cuenta",
+		UserID:  registered.ID,
+		Email:   registered.Email,
+	})
+}
+
+// VerifyEmail procesa el enlace del correo de verificación.
+func (h *handler) VerifyEmail(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	token := r.URL.Query().Get("token")
+	if token == "" {
+		rest.RespondProblem(w, r, http.StatusBadRequest, "validation_error", "token is required")
+		return
+	}
+
+	_, err := h.userService.VerifyEmail(ctx, token)
+	if err != nil {
+		rest.RespondError(w, r, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, entities.MessageResponse{
+		Message: "Correo verificado exitosamente",
+	})
+}
+
+// ResendVerification responde igual exista o no la cuenta.
+func (h *handler) ResendVerification(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var request entities.ResendVerificationRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		rest.RespondDecodeError(w, r, err)
+		return
+	}
+
+	if err := validate.Struct(request); err != nil {
+		rest.RespondError(w, r, err)
+		return
+	}
+
+	if err := h.userService.ResendVerification(ctx, request.ToDomain()); err != nil {
+		rest.RespondError(w, r, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusAccepted, entities.MessageResponse{
+		Message: "Si la cuenta existe y no está verificada, recibirás un nuevo correo",
+	})
+}
+
+func (h *handler) Login(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var request entities.LoginRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		rest.RespondDecodeError(w, r, err)
+		return
+	}
+
+	if err := validate.Struct(request); err != nil {
+		rest.RespondError(w, r, err)
+		return
+	}
+
+	loggedIn, err := h.userService.Login(ctx, request.ToDomain())
+	if err != nil {
+		respondWithLoginError(w, r, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, entities.AuthResponse{
+		Message: "Inicio de sesión exitoso",
+		UserID:  loggedIn.ID,
+		Email:   loggedIn.Email,
+	})
+}
+
+func (h *handler) LoginTwoFactor(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var request entities.LoginTwoFactorRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		rest.RespondDecodeError(w, r, err)
+		return
+	}
+
+	if err := validate.Struct(request); err != nil {
+		rest.RespondError(w, r, err)
+		return
+	}
+
+	loggedIn, err := h.userService.LoginTwoFactor(ctx, request.ToDomain())
+	if err != nil {
+		respondWithLoginError(w, r, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, entities.AuthResponse{
+		Message: "Inicio de sesión exitoso",
+		UserID:  loggedIn.ID,
+		Email:   loggedIn.Email,
+	})
+}
+
+// StartExternalLogin devuelve la URL del proveedor OpenID Connect. Si la petición
+// viene autenticada con un token de acceso, la identidad externa se vinculará a
+// ese usuario; X-User-ID no basta, cualquiera puede enviarla.
+func (h *handler) StartExternalLogin(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	response, err := h.userService.StartExternalLogin(ctx, rest.AuthenticatedUserID(r))
+	if err != nil {
+		respondWithLoginError(w, r, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, response)
+}
+
+func (h *handler) ExternalLoginCallback(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var request entities.ExternalLoginRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		rest.RespondDecodeError(w, r, err)
+		return
+	}
+
+	if err := validate.Struct(request); err != nil {
+		rest.RespondError(w, r, err)
+		return
+	}
+
+	loggedIn, err := h.userService.CompleteExternalLogin(ctx, request.ToDomain())
+	if err != nil {
+		respondWithLoginError(w, r, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, entities.AuthResponse{
+		Message:               "Inicio de sesión exitoso",
+		UserID:                loggedIn.User.ID,
+		Email:                 loggedIn.User.Email,
+		ReauthenticationToken: loggedIn.ReauthenticationToken,
+	})
+}
+
+// authenticatedUser devuelve el usuario del token de acceso o responde 401. La
+// verificación en dos pasos cambia cómo se entra en la cuenta, así que no basta
+// con X-User-ID; además se vuelve a pedir la contraseña o el código.
+func authenticatedUser(w http.ResponseWriter, r *http.Request) (int, bool) {
+	userID := rest.AuthenticatedUserID(r)
+	if userID <= 0 {
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		rest.RespondProblem(w, r, http.StatusUnauthorized, "authentication_required", "Esta operación requiere un token de acceso")
+		return 0, false
+	}
+	return userID, true
+}
+
+func (h *handler) EnrollTwoFactor(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, ok := authenticatedUser(w, r)
+	if !ok {
+		return
+	}
+
+	var request entities.EnrollTwoFactorRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		rest.RespondDecodeError(w, r, err)
+		return
+	}
+
+	enrollment, err := h.userService.EnrollTwoFactor(ctx, userID, request.ToDomain())
+	if err != nil {
+		rest.RespondError(w, r, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, enrollment)
+}
+
+func (h *handler) EnableTwoFactor(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, ok := authenticatedUser(w, r)
+	if !ok {
+		return
+	}
+
+	var request entities.EnableTwoFactorRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		rest.RespondDecodeError(w, r, err)
+		return
+	}
+
+	if err := validate.Struct(request); err != nil {
+		rest.RespondError(w, r, err)
+		return
+	}
+
+	codes, err := h.userService.EnableTwoFactor(ctx, userID, request.Code)
+	if err != nil {
+		rest.RespondError(w, r, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, user.RecoveryCodesResponse{
+		RecoveryCodes: codes,
+	})
+}
+
+func (h *handler) DisableTwoFactor(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, ok := authenticatedUser(w, r)
+	if !ok {
+		return
+	}
+
+	var request entities.ReauthenticateRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		rest.RespondDecodeError(w, r, err)
+		return
+	}
+
+	if err := validate.Struct(request); err != nil {
+		rest.RespondError(w, r, err)
+		return
+	}
+
+	if err := h.userService.DisableTwoFactor(ctx, userID, request.ToDomain()); err != nil {
+		rest.RespondError(w, r, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, entities.MessageResponse{
+		Message: "Verificación en dos pasos desactivada",
+	})
+}
+
+func (h *handler) RegenerateRecoveryCodes(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, ok := authenticatedUser(w, r)
+	if !ok {
+		return
+	}
+
+	var request entities.ReauthenticateRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		rest.RespondDecodeError(w, r, err)
+		return
+	}
+
+	if err := validate.Struct(request); err != nil {
+		rest.RespondError(w, r, err)
+		return
+	}
+
+	codes, err := h.userService.RegenerateRecoveryCodes(ctx, userID, request.ToDomain())
+	if err != nil {
+		rest.RespondError(w, r, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, user.RecoveryCodesResponse{
+		RecoveryCodes: codes,
+	})
+}
+
+func (h *handler) Logout(w http.ResponseWriter, r *http.Request) {
+	respondWithJSON(w, http.StatusOK, entities.MessageResponse{
+		Message: "Sesión cerrada exitosamente",
+	})
+}
+
+// respondWithLoginError responde al login con contraseña, con segundo factor o
+// con el proveedor externo. Si falta el segundo factor no es un error: se
+// devuelve el reto con 200.
+func respondWithLoginError(w http.ResponseWriter, r *http.Request, err error) {
+	var challenge *user.TwoFactorRequiredError
+	if errors.As(err, &challenge) {
+		respondWithJSON(w, http.StatusOK, user.TwoFactorChallengeResponse{
+			Message:           "Introduce el código de verificación",
+			TwoFactorRequired: true,
+			ChallengeToken:    challenge.ChallengeToken,
+			ExpiresAt:         challenge.ExpiresAt.Format(time.RFC3339),
+		})
+		return
+	}
+
+	rest.RespondError(w, r, err)
+}
+
+func respondWithJSON(w http.ResponseWriter, code int, payload interface{}) {
+	response, _ := json.Marshal(payload)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	w.Write(response)
+}
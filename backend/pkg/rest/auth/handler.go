@@ -0,0 +1,25 @@
+package auth
+
+import (
+	"net/http"
+
+	"github.com/payvue/payvue-backend/pkg/domain/account"
+	"github.com/payvue/payvue-backend/pkg/domain/user"
+	"github.com/payvue/payvue-backend/pkg/rest"
+)
+
+type handler struct {
+	userService    user.Service
+	accountService account.Service
+	middlewares    []func(http.Handler) http.Handler
+}
+
+// NewHandler recibe opcionalmente middlewares que solo se aplican a /auth,
+// como el limitador de peticiones.
+func NewHandler(userService user.Service, accountService account.Service, middlewares ...func(http.Handler) http.Handler) rest.Handler {
+	return &handler{
+		userService:    userService,
+		accountService: accountService,
+		middlewares:    middlewares,
+	}
+}
@@ -0,0 +1,31 @@
+package auth
+
+import (
+	"github.com/go-chi/chi/v5"
+	"github.com/payvue/payvue-backend/pkg/rest"
+)
+
+// RouteURLs monta /auth solo en modo escritura: incluso GET /auth/verify
+// modifica el usuario.
+func (h *handler) RouteURLs(router *chi.Mux, mode rest.Mode) {
+	if !mode.Writes() {
+		return
+	}
+	router.Route("/auth", func(r chi.Router) {
+		r.Use(h.middlewares...)
+		r.Post("/register", h.Register)
+		r.Post("/login", h.Login)
+		r.Post("/login/2fa", h.LoginTwoFactor)
+		r.Get("/verify", h.VerifyEmail)
+		r.Post("/verify/resend", h.ResendVerification)
+		r.Post("/2fa/enroll", h.EnrollTwoFactor)
+		r.Post("/2fa/enable", h.EnableTwoFactor)
+		r.Post("/2fa/disable", h.DisableTwoFactor)
+		r.Post("/2fa/recovery-codes", h.RegenerateRecoveryCodes)
+		r.Post("/oidc/login", h.StartExternalLogin)
+		r.Post("/oidc/callback", h.ExternalLoginCallback)
+		r.Delete("/account", h.DeleteAccount)
+		r.Post("/account/cancel-deletion", h.CancelAccountDeletion)
+		r.Post("/logout", h.Logout)
+	})
+}
@@ -0,0 +1,216 @@
+package debt
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/payvue/payvue-backend/pkg/domain/debt"
+	"github.com/payvue/payvue-backend/pkg/rest"
+	"github.com/payvue/payvue-backend/pkg/rest/entities"
+	"github.com/payvue/payvue-backend/pkg/utils/validation"
+)
+
+var validate = validation.New()
+
+func (h *handler) GetAllDebts(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	// Se listan los registros del usuario y los de sus hogares
+	debts, err := h.debtService.GetDebtsByUserID(ctx, rest.UserIDFromRequest(r))
+	if err != nil {
+		rest.RespondError(w, r, err)
+		return
+	}
+
+	response := debt.ToDebtListResponse(debts)
+	respondWithJSON(w, http.StatusOK, response.Debts)
+}
+
+func (h *handler) GetDebtByID(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		rest.RespondProblem(w, r, http.StatusBadRequest, "invalid_id", "ID must be a number")
+		return
+	}
+
+	debtData, err := h.debtService.GetDebtByID(ctx, id)
+	if err != nil {
+		rest.RespondError(w, r, err)
+		return
+	}
+
+	response := debt.ToDebtResponse(debtData)
+	rest.SetETag(w, debtData.Version)
+	respondWithJSON(w, http.StatusOK, response)
+}
+
+func (h *handler) GetDeletedDebts(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	debts, err := h.debtService.GetDeletedDebts(ctx, rest.UserIDFromRequest(r))
+	if err != nil {
+		rest.RespondError(w, r, err)
+		return
+	}
+
+	response := debt.ToDebtListResponse(debts)
+	respondWithJSON(w, http.StatusOK, response.Debts)
+}
+
+func (h *handler) CreateDebt(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var request entities.CreateDebtRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		rest.RespondDecodeError(w, r, err)
+		return
+	}
+
+	if err := validate.Struct(request); err != nil {
+		rest.RespondError(w, r, err)
+		return
+	}
+
+	domainRequest := request.ToDomain()
+	domainRequest.UserID = rest.UserIDFromRequest(r)
+
+	created, err := h.debtService.CreateDebt(ctx, domainRequest)
+	if err != nil {
+		rest.RespondError(w, r, err)
+		return
+	}
+
+	rest.SetETag(w, created.Version)
+	respondWithJSON(w, http.StatusCreated, debt.ToDebtResponse(created))
+}
+
+func (h *handler) UpdateDebt(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		rest.RespondProblem(w, r, http.StatusBadRequest, "invalid_id", "ID must be a number")
+		return
+	}
+
+	version, err := rest.IfMatchVersion(r)
+	if err != nil {
+		rest.RespondError(w, r, err)
+		return
+	}
+
+	var request entities.UpdateDebtRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		rest.RespondDecodeError(w, r, err)
+		return
+	}
+
+	if err := validate.Struct(request); err != nil {
+		rest.RespondError(w, r, err)
+		return
+	}
+
+	domainRequest := request.ToDomain()
+	domainRequest.Version = version
+
+	updated, err := h.debtService.UpdateDebt(ctx, id, domainRequest)
+	if err != nil {
+		rest.RespondError(w, r, err)
+		return
+	}
+
+	rest.SetETag(w, updated.Version)
+	respondWithJSON(w, http.StatusOK, debt.ToDebtResponse(updated))
+}
+
+func (h *handler) PatchDebt(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		rest.RespondProblem(w, r, http.StatusBadRequest, "invalid_id", "ID must be a number")
+		return
+	}
+
+	if !rest.IsMergePatch(r) {
+		rest.RespondProblem(w, r, http.StatusUnsupportedMediaType, "unsupported_media_type", "Content-Type must be application/merge-patch+json")
+		return
+	}
+
+	version, err := rest.IfMatchVersion(r)
+	if err != nil {
+		rest.RespondError(w, r, err)
+		return
+	}
+
+	patch, err := io.ReadAll(r.Body)
+	if err != nil {
+		rest.RespondProblem(w, r, http.StatusBadRequest, "error_reading_body", err.Error())
+		return
+	}
+
+	updated, err := h.debtService.PatchDebt(ctx, id, debt.PatchDebtRequest{Patch: patch, Version: version})
+	if err != nil {
+		rest.RespondError(w, r, err)
+		return
+	}
+
+	rest.SetETag(w, updated.Version)
+	respondWithJSON(w, http.StatusOK, debt.ToDebtResponse(updated))
+}
+
+func (h *handler) DeleteDebt(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		rest.RespondProblem(w, r, http.StatusBadRequest, "invalid_id", "ID must be a number")
+		return
+	}
+
+	err = h.debtService.DeleteDebt(ctx, id)
+	if err != nil {
+		rest.RespondError(w, r, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, entities.MessageResponse{
+		Message: "Deuda eliminada exitosamente",
+	})
+}
+
+func (h *handler) RestoreDebt(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		rest.RespondProblem(w, r, http.StatusBadRequest, "invalid_id", "ID must be a number")
+		return
+	}
+
+	restored, err := h.debtService.RestoreDebt(ctx, id)
+	if err != nil {
+		rest.RespondError(w, r, err)
+		return
+	}
+
+	rest.SetETag(w, restored.Version)
+	respondWithJSON(w, http.StatusOK, debt.ToDebtResponse(restored))
+}
+
+func respondWithJSON(w http.ResponseWriter, code int, payload interface{}) {
+	response, _ := json.Marshal(payload)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	w.Write(response)
+}
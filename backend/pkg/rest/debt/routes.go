@@ -0,0 +1,24 @@
+package debt
+
+import (
+	"github.com/go-chi/chi/v5"
+	"github.com/payvue/payvue-backend/pkg/rest"
+)
+
+func (h *handler) RouteURLs(router *chi.Mux, mode rest.Mode) {
+	router.Route("/finances/debt", func(r chi.Router) {
+		r = r.With(rest.RequireUser)
+		if mode.Reads() {
+			r.Get("/", h.GetAllDebts)
+			r.Get("/trash", h.GetDeletedDebts)
+			r.Get("/{id}", h.GetDebtByID)
+		}
+		if mode.Writes() {
+			r.Post("/", h.CreateDebt)
+			r.Put("/{id}", h.UpdateDebt)
+			r.Patch("/{id}", h.PatchDebt)
+			r.Delete("/{id}", h.DeleteDebt)
+			r.Post("/{id}/restore", h.RestoreDebt)
+		}
+	})
+}
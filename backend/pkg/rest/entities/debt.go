@@ -5,18 +5,19 @@ import "github.com/payvue/payvue-backend/pkg/domain/debt"
 type CreateDebtRequest struct {
 	Name              string  `json:"name" validate:"required"`
 	TotalAmount       float64 `json:"total_amount" validate:"required,gt=0"`
-	RemainingAmount   float64 `json:"remaining_amount" validate:"required,gte=0"`
+	RemainingAmount   float64 `json:"remaining_amount" validate:"gte=0"`
 	DueDate           string  `json:"due_date" validate:"required"`
 	InterestRate      float64 `json:"interest_rate" validate:"gte=0"`
 	NumInstallments   int     `json:"num_installments" validate:"required,gt=0"`
 	InstallmentAmount float64 `json:"installment_amount" validate:"required,gt=0"`
 	PaymentDay        int     `json:"payment_day" validate:"required,min=1,max=31"`
+	HouseholdID       int     `json:"household_id" validate:"gte=0"`
 }
 
 type UpdateDebtRequest struct {
 	Name              string  `json:"name" validate:"required"`
 	TotalAmount       float64 `json:"total_amount" validate:"required,gt=0"`
-	RemainingAmount   float64 `json:"remaining_amount" validate:"required,gte=0"`
+	RemainingAmount   float64 `json:"remaining_amount" validate:"gte=0"`
 	DueDate           string  `json:"due_date" validate:"required"`
 	InterestRate      float64 `json:"interest_rate" validate:"gte=0"`
 	NumInstallments   int     `json:"num_installments" validate:"required,gt=0"`
@@ -35,6 +36,7 @@ func (r CreateDebtRequest) ToDomain() debt.CreateDebtRequest {
 		NumInstallments:   r.NumInstallments,
 		InstallmentAmount: r.InstallmentAmount,
 		PaymentDay:        r.PaymentDay,
+		HouseholdID:       r.HouseholdID,
 	}
 }
 
@@ -1,8 +1,25 @@
 package entities
 
+// ErrorResponse es el cuerpo de todas las respuestas de error, con el formato
+// application/problem+json (RFC 7807). error y message repiten el código y el
+// detalle para los clientes anteriores a ese formato.
 type ErrorResponse struct {
-	Error   string `json:"error"`
-	Message string `json:"message,omitempty"`
+	Type      string       `json:"type"`
+	Title     string       `json:"title"`
+	Status    int          `json:"status"`
+	Detail    string       `json:"detail,omitempty"`
+	Instance  string       `json:"instance,omitempty"`
+	Error     string       `json:"error"`
+	Message   string       `json:"message,omitempty"`
+	RequestID string       `json:"request_id,omitempty"`
+	Errors    []FieldError `json:"errors,omitempty"`
+}
+
+// FieldError describe un campo que no pasó la validación.
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
 }
 
 type MessageResponse struct {
@@ -3,9 +3,10 @@ package entities
 import "github.com/payvue/payvue-backend/pkg/domain/income"
 
 type CreateIncomeRequest struct {
-	Amount float64 `json:"amount" validate:"required,gt=0"`
-	Source string  `json:"source" validate:"required"`
-	Date   string  `json:"date" validate:"required"`
+	Amount      float64 `json:"amount" validate:"required,gt=0"`
+	Source      string  `json:"source" validate:"required"`
+	Date        string  `json:"date" validate:"required"`
+	HouseholdID int     `json:"household_id" validate:"gte=0"`
 }
 
 type UpdateIncomeRequest struct {
@@ -16,9 +17,10 @@ type UpdateIncomeRequest struct {
 
 func (r CreateIncomeRequest) ToDomain() income.CreateIncomeRequest {
 	return income.CreateIncomeRequest{
-		Amount: r.Amount,
-		Source: r.Source,
-		Date:   r.Date,
+		Amount:      r.Amount,
+		Source:      r.Source,
+		Date:        r.Date,
+		HouseholdID: r.HouseholdID,
 	}
 }
 
@@ -12,6 +12,27 @@ type LoginRequest struct {
 	Password string `json:"password" validate:"required"`
 }
 
+// AuthResponse es la respuesta de registro e inicio de sesión; user_id es el valor
+// que el cliente envía después en X-User-ID.
+type AuthResponse struct {
+	Message string `json:"message"`
+	UserID  int    `json:"user_id"`
+	Email   string `json:"email"`
+	// ReauthenticationToken solo viene en el login externo de las cuentas sin
+	// contraseña; sirve unos minutos para confirmar operaciones sensibles.
+	ReauthenticationToken string `json:"reauthentication_token,omitempty"`
+}
+
+type ResendVerificationRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+func (r ResendVerificationRequest) ToDomain() user.ResendVerificationRequest {
+	return user.ResendVerificationRequest{
+		Email: r.Email,
+	}
+}
+
 func (r RegisterRequest) ToDomain() user.RegisterRequest {
 	return user.RegisterRequest{
 		Email:    r.Email,
@@ -25,3 +46,59 @@ func (r LoginRequest) ToDomain() user.LoginRequest {
 		Password: r.Password,
 	}
 }
+
+type LoginTwoFactorRequest struct {
+	ChallengeToken string `json:"challenge_token" validate:"required"`
+	Code           string `json:"code" validate:"required"`
+}
+
+type EnableTwoFactorRequest struct {
+	Code string `json:"code" validate:"required"`
+}
+
+// EnrollTwoFactorRequest lleva la contraseña; las cuentas sin contraseña (creadas
+// con el proveedor externo) envían en su lugar el reauthentication_token de un
+// login externo reciente.
+type EnrollTwoFactorRequest struct {
+	Password              string `json:"password"`
+	ReauthenticationToken string `json:"reauthentication_token"`
+}
+
+func (r EnrollTwoFactorRequest) ToDomain() user.Credentials {
+	return user.Credentials{
+		Password:              r.Password,
+		ReauthenticationToken: r.ReauthenticationToken,
+	}
+}
+
+// ReauthenticateRequest: Password se deja vacía en las cuentas sin contraseña.
+type ReauthenticateRequest struct {
+	Password string `json:"password"`
+	Code     string `json:"code" validate:"required"`
+}
+
+func (r LoginTwoFactorRequest) ToDomain() user.LoginTwoFactorRequest {
+	return user.LoginTwoFactorRequest{
+		ChallengeToken: r.ChallengeToken,
+		Code:           r.Code,
+	}
+}
+
+func (r ReauthenticateRequest) ToDomain() user.ReauthenticateRequest {
+	return user.ReauthenticateRequest{
+		Password: r.Password,
+		Code:     r.Code,
+	}
+}
+
+type ExternalLoginRequest struct {
+	Code  string `json:"code" validate:"required"`
+	State string `json:"state" validate:"required"`
+}
+
+func (r ExternalLoginRequest) ToDomain() user.ExternalLoginRequest {
+	return user.ExternalLoginRequest{
+		Code:  r.Code,
+		State: r.State,
+	}
+}
@@ -0,0 +1,231 @@
+package rest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-playground/validator/v10"
+	"github.com/payvue/payvue-backend/pkg/domain/accesstoken"
+	"github.com/payvue/payvue-backend/pkg/domain/account"
+	"github.com/payvue/payvue-backend/pkg/domain/audit"
+	"github.com/payvue/payvue-backend/pkg/domain/debt"
+	"github.com/payvue/payvue-backend/pkg/domain/household"
+	"github.com/payvue/payvue-backend/pkg/domain/idempotency"
+	"github.com/payvue/payvue-backend/pkg/domain/income"
+	"github.com/payvue/payvue-backend/pkg/domain/payment"
+	"github.com/payvue/payvue-backend/pkg/domain/security"
+	"github.com/payvue/payvue-backend/pkg/domain/user"
+	"github.com/payvue/payvue-backend/pkg/rest/entities"
+	"github.com/payvue/payvue-backend/pkg/utils/logger"
+)
+
+const problemContentType = "application/problem+json"
+
+type errorMapping struct {
+	err     error
+	status  int
+	code    string
+	message string
+	// withCause usa el texto del error como detalle; solo para errores que
+	// envuelven una causa útil para el cliente, como un patch mal formado.
+	withCause bool
+}
+
+// domainErrors traduce los errores centinela del dominio a respuestas HTTP. Se
+// comparan con errors.Is, así que también valen envueltos.
+var domainErrors = []errorMapping{
+	{err: debt.ErrDebtNotFound, status: http.StatusNotFound, code: "debt_not_found", message: "Deuda no encontrada"},
+	{err: debt.ErrInvalidDebtData, status: http.StatusBadRequest, code: "validation_error", message: "Los datos de la deuda no son válidos"},
+	{err: debt.ErrInvalidPatch, status: http.StatusBadRequest, code: "validation_error", message: "El patch de la deuda no es válido", withCause: true},
+	{err: debt.ErrVersionConflict, status: http.StatusPreconditionFailed, code: "version_conflict", message: "El registro fue modificado por otra petición"},
+	{err: debt.ErrForbidden, status: http.StatusForbidden, code: "forbidden", message: "La deuda es de solo lectura para este usuario"},
+
+	{err: income.ErrIncomeNotFound, status: http.StatusNotFound, code: "income_not_found", message: "Ingreso no encontrado"},
+	{err: income.ErrInvalidIncomeData, status: http.StatusBadRequest, code: "validation_error", message: "Los datos del ingreso no son válidos"},
+	{err: income.ErrInvalidPatch, status: http.StatusBadRequest, code: "validation_error", message: "El patch del ingreso no es válido", withCause: true},
+	{err: income.ErrVersionConflict, status: http.StatusPreconditionFailed, code: "version_conflict", message: "El registro fue modificado por otra petición"},
+	{err: income.ErrForbidden, status: http.StatusForbidden, code: "forbidden", message: "El ingreso es de solo lectura para este usuario"},
+
+	{err: payment.ErrPaymentNotFound, status: http.StatusNotFound, code: "payment_not_found", message: "Pago no encontrado"},
+	{err: payment.ErrInvalidReceiptLink, status: http.StatusUnauthorized, code: "invalid_receipt_link", message: "El enlace del recibo no es válido o ha caducado"},
+	{err: payment.ErrDebtNotFound, status: http.StatusNotFound, code: "debt_not_found", message: "Deuda no encontrada"},
+	{err: payment.ErrInvalidPaymentData, status: http.StatusBadRequest, code: "validation_error", message: "Los datos del pago no son válidos"},
+	{err: payment.ErrForbidden, status: http.StatusForbidden, code: "forbidden", message: "El pago es de solo lectura para este usuario"},
+
+	{err: household.ErrHouseholdNotFound, status: http.StatusNotFound, code: "household_not_found", message: "Hogar no encontrado"},
+	{err: household.ErrMemberNotFound, status: http.StatusNotFound, code: "member_not_found", message: "Miembro no encontrado"},
+	{err: household.ErrInvitationNotFound, status: http.StatusNotFound, code: "invitation_not_found", message: "Invitación no encontrada"},
+	{err: household.ErrInvalidInvitation, status: http.StatusGone, code: "invalid_invitation", message: "La invitación no existe, ya se usó o ha caducado"},
+	{err: household.ErrForbidden, status: http.StatusForbidden, code: "forbidden", message: "Solo los owners del hogar pueden hacer esto"},
+	{err: household.ErrAlreadyMember, status: http.StatusConflict, code: "already_member", message: "El usuario ya pertenece al hogar"},
+	{err: household.ErrLastOwner, status: http.StatusConflict, code: "last_owner", message: "El hogar debe conservar al menos un owner"},
+	{err: household.ErrInvalidHouseholdData, status: http.StatusBadRequest, code: "validation_error", message: "Los datos del hogar no son válidos"},
+
+	{err: user.ErrUserNotFound, status: http.StatusNotFound, code: "user_not_found", message: "Usuario no encontrado"},
+	{err: user.ErrInvalidCredentials, status: http.StatusUnauthorized, code: "invalid_credentials", message: "Credenciales inválidas"},
+	{err: user.ErrEmailAlreadyExists, status: http.StatusConflict, code: "email_already_exists", message: "El correo ya está registrado"},
+	{err: user.ErrReauthenticationRequired, status: http.StatusUnauthorized, code: "reauthentication_required", message: "Vuelve a iniciar sesión con el proveedor externo para confirmar la operación"},
+	{err: user.ErrEmailNotVerified, status: http.StatusForbidden, code: "email_verification_required", message: "Verifica tu correo antes de iniciar sesión"},
+	{err: user.ErrInvalidVerificationToken, status: http.StatusBadRequest, code: "invalid_verification_token", message: "El enlace de verificación no es válido o ha caducado"},
+	{err: user.ErrInvalidTwoFactorCode, status: http.StatusUnauthorized, code: "invalid_two_factor_code", message: "Código de verificación inválido"},
+	{err: user.ErrInvalidChallenge, status: http.StatusUnauthorized, code: "invalid_challenge", message: "El inicio de sesión caducó, vuelve a introducir la contraseña"},
+	{err: user.ErrTwoFactorAlreadyEnabled, status: http.StatusConflict, code: "two_factor_already_enabled", message: "La verificación en dos pasos ya está activada"},
+	{err: user.ErrTwoFactorNotEnabled, status: http.StatusConflict, code: "two_factor_not_enabled", message: "La verificación en dos pasos no está activada"},
+	{err: user.ErrTwoFactorNotEnrolled, status: http.StatusConflict, code: "two_factor_not_enrolled", message: "Primero genera el secreto con /auth/2fa/enroll"},
+	{err: user.ErrExternalLoginDisabled, status: http.StatusNotFound, code: "external_login_disabled", message: "El inicio de sesión externo no está configurado"},
+	{err: user.ErrInvalidExternalLoginState, status: http.StatusBadRequest, code: "invalid_state", message: "El inicio de sesión caducó, vuelve a intentarlo"},
+	{err: user.ErrExternalLoginFailed, status: http.StatusUnauthorized, code: "external_login_failed", message: "No se pudo iniciar sesión con el proveedor externo"},
+	{err: user.ErrIdentityNotFound, status: http.StatusNotFound, code: "identity_not_found", message: "Identidad externa no encontrada"},
+	{err: user.ErrIdentityAlreadyLinked, status: http.StatusConflict, code: "identity_already_linked", message: "Esta cuenta externa ya está vinculada a otro usuario"},
+	{err: user.ErrUnverifiedExternalEmail, status: http.StatusConflict, code: "email_not_verified", message: "Ya existe una cuenta con ese correo; inicia sesión y vincula la cuenta externa"},
+
+	{err: account.ErrAccountNotFound, status: http.StatusNotFound, code: "user_not_found", message: "Usuario no encontrado"},
+	{err: account.ErrDeletionNotScheduled, status: http.StatusConflict, code: "deletion_not_scheduled", message: "La cuenta no tiene un borrado pendiente"},
+
+	{err: accesstoken.ErrTokenNotFound, status: http.StatusNotFound, code: "token_not_found", message: "Token no encontrado"},
+	{err: accesstoken.ErrInvalidToken, status: http.StatusUnauthorized, code: "invalid_token", message: "El token de acceso no es válido o ha caducado"},
+
+	{err: audit.ErrInvalidFilter, status: http.StatusBadRequest, code: "invalid_filter", message: "Filtros de auditoría inválidos"},
+
+	{err: idempotency.ErrInvalidKey, status: http.StatusBadRequest, code: "invalid_idempotency_key", message: "La clave de idempotencia no es válida"},
+	{err: idempotency.ErrKeyMismatch, status: http.StatusUnprocessableEntity, code: "idempotency_key_reused", message: "La clave de idempotencia ya se usó con otra petición"},
+	{err: idempotency.ErrKeyInProgress, status: http.StatusConflict, code: "idempotency_key_in_progress", message: "Hay otra petición en curso con esta clave de idempotencia"},
+
+	{err: security.ErrTooManyAttempts, status: http.StatusTooManyRequests, code: "too_many_attempts", message: "Demasiados intentos fallidos, inténtalo más tarde"},
+
+	{err: ErrInvalidIfMatch, status: http.StatusPreconditionFailed, code: "invalid_if_match", message: "La cabecera If-Match no es válida"},
+}
+
+// RespondError responde con el problema que corresponde a err. Los errores de
+// validación incluyen el detalle por campo; los que no están en la tabla se
+// registran en el log y se devuelven como 500 sin exponer la causa.
+func RespondError(w http.ResponseWriter, r *http.Request, err error) {
+	var validationErrors validator.ValidationErrors
+	if errors.As(err, &validationErrors) {
+		problem := newProblem(r, http.StatusBadRequest, "validation_error", "La petición tiene campos no válidos")
+		for _, mapping := range domainErrors {
+			if mapping.status == http.StatusBadRequest && errors.Is(err, mapping.err) {
+				problem.Detail = mapping.message
+				problem.Message = mapping.message
+				break
+			}
+		}
+		problem.Errors = fieldErrors(validationErrors)
+		writeProblem(w, problem)
+		return
+	}
+
+	var lockout *security.LockoutError
+	if errors.As(err, &lockout) {
+		w.Header().Set("Retry-After", strconv.Itoa(lockout.RetryAfter()))
+		RespondProblem(w, r, http.StatusTooManyRequests, "too_many_attempts", "Demasiados intentos fallidos, inténtalo más tarde")
+		return
+	}
+
+	for _, mapping := range domainErrors {
+		if errors.Is(err, mapping.err) {
+			detail := mapping.message
+			if mapping.withCause && err != mapping.err {
+				detail = err.Error()
+			}
+			RespondProblem(w, r, mapping.status, mapping.code, detail)
+			return
+		}
+	}
+
+	logger.FromContext(r.Context()).Error("unhandled error", slog.Any("error", err))
+	RespondProblem(w, r, http.StatusInternalServerError, "internal_error", "Error interno del servidor")
+}
+
+// RespondProblem responde con un problema que no viene de un error de dominio,
+// como un parámetro de la URL mal formado.
+func RespondProblem(w http.ResponseWriter, r *http.Request, status int, code string, detail string) {
+	writeProblem(w, newProblem(r, status, code, detail))
+}
+
+// RespondFieldErrors responde 400 con el detalle por campo, igual que los errores
+// de validación del dominio, para validaciones que se hacen fuera de los handlers.
+func RespondFieldErrors(w http.ResponseWriter, r *http.Request, detail string, fields []entities.FieldError) {
+	problem := newProblem(r, http.StatusBadRequest, "validation_error", detail)
+	problem.Errors = fields
+	writeProblem(w, problem)
+}
+
+// RespondDecodeError responde 400 a un cuerpo JSON que no se pudo leer. El
+// detalle es fijo porque los mensajes de encoding/json hablan de tipos de Go;
+// solo un tipo incorrecto indica además el campo.
+func RespondDecodeError(w http.ResponseWriter, r *http.Request, err error) {
+	problem := newProblem(r, http.StatusBadRequest, "error_decoding_json", "El cuerpo de la petición no es un JSON válido")
+	var typeError *json.UnmarshalTypeError
+	if errors.As(err, &typeError) && typeError.Field != "" {
+		problem.Detail = "El campo " + typeError.Field + " tiene un tipo no válido"
+		problem.Message = problem.Detail
+		problem.Errors = []entities.FieldError{{Field: typeError.Field, Rule: "type", Message: "Tipo no válido"}}
+	}
+	writeProblem(w, problem)
+}
+
+func newProblem(r *http.Request, status int, code string, detail string) entities.ErrorResponse {
+	return entities.ErrorResponse{
+		Type:      "about:blank",
+		Title:     http.StatusText(status),
+		Status:    status,
+		Detail:    detail,
+		Instance:  r.URL.Path,
+		Error:     code,
+		Message:   detail,
+		RequestID: middleware.GetReqID(r.Context()),
+	}
+}
+
+func writeProblem(w http.ResponseWriter, problem entities.ErrorResponse) {
+	response, _ := json.Marshal(problem)
+	w.Header().Set("Content-Type", problemContentType)
+	w.WriteHeader(problem.Status)
+	w.Write(response)
+}
+
+func fieldErrors(validationErrors validator.ValidationErrors) []entities.FieldError {
+	fields := make([]entities.FieldError, len(validationErrors))
+	for i, fieldError := range validationErrors {
+		fields[i] = entities.FieldError{
+			Field:   fieldError.Field(),
+			Rule:    fieldError.Tag(),
+			Message: fieldMessage(fieldError),
+		}
+	}
+	return fields
+}
+
+func fieldMessage(fieldError validator.FieldError) string {
+	param := fieldError.Param()
+	switch fieldError.Tag() {
+	case "required":
+		return "Campo obligatorio"
+	case "email":
+		return "Debe ser un correo válido"
+	case "gt":
+		return fmt.Sprintf("Debe ser mayor que %s", param)
+	case "gte":
+		return fmt.Sprintf("Debe ser mayor o igual que %s", param)
+	case "lt":
+		return fmt.Sprintf("Debe ser menor que %s", param)
+	case "lte":
+		return fmt.Sprintf("Debe ser menor o igual que %s", param)
+	case "min":
+		return fmt.Sprintf("El mínimo es %s", param)
+	case "max":
+		return fmt.Sprintf("El máximo es %s", param)
+	case "len":
+		return fmt.Sprintf("Debe tener longitud %s", param)
+	case "oneof":
+		return fmt.Sprintf("Debe ser uno de: %s", param)
+	default:
+		return fmt.Sprintf("No cumple la regla %s", fieldError.Tag())
+	}
+}
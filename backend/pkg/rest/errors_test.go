@@ -0,0 +1,54 @@
+package rest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/payvue/payvue-backend/pkg/rest/entities"
+)
+
+func TestRespondDecodeError(t *testing.T) {
+	tests := []struct {
+		name   string
+		body   string
+		detail string
+		field  string
+	}{
+		{"empty body", ``, "El cuerpo de la petición no es un JSON válido", ""},
+		{"syntax error", `{"name":`, "El cuerpo de la petición no es un JSON válido", ""},
+		{"not an object", `[1]`, "El cuerpo de la petición no es un JSON válido", ""},
+		{"wrong type", `{"name":"Coche","amount":"mucho"}`, "El campo amount tiene un tipo no válido", "amount"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var request struct {
+				Name   string  `json:"name"`
+				Amount float64 `json:"amount"`
+			}
+			err := json.NewDecoder(strings.NewReader(tt.body)).Decode(&request)
+			if err == nil {
+				t.Fatalf("Decode(%q) succeeded", tt.body)
+			}
+
+			rec := httptest.NewRecorder()
+			RespondDecodeError(rec, httptest.NewRequest(http.MethodPost, "/finances/debt", nil), err)
+
+			var problem entities.ErrorResponse
+			if err := json.Unmarshal(rec.Body.Bytes(), &problem); err != nil {
+				t.Fatal(err)
+			}
+			if rec.Code != http.StatusBadRequest || problem.Error != "error_decoding_json" || problem.Detail != tt.detail {
+				t.Errorf("response = %d %+v, want 400 error_decoding_json %q", rec.Code, problem, tt.detail)
+			}
+			if tt.field == "" && len(problem.Errors) != 0 {
+				t.Errorf("Errors = %+v, want none", problem.Errors)
+			}
+			if tt.field != "" && (len(problem.Errors) != 1 || problem.Errors[0].Field != tt.field) {
+				t.Errorf("Errors = %+v, want the field %s", problem.Errors, tt.field)
+			}
+		})
+	}
+}
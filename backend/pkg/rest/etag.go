@@ -0,0 +1,44 @@
+package rest
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+var ErrInvalidIfMatch = errors.New("invalid If-Match header")
+
+// ETag construye la etiqueta fuerte de un recurso a partir de su versión.
+func ETag(version int) string {
+	return `"` + strconv.Itoa(version) + `"`
+}
+
+// SetETag escribe la cabecera ETag si el recurso tiene versión.
+func SetETag(w http.ResponseWriter, version int) {
+	if version > 0 {
+		w.Header().Set("ETag", ETag(version))
+	}
+}
+
+// IfMatchVersion devuelve la versión esperada según la cabecera If-Match.
+// Devuelve 0 si la cabecera no viene o es "*", en cuyo caso no se comprueba la versión.
+// If-Match usa la comparación fuerte (RFC 9110), así que una etiqueta débil
+// (W/"3") nunca coincide y se rechaza con ErrInvalidIfMatch.
+func IfMatchVersion(r *http.Request) (int, error) {
+	value := strings.TrimSpace(r.Header.Get("If-Match"))
+	if value == "" || value == "*" {
+		return 0, nil
+	}
+
+	if len(value) < 2 || !strings.HasPrefix(value, `"`) || !strings.HasSuffix(value, `"`) {
+		return 0, ErrInvalidIfMatch
+	}
+
+	version, err := strconv.Atoi(value[1 : len(value)-1])
+	if err != nil || version <= 0 {
+		return 0, ErrInvalidIfMatch
+	}
+
+	return version, nil
+}
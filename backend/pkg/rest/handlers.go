@@ -4,6 +4,26 @@ import (
 	"github.com/go-chi/chi/v5"
 )
 
+// Mode indica qué rutas monta un binario: el reader solo las de lectura, el
+// writer las de escritura y el servidor unificado todas. Los handlers son los
+// mismos en los tres casos.
+type Mode int
+
+const (
+	ModeRead Mode = 1 << iota
+	ModeWrite
+
+	ModeAll = ModeRead | ModeWrite
+)
+
+func (m Mode) Reads() bool {
+	return m&ModeRead != 0
+}
+
+func (m Mode) Writes() bool {
+	return m&ModeWrite != 0
+}
+
 type Handler interface {
-	RouteURLs(router *chi.Mux)
+	RouteURLs(router *chi.Mux, mode Mode)
 }
@@ -0,0 +1,16 @@
+package household
+
+import (
+	"github.com/payvue/payvue-backend/pkg/domain/household"
+	"github.com/payvue/payvue-backend/pkg/rest"
+)
+
+type handler struct {
+	householdService household.Service
+}
+
+func NewHandler(householdService household.Service) rest.Handler {
+	return &handler{
+		householdService: householdService,
+	}
+}
@@ -0,0 +1,287 @@
+package household
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/payvue/payvue-backend/pkg/domain/household"
+	"github.com/payvue/payvue-backend/pkg/rest"
+	"github.com/payvue/payvue-backend/pkg/rest/entities"
+	"github.com/payvue/payvue-backend/pkg/utils/validation"
+)
+
+var validate = validation.New()
+
+// GetHouseholds lista los hogares del usuario con su rol en cada uno.
+func (h *handler) GetHouseholds(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID := rest.UserIDFromRequest(r)
+	if userID <= 0 {
+		rest.RespondProblem(w, r, http.StatusUnauthorized, "unauthorized", "Usuario no identificado")
+		return
+	}
+
+	households, err := h.householdService.GetHouseholds(ctx, userID)
+	if err != nil {
+		rest.RespondError(w, r, err)
+		return
+	}
+
+	response := household.ToHouseholdListResponse(households)
+	respondWithJSON(w, http.StatusOK, response.Households)
+}
+
+func (h *handler) GetHousehold(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID := rest.UserIDFromRequest(r)
+	if userID <= 0 {
+		rest.RespondProblem(w, r, http.StatusUnauthorized, "unauthorized", "Usuario no identificado")
+		return
+	}
+
+	householdID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		rest.RespondProblem(w, r, http.StatusBadRequest, "invalid_id", "ID must be a number")
+		return
+	}
+
+	found, err := h.householdService.GetHousehold(ctx, userID, householdID)
+	if err != nil {
+		rest.RespondError(w, r, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, household.ToHouseholdResponse(found))
+}
+
+// GetInvitations lista las invitaciones pendientes; solo para owners.
+func (h *handler) GetInvitations(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID := rest.UserIDFromRequest(r)
+	if userID <= 0 {
+		rest.RespondProblem(w, r, http.StatusUnauthorized, "unauthorized", "Usuario no identificado")
+		return
+	}
+
+	householdID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		rest.RespondProblem(w, r, http.StatusBadRequest, "invalid_id", "ID must be a number")
+		return
+	}
+
+	invitations, err := h.householdService.GetInvitations(ctx, userID, householdID)
+	if err != nil {
+		rest.RespondError(w, r, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, household.ToInvitationListResponse(invitations))
+}
+
+func (h *handler) CreateHousehold(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID := rest.UserIDFromRequest(r)
+	if userID <= 0 {
+		rest.RespondProblem(w, r, http.StatusUnauthorized, "unauthorized", "Usuario no identificado")
+		return
+	}
+
+	var request household.CreateHouseholdRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		rest.RespondDecodeError(w, r, err)
+		return
+	}
+
+	if err := validate.Struct(request); err != nil {
+		rest.RespondError(w, r, err)
+		return
+	}
+
+	created, err := h.householdService.CreateHousehold(ctx, userID, request)
+	if err != nil {
+		rest.RespondError(w, r, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, household.ToHouseholdResponse(created))
+}
+
+// Invite crea una invitación; el token solo se devuelve en esta respuesta.
+func (h *handler) Invite(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID := rest.UserIDFromRequest(r)
+	if userID <= 0 {
+		rest.RespondProblem(w, r, http.StatusUnauthorized, "unauthorized", "Usuario no identificado")
+		return
+	}
+
+	householdID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		rest.RespondProblem(w, r, http.StatusBadRequest, "invalid_id", "ID must be a number")
+		return
+	}
+
+	var request household.InviteRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		rest.RespondDecodeError(w, r, err)
+		return
+	}
+
+	if err := validate.Struct(request); err != nil {
+		rest.RespondError(w, r, err)
+		return
+	}
+
+	invitation, err := h.householdService.Invite(ctx, userID, householdID, request)
+	if err != nil {
+		rest.RespondError(w, r, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, household.ToInvitationResponse(invitation))
+}
+
+func (h *handler) RevokeInvitation(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID := rest.UserIDFromRequest(r)
+	if userID <= 0 {
+		rest.RespondProblem(w, r, http.StatusUnauthorized, "unauthorized", "Usuario no identificado")
+		return
+	}
+
+	householdID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		rest.RespondProblem(w, r, http.StatusBadRequest, "invalid_id", "ID must be a number")
+		return
+	}
+	invitationID, err := strconv.Atoi(chi.URLParam(r, "invitationID"))
+	if err != nil {
+		rest.RespondProblem(w, r, http.StatusBadRequest, "invalid_id", "ID must be a number")
+		return
+	}
+
+	if err := h.householdService.RevokeInvitation(ctx, userID, householdID, invitationID); err != nil {
+		rest.RespondError(w, r, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, entities.MessageResponse{
+		Message: "Invitación revocada exitosamente",
+	})
+}
+
+func (h *handler) AcceptInvitation(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID := rest.UserIDFromRequest(r)
+	if userID <= 0 {
+		rest.RespondProblem(w, r, http.StatusUnauthorized, "unauthorized", "Usuario no identificado")
+		return
+	}
+
+	var request household.AcceptInvitationRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		rest.RespondDecodeError(w, r, err)
+		return
+	}
+
+	if err := validate.Struct(request); err != nil {
+		rest.RespondError(w, r, err)
+		return
+	}
+
+	joined, err := h.householdService.AcceptInvitation(ctx, userID, request.Token)
+	if err != nil {
+		rest.RespondError(w, r, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, household.ToHouseholdResponse(joined))
+}
+
+func (h *handler) UpdateMember(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID := rest.UserIDFromRequest(r)
+	if userID <= 0 {
+		rest.RespondProblem(w, r, http.StatusUnauthorized, "unauthorized", "Usuario no identificado")
+		return
+	}
+
+	householdID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		rest.RespondProblem(w, r, http.StatusBadRequest, "invalid_id", "ID must be a number")
+		return
+	}
+	memberID, err := strconv.Atoi(chi.URLParam(r, "userID"))
+	if err != nil {
+		rest.RespondProblem(w, r, http.StatusBadRequest, "invalid_id", "ID must be a number")
+		return
+	}
+
+	var request household.UpdateMemberRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		rest.RespondDecodeError(w, r, err)
+		return
+	}
+
+	if err := validate.Struct(request); err != nil {
+		rest.RespondError(w, r, err)
+		return
+	}
+
+	if err := h.householdService.UpdateMemberRole(ctx, userID, householdID, memberID, request); err != nil {
+		rest.RespondError(w, r, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, entities.MessageResponse{
+		Message: "Rol actualizado exitosamente",
+	})
+}
+
+// RemoveMember expulsa a un miembro o, si el id es el del propio usuario, abandona el hogar.
+func (h *handler) RemoveMember(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID := rest.UserIDFromRequest(r)
+	if userID <= 0 {
+		rest.RespondProblem(w, r, http.StatusUnauthorized, "unauthorized", "Usuario no identificado")
+		return
+	}
+
+	householdID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		rest.RespondProblem(w, r, http.StatusBadRequest, "invalid_id", "ID must be a number")
+		return
+	}
+	memberID, err := strconv.Atoi(chi.URLParam(r, "userID"))
+	if err != nil {
+		rest.RespondProblem(w, r, http.StatusBadRequest, "invalid_id", "ID must be a number")
+		return
+	}
+
+	if err := h.householdService.RemoveMember(ctx, userID, householdID, memberID); err != nil {
+		rest.RespondError(w, r, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, entities.MessageResponse{
+		Message: "Miembro eliminado del hogar",
+	})
+}
+
+func respondWithJSON(w http.ResponseWriter, code int, payload interface{}) {
+	response, _ := json.Marshal(payload)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	w.Write(response)
+}
@@ -0,0 +1,24 @@
+package household
+
+import (
+	"github.com/go-chi/chi/v5"
+	"github.com/payvue/payvue-backend/pkg/rest"
+)
+
+func (h *handler) RouteURLs(router *chi.Mux, mode rest.Mode) {
+	router.Route("/households", func(r chi.Router) {
+		if mode.Reads() {
+			r.Get("/", h.GetHouseholds)
+			r.Get("/{id}", h.GetHousehold)
+			r.Get("/{id}/invitations", h.GetInvitations)
+		}
+		if mode.Writes() {
+			r.Post("/", h.CreateHousehold)
+			r.Post("/invitations/accept", h.AcceptInvitation)
+			r.Post("/{id}/invitations", h.Invite)
+			r.Delete("/{id}/invitations/{invitationID}", h.RevokeInvitation)
+			r.Put("/{id}/members/{userID}", h.UpdateMember)
+			r.Delete("/{id}/members/{userID}", h.RemoveMember)
+		}
+	})
+}
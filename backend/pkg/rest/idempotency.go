@@ -0,0 +1,191 @@
+package rest
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"hash"
+	"io"
+	"log/slog"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"strings"
+
+	"github.com/payvue/payvue-backend/pkg/domain/idempotency"
+	"github.com/payvue/payvue-backend/pkg/utils/fileupload"
+	"github.com/payvue/payvue-backend/pkg/utils/logger"
+)
+
+// maxIdempotentBody limita lo que se lee en memoria para calcular la huella del cuerpo.
+const maxIdempotentBody = fileupload.MaxFileSize + 1<<20
+
+// replayedHeaders son las cabeceras de la respuesta original que se repiten en un replay.
+var replayedHeaders = []string{"Content-Type", "ETag", "Location"}
+
+// Idempotency permite reintentar sin efectos duplicados las peticiones de escritura
+// que traen la cabecera Idempotency-Key: la primera se ejecuta y su respuesta se
+// guarda; las repeticiones con el mismo cuerpo reciben esa misma respuesta. Las
+// respuestas 5xx no se guardan para que el cliente pueda reintentar. Debe montarse
+// después de ActorContext.
+func Idempotency(service idempotency.Service) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := r.Header.Get("Idempotency-Key")
+			if key == "" || !isWriteMethod(r.Method) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxIdempotentBody))
+			if err != nil {
+				RespondProblem(w, r, http.StatusRequestEntityTooLarge, "request_too_large", "El cuerpo de la petición es demasiado grande")
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+
+			record := &idempotency.Record{
+				UserID:      UserIDFromRequest(r),
+				Key:         key,
+				Method:      r.Method,
+				Path:        r.URL.RequestURI(),
+				RequestHash: requestHash(r, body),
+			}
+
+			stored, err := service.Begin(r.Context(), record)
+			if err != nil {
+				RespondError(w, r, err)
+				return
+			}
+
+			if stored != nil {
+				for name, value := range stored.ResponseHeaders {
+					w.Header().Set(name, value)
+				}
+				w.Header().Set("Idempotent-Replayed", "true")
+				w.WriteHeader(stored.StatusCode)
+				w.Write(stored.ResponseBody)
+				return
+			}
+
+			// Guardar o liberar la clave no debe depender de que la petición siga viva,
+			// pero el contexto conserva el logger y la traza de la petición
+			ctx := context.WithoutCancel(r.Context())
+			recorder := &responseRecorder{ResponseWriter: w, status: http.StatusOK}
+			completed := false
+			defer func() {
+				// Si el handler no terminó (panic) se libera la clave
+				if !completed {
+					if err := service.Release(ctx, record); err != nil {
+						logger.FromContext(ctx).Warn("could not release idempotency key", slog.String("key", key), slog.Any("error", err))
+					}
+				}
+			}()
+
+			next.ServeHTTP(recorder, r)
+
+			if recorder.status >= http.StatusInternalServerError {
+				return
+			}
+
+			record.StatusCode = recorder.status
+			record.ResponseBody = recorder.body.Bytes()
+			record.ResponseHeaders = map[string]string{}
+			for _, name := range replayedHeaders {
+				if value := recorder.Header().Get(name); value != "" {
+					record.ResponseHeaders[name] = value
+				}
+			}
+
+			if err := service.Complete(ctx, record); err != nil {
+				logger.FromContext(ctx).Warn("could not store idempotent response", slog.String("key", key), slog.Any("error", err))
+				return
+			}
+			completed = true
+		})
+	}
+}
+
+func isWriteMethod(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// requestHash calcula la huella de la petición. En los multipart se recorren las
+// partes para que el boundary, que cada reintento puede generar distinto, no cuente.
+func requestHash(r *http.Request, body []byte) string {
+	h := sha256.New()
+	writeField(h, []byte(r.Method))
+	writeField(h, []byte(r.URL.RequestURI()))
+
+	mediaType, params, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err == nil && strings.HasPrefix(mediaType, "multipart/") && params["boundary"] != "" {
+		if hashMultipart(h, body, params["boundary"]) == nil {
+			return hex.EncodeToString(h.Sum(nil))
+		}
+		h.Reset()
+		writeField(h, []byte(r.Method))
+		writeField(h, []byte(r.URL.RequestURI()))
+	}
+
+	writeField(h, body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func hashMultipart(h hash.Hash, body []byte, boundary string) error {
+	reader := multipart.NewReader(bytes.NewReader(body), boundary)
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		content, err := io.ReadAll(part)
+		if err != nil {
+			return err
+		}
+		writeField(h, []byte(part.FormName()))
+		writeField(h, []byte(part.FileName()))
+		writeField(h, []byte(part.Header.Get("Content-Type")))
+		writeField(h, content)
+	}
+}
+
+// writeField antepone la longitud para que campos contiguos no se puedan confundir.
+func writeField(h hash.Hash, data []byte) {
+	var length [8]byte
+	binary.BigEndian.PutUint64(length[:], uint64(len(data)))
+	h.Write(length[:])
+	h.Write(data)
+}
+
+type responseRecorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+	body        bytes.Buffer
+}
+
+func (rr *responseRecorder) WriteHeader(code int) {
+	if !rr.wroteHeader {
+		rr.status = code
+		rr.wroteHeader = true
+	}
+	rr.ResponseWriter.WriteHeader(code)
+}
+
+func (rr *responseRecorder) Write(data []byte) (int, error) {
+	if !rr.wroteHeader {
+		rr.WriteHeader(http.StatusOK)
+	}
+	rr.body.Write(data)
+	return rr.ResponseWriter.Write(data)
+}
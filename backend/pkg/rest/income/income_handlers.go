@@ -0,0 +1,216 @@
+package income
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/payvue/payvue-backend/pkg/domain/income"
+	"github.com/payvue/payvue-backend/pkg/rest"
+	"github.com/payvue/payvue-backend/pkg/rest/entities"
+	"github.com/payvue/payvue-backend/pkg/utils/validation"
+)
+
+var validate = validation.New()
+
+func (h *handler) GetAllIncomes(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	// Se listan los registros del usuario y los de sus hogares
+	incomes, err := h.incomeService.GetIncomesByUserID(ctx, rest.UserIDFromRequest(r))
+	if err != nil {
+		rest.RespondError(w, r, err)
+		return
+	}
+
+	response := income.ToIncomeListResponse(incomes)
+	respondWithJSON(w, http.StatusOK, response.Incomes)
+}
+
+func (h *handler) GetIncomeByID(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		rest.RespondProblem(w, r, http.StatusBadRequest, "invalid_id", "ID must be a number")
+		return
+	}
+
+	incomeData, err := h.incomeService.GetIncomeByID(ctx, id)
+	if err != nil {
+		rest.RespondError(w, r, err)
+		return
+	}
+
+	response := income.ToIncomeResponse(incomeData)
+	rest.SetETag(w, incomeData.Version)
+	respondWithJSON(w, http.StatusOK, response)
+}
+
+func (h *handler) GetDeletedIncomes(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	incomes, err := h.incomeService.GetDeletedIncomes(ctx, rest.UserIDFromRequest(r))
+	if err != nil {
+		rest.RespondError(w, r, err)
+		return
+	}
+
+	response := income.ToIncomeListResponse(incomes)
+	respondWithJSON(w, http.StatusOK, response.Incomes)
+}
+
+func (h *handler) CreateIncome(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var request entities.CreateIncomeRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		rest.RespondDecodeError(w, r, err)
+		return
+	}
+
+	if err := validate.Struct(request); err != nil {
+		rest.RespondError(w, r, err)
+		return
+	}
+
+	domainRequest := request.ToDomain()
+	domainRequest.UserID = rest.UserIDFromRequest(r)
+
+	created, err := h.incomeService.CreateIncome(ctx, domainRequest)
+	if err != nil {
+		rest.RespondError(w, r, err)
+		return
+	}
+
+	rest.SetETag(w, created.Version)
+	respondWithJSON(w, http.StatusCreated, income.ToIncomeResponse(created))
+}
+
+func (h *handler) UpdateIncome(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		rest.RespondProblem(w, r, http.StatusBadRequest, "invalid_id", "ID must be a number")
+		return
+	}
+
+	version, err := rest.IfMatchVersion(r)
+	if err != nil {
+		rest.RespondError(w, r, err)
+		return
+	}
+
+	var request entities.UpdateIncomeRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		rest.RespondDecodeError(w, r, err)
+		return
+	}
+
+	if err := validate.Struct(request); err != nil {
+		rest.RespondError(w, r, err)
+		return
+	}
+
+	domainRequest := request.ToDomain()
+	domainRequest.Version = version
+
+	updated, err := h.incomeService.UpdateIncome(ctx, id, domainRequest)
+	if err != nil {
+		rest.RespondError(w, r, err)
+		return
+	}
+
+	rest.SetETag(w, updated.Version)
+	respondWithJSON(w, http.StatusOK, income.ToIncomeResponse(updated))
+}
+
+func (h *handler) PatchIncome(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		rest.RespondProblem(w, r, http.StatusBadRequest, "invalid_id", "ID must be a number")
+		return
+	}
+
+	if !rest.IsMergePatch(r) {
+		rest.RespondProblem(w, r, http.StatusUnsupportedMediaType, "unsupported_media_type", "Content-Type must be application/merge-patch+json")
+		return
+	}
+
+	version, err := rest.IfMatchVersion(r)
+	if err != nil {
+		rest.RespondError(w, r, err)
+		return
+	}
+
+	patch, err := io.ReadAll(r.Body)
+	if err != nil {
+		rest.RespondProblem(w, r, http.StatusBadRequest, "error_reading_body", err.Error())
+		return
+	}
+
+	updated, err := h.incomeService.PatchIncome(ctx, id, income.PatchIncomeRequest{Patch: patch, Version: version})
+	if err != nil {
+		rest.RespondError(w, r, err)
+		return
+	}
+
+	rest.SetETag(w, updated.Version)
+	respondWithJSON(w, http.StatusOK, income.ToIncomeResponse(updated))
+}
+
+func (h *handler) DeleteIncome(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		rest.RespondProblem(w, r, http.StatusBadRequest, "invalid_id", "ID must be a number")
+		return
+	}
+
+	err = h.incomeService.DeleteIncome(ctx, id)
+	if err != nil {
+		rest.RespondError(w, r, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, entities.MessageResponse{
+		Message: "Ingreso eliminado exitosamente",
+	})
+}
+
+func (h *handler) RestoreIncome(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		rest.RespondProblem(w, r, http.StatusBadRequest, "invalid_id", "ID must be a number")
+		return
+	}
+
+	restored, err := h.incomeService.RestoreIncome(ctx, id)
+	if err != nil {
+		rest.RespondError(w, r, err)
+		return
+	}
+
+	rest.SetETag(w, restored.Version)
+	respondWithJSON(w, http.StatusOK, income.ToIncomeResponse(restored))
+}
+
+func respondWithJSON(w http.ResponseWriter, code int, payload interface{}) {
+	response, _ := json.Marshal(payload)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	w.Write(response)
+}
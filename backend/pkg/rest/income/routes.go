@@ -0,0 +1,24 @@
+package income
+
+import (
+	"github.com/go-chi/chi/v5"
+	"github.com/payvue/payvue-backend/pkg/rest"
+)
+
+func (h *handler) RouteURLs(router *chi.Mux, mode rest.Mode) {
+	router.Route("/finances/income", func(r chi.Router) {
+		r = r.With(rest.RequireUser)
+		if mode.Reads() {
+			r.Get("/", h.GetAllIncomes)
+			r.Get("/trash", h.GetDeletedIncomes)
+			r.Get("/{id}", h.GetIncomeByID)
+		}
+		if mode.Writes() {
+			r.Post("/", h.CreateIncome)
+			r.Put("/{id}", h.UpdateIncome)
+			r.Patch("/{id}", h.PatchIncome)
+			r.Delete("/{id}", h.DeleteIncome)
+			r.Post("/{id}/restore", h.RestoreIncome)
+		}
+	})
+}
@@ -0,0 +1,44 @@
+package rest
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/payvue/payvue-backend/pkg/metrics"
+)
+
+var (
+	httpRequests = metrics.Default.NewCounter("payvue_http_requests_total",
+		"Peticiones HTTP atendidas por método, ruta y estado.",
+		"method", "route", "status")
+	httpDuration = metrics.Default.NewHistogram("payvue_http_request_duration_seconds",
+		"Duración de las peticiones HTTP por método, ruta y estado.",
+		metrics.DefaultBuckets, "method", "route", "status")
+)
+
+// RequestMetrics cuenta y mide cada petición. La ruta es el patrón de chi
+// (/finances/debt/{id}), no la URL, para que los IDs no creen series nuevas; las
+// peticiones que no casan con ninguna ruta van a "unmatched".
+func RequestMetrics(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+		next.ServeHTTP(ww, r)
+
+		status := ww.Status()
+		if status == 0 {
+			status = http.StatusOK
+		}
+		route := "unmatched"
+		if routeContext := chi.RouteContext(r.Context()); routeContext != nil && routeContext.RoutePattern() != "" {
+			route = routeContext.RoutePattern()
+		}
+
+		labels := []string{r.Method, route, strconv.Itoa(status)}
+		httpRequests.Inc(labels...)
+		httpDuration.Observe(time.Since(start).Seconds(), labels...)
+	})
+}
@@ -0,0 +1,193 @@
+package rest
+
+import (
+	"context"
+	"log/slog"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/payvue/payvue-backend/pkg/domain/accesstoken"
+	"github.com/payvue/payvue-backend/pkg/domain/user"
+	"github.com/payvue/payvue-backend/pkg/tracing"
+	"github.com/payvue/payvue-backend/pkg/utils/actor"
+	"github.com/payvue/payvue-backend/pkg/utils/logger"
+)
+
+type accessTokenKey struct{}
+
+// RequestLogger deja en el contexto un logger con el ID de la petición, el
+// método y la ruta, y al terminar escribe una línea de acceso con el estado, el
+// patrón de la ruta y la duración. Los errores 5xx se registran como error.
+// Debe montarse después de middleware.RequestID y, si se usa, de RequestTracing.
+func RequestLogger(base *slog.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			fields := []any{
+				slog.String("request_id", middleware.GetReqID(r.Context())),
+				slog.String("method", r.Method),
+				slog.String("path", r.URL.Path),
+			}
+			if span := tracing.SpanFromContext(r.Context()); span != nil {
+				fields = append(fields,
+					slog.String("trace_id", span.SpanContext().TraceID.String()),
+					slog.String("span_id", span.SpanContext().SpanID.String()),
+				)
+			}
+			ctx := logger.WithContext(r.Context(), base.With(fields...))
+
+			ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+			next.ServeHTTP(ww, r.WithContext(ctx))
+
+			status := ww.Status()
+			if status == 0 {
+				status = http.StatusOK
+			}
+			level := slog.LevelInfo
+			if status >= http.StatusInternalServerError {
+				level = slog.LevelError
+			}
+
+			attrs := []slog.Attr{
+				slog.Int("status", status),
+				slog.Int("bytes", ww.BytesWritten()),
+				slog.Duration("duration", time.Since(start)),
+			}
+			if routeContext := chi.RouteContext(ctx); routeContext != nil && routeContext.RoutePattern() != "" {
+				attrs = append(attrs, slog.String("route", routeContext.RoutePattern()))
+			}
+			logger.FromContext(ctx).LogAttrs(ctx, level, "request", attrs...)
+		})
+	}
+}
+
+// ActorContext guarda en el contexto quién hace la petición para que los
+// servicios puedan registrarlo en el log de auditoría. Debe montarse después
+// de middleware.RequestID.
+func ActorContext(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ip, _, err := net.SplitHostPort(r.RemoteAddr)
+		if err != nil {
+			ip = r.RemoteAddr
+		}
+
+		userID := UserIDFromRequest(r)
+		if userID > 0 {
+			logger.AddAttrs(r.Context(), slog.Int("user_id", userID))
+		}
+
+		ctx := actor.WithActor(r.Context(), actor.Actor{
+			UserID:    userID,
+			RequestID: middleware.GetReqID(r.Context()),
+			IP:        ip,
+		})
+
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// BearerAuth acepta tokens de acceso personal en Authorization: Bearer. El
+// usuario del token tiene prioridad sobre X-User-ID y los tokens de solo lectura
+// no pueden hacer peticiones de escritura. Las peticiones sin token pasan sin
+// cambios. Debe montarse antes de ActorContext.
+func BearerAuth(tokens accesstoken.Authenticator) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			scheme, value, found := strings.Cut(r.Header.Get("Authorization"), " ")
+			if !found || !strings.EqualFold(scheme, "Bearer") {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			token, err := tokens.Authenticate(r.Context(), strings.TrimSpace(value))
+			if err != nil {
+				if err == accesstoken.ErrInvalidToken {
+					w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+				}
+				RespondError(w, r, err)
+				return
+			}
+
+			if isWriteMethod(r.Method) && !token.Scope.CanWrite() {
+				w.Header().Set("WWW-Authenticate", `Bearer error="insufficient_scope"`)
+				RespondProblem(w, r, http.StatusForbidden, "insufficient_scope", "El token de acceso es de solo lectura")
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), accessTokenKey{}, token)))
+		})
+	}
+}
+
+// AccessTokenFromContext devuelve el token con el que se autenticó la petición,
+// o nil si no vino ninguno.
+func AccessTokenFromContext(ctx context.Context) *accesstoken.AccessToken {
+	token, _ := ctx.Value(accessTokenKey{}).(*accesstoken.AccessToken)
+	return token
+}
+
+// RequireUser responde 401 a las peticiones sin usuario identificado, antes de
+// que los servicios calculen su alcance.
+func RequireUser(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if UserIDFromRequest(r) <= 0 {
+			RespondProblem(w, r, http.StatusUnauthorized, "unauthorized", "Usuario no identificado")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// RequireVerifiedEmail aplica la verificación obligatoria del correo a todas
+// las peticiones con usuario, no solo al login: sin ella bastaría con usar el
+// user_id que devuelve el registro. Las de /auth quedan fuera porque son las que
+// permiten verificar el correo o entrar, y ya lo comprueban. Cada petición
+// cuesta una consulta del usuario, así que solo se monta si la verificación es
+// obligatoria. Debe montarse después de BearerAuth.
+func RequireVerifiedEmail(users user.VerificationChecker) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			userID := UserIDFromRequest(r)
+			if userID <= 0 || strings.HasPrefix(r.URL.Path, "/auth/") {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if err := users.CheckEmailVerified(r.Context(), userID); err != nil {
+				RespondError(w, r, err)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// AuthenticatedUserID devuelve el usuario del token de acceso, o 0 si la
+// petición no trae uno. A diferencia de UserIDFromRequest no se fía de
+// X-User-ID: es para las operaciones que cambian cómo se entra en la cuenta.
+func AuthenticatedUserID(r *http.Request) int {
+	if token := AccessTokenFromContext(r.Context()); token != nil {
+		return token.UserID
+	}
+	return 0
+}
+
+// UserIDFromRequest devuelve el usuario del token de acceso si la petición trae
+// uno y, si no, lee la cabecera X-User-ID o, en su defecto, el parámetro user_id.
+// Devuelve 0 si no viene ninguno.
+func UserIDFromRequest(r *http.Request) int {
+	if token := AccessTokenFromContext(r.Context()); token != nil {
+		return token.UserID
+	}
+
+	userIDStr := r.Header.Get("X-User-ID")
+	if userIDStr == "" {
+		userIDStr = r.URL.Query().Get("user_id")
+	}
+	userID, _ := strconv.Atoi(userIDStr)
+	return userID
+}
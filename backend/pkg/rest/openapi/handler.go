@@ -0,0 +1,16 @@
+package openapi
+
+import (
+	"github.com/go-chi/chi/v5"
+	"github.com/payvue/payvue-backend/pkg/rest"
+)
+
+type handler struct {
+	// routes es el router completo: el documento publicado se filtra con las
+	// rutas que tiene montadas, que aún no existen al llamar a RouteURLs.
+	routes chi.Routes
+}
+
+func NewHandler() rest.Handler {
+	return &handler{}
+}
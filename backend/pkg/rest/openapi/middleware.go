@@ -0,0 +1,263 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/payvue/payvue-backend/pkg/rest"
+	"github.com/payvue/payvue-backend/pkg/rest/entities"
+	"github.com/payvue/payvue-backend/pkg/utils/logger"
+)
+
+// maxCapturedResponse es lo que se guarda de cada respuesta para validarla; las
+// más grandes (recibos, exportaciones) no se comprueban.
+const maxCapturedResponse = 1 << 20
+
+// Validator comprueba las peticiones y respuestas de las rutas documentadas
+// contra el documento. Una petición que no lo cumple recibe un 400 con el
+// detalle por campo sin llegar al handler; una respuesta que no lo cumple solo
+// deja un aviso en el log, nunca se modifica. Pensado para desarrollo: se monta
+// con environment=development.
+//
+// Solo se validan las operaciones montadas en routes: las demás pasan sin tocar
+// para que el router responda 404 o 405, como en producción, y no un 400 por el
+// cuerpo de una operación que ese binario no sirve. Las rutas se leen en la
+// primera petición, cuando el router ya está completo.
+func Validator(routes chi.Routes) func(http.Handler) http.Handler {
+	var (
+		once   sync.Once
+		served map[string]bool
+	)
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			once.Do(func() {
+				served, _, _ = spec.mounted(routes)
+			})
+
+			op, pathParams, path := spec.find(r.Method, r.URL.Path)
+			if op == nil || !served[r.Method+" "+path] {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if !spec.checkRequest(w, r, op, pathParams) {
+				return
+			}
+
+			capture := &captureBuffer{limit: maxCapturedResponse}
+			ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+			ww.Tee(capture)
+			next.ServeHTTP(ww, r)
+
+			status := ww.Status()
+			if status == 0 {
+				status = http.StatusOK
+			}
+			if problems := spec.checkResponse(op, status, ww.Header().Get("Content-Type"), capture); len(problems) > 0 {
+				logger.FromContext(r.Context()).Warn("response does not match the OpenAPI specification",
+					slog.String("operation", op.OperationID),
+					slog.Int("status", status),
+					slog.Any("problems", problems),
+				)
+			}
+		})
+	}
+}
+
+// checkRequest valida parámetros y cuerpo. Si la petición no cumple el
+// documento responde con el problema y devuelve false.
+func (s *Spec) checkRequest(w http.ResponseWriter, r *http.Request, op *operation, pathParams map[string]string) bool {
+	var errs []entities.FieldError
+	query := r.URL.Query()
+	for _, param := range op.Parameters {
+		var value string
+		var present bool
+		switch param.In {
+		case "path":
+			value, present = pathParams[param.Name]
+		case "query":
+			if values, ok := query[param.Name]; ok {
+				value, present = values[0], true
+			}
+		case "header":
+			value = r.Header.Get(param.Name)
+			present = value != ""
+		}
+		if !present {
+			if param.Required {
+				errs = append(errs, fieldError(param.Name, "required", "Parámetro obligatorio"))
+			}
+			continue
+		}
+		if param.Schema != nil {
+			errs = append(errs, s.validate(param.Schema, s.parseParameter(param.Schema, value), param.Name)...)
+		}
+	}
+
+	if op.RequestBody != nil {
+		bodyErrs, ok := s.checkRequestBody(w, r, op.RequestBody)
+		if !ok {
+			return false
+		}
+		errs = append(errs, bodyErrs...)
+	}
+
+	if len(errs) > 0 {
+		rest.RespondFieldErrors(w, r, "La petición no cumple la especificación OpenAPI", errs)
+		return false
+	}
+	return true
+}
+
+// checkRequestBody lee el cuerpo JSON para validarlo y lo deja otra vez en r.Body
+// para el handler. De los formularios multipart solo comprueba el tipo: el
+// handler los procesa en streaming y leerlos aquí obligaría a cargar el recibo.
+func (s *Spec) checkRequestBody(w http.ResponseWriter, r *http.Request, body *requestBody) ([]entities.FieldError, bool) {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType != "" && !isJSON(mediaType) {
+		if _, ok := body.Content[mediaType]; !ok {
+			respondUnsupportedMediaType(w, r, body)
+			return nil, false
+		}
+		return nil, true
+	}
+
+	data, err := io.ReadAll(r.Body)
+	if err != nil {
+		rest.RespondProblem(w, r, http.StatusBadRequest, "error_reading_body", err.Error())
+		return nil, false
+	}
+	r.Body = io.NopCloser(bytes.NewReader(data))
+
+	if len(bytes.TrimSpace(data)) == 0 {
+		if body.Required {
+			return []entities.FieldError{fieldError("body", "required", "El cuerpo es obligatorio")}, true
+		}
+		return nil, true
+	}
+
+	content, ok := body.Content[mediaType]
+	if !ok {
+		respondUnsupportedMediaType(w, r, body)
+		return nil, false
+	}
+	if content.Schema == nil {
+		return nil, true
+	}
+
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		rest.RespondDecodeError(w, r, err)
+		return nil, false
+	}
+	return s.validate(content.Schema, value, ""), true
+}
+
+func respondUnsupportedMediaType(w http.ResponseWriter, r *http.Request, body *requestBody) {
+	rest.RespondProblem(w, r, http.StatusUnsupportedMediaType, "unsupported_media_type",
+		"Content-Type debe ser "+strings.Join(mediaTypes(body.Content), " o "))
+}
+
+// checkResponse devuelve las diferencias entre la respuesta y el documento. La
+// respuesta "default" solo cubre los errores: un 2xx sin documentar es un
+// cambio de contrato aunque haya default.
+func (s *Spec) checkResponse(op *operation, status int, contentType string, body *captureBuffer) []string {
+	if status == http.StatusNotModified {
+		return nil
+	}
+
+	resp, ok := op.Responses[strconv.Itoa(status)]
+	if !ok {
+		resp, ok = op.Responses[fmt.Sprintf("%dXX", status/100)]
+	}
+	if !ok && status >= http.StatusBadRequest {
+		resp, ok = op.Responses["default"]
+	}
+	if !ok {
+		return []string{fmt.Sprintf("status %d is not documented", status)}
+	}
+
+	if len(resp.Content) == 0 {
+		if body.Len() > 0 || body.truncated {
+			return []string{"the response has a body but the specification documents none"}
+		}
+		return nil
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	content := matchMediaType(resp.Content, mediaType)
+	if content == nil {
+		return []string{fmt.Sprintf("content type %q is not documented", contentType)}
+	}
+	if !isJSON(mediaType) || content.Schema == nil || body.truncated {
+		return nil
+	}
+
+	var value interface{}
+	if err := json.Unmarshal(body.Bytes(), &value); err != nil {
+		return []string{"invalid JSON: " + err.Error()}
+	}
+	var problems []string
+	for _, err := range s.validate(content.Schema, value, "") {
+		problems = append(problems, fmt.Sprintf("%s: %s (%s)", err.Field, err.Message, err.Rule))
+	}
+	return problems
+}
+
+// matchMediaType admite los rangos "tipo/*" y "*/*" del documento, como en el
+// recibo de un pago, que puede ser una imagen o un PDF.
+func matchMediaType(content map[string]*mediaType, mediaType string) *mediaType {
+	if media, ok := content[mediaType]; ok {
+		return media
+	}
+	if i := strings.Index(mediaType, "/"); i > 0 {
+		if media, ok := content[mediaType[:i]+"/*"]; ok {
+			return media
+		}
+	}
+	return content["*/*"]
+}
+
+func mediaTypes(content map[string]*mediaType) []string {
+	types := make([]string, 0, len(content))
+	for mediaType := range content {
+		types = append(types, mediaType)
+	}
+	sort.Strings(types)
+	return types
+}
+
+func isJSON(mediaType string) bool {
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
+
+// captureBuffer guarda una copia de la respuesta hasta limit bytes; si la
+// respuesta es más grande la descarta y marca truncated.
+type captureBuffer struct {
+	bytes.Buffer
+	limit     int
+	truncated bool
+}
+
+func (c *captureBuffer) Write(p []byte) (int, error) {
+	if c.truncated {
+		return len(p), nil
+	}
+	if c.Len()+len(p) > c.limit {
+		c.truncated = true
+		c.Reset()
+		return len(p), nil
+	}
+	return c.Buffer.Write(p)
+}
@@ -0,0 +1,51 @@
+package openapi
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/payvue/payvue-backend/pkg/rest"
+)
+
+// GetSpecification sirve el documento con las operaciones montadas en este
+// binario.
+func (h *handler) GetSpecification(w http.ResponseWriter, r *http.Request) {
+	document, err := spec.forRoutes(h.routes)
+	if err != nil {
+		rest.RespondError(w, r, err)
+		return
+	}
+
+	response, _ := json.Marshal(document)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(response)
+}
+
+// docsPage carga Swagger UI desde unpkg; el binario no incluye sus ficheros.
+const docsPage = `<!DOCTYPE html>
+<html lang="es">
+<head>
+  <meta charset="utf-8">
+  <meta name="viewport" content="width=device-width, initial-scale=1">
+  <title>PayVue API</title>
+  <link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@5/swagger-ui.css">
+</head>
+<body>
+  <div id="swagger-ui"></div>
+  <script src="https://unpkg.com/swagger-ui-dist@5/swagger-ui-bundle.js" crossorigin></script>
+  <script>
+    window.onload = () => {
+      window.ui = SwaggerUIBundle({ url: "openapi.json", dom_id: "#swagger-ui" });
+    };
+  </script>
+</body>
+</html>
+`
+
+// GetDocs sirve la página de Swagger UI, que lee /openapi.json del mismo binario.
+func (h *handler) GetDocs(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(docsPage))
+}
@@ -0,0 +1,14 @@
+package openapi
+
+import (
+	"github.com/go-chi/chi/v5"
+	"github.com/payvue/payvue-backend/pkg/rest"
+)
+
+// RouteURLs monta el documento y la página de Swagger UI en los tres binarios;
+// cada uno publica solo las operaciones que sirve.
+func (h *handler) RouteURLs(router *chi.Mux, mode rest.Mode) {
+	h.routes = router
+	router.Get("/openapi.json", h.GetSpecification)
+	router.Get("/docs", h.GetDocs)
+}
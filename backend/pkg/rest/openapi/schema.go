@@ -0,0 +1,261 @@
+package openapi
+
+import (
+	"fmt"
+	"math"
+	"net/mail"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/payvue/payvue-backend/pkg/rest/entities"
+)
+
+// Schema es el subconjunto de JSON Schema de OpenAPI 3.0 que usa el documento.
+// Las palabras clave que no aparecen aquí se ignoran al validar.
+type Schema struct {
+	Ref                  string             `json:"$ref"`
+	Type                 string             `json:"type"`
+	Format               string             `json:"format"`
+	Nullable             bool               `json:"nullable"`
+	Enum                 []interface{}      `json:"enum"`
+	Properties           map[string]*Schema `json:"properties"`
+	Required             []string           `json:"required"`
+	AdditionalProperties *bool              `json:"additionalProperties"`
+	Items                *Schema            `json:"items"`
+	OneOf                []*Schema          `json:"oneOf"`
+	Minimum              *float64           `json:"minimum"`
+	Maximum              *float64           `json:"maximum"`
+	ExclusiveMinimum     bool               `json:"exclusiveMinimum"`
+	MinLength            *int               `json:"minLength"`
+	MaxLength            *int               `json:"maxLength"`
+}
+
+// validate comprueba value, ya decodificado con encoding/json, contra schema.
+// field es la ruta del valor en el cuerpo (p. ej. "errors[0].field") y se usa
+// en los errores.
+func (s *Spec) validate(schema *Schema, value interface{}, field string) []entities.FieldError {
+	schema, err := s.resolve(schema)
+	if err != nil {
+		return []entities.FieldError{fieldError(field, "$ref", err.Error())}
+	}
+
+	if value == nil {
+		if schema.Nullable || schema.Type == "" && len(schema.OneOf) == 0 {
+			return nil
+		}
+		return []entities.FieldError{fieldError(field, "type", "No puede ser null")}
+	}
+
+	if len(schema.OneOf) > 0 {
+		return s.validateOneOf(schema, value, field)
+	}
+
+	if len(schema.Enum) > 0 && !inEnum(schema.Enum, value) {
+		return []entities.FieldError{fieldError(field, "enum", fmt.Sprintf("Debe ser uno de %v", schema.Enum))}
+	}
+
+	switch schema.Type {
+	case "object":
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return []entities.FieldError{typeError(field, "un objeto")}
+		}
+		return s.validateObject(schema, object, field)
+	case "array":
+		items, ok := value.([]interface{})
+		if !ok {
+			return []entities.FieldError{typeError(field, "una lista")}
+		}
+		if schema.Items == nil {
+			return nil
+		}
+		var errs []entities.FieldError
+		for i, item := range items {
+			errs = append(errs, s.validate(schema.Items, item, fmt.Sprintf("%s[%d]", field, i))...)
+		}
+		return errs
+	case "string":
+		str, ok := value.(string)
+		if !ok {
+			return []entities.FieldError{typeError(field, "un texto")}
+		}
+		return validateString(schema, str, field)
+	case "integer":
+		number, ok := value.(float64)
+		if !ok || number != math.Trunc(number) {
+			return []entities.FieldError{typeError(field, "un número entero")}
+		}
+		return validateNumber(schema, number, field)
+	case "number":
+		number, ok := value.(float64)
+		if !ok {
+			return []entities.FieldError{typeError(field, "un número")}
+		}
+		return validateNumber(schema, number, field)
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return []entities.FieldError{typeError(field, "true o false")}
+		}
+	}
+	return nil
+}
+
+func (s *Spec) resolve(schema *Schema) (*Schema, error) {
+	for schema.Ref != "" {
+		name := strings.TrimPrefix(schema.Ref, "#/components/schemas/")
+		resolved, ok := s.schemas[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown schema %s", schema.Ref)
+		}
+		schema = resolved
+	}
+	return schema, nil
+}
+
+func (s *Spec) validateObject(schema *Schema, object map[string]interface{}, field string) []entities.FieldError {
+	var errs []entities.FieldError
+	for _, name := range schema.Required {
+		if _, ok := object[name]; !ok {
+			errs = append(errs, fieldError(join(field, name), "required", "Campo obligatorio"))
+		}
+	}
+	// En orden para que los errores salgan siempre igual
+	names := make([]string, 0, len(object))
+	for name := range object {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		value := object[name]
+		property, ok := schema.Properties[name]
+		if !ok {
+			if schema.AdditionalProperties != nil && !*schema.AdditionalProperties {
+				errs = append(errs, fieldError(join(field, name), "additionalProperties", "Campo no documentado"))
+			}
+			continue
+		}
+		errs = append(errs, s.validate(property, value, join(field, name))...)
+	}
+	return errs
+}
+
+// validateOneOf exige que el valor cumpla exactamente una de las variantes. Si
+// no cumple ninguna se devuelven los errores de la variante más cercana.
+func (s *Spec) validateOneOf(schema *Schema, value interface{}, field string) []entities.FieldError {
+	var closest []entities.FieldError
+	matches := 0
+	for i, variant := range schema.OneOf {
+		errs := s.validate(variant, value, field)
+		if len(errs) == 0 {
+			matches++
+			continue
+		}
+		if i == 0 || len(errs) < len(closest) {
+			closest = errs
+		}
+	}
+	switch {
+	case matches == 1:
+		return nil
+	case matches > 1:
+		return []entities.FieldError{fieldError(field, "oneOf", "Cumple más de una de las variantes")}
+	}
+	return closest
+}
+
+func validateString(schema *Schema, value string, field string) []entities.FieldError {
+	length := utf8.RuneCountInString(value)
+	if schema.MinLength != nil && length < *schema.MinLength {
+		if *schema.MinLength == 1 {
+			return []entities.FieldError{fieldError(field, "minLength", "No puede estar vacío")}
+		}
+		return []entities.FieldError{fieldError(field, "minLength", fmt.Sprintf("Debe tener al menos %d caracteres", *schema.MinLength))}
+	}
+	if schema.MaxLength != nil && length > *schema.MaxLength {
+		return []entities.FieldError{fieldError(field, "maxLength", fmt.Sprintf("Debe tener como mucho %d caracteres", *schema.MaxLength))}
+	}
+
+	var err error
+	switch schema.Format {
+	case "date":
+		_, err = time.Parse("2006-01-02", value)
+	case "date-time":
+		_, err = time.Parse(time.RFC3339, value)
+	case "email":
+		_, err = mail.ParseAddress(value)
+	}
+	if err != nil {
+		return []entities.FieldError{fieldError(field, "format", "Debe tener formato "+schema.Format)}
+	}
+	return nil
+}
+
+func validateNumber(schema *Schema, value float64, field string) []entities.FieldError {
+	if schema.Minimum != nil {
+		minimum := *schema.Minimum
+		if schema.ExclusiveMinimum && value <= minimum {
+			return []entities.FieldError{fieldError(field, "exclusiveMinimum", "Debe ser mayor que "+formatNumber(minimum))}
+		}
+		if value < minimum {
+			return []entities.FieldError{fieldError(field, "minimum", "Debe ser mayor o igual que "+formatNumber(minimum))}
+		}
+	}
+	if schema.Maximum != nil && value > *schema.Maximum {
+		return []entities.FieldError{fieldError(field, "maximum", "Debe ser menor o igual que "+formatNumber(*schema.Maximum))}
+	}
+	return nil
+}
+
+// parseParameter convierte el texto de un parámetro de la URL o de una cabecera
+// al tipo de su esquema, para validarlo igual que un valor del cuerpo.
+func (s *Spec) parseParameter(schema *Schema, value string) interface{} {
+	schema, err := s.resolve(schema)
+	if err != nil {
+		return value
+	}
+	switch schema.Type {
+	case "integer", "number":
+		if number, err := strconv.ParseFloat(value, 64); err == nil {
+			return number
+		}
+	case "boolean":
+		if b, err := strconv.ParseBool(value); err == nil {
+			return b
+		}
+	}
+	return value
+}
+
+func inEnum(enum []interface{}, value interface{}) bool {
+	for _, allowed := range enum {
+		if allowed == value {
+			return true
+		}
+	}
+	return false
+}
+
+func join(parent, name string) string {
+	if parent == "" {
+		return name
+	}
+	return parent + "." + name
+}
+
+func formatNumber(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
+
+func typeError(field, expected string) entities.FieldError {
+	return fieldError(field, "type", "Debe ser "+expected)
+}
+
+func fieldError(field, rule, message string) entities.FieldError {
+	if field == "" {
+		field = "body"
+	}
+	return entities.FieldError{Field: field, Rule: rule, Message: message}
+}
@@ -0,0 +1,298 @@
+package openapi
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/payvue/payvue-backend/pkg/rest"
+)
+
+// specJSON es el contrato de /auth y /finances. Se mantiene a mano: cualquier
+// cambio en esas rutas o en sus cuerpos tiene que reflejarse aquí.
+//
+//go:embed openapi.json
+var specJSON []byte
+
+// document son las partes del documento que usa el validador. El resto (textos,
+// ejemplos) solo se sirve tal cual en /openapi.json.
+type document struct {
+	Paths      map[string]map[string]*operation `json:"paths"`
+	Components struct {
+		Schemas    map[string]*Schema    `json:"schemas"`
+		Parameters map[string]*parameter `json:"parameters"`
+		Responses  map[string]*response  `json:"responses"`
+	} `json:"components"`
+}
+
+type operation struct {
+	OperationID string               `json:"operationId"`
+	Parameters  []*parameter         `json:"parameters"`
+	RequestBody *requestBody         `json:"requestBody"`
+	Responses   map[string]*response `json:"responses"`
+}
+
+type parameter struct {
+	Ref      string  `json:"$ref"`
+	Name     string  `json:"name"`
+	In       string  `json:"in"`
+	Required bool    `json:"required"`
+	Schema   *Schema `json:"schema"`
+}
+
+type requestBody struct {
+	Required bool                  `json:"required"`
+	Content  map[string]*mediaType `json:"content"`
+}
+
+type response struct {
+	Ref     string                `json:"$ref"`
+	Content map[string]*mediaType `json:"content"`
+}
+
+type mediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+// Spec es el documento ya interpretado, con las referencias a parámetros y
+// respuestas resueltas. Las de esquemas se resuelven al validar.
+type Spec struct {
+	raw     map[string]interface{}
+	doc     document
+	schemas map[string]*Schema
+	routes  []route
+}
+
+// route es una ruta del documento partida en segmentos para buscarla sin
+// depender del router.
+type route struct {
+	path       string
+	segments   []string
+	literals   int
+	operations map[string]*operation
+}
+
+// spec se interpreta al arrancar: el documento va embebido en el binario, así que
+// si no es válido es un error de programación y conviene verlo cuanto antes.
+var spec = mustParse(specJSON)
+
+func mustParse(data []byte) *Spec {
+	s, err := parse(data)
+	if err != nil {
+		panic(fmt.Sprintf("openapi: invalid embedded specification: %v", err))
+	}
+	return s
+}
+
+func parse(data []byte) (*Spec, error) {
+	s := &Spec{}
+	if err := json.Unmarshal(data, &s.raw); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &s.doc); err != nil {
+		return nil, err
+	}
+	s.schemas = s.doc.Components.Schemas
+
+	for path, operations := range s.doc.Paths {
+		for method, op := range operations {
+			for i, param := range op.Parameters {
+				resolved, err := s.resolveParameter(param)
+				if err != nil {
+					return nil, fmt.Errorf("%s %s: %w", strings.ToUpper(method), path, err)
+				}
+				op.Parameters[i] = resolved
+			}
+			for status, resp := range op.Responses {
+				resolved, err := s.resolveResponse(resp)
+				if err != nil {
+					return nil, fmt.Errorf("%s %s: %w", strings.ToUpper(method), path, err)
+				}
+				op.Responses[status] = resolved
+			}
+		}
+
+		r := route{path: path, segments: splitPath(path), operations: operations}
+		for _, segment := range r.segments {
+			if !isTemplate(segment) {
+				r.literals++
+			}
+		}
+		s.routes = append(s.routes, r)
+	}
+
+	// Las rutas con más segmentos fijos van primero: /finances/debt/trash gana a
+	// /finances/debt/{id}
+	sort.Slice(s.routes, func(i, j int) bool {
+		if s.routes[i].literals != s.routes[j].literals {
+			return s.routes[i].literals > s.routes[j].literals
+		}
+		return s.routes[i].path < s.routes[j].path
+	})
+	return s, nil
+}
+
+func (s *Spec) resolveParameter(param *parameter) (*parameter, error) {
+	if param.Ref == "" {
+		return param, nil
+	}
+	name := strings.TrimPrefix(param.Ref, "#/components/parameters/")
+	resolved, ok := s.doc.Components.Parameters[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown parameter %s", param.Ref)
+	}
+	return resolved, nil
+}
+
+func (s *Spec) resolveResponse(resp *response) (*response, error) {
+	if resp.Ref == "" {
+		return resp, nil
+	}
+	name := strings.TrimPrefix(resp.Ref, "#/components/responses/")
+	resolved, ok := s.doc.Components.Responses[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown response %s", resp.Ref)
+	}
+	return resolved, nil
+}
+
+// find busca la operación de una petición. Devuelve también los parámetros y
+// la ruta del documento; si la ruta no está documentada o no admite el método
+// devuelve nil.
+func (s *Spec) find(method, path string) (*operation, map[string]string, string) {
+	r, ok := s.route(path)
+	if !ok {
+		return nil, nil, ""
+	}
+	params, _ := r.match(splitPath(path))
+	return r.operations[strings.ToLower(method)], params, r.path
+}
+
+// route devuelve la ruta del documento que corresponde a path, que puede ser
+// una URL o un patrón de chi como /finances/debt/{id}.
+func (s *Spec) route(path string) (route, bool) {
+	segments := splitPath(path)
+	for _, r := range s.routes {
+		if _, ok := r.match(segments); ok {
+			return r, true
+		}
+	}
+	return route{}, false
+}
+
+func (r route) match(segments []string) (map[string]string, bool) {
+	if len(segments) != len(r.segments) {
+		return nil, false
+	}
+	params := make(map[string]string)
+	for i, segment := range r.segments {
+		if isTemplate(segment) {
+			params[segment[1:len(segment)-1]] = segments[i]
+			continue
+		}
+		if segment != segments[i] {
+			return nil, false
+		}
+	}
+	return params, true
+}
+
+// splitPath ignora la barra final: el router monta los listados en
+// /finances/debt/ y el documento los describe sin ella.
+func splitPath(path string) []string {
+	path = strings.Trim(path, "/")
+	if path == "" {
+		return nil
+	}
+	return strings.Split(path, "/")
+}
+
+func isTemplate(segment string) bool {
+	return strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}")
+}
+
+// documented indica si la ruta pertenece a la parte de la API que cubre el
+// documento.
+func documented(path string) bool {
+	return strings.HasPrefix(path, "/auth/") || strings.HasPrefix(path, "/finances/")
+}
+
+// mounted recorre las rutas de /auth y /finances montadas en routes. Devuelve
+// las operaciones del documento que sirven, como "GET /finances/debt/{id}", y
+// las rutas que no están documentadas.
+func (s *Spec) mounted(routes chi.Routes) (map[string]bool, []string, error) {
+	operations := make(map[string]bool)
+	var undocumented []string
+	err := chi.Walk(routes, func(method, path string, handler http.Handler, middlewares ...func(http.Handler) http.Handler) error {
+		if !documented(path) {
+			return nil
+		}
+		r, ok := s.route(path)
+		if !ok || r.operations[strings.ToLower(method)] == nil {
+			undocumented = append(undocumented, method+" "+path)
+			return nil
+		}
+		operations[method+" "+r.path] = true
+		return nil
+	})
+	return operations, undocumented, err
+}
+
+// CheckRoutes compara el documento con las rutas de /auth y /finances montadas
+// en routes y describe cada diferencia. Las operaciones documentadas que faltan
+// solo se comprueban con ModeAll: el reader y el writer sirven una parte.
+func CheckRoutes(routes chi.Routes, mode rest.Mode) []string {
+	operations, undocumented, err := spec.mounted(routes)
+	if err != nil {
+		return []string{err.Error()}
+	}
+
+	var problems []string
+	for _, operation := range undocumented {
+		problems = append(problems, "route "+operation+" is not in the OpenAPI specification")
+	}
+	if mode == rest.ModeAll {
+		for _, r := range spec.routes {
+			for method := range r.operations {
+				operation := strings.ToUpper(method) + " " + r.path
+				if !operations[operation] {
+					problems = append(problems, "operation "+operation+" is documented but not mounted")
+				}
+			}
+		}
+	}
+	sort.Strings(problems)
+	return problems
+}
+
+// forRoutes devuelve el documento con solo las operaciones montadas en routes,
+// para que cada binario publique lo que de verdad sirve.
+func (s *Spec) forRoutes(routes chi.Routes) (map[string]interface{}, error) {
+	operations, _, err := s.mounted(routes)
+	if err != nil {
+		return nil, err
+	}
+
+	filtered := make(map[string]interface{}, len(s.raw))
+	for key, value := range s.raw {
+		filtered[key] = value
+	}
+	paths := make(map[string]interface{})
+	for path, item := range s.raw["paths"].(map[string]interface{}) {
+		methods := make(map[string]interface{})
+		for method, op := range item.(map[string]interface{}) {
+			if operations[strings.ToUpper(method)+" "+path] {
+				methods[method] = op
+			}
+		}
+		if len(methods) > 0 {
+			paths[path] = methods
+		}
+	}
+	filtered["paths"] = paths
+	return filtered, nil
+}
@@ -0,0 +1,18 @@
+package rest
+
+import (
+	"mime"
+	"net/http"
+
+	"github.com/payvue/payvue-backend/pkg/utils/mergepatch"
+)
+
+// IsMergePatch indica si el cuerpo de la petición se declara como JSON Merge Patch.
+// Se acepta también application/json para clientes que no envían el media type específico.
+func IsMergePatch(r *http.Request) bool {
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil {
+		return false
+	}
+	return mediaType == mergepatch.ContentType || mediaType == "application/json"
+}
@@ -0,0 +1,243 @@
+package payment
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"os"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/payvue/payvue-backend/pkg/domain/payment"
+	"github.com/payvue/payvue-backend/pkg/metrics"
+	"github.com/payvue/payvue-backend/pkg/rest"
+	"github.com/payvue/payvue-backend/pkg/rest/entities"
+	"github.com/payvue/payvue-backend/pkg/tracing"
+	"github.com/payvue/payvue-backend/pkg/utils/fileupload"
+)
+
+func (h *handler) GetAllPayments(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	// Se listan los pagos del usuario y los de sus hogares
+	userID := rest.UserIDFromRequest(r)
+	payments, err := h.paymentService.GetPaymentsByUserID(ctx, userID)
+	if err != nil {
+		rest.RespondError(w, r, err)
+		return
+	}
+
+	h.respondWithPayments(w, r, userID, payments)
+}
+
+// GetReceipt sirve el recibo a quien ve su pago. El usuario sale de la petición o,
+// para <img src>, del token de la URL firmada que devuelven los listados.
+func (h *handler) GetReceipt(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	filename := chi.URLParam(r, "filename")
+
+	if filename == "" {
+		rest.RespondProblem(w, r, http.StatusBadRequest, "invalid_filename", "Filename is required")
+		return
+	}
+
+	userID := rest.UserIDFromRequest(r)
+	if token := r.URL.Query().Get("token"); token != "" {
+		var err error
+		if userID, err = h.paymentService.ReceiptLinkUser(token, filename); err != nil {
+			rest.RespondError(w, r, err)
+			return
+		}
+	}
+	if userID <= 0 {
+		rest.RespondProblem(w, r, http.StatusUnauthorized, "unauthorized", "Usuario no identificado")
+		return
+	}
+
+	// Fuera del ámbito del usuario el recibo no existe
+	if _, err := h.paymentService.GetReceipt(ctx, userID, filename); err != nil {
+		if errors.Is(err, payment.ErrPaymentNotFound) {
+			rest.RespondProblem(w, r, http.StatusNotFound, "file_not_found", "Receipt file not found")
+			return
+		}
+		rest.RespondError(w, r, err)
+		return
+	}
+
+	// Construir ruta del archivo
+	filePath := fileupload.GetFilePath(filename)
+
+	// Verificar que el archivo existe
+	if _, err := os.Stat(filePath); os.IsNotExist(err) {
+		rest.RespondProblem(w, r, http.StatusNotFound, "file_not_found", "Receipt file not found")
+		return
+	}
+
+	// Servir el archivo
+	http.ServeFile(w, r, filePath)
+}
+
+func (h *handler) GetDeletedPayments(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID := rest.UserIDFromRequest(r)
+	payments, err := h.paymentService.GetDeletedPayments(ctx, userID)
+	if err != nil {
+		rest.RespondError(w, r, err)
+		return
+	}
+
+	h.respondWithPayments(w, r, userID, payments)
+}
+
+// respondWithPayments firma las URLs de los recibos para userID.
+func (h *handler) respondWithPayments(w http.ResponseWriter, r *http.Request, userID int, payments []payment.PaymentWithDebt) {
+	response := payment.ToPaymentListResponse(payments)
+	if err := h.paymentService.SignReceiptURLs(userID, response.Payments); err != nil {
+		rest.RespondError(w, r, err)
+		return
+	}
+	respondWithJSON(w, http.StatusOK, response.Payments)
+}
+
+// CreatePayment acepta multipart/form-data o un formulario simple. El recibo es
+// opcional y se envía en el campo "receipt"; "file" se mantiene por compatibilidad.
+func (h *handler) CreatePayment(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	// Parse multipart form (10 MB max). Con recibos grandes es lo que más tarda,
+	// así que lleva su propio span
+	_, parseSpan := tracing.Start(ctx, "payment.ParseMultipartForm")
+	err := r.ParseMultipartForm(fileupload.MaxFileSize)
+	if err != http.ErrNotMultipart {
+		parseSpan.RecordError(err)
+	}
+	parseSpan.End()
+	if err != nil && err != http.ErrNotMultipart {
+		rest.RespondProblem(w, r, http.StatusBadRequest, "error_parsing_form", err.Error())
+		return
+	}
+
+	// Obtener campos del formulario
+	amountStr := r.FormValue("amount")
+	debtIDStr := r.FormValue("debt_id")
+	date := r.FormValue("date")
+
+	// Validar campos requeridos
+	if amountStr == "" || debtIDStr == "" {
+		rest.RespondProblem(w, r, http.StatusBadRequest, "missing_fields", "Amount and debt_id are required")
+		return
+	}
+
+	amount, err := strconv.ParseFloat(amountStr, 64)
+	if err != nil {
+		rest.RespondProblem(w, r, http.StatusBadRequest, "invalid_amount", "Amount must be a number")
+		return
+	}
+
+	debtID, err := strconv.Atoi(debtIDStr)
+	if err != nil {
+		rest.RespondProblem(w, r, http.StatusBadRequest, "invalid_debt_id", "Debt ID must be a number")
+		return
+	}
+
+	// Guardar el recibo, si viene
+	filename, err := saveReceipt(ctx, r)
+	if err != nil {
+		rest.RespondError(w, r, err)
+		return
+	}
+
+	// Crear request
+	request := payment.CreatePaymentRequest{
+		UserID: rest.UserIDFromRequest(r),
+		Amount: amount,
+		DebtID: debtID,
+		Date:   date,
+	}
+
+	// Crear pago
+	created, err := h.paymentService.CreatePayment(ctx, request, filename)
+	if err != nil {
+		if filename != "" {
+			fileupload.DeleteFile(filename)
+		}
+		rest.RespondError(w, r, err)
+		return
+	}
+	paymentsCreated.Inc()
+
+	respondWithJSON(w, http.StatusCreated, created)
+}
+
+func (h *handler) DeletePayment(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		rest.RespondProblem(w, r, http.StatusBadRequest, "invalid_id", "ID must be a number")
+		return
+	}
+
+	err = h.paymentService.DeletePayment(ctx, id)
+	if err != nil {
+		rest.RespondError(w, r, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, entities.MessageResponse{
+		Message: "Pago eliminado exitosamente",
+	})
+}
+
+func (h *handler) RestorePayment(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		rest.RespondProblem(w, r, http.StatusBadRequest, "invalid_id", "ID must be a number")
+		return
+	}
+
+	restored, err := h.paymentService.RestorePayment(ctx, id)
+	if err != nil {
+		rest.RespondError(w, r, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, restored)
+}
+
+var paymentsCreated = metrics.Default.NewCounter("payvue_payments_created_total",
+	"Pagos registrados desde el arranque.")
+
+func saveReceipt(ctx context.Context, r *http.Request) (string, error) {
+	if r.MultipartForm == nil {
+		return "", nil
+	}
+	for _, field := range []string{"receipt", "file"} {
+		file, header, err := r.FormFile(field)
+		if err != nil {
+			continue
+		}
+		defer file.Close()
+
+		_, span := tracing.Start(ctx, "payment.SaveReceipt", slog.Int64("file.size", header.Size))
+		defer span.End()
+		filename, err := fileupload.SaveFile(file, header)
+		span.RecordError(err)
+		return filename, err
+	}
+	return "", nil
+}
+
+func respondWithJSON(w http.ResponseWriter, code int, payload interface{}) {
+	response, _ := json.Marshal(payload)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	w.Write(response)
+}
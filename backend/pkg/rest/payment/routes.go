@@ -0,0 +1,24 @@
+package payment
+
+import (
+	"github.com/go-chi/chi/v5"
+	"github.com/payvue/payvue-backend/pkg/rest"
+)
+
+func (h *handler) RouteURLs(router *chi.Mux, mode rest.Mode) {
+	router.Route("/finances/payment", func(r chi.Router) {
+		user := r.With(rest.RequireUser)
+		if mode.Reads() {
+			user.Get("/", h.GetAllPayments)
+			user.Get("/trash", h.GetDeletedPayments)
+			// El frontend muestra los recibos con <img src>, que no envía X-User-ID:
+			// GetReceipt acepta también la URL firmada de los listados
+			r.Get("/receipt/{filename}", h.GetReceipt)
+		}
+		if mode.Writes() {
+			user.Post("/", h.CreatePayment)
+			user.Delete("/{id}", h.DeletePayment)
+			user.Post("/{id}/restore", h.RestorePayment)
+		}
+	})
+}
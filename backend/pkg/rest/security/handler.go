@@ -0,0 +1,16 @@
+package security
+
+import (
+	"github.com/payvue/payvue-backend/pkg/domain/security"
+	"github.com/payvue/payvue-backend/pkg/rest"
+)
+
+type handler struct {
+	securityService security.Service
+}
+
+func NewHandler(securityService security.Service) rest.Handler {
+	return &handler{
+		securityService: securityService,
+	}
+}
@@ -0,0 +1,15 @@
+package security
+
+import (
+	"github.com/go-chi/chi/v5"
+	"github.com/payvue/payvue-backend/pkg/rest"
+)
+
+func (h *handler) RouteURLs(router *chi.Mux, mode rest.Mode) {
+	if !mode.Reads() {
+		return
+	}
+	router.Route("/security", func(r chi.Router) {
+		r.Get("/events", h.GetEvents)
+	})
+}
@@ -0,0 +1,46 @@
+package security
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/payvue/payvue-backend/pkg/domain/security"
+	"github.com/payvue/payvue-backend/pkg/rest"
+)
+
+// GetEvents lista los sucesos de seguridad (bloqueos de login) del usuario.
+func (h *handler) GetEvents(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID := rest.UserIDFromRequest(r)
+	if userID <= 0 {
+		rest.RespondProblem(w, r, http.StatusUnauthorized, "unauthorized", "Usuario no identificado")
+		return
+	}
+
+	limit := 0
+	if v := r.URL.Query().Get("limit"); v != "" {
+		var err error
+		if limit, err = strconv.Atoi(v); err != nil || limit < 0 {
+			rest.RespondProblem(w, r, http.StatusBadRequest, "invalid_limit", "limit must be a positive number")
+			return
+		}
+	}
+
+	events, err := h.securityService.GetEvents(ctx, userID, limit)
+	if err != nil {
+		rest.RespondError(w, r, err)
+		return
+	}
+
+	response := security.ToEventListResponse(events)
+	respondWithJSON(w, http.StatusOK, response.Events)
+}
+
+func respondWithJSON(w http.ResponseWriter, code int, payload interface{}) {
+	response, _ := json.Marshal(payload)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	w.Write(response)
+}
@@ -0,0 +1,44 @@
+package rest
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/payvue/payvue-backend/pkg/tracing"
+)
+
+// RequestTracing abre el span de servidor de cada petición, continuando la
+// traza si viene una cabecera traceparent, y devuelve su ID en X-Trace-ID. El
+// span se nombra con el patrón de la ruta al terminar, igual que las métricas.
+// Debe montarse antes de RequestLogger para que el log lleve el trace_id.
+func RequestTracing(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := tracing.Extract(r.Context(), r.Header)
+		ctx, span := tracing.Default().Start(ctx, r.Method, tracing.KindServer,
+			slog.String("http.request.method", r.Method),
+			slog.String("url.path", r.URL.Path),
+			slog.String("http.request_id", middleware.GetReqID(ctx)),
+		)
+		defer span.End()
+
+		w.Header().Set("X-Trace-ID", span.SpanContext().TraceID.String())
+
+		ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+		next.ServeHTTP(ww, r.WithContext(ctx))
+
+		status := ww.Status()
+		if status == 0 {
+			status = http.StatusOK
+		}
+		if routeContext := chi.RouteContext(ctx); routeContext != nil && routeContext.RoutePattern() != "" {
+			span.SetName(r.Method + " " + routeContext.RoutePattern())
+			span.SetAttributes(slog.String("http.route", routeContext.RoutePattern()))
+		}
+		span.SetAttributes(slog.Int("http.response.status_code", status))
+		if status >= http.StatusInternalServerError {
+			span.SetFailed()
+		}
+	})
+}
@@ -0,0 +1,158 @@
+package scheduler
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/payvue/payvue-backend/pkg/tracing"
+	"github.com/payvue/payvue-backend/pkg/utils/logger"
+)
+
+// Job es una tarea periódica. Se ejecuta al arrancar el scheduler y luego
+// cada Interval.
+type Job struct {
+	Name     string
+	Interval time.Duration
+	Run      func(ctx context.Context) error
+}
+
+type Scheduler struct {
+	jobs   []Job
+	logger *slog.Logger
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	mu      sync.Mutex
+	running bool
+	status  map[string]*JobStatus
+}
+
+// JobStatus es el resultado de la última ejecución de un job.
+type JobStatus struct {
+	Name      string     `json:"name"`
+	Interval  string     `json:"interval"`
+	LastRun   *time.Time `json:"last_run,omitempty"`
+	Duration  string     `json:"last_duration,omitempty"`
+	LastError string     `json:"last_error,omitempty"`
+}
+
+// Status es el estado del scheduler para los health checks.
+type Status struct {
+	Running bool        `json:"running"`
+	Jobs    []JobStatus `json:"jobs"`
+}
+
+// New crea el scheduler. Cada job recibe en el contexto el logger con su nombre
+// y cada ejecución abre un span.
+func New(logger *slog.Logger, jobs ...Job) *Scheduler {
+	status := make(map[string]*JobStatus, len(jobs))
+	for _, job := range jobs {
+		status[job.Name] = &JobStatus{Name: job.Name, Interval: job.Interval.String()}
+	}
+	return &Scheduler{
+		jobs:   jobs,
+		logger: logger,
+		status: status,
+	}
+}
+
+func (s *Scheduler) Start(ctx context.Context) {
+	ctx, s.cancel = context.WithCancel(ctx)
+	s.setRunning(true)
+
+	for _, job := range s.jobs {
+		s.wg.Add(1)
+		go s.loop(ctx, job)
+	}
+}
+
+// Stop cancela los jobs y espera a que termine la ejecución en curso.
+func (s *Scheduler) Stop() {
+	if s.cancel != nil {
+		s.cancel()
+	}
+	s.setRunning(false)
+	s.wg.Wait()
+}
+
+// Status devuelve si el scheduler está en marcha y cómo fue la última ejecución
+// de cada job.
+func (s *Scheduler) Status() Status {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	status := Status{Running: s.running, Jobs: make([]JobStatus, 0, len(s.jobs))}
+	for _, job := range s.jobs {
+		status.Jobs = append(status.Jobs, *s.status[job.Name])
+	}
+	return status
+}
+
+func (s *Scheduler) setRunning(running bool) {
+	s.mu.Lock()
+	s.running = running
+	s.mu.Unlock()
+}
+
+// Shutdown es Stop con plazo: si los jobs no terminan antes de que venza ctx
+// devuelve su error sin esperarlos más.
+func (s *Scheduler) Shutdown(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		s.Stop()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (s *Scheduler) loop(ctx context.Context, job Job) {
+	defer s.wg.Done()
+
+	jobLogger := s.logger.With(slog.String("job", job.Name))
+
+	ticker := time.NewTicker(job.Interval)
+	defer ticker.Stop()
+
+	for {
+		s.run(ctx, job, jobLogger)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// run ejecuta el job una vez en su propia traza; el logger del contexto lleva
+// el trace_id para poder relacionar sus mensajes con los spans.
+func (s *Scheduler) run(ctx context.Context, job Job, jobLogger *slog.Logger) {
+	ctx, span := tracing.Start(ctx, "job."+job.Name)
+	defer span.End()
+
+	start := time.Now()
+	runLogger := jobLogger.With(slog.String("trace_id", span.SpanContext().TraceID.String()))
+	err := job.Run(logger.WithContext(ctx, runLogger))
+	if err != nil {
+		span.RecordError(err)
+		runLogger.Error("job failed", slog.Any("error", err))
+	}
+
+	s.mu.Lock()
+	status := s.status[job.Name]
+	status.LastRun = &start
+	status.Duration = time.Since(start).String()
+	status.LastError = ""
+	if err != nil {
+		status.LastError = err.Error()
+	}
+	s.mu.Unlock()
+}
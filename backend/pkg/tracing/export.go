@@ -0,0 +1,227 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Exporter envía un lote de spans terminados. El tracer lo llama siempre desde
+// la misma goroutine.
+type Exporter interface {
+	Export(ctx context.Context, spans []SpanData) error
+}
+
+// stdoutExporter escribe un span por línea en JSON; pensado para desarrollo.
+type stdoutExporter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func NewStdoutExporter(w io.Writer) Exporter {
+	return &stdoutExporter{w: w}
+}
+
+type stdoutSpan struct {
+	TraceID    string                 `json:"trace_id"`
+	SpanID     string                 `json:"span_id"`
+	ParentID   string                 `json:"parent_id,omitempty"`
+	Name       string                 `json:"name"`
+	Start      time.Time              `json:"start"`
+	DurationMS float64                `json:"duration_ms"`
+	Attributes map[string]interface{} `json:"attributes,omitempty"`
+	Error      string                 `json:"error,omitempty"`
+	Failed     bool                   `json:"failed,omitempty"`
+}
+
+func (e *stdoutExporter) Export(ctx context.Context, spans []SpanData) error {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	for _, span := range spans {
+		line := stdoutSpan{
+			TraceID:    span.Context.TraceID.String(),
+			SpanID:     span.Context.SpanID.String(),
+			Name:       span.Name,
+			Start:      span.Start,
+			DurationMS: float64(span.End.Sub(span.Start).Microseconds()) / 1000,
+			Error:      span.Error,
+			Failed:     span.Failed,
+		}
+		if span.ParentID.IsValid() {
+			line.ParentID = span.ParentID.String()
+		}
+		if len(span.Attributes) > 0 {
+			line.Attributes = make(map[string]interface{}, len(span.Attributes))
+			for _, attr := range span.Attributes {
+				line.Attributes[attr.Key] = attr.Value.Resolve().Any()
+			}
+		}
+		if err := encoder.Encode(line); err != nil {
+			return err
+		}
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	_, err := e.w.Write(buf.Bytes())
+	return err
+}
+
+// otlpExporter envía los spans a un colector de OpenTelemetry con OTLP/HTTP en
+// su codificación JSON, que no necesita protobuf.
+type otlpExporter struct {
+	endpoint    string
+	serviceName string
+	client      *http.Client
+}
+
+// NewOTLPExporter envía a endpoint, la URL completa del receptor de trazas
+// (normalmente http://colector:4318/v1/traces).
+func NewOTLPExporter(endpoint, serviceName string) Exporter {
+	return &otlpExporter{
+		endpoint:    endpoint,
+		serviceName: serviceName,
+		client:      &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (e *otlpExporter) Export(ctx context.Context, spans []SpanData) error {
+	body, err := json.Marshal(e.request(spans))
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("otlp collector answered %s", resp.Status)
+	}
+	return nil
+}
+
+// Estructuras de ExportTraceServiceRequest en JSON. Los IDs van en hexadecimal
+// y los tiempos como cadenas, como pide la especificación de OTLP/JSON.
+type (
+	otlpRequest struct {
+		ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+	}
+	otlpResourceSpans struct {
+		Resource   otlpResource     `json:"resource"`
+		ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+	}
+	otlpResource struct {
+		Attributes []otlpAttribute `json:"attributes"`
+	}
+	otlpScopeSpans struct {
+		Scope otlpScope  `json:"scope"`
+		Spans []otlpSpan `json:"spans"`
+	}
+	otlpScope struct {
+		Name string `json:"name"`
+	}
+	otlpSpan struct {
+		TraceID           string          `json:"traceId"`
+		SpanID            string          `json:"spanId"`
+		ParentSpanID      string          `json:"parentSpanId,omitempty"`
+		Name              string          `json:"name"`
+		Kind              Kind            `json:"kind"`
+		StartTimeUnixNano string          `json:"startTimeUnixNano"`
+		EndTimeUnixNano   string          `json:"endTimeUnixNano"`
+		Attributes        []otlpAttribute `json:"attributes,omitempty"`
+		Status            otlpStatus      `json:"status"`
+	}
+	otlpStatus struct {
+		Code    int    `json:"code,omitempty"`
+		Message string `json:"message,omitempty"`
+	}
+	otlpAttribute struct {
+		Key   string    `json:"key"`
+		Value otlpValue `json:"value"`
+	}
+	otlpValue struct {
+		StringValue *string  `json:"stringValue,omitempty"`
+		IntValue    *string  `json:"intValue,omitempty"`
+		DoubleValue *float64 `json:"doubleValue,omitempty"`
+		BoolValue   *bool    `json:"boolValue,omitempty"`
+	}
+)
+
+// statusError es el código de Status de un span fallido en OTLP.
+const statusError = 2
+
+func (e *otlpExporter) request(spans []SpanData) otlpRequest {
+	converted := make([]otlpSpan, 0, len(spans))
+	for _, span := range spans {
+		s := otlpSpan{
+			TraceID:           span.Context.TraceID.String(),
+			SpanID:            span.Context.SpanID.String(),
+			Name:              span.Name,
+			Kind:              span.Kind,
+			StartTimeUnixNano: strconv.FormatInt(span.Start.UnixNano(), 10),
+			EndTimeUnixNano:   strconv.FormatInt(span.End.UnixNano(), 10),
+			Attributes:        otlpAttributes(span.Attributes),
+		}
+		if span.ParentID.IsValid() {
+			s.ParentSpanID = span.ParentID.String()
+		}
+		if span.Failed {
+			s.Status = otlpStatus{Code: statusError, Message: span.Error}
+		}
+		converted = append(converted, s)
+	}
+
+	return otlpRequest{ResourceSpans: []otlpResourceSpans{{
+		Resource: otlpResource{Attributes: otlpAttributes([]slog.Attr{
+			slog.String("service.name", e.serviceName),
+		})},
+		ScopeSpans: []otlpScopeSpans{{
+			Scope: otlpScope{Name: "github.com/payvue/payvue-backend/pkg/tracing"},
+			Spans: converted,
+		}},
+	}}}
+}
+
+func otlpAttributes(attrs []slog.Attr) []otlpAttribute {
+	converted := make([]otlpAttribute, 0, len(attrs))
+	for _, attr := range attrs {
+		value := attr.Value.Resolve()
+		var v otlpValue
+		switch value.Kind() {
+		case slog.KindInt64:
+			s := strconv.FormatInt(value.Int64(), 10)
+			v.IntValue = &s
+		case slog.KindUint64:
+			s := strconv.FormatUint(value.Uint64(), 10)
+			v.IntValue = &s
+		case slog.KindFloat64:
+			f := value.Float64()
+			v.DoubleValue = &f
+		case slog.KindBool:
+			b := value.Bool()
+			v.BoolValue = &b
+		default:
+			s := value.String()
+			v.StringValue = &s
+		}
+		converted = append(converted, otlpAttribute{Key: attr.Key, Value: v})
+	}
+	return converted
+}
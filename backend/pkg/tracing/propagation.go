@@ -0,0 +1,67 @@
+package tracing
+
+import (
+	"context"
+	"encoding/hex"
+	"net/http"
+	"strings"
+)
+
+const traceparentHeader = "traceparent"
+
+// Extract lee la cabecera traceparent de W3C Trace Context. Si es válida, los
+// spans que se abran con el contexto devuelto continúan esa traza.
+func Extract(ctx context.Context, header http.Header) context.Context {
+	sc, ok := ParseTraceparent(header.Get(traceparentHeader))
+	if !ok {
+		return ctx
+	}
+	return context.WithValue(ctx, remoteKey{}, sc)
+}
+
+// Inject escribe en header la cabecera traceparent del span activo de ctx, para
+// continuar la traza en otro servicio.
+func Inject(ctx context.Context, header http.Header) {
+	if sc := parentContext(ctx); sc.IsValid() {
+		header.Set(traceparentHeader, sc.Traceparent())
+	}
+}
+
+// Traceparent devuelve el span en el formato de la cabecera traceparent:
+// "00-<traza>-<span>-<flags>".
+func (sc SpanContext) Traceparent() string {
+	flags := "00"
+	if sc.Sampled {
+		flags = "01"
+	}
+	return "00-" + sc.TraceID.String() + "-" + sc.SpanID.String() + "-" + flags
+}
+
+// ParseTraceparent acepta la versión 00 y, como pide la especificación, las
+// versiones posteriores mientras empiecen con los mismos campos.
+func ParseTraceparent(value string) (SpanContext, bool) {
+	parts := strings.Split(strings.TrimSpace(value), "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || parts[0] == "ff" || (parts[0] == "00" && len(parts) != 4) {
+		return SpanContext{}, false
+	}
+
+	var sc SpanContext
+	if !decodeHex(sc.TraceID[:], parts[1]) || !decodeHex(sc.SpanID[:], parts[2]) || !sc.IsValid() {
+		return SpanContext{}, false
+	}
+	var flags [1]byte
+	if !decodeHex(flags[:], parts[3]) {
+		return SpanContext{}, false
+	}
+	sc.Sampled = flags[0]&1 == 1
+	return sc, true
+}
+
+// decodeHex solo acepta hexadecimal en minúsculas de la longitud exacta de dst.
+func decodeHex(dst []byte, value string) bool {
+	if len(value) != 2*len(dst) || strings.ToLower(value) != value {
+		return false
+	}
+	_, err := hex.Decode(dst, []byte(value))
+	return err == nil
+}
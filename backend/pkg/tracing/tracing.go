@@ -0,0 +1,326 @@
+package tracing
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"log/slog"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// TraceID y SpanID siguen el formato de W3C Trace Context, así que las trazas
+// se pueden enviar a cualquier backend compatible con OpenTelemetry.
+type TraceID [16]byte
+
+func (t TraceID) String() string { return hex.EncodeToString(t[:]) }
+func (t TraceID) IsValid() bool  { return t != TraceID{} }
+
+type SpanID [8]byte
+
+func (s SpanID) String() string { return hex.EncodeToString(s[:]) }
+func (s SpanID) IsValid() bool  { return s != SpanID{} }
+
+// SpanContext es lo que se propaga entre servicios: la traza, el span y si se
+// muestrea.
+type SpanContext struct {
+	TraceID TraceID
+	SpanID  SpanID
+	Sampled bool
+}
+
+func (sc SpanContext) IsValid() bool {
+	return sc.TraceID.IsValid() && sc.SpanID.IsValid()
+}
+
+// Kind tiene los mismos valores que SpanKind en OTLP.
+type Kind int
+
+const (
+	KindInternal Kind = 1
+	KindServer   Kind = 2
+	KindClient   Kind = 3
+)
+
+// SpanData es un span terminado, tal y como lo reciben los exporters.
+type SpanData struct {
+	Name       string
+	Kind       Kind
+	Context    SpanContext
+	ParentID   SpanID
+	Start      time.Time
+	End        time.Time
+	Attributes []slog.Attr
+	// Error es el mensaje del error registrado con RecordError, si lo hubo.
+	Error string
+	// Failed marca el span como fallido aunque no haya mensaje (p. ej. un 5xx).
+	Failed bool
+}
+
+// Span mide una operación. Siempre es distinto de nil y se puede usar desde
+// varias goroutines.
+type Span struct {
+	tracer *Tracer
+	mu     sync.Mutex
+	data   SpanData
+	ended  bool
+}
+
+// SpanContext devuelve los identificadores del span.
+func (s *Span) SpanContext() SpanContext {
+	return s.data.Context
+}
+
+// SetName cambia el nombre del span; el del servidor HTTP solo se conoce al
+// terminar de enrutar.
+func (s *Span) SetName(name string) {
+	s.mu.Lock()
+	s.data.Name = name
+	s.mu.Unlock()
+}
+
+func (s *Span) SetAttributes(attrs ...slog.Attr) {
+	s.mu.Lock()
+	s.data.Attributes = append(s.data.Attributes, attrs...)
+	s.mu.Unlock()
+}
+
+// RecordError marca el span como fallido. No hace nada con err nil.
+func (s *Span) RecordError(err error) {
+	if err == nil {
+		return
+	}
+	s.mu.Lock()
+	s.data.Error = err.Error()
+	s.data.Failed = true
+	s.mu.Unlock()
+}
+
+// SetFailed marca el span como fallido sin error asociado.
+func (s *Span) SetFailed() {
+	s.mu.Lock()
+	s.data.Failed = true
+	s.mu.Unlock()
+}
+
+// End termina el span y lo entrega al exporter si está muestreado. Las llamadas
+// posteriores no hacen nada.
+func (s *Span) End() {
+	s.mu.Lock()
+	if s.ended {
+		s.mu.Unlock()
+		return
+	}
+	s.ended = true
+	s.data.End = time.Now()
+	data := s.data
+	s.mu.Unlock()
+
+	if data.Context.Sampled {
+		s.tracer.export(data)
+	}
+}
+
+// Tracer crea los spans y los manda en lotes a su Exporter. Sin exporter los
+// spans tienen identificadores (para los logs y las cabeceras) pero no se
+// envían a ningún sitio.
+type Tracer struct {
+	exporter Exporter
+	ratio    float64
+	done     chan struct{}
+
+	// mu protege queue de cerrarse mientras se envía a ella.
+	mu      sync.RWMutex
+	queue   chan SpanData
+	stopped bool
+}
+
+const (
+	queueSize     = 2048
+	batchSize     = 512
+	flushInterval = 5 * time.Second
+)
+
+// New crea un tracer que muestrea la fracción ratio (0 a 1) de las trazas que
+// empiezan aquí; las que llegan con traceparent respetan la decisión del
+// llamador. Con exporter nil no envía nada.
+func New(exporter Exporter, ratio float64) *Tracer {
+	t := &Tracer{
+		exporter: exporter,
+		ratio:    ratio,
+		done:     make(chan struct{}),
+	}
+	if exporter == nil {
+		close(t.done)
+		return t
+	}
+	t.queue = make(chan SpanData, queueSize)
+	go t.loop()
+	return t
+}
+
+var defaultTracer atomic.Pointer[Tracer]
+
+func init() {
+	defaultTracer.Store(New(nil, 0))
+}
+
+// SetDefault cambia el tracer que usan Start y el resto de funciones del paquete.
+func SetDefault(t *Tracer) {
+	defaultTracer.Store(t)
+}
+
+// Default devuelve el tracer por defecto.
+func Default() *Tracer {
+	return defaultTracer.Load()
+}
+
+// Start abre un span interno con el tracer por defecto, hijo del span que haya
+// en ctx. Hay que terminarlo con End.
+func Start(ctx context.Context, name string, attrs ...slog.Attr) (context.Context, *Span) {
+	return Default().Start(ctx, name, KindInternal, attrs...)
+}
+
+// Start abre un span hijo del que haya en ctx, ya sea local o el remoto que dejó
+// Extract; si no hay ninguno empieza una traza nueva.
+func (t *Tracer) Start(ctx context.Context, name string, kind Kind, attrs ...slog.Attr) (context.Context, *Span) {
+	parent := parentContext(ctx)
+
+	sc := SpanContext{SpanID: newSpanID()}
+	if parent.IsValid() {
+		sc.TraceID = parent.TraceID
+		sc.Sampled = parent.Sampled
+	} else {
+		sc.TraceID = newTraceID()
+		sc.Sampled = t.sample(sc.TraceID)
+	}
+
+	span := &Span{
+		tracer: t,
+		data: SpanData{
+			Name:       name,
+			Kind:       kind,
+			Context:    sc,
+			ParentID:   parent.SpanID,
+			Start:      time.Now(),
+			Attributes: attrs,
+		},
+	}
+	return context.WithValue(ctx, spanKey{}, span), span
+}
+
+// sample decide con los 8 últimos bytes de la traza, como TraceIDRatioBased de
+// OpenTelemetry, para que todas las instancias tomen la misma decisión.
+func (t *Tracer) sample(id TraceID) bool {
+	switch {
+	case t.exporter == nil || t.ratio <= 0:
+		return false
+	case t.ratio >= 1:
+		return true
+	}
+	return binary.BigEndian.Uint64(id[8:])>>1 < uint64(t.ratio*(1<<63))
+}
+
+func (t *Tracer) export(data SpanData) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	if t.queue == nil || t.stopped {
+		return
+	}
+	// Con la cola llena se descarta el span antes que frenar la petición
+	select {
+	case t.queue <- data:
+	default:
+	}
+}
+
+func (t *Tracer) loop() {
+	defer close(t.done)
+
+	ticker := time.NewTicker(flushInterval)
+	defer ticker.Stop()
+
+	batch := make([]SpanData, 0, batchSize)
+	send := func() {
+		if len(batch) == 0 {
+			return
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		if err := t.exporter.Export(ctx, batch); err != nil {
+			slog.Warn("failed to export spans", slog.Int("spans", len(batch)), slog.Any("error", err))
+		}
+		cancel()
+		batch = make([]SpanData, 0, batchSize)
+	}
+
+	for {
+		select {
+		case data, ok := <-t.queue:
+			if !ok {
+				send()
+				return
+			}
+			batch = append(batch, data)
+			if len(batch) >= batchSize {
+				send()
+			}
+		case <-ticker.C:
+			send()
+		}
+	}
+}
+
+// Shutdown envía los spans pendientes y deja de aceptar nuevos. Si ctx vence
+// antes, devuelve su error y los pendientes se pierden.
+func (t *Tracer) Shutdown(ctx context.Context) error {
+	t.mu.Lock()
+	if t.queue != nil && !t.stopped {
+		t.stopped = true
+		close(t.queue)
+	}
+	t.mu.Unlock()
+	select {
+	case <-t.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+type spanKey struct{}
+
+type remoteKey struct{}
+
+// SpanFromContext devuelve el span activo de ctx, o nil si no hay ninguno.
+func SpanFromContext(ctx context.Context) *Span {
+	span, _ := ctx.Value(spanKey{}).(*Span)
+	return span
+}
+
+func parentContext(ctx context.Context) SpanContext {
+	if span := SpanFromContext(ctx); span != nil {
+		return span.SpanContext()
+	}
+	sc, _ := ctx.Value(remoteKey{}).(SpanContext)
+	return sc
+}
+
+// math/rand basta: los identificadores tienen que ser únicos, no secretos.
+func newTraceID() TraceID {
+	var id TraceID
+	for !id.IsValid() {
+		binary.BigEndian.PutUint64(id[:8], rand.Uint64())
+		binary.BigEndian.PutUint64(id[8:], rand.Uint64())
+	}
+	return id
+}
+
+func newSpanID() SpanID {
+	var id SpanID
+	for !id.IsValid() {
+		binary.BigEndian.PutUint64(id[:], rand.Uint64())
+	}
+	return id
+}
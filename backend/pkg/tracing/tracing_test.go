@@ -0,0 +1,311 @@
+package tracing
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// recordingExporter guarda los spans que recibe.
+type recordingExporter struct {
+	mu    sync.Mutex
+	spans []SpanData
+}
+
+func (e *recordingExporter) Export(ctx context.Context, spans []SpanData) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.spans = append(e.spans, spans...)
+	return nil
+}
+
+func shutdown(t *testing.T, tracer *Tracer) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := tracer.Shutdown(ctx); err != nil {
+		t.Fatal(err)
+	}
+}
+
+const (
+	traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	spanID  = "00f067aa0ba902b7"
+)
+
+func TestParseTraceparent(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		ok      bool
+		sampled bool
+	}{
+		{"sampled", "00-" + traceID + "-" + spanID + "-01", true, true},
+		{"not sampled", "00-" + traceID + "-" + spanID + "-00", true, false},
+		{"other flags", "00-" + traceID + "-" + spanID + "-09", true, true},
+		{"surrounding spaces", " 00-" + traceID + "-" + spanID + "-01 ", true, true},
+		{"later version with more fields", "01-" + traceID + "-" + spanID + "-01-extra", true, true},
+		{"version ff", "ff-" + traceID + "-" + spanID + "-01", false, false},
+		{"version 00 with more fields", "00-" + traceID + "-" + spanID + "-01-extra", false, false},
+		{"uppercase", "00-4BF92F3577B34DA6A3CE929D0E0E4736-" + spanID + "-01", false, false},
+		{"zero trace", "00-00000000000000000000000000000000-" + spanID + "-01", false, false},
+		{"zero span", "00-" + traceID + "-0000000000000000-01", false, false},
+		{"short trace", "00-" + traceID[2:] + "-" + spanID + "-01", false, false},
+		{"long span", "00-" + traceID + "-" + spanID + "00-01", false, false},
+		{"invalid flags", "00-" + traceID + "-" + spanID + "-zz", false, false},
+		{"missing flags", "00-" + traceID + "-" + spanID, false, false},
+		{"long version", "000-" + traceID + "-" + spanID + "-01", false, false},
+		{"empty", "", false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sc, ok := ParseTraceparent(tt.value)
+			if ok != tt.ok {
+				t.Fatalf("ParseTraceparent(%q) ok = %v, want %v", tt.value, ok, tt.ok)
+			}
+			if !ok {
+				if sc != (SpanContext{}) {
+					t.Errorf("ParseTraceparent(%q) = %+v, want the zero value", tt.value, sc)
+				}
+				return
+			}
+			if sc.TraceID.String() != traceID || sc.SpanID.String() != spanID || sc.Sampled != tt.sampled {
+				t.Errorf("ParseTraceparent(%q) = %+v", tt.value, sc)
+			}
+		})
+	}
+}
+
+func TestTraceparentRoundTrip(t *testing.T) {
+	for _, value := range []string{"00-" + traceID + "-" + spanID + "-01", "00-" + traceID + "-" + spanID + "-00"} {
+		sc, ok := ParseTraceparent(value)
+		if !ok {
+			t.Fatalf("ParseTraceparent(%q) failed", value)
+		}
+		if got := sc.Traceparent(); got != value {
+			t.Errorf("Traceparent() = %q, want %q", got, value)
+		}
+	}
+}
+
+func TestExtractAndInject(t *testing.T) {
+	tracer := New(&recordingExporter{}, 0)
+	defer shutdown(t, tracer)
+
+	incoming := http.Header{}
+	incoming.Set("traceparent", "00-"+traceID+"-"+spanID+"-01")
+	ctx, span := tracer.Start(Extract(context.Background(), incoming), "GET /", KindServer)
+
+	// Se continúa la traza y la decisión de muestreo del llamador, aunque el
+	// ratio local sea 0
+	sc := span.SpanContext()
+	if sc.TraceID.String() != traceID || !sc.Sampled || sc.SpanID.String() == spanID {
+		t.Errorf("span context = %+v, want a new span in the incoming sampled trace", sc)
+	}
+	if span.data.ParentID.String() != spanID {
+		t.Errorf("ParentID = %s, want %s", span.data.ParentID, spanID)
+	}
+
+	outgoing := http.Header{}
+	Inject(ctx, outgoing)
+	if got, want := outgoing.Get("traceparent"), "00-"+traceID+"-"+sc.SpanID.String()+"-01"; got != want {
+		t.Errorf("Inject traceparent = %q, want %q", got, want)
+	}
+
+	// Sin cabecera o con una inválida empieza una traza nueva y no se inyecta nada
+	invalid := http.Header{}
+	invalid.Set("traceparent", "00-"+traceID+"-"+spanID)
+	_, root := tracer.Start(Extract(context.Background(), invalid), "GET /", KindServer)
+	if root.SpanContext().TraceID.String() == traceID || root.data.ParentID.IsValid() {
+		t.Errorf("span with an invalid traceparent = %+v, want a new trace", root.data)
+	}
+	empty := http.Header{}
+	Inject(context.Background(), empty)
+	if len(empty) != 0 {
+		t.Errorf("Inject without a span wrote %v", empty)
+	}
+}
+
+func TestSampling(t *testing.T) {
+	low, high := TraceID{0: 1}, TraceID{0: 1}
+	for i := 8; i < 16; i++ {
+		high[i] = 0xff
+	}
+
+	tests := []struct {
+		name     string
+		exporter Exporter
+		ratio    float64
+		id       TraceID
+		want     bool
+	}{
+		{"no exporter", nil, 1, low, false},
+		{"ratio 0", &recordingExporter{}, 0, low, false},
+		{"negative ratio", &recordingExporter{}, -1, low, false},
+		{"ratio 1", &recordingExporter{}, 1, high, true},
+		{"ratio above 1", &recordingExporter{}, 2, high, true},
+		{"half, low trace id", &recordingExporter{}, 0.5, low, true},
+		{"half, high trace id", &recordingExporter{}, 0.5, high, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tracer := New(tt.exporter, tt.ratio)
+			defer shutdown(t, tracer)
+			if got := tracer.sample(tt.id); got != tt.want {
+				t.Errorf("sample(%s) = %v, want %v", tt.id, got, tt.want)
+			}
+		})
+	}
+
+	// La decisión va por el identificador, así que es la misma en cada instancia
+	a, b := New(&recordingExporter{}, 0.25), New(&recordingExporter{}, 0.25)
+	defer shutdown(t, a)
+	defer shutdown(t, b)
+	for i := 0; i < 100; i++ {
+		id := newTraceID()
+		if a.sample(id) != b.sample(id) {
+			t.Fatalf("two tracers disagree on %s", id)
+		}
+	}
+}
+
+func TestOnlySampledSpansAreExported(t *testing.T) {
+	exporter := &recordingExporter{}
+	tracer := New(exporter, 1)
+
+	ctx, parent := tracer.Start(context.Background(), "parent", KindInternal)
+	_, child := tracer.Start(ctx, "child", KindInternal)
+	child.End()
+	child.End()
+	parent.End()
+
+	notSampled := http.Header{}
+	notSampled.Set("traceparent", "00-"+traceID+"-"+spanID+"-00")
+	_, dropped := tracer.Start(Extract(context.Background(), notSampled), "dropped", KindServer)
+	dropped.End()
+
+	shutdown(t, tracer)
+	if len(exporter.spans) != 2 || exporter.spans[0].Name != "child" || exporter.spans[1].Name != "parent" {
+		t.Fatalf("exported %+v, want child and parent once", exporter.spans)
+	}
+	if exporter.spans[0].ParentID != parent.SpanContext().SpanID || exporter.spans[0].Context.TraceID != parent.SpanContext().TraceID {
+		t.Errorf("child = %+v, want a child of %+v", exporter.spans[0], parent.SpanContext())
+	}
+}
+
+func TestOTLPExporterPayload(t *testing.T) {
+	var (
+		body        []byte
+		contentType string
+	)
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		contentType = r.Header.Get("Content-Type")
+		body, _ = io.ReadAll(r.Body)
+	}))
+	defer collector.Close()
+
+	start := time.Unix(1700000000, 123)
+	parent, _ := ParseTraceparent("00-" + traceID + "-" + spanID + "-01")
+	span := SpanData{
+		Name:     "GET /finances/debt",
+		Kind:     KindServer,
+		Context:  SpanContext{TraceID: parent.TraceID, SpanID: SpanID{1, 2, 3, 4, 5, 6, 7, 8}, Sampled: true},
+		ParentID: parent.SpanID,
+		Start:    start,
+		End:      start.Add(time.Second),
+		Attributes: []slog.Attr{
+			slog.String("http.method", "GET"),
+			slog.Int("http.status_code", 500),
+			slog.Float64("ratio", 0.5),
+			slog.Bool("cached", false),
+		},
+		Error:  "boom",
+		Failed: true,
+	}
+
+	exporter := NewOTLPExporter(collector.URL, "payvue-test")
+	if err := exporter.Export(context.Background(), []SpanData{span}); err != nil {
+		t.Fatal(err)
+	}
+	if contentType != "application/json" {
+		t.Errorf("Content-Type = %q", contentType)
+	}
+
+	var got map[string]interface{}
+	if err := json.Unmarshal(body, &got); err != nil {
+		t.Fatalf("payload %s: %v", body, err)
+	}
+	want := map[string]interface{}{
+		"resourceSpans": []interface{}{map[string]interface{}{
+			"resource": map[string]interface{}{"attributes": []interface{}{
+				map[string]interface{}{"key": "service.name", "value": map[string]interface{}{"stringValue": "payvue-test"}},
+			}},
+			"scopeSpans": []interface{}{map[string]interface{}{
+				"scope": map[string]interface{}{"name": "github.com/payvue/payvue-backend/pkg/tracing"},
+				"spans": []interface{}{map[string]interface{}{
+					"traceId":           traceID,
+					"spanId":            "0102030405060708",
+					"parentSpanId":      spanID,
+					"name":              "GET /finances/debt",
+					"kind":              float64(KindServer),
+					"startTimeUnixNano": "1700000000000000123",
+					"endTimeUnixNano":   "1700000001000000123",
+					"attributes": []interface{}{
+						map[string]interface{}{"key": "http.method", "value": map[string]interface{}{"stringValue": "GET"}},
+						map[string]interface{}{"key": "http.status_code", "value": map[string]interface{}{"intValue": "500"}},
+						map[string]interface{}{"key": "ratio", "value": map[string]interface{}{"doubleValue": 0.5}},
+						map[string]interface{}{"key": "cached", "value": map[string]interface{}{"boolValue": false}},
+					},
+					"status": map[string]interface{}{"code": float64(statusError), "message": "boom"},
+				}},
+			}},
+		}},
+	}
+	gotJSON, _ := json.MarshalIndent(got, "", "  ")
+	wantJSON, _ := json.MarshalIndent(want, "", "  ")
+	if string(gotJSON) != string(wantJSON) {
+		t.Errorf("payload =\n%s\nwant\n%s", gotJSON, wantJSON)
+	}
+}
+
+func TestOTLPExporterRootSpanAndErrors(t *testing.T) {
+	var body []byte
+	status := http.StatusOK
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ = io.ReadAll(r.Body)
+		w.WriteHeader(status)
+	}))
+	defer collector.Close()
+
+	exporter := NewOTLPExporter(collector.URL, "payvue-test")
+	root := SpanData{Name: "job", Kind: KindInternal, Context: SpanContext{TraceID: TraceID{1}, SpanID: SpanID{1}, Sampled: true}}
+	if err := exporter.Export(context.Background(), []SpanData{root}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Un span raíz sin fallo no lleva parentSpanId ni código de estado
+	var payload otlpRequest
+	if err := json.Unmarshal(body, &payload); err != nil {
+		t.Fatal(err)
+	}
+	span := payload.ResourceSpans[0].ScopeSpans[0].Spans[0]
+	if span.ParentSpanID != "" || span.Status != (otlpStatus{}) || span.Attributes != nil {
+		t.Errorf("root span = %+v", span)
+	}
+
+	status = http.StatusServiceUnavailable
+	if err := exporter.Export(context.Background(), []SpanData{root}); err == nil {
+		t.Error("Export ignored a 503 from the collector")
+	}
+
+	unreachable := NewOTLPExporter("http://127.0.0.1:1/v1/traces", "payvue-test")
+	if err := unreachable.Export(context.Background(), []SpanData{root}); err == nil {
+		t.Error("Export to an unreachable collector succeeded")
+	}
+}
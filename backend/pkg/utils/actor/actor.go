@@ -0,0 +1,22 @@
+package actor
+
+import "context"
+
+// Actor identifica quién origina una petición: el usuario, el request ID
+// asignado por el router y la IP de origen.
+type Actor struct {
+	UserID    int
+	RequestID string
+	IP        string
+}
+
+type contextKey struct{}
+
+func WithActor(ctx context.Context, a Actor) context.Context {
+	return context.WithValue(ctx, contextKey{}, a)
+}
+
+func FromContext(ctx context.Context) Actor {
+	a, _ := ctx.Value(contextKey{}).(Actor)
+	return a
+}
@@ -7,18 +7,26 @@ import (
 	"os"
 	"path/filepath"
 	"time"
-)
 
-const (
-	UploadFolder = "./uploads"
-	MaxFileSize  = 10 << 20 // 10 MB
+	"github.com/payvue/payvue-backend/pkg/metrics"
 )
 
-func init() {
-	// Crear carpeta de uploads si no existe
-	if err := os.MkdirAll(UploadFolder, os.ModePerm); err != nil {
-		fmt.Printf("Error creating uploads folder: %v\n", err)
+const MaxFileSize = 10 << 20 // 10 MB
+
+var uploadFolder = "./uploads"
+
+var uploadSize = metrics.Default.NewHistogram("payvue_upload_size_bytes",
+	"Tamaño de los recibos subidos.",
+	[]float64{10 << 10, 100 << 10, 500 << 10, 1 << 20, 2 << 20, 5 << 20, 10 << 20})
+
+// Init fija la carpeta de los recibos y la crea si no existe. Se llama una vez
+// al arrancar, antes de servir peticiones.
+func Init(dir string) error {
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return fmt.Errorf("error creating uploads folder: %w", err)
 	}
+	uploadFolder = dir
+	return nil
 }
 
 func SaveFile(file multipart.File, header *multipart.FileHeader) (string, error) {
@@ -27,7 +35,7 @@ func SaveFile(file multipart.File, header *multipart.FileHeader) (string, error)
 	filename := fmt.Sprintf("%d_%s", timestamp, header.Filename)
 
 	// Crear archivo destino
-	dstPath := filepath.Join(UploadFolder, filename)
+	dstPath := filepath.Join(uploadFolder, filename)
 	dst, err := os.Create(dstPath)
 	if err != nil {
 		return "", fmt.Errorf("error creating file: %w", err)
@@ -35,14 +43,35 @@ func SaveFile(file multipart.File, header *multipart.FileHeader) (string, error)
 	defer dst.Close()
 
 	// Copiar contenido
-	_, err = io.Copy(dst, file)
+	size, err := io.Copy(dst, file)
 	if err != nil {
 		return "", fmt.Errorf("error copying file: %w", err)
 	}
+	uploadSize.Observe(float64(size))
 
 	return filename, nil
 }
 
+// CheckWritable comprueba que se pueden guardar recibos creando y borrando un
+// fichero vacío en la carpeta.
+func CheckWritable() error {
+	probe, err := os.CreateTemp(uploadFolder, ".healthcheck-*")
+	if err != nil {
+		return fmt.Errorf("uploads folder is not writable: %w", err)
+	}
+	probe.Close()
+	return os.Remove(probe.Name())
+}
+
 func GetFilePath(filename string) string {
-	return filepath.Join(UploadFolder, filename)
+	return filepath.Join(uploadFolder, filename)
+}
+
+// DeleteFile borra un recibo del disco; no falla si ya no existe.
+func DeleteFile(filename string) error {
+	err := os.Remove(GetFilePath(filename))
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("error deleting file: %w", err)
+	}
+	return nil
 }
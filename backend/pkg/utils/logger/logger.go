@@ -0,0 +1,69 @@
+package logger
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"strings"
+	"sync"
+)
+
+// New crea el logger del servicio con format "json" o "text". El nivel es un
+// slog.Leveler para poder cambiarlo en caliente con un *slog.LevelVar.
+func New(w io.Writer, level slog.Leveler, format string) *slog.Logger {
+	options := &slog.HandlerOptions{Level: level}
+	if strings.EqualFold(format, "json") {
+		return slog.New(slog.NewJSONHandler(w, options))
+	}
+	return slog.New(slog.NewTextHandler(w, options))
+}
+
+// ParseLevel acepta debug, info, warn o error; info si no se reconoce.
+func ParseLevel(level string) slog.Level {
+	switch strings.ToLower(level) {
+	case "debug":
+		return slog.LevelDebug
+	case "warn", "warning":
+		return slog.LevelWarn
+	case "error":
+		return slog.LevelError
+	default:
+		return slog.LevelInfo
+	}
+}
+
+type loggerKey struct{}
+
+// entry permite añadir campos a un logger ya guardado en el contexto: el
+// middleware de la petición lo crea y los que vienen detrás (p. ej. el que
+// resuelve el usuario) le añaden sus campos.
+type entry struct {
+	mu     sync.Mutex
+	logger *slog.Logger
+}
+
+// WithContext guarda el logger en el contexto.
+func WithContext(ctx context.Context, logger *slog.Logger) context.Context {
+	return context.WithValue(ctx, loggerKey{}, &entry{logger: logger})
+}
+
+// FromContext devuelve el logger del contexto o, si no hay ninguno, el de slog
+// por defecto.
+func FromContext(ctx context.Context) *slog.Logger {
+	if e, ok := ctx.Value(loggerKey{}).(*entry); ok {
+		e.mu.Lock()
+		defer e.mu.Unlock()
+		return e.logger
+	}
+	return slog.Default()
+}
+
+// AddAttrs añade campos al logger del contexto; los verán todos los mensajes
+// posteriores que usen ese contexto. Sin logger en el contexto no hace nada.
+func AddAttrs(ctx context.Context, args ...any) {
+	if e, ok := ctx.Value(loggerKey{}).(*entry); ok {
+		e.mu.Lock()
+		e.logger = e.logger.With(args...)
+		e.mu.Unlock()
+	}
+}
@@ -0,0 +1,109 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/smtp"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer envía correos transaccionales. Las implementaciones de log y de fichero
+// sirven para desarrollo: el correo no sale de la máquina.
+type Mailer interface {
+	Send(ctx context.Context, message Message) error
+}
+
+type logMailer struct {
+	logger *slog.Logger
+}
+
+// NewLogMailer escribe los correos en el log del servicio.
+func NewLogMailer(logger *slog.Logger) Mailer {
+	return logMailer{logger: logger}
+}
+
+func (m logMailer) Send(ctx context.Context, message Message) error {
+	m.logger.InfoContext(ctx, "mail", slog.String("to", message.To), slog.String("subject", message.Subject), slog.String("body", message.Body))
+	return nil
+}
+
+type fileMailer struct {
+	dir string
+}
+
+// NewFileMailer guarda cada correo como un fichero .eml en dir.
+func NewFileMailer(dir string) (Mailer, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &fileMailer{dir: dir}, nil
+}
+
+func (m *fileMailer) Send(ctx context.Context, message Message) error {
+	name := fmt.Sprintf("%d_%s.eml", time.Now().UnixNano(), sanitize(message.To))
+	return os.WriteFile(filepath.Join(m.dir, name), format("", message), 0o600)
+}
+
+type SMTPConfig struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+}
+
+type smtpMailer struct {
+	config SMTPConfig
+}
+
+// NewSMTPMailer envía los correos a través de un servidor SMTP. Si hay usuario,
+// se autentica con PLAIN, que net/smtp solo permite sobre TLS o contra localhost.
+func NewSMTPMailer(config SMTPConfig) Mailer {
+	return &smtpMailer{config: config}
+}
+
+func (m *smtpMailer) Send(ctx context.Context, message Message) error {
+	addr := net.JoinHostPort(m.config.Host, fmt.Sprint(m.config.Port))
+
+	var auth smtp.Auth
+	if m.config.Username != "" {
+		auth = smtp.PlainAuth("", m.config.Username, m.config.Password, m.config.Host)
+	}
+
+	return smtp.SendMail(addr, auth, m.config.From, []string{message.To}, format(m.config.From, message))
+}
+
+func format(from string, message Message) []byte {
+	var b strings.Builder
+	if from != "" {
+		fmt.Fprintf(&b, "From: %s\r\n", from)
+	}
+	fmt.Fprintf(&b, "To: %s\r\n", message.To)
+	fmt.Fprintf(&b, "Subject: %s\r\n", message.Subject)
+	fmt.Fprintf(&b, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n\r\n")
+	b.WriteString(strings.ReplaceAll(message.Body, "\n", "\r\n"))
+	return []byte(b.String())
+}
+
+func sanitize(address string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '.', r == '-', r == '_':
+			return r
+		}
+		return '_'
+	}, address)
+}
@@ -0,0 +1,67 @@
+package mergepatch
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+)
+
+var ErrInvalidPatch = errors.New("invalid merge patch")
+
+// ContentType es el media type de JSON Merge Patch (RFC 7386).
+const ContentType = "application/merge-patch+json"
+
+// Apply aplica un JSON Merge Patch (RFC 7386) sobre doc y devuelve el documento resultante.
+func Apply(doc, patch []byte) ([]byte, error) {
+	patchValue, err := decode(patch)
+	if err != nil {
+		return nil, ErrInvalidPatch
+	}
+
+	var docValue interface{}
+	if len(bytes.TrimSpace(doc)) > 0 {
+		if docValue, err = decode(doc); err != nil {
+			return nil, err
+		}
+	}
+
+	return json.Marshal(merge(docValue, patchValue))
+}
+
+func merge(target, patch interface{}) interface{} {
+	patchObject, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+
+	targetObject, ok := target.(map[string]interface{})
+	if !ok {
+		targetObject = map[string]interface{}{}
+	}
+
+	for key, value := range patchObject {
+		if value == nil {
+			delete(targetObject, key)
+			continue
+		}
+		targetObject[key] = merge(targetObject[key], value)
+	}
+
+	return targetObject
+}
+
+// decode conserva los números como json.Number para no perder precisión al re-serializar.
+func decode(data []byte) (interface{}, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+	if decoder.More() {
+		return nil, ErrInvalidPatch
+	}
+
+	return value, nil
+}
@@ -0,0 +1,199 @@
+package oidc
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"math/big"
+	"strings"
+)
+
+type jwtHeader struct {
+	Algorithm string `json:"alg"`
+	KeyID     string `json:"kid"`
+}
+
+type jwt struct {
+	header    jwtHeader
+	payload   []byte
+	signed    string
+	signature []byte
+}
+
+func parseJWT(raw string) (*jwt, error) {
+	parts := strings.Split(raw, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("%w: malformed token", ErrInvalidIDToken)
+	}
+
+	headerJSON, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed header", ErrInvalidIDToken)
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed payload", ErrInvalidIDToken)
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed signature", ErrInvalidIDToken)
+	}
+
+	var header jwtHeader
+	if err := json.Unmarshal(headerJSON, &header); err != nil {
+		return nil, fmt.Errorf("%w: malformed header", ErrInvalidIDToken)
+	}
+
+	return &jwt{
+		header:    header,
+		payload:   payload,
+		signed:    parts[0] + "." + parts[1],
+		signature: signature,
+	}, nil
+}
+
+// verifySignature solo admite algoritmos asimétricos; "none" y HS* se rechazan.
+func (t *jwt) verifySignature(key interface{}) error {
+	algorithm := t.header.Algorithm
+	if len(algorithm) != 5 {
+		return fmt.Errorf("%w: unsupported alg %q", ErrInvalidIDToken, algorithm)
+	}
+
+	var (
+		h      hash.Hash
+		hashID crypto.Hash
+	)
+	switch algorithm[2:] {
+	case "256":
+		h, hashID = sha256.New(), crypto.SHA256
+	case "384":
+		h, hashID = sha512.New384(), crypto.SHA384
+	case "512":
+		h, hashID = sha512.New(), crypto.SHA512
+	default:
+		return fmt.Errorf("%w: unsupported alg %q", ErrInvalidIDToken, algorithm)
+	}
+	h.Write([]byte(t.signed))
+	digest := h.Sum(nil)
+
+	switch algorithm[:2] {
+	case "RS":
+		publicKey, ok := key.(*rsa.PublicKey)
+		if ok && rsa.VerifyPKCS1v15(publicKey, hashID, digest, t.signature) == nil {
+			return nil
+		}
+	case "PS":
+		publicKey, ok := key.(*rsa.PublicKey)
+		if ok && rsa.VerifyPSS(publicKey, hashID, digest, t.signature, nil) == nil {
+			return nil
+		}
+	case "ES":
+		publicKey, ok := key.(*ecdsa.PublicKey)
+		if !ok {
+			break
+		}
+		size := (publicKey.Curve.Params().BitSize + 7) / 8
+		if len(t.signature) == 2*size {
+			r := new(big.Int).SetBytes(t.signature[:size])
+			s := new(big.Int).SetBytes(t.signature[size:])
+			if ecdsa.Verify(publicKey, digest, r, s) {
+				return nil
+			}
+		}
+	default:
+		return fmt.Errorf("%w: unsupported alg %q", ErrInvalidIDToken, algorithm)
+	}
+
+	return fmt.Errorf("%w: bad signature", ErrInvalidIDToken)
+}
+
+type jsonWebKey struct {
+	KeyType string `json:"kty"`
+	KeyID   string `json:"kid"`
+	Use     string `json:"use"`
+	N       string `json:"n"`
+	E       string `json:"e"`
+	Curve   string `json:"crv"`
+	X       string `json:"x"`
+	Y       string `json:"y"`
+}
+
+type jsonWebKeySet struct {
+	Keys []jsonWebKey `json:"keys"`
+}
+
+type keySet struct {
+	keys map[string]interface{}
+}
+
+// parse ignora las claves que no sabe interpretar o que no son de firma.
+func (s jsonWebKeySet) parse() *keySet {
+	set := &keySet{keys: map[string]interface{}{}}
+	for _, jwk := range s.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		if key := jwk.publicKey(); key != nil {
+			set.keys[jwk.KeyID] = key
+		}
+	}
+	return set
+}
+
+// find admite un kid vacío solo si el proveedor publica una única clave.
+func (s *keySet) find(keyID string) (interface{}, bool) {
+	if key, ok := s.keys[keyID]; ok {
+		return key, true
+	}
+	if keyID == "" && len(s.keys) == 1 {
+		for _, key := range s.keys {
+			return key, true
+		}
+	}
+	return nil, false
+}
+
+func (k jsonWebKey) publicKey() interface{} {
+	switch k.KeyType {
+	case "RSA":
+		n, errN := base64.RawURLEncoding.DecodeString(k.N)
+		e, errE := base64.RawURLEncoding.DecodeString(k.E)
+		if errN != nil || errE != nil || len(e) == 0 || len(e) > 4 {
+			return nil
+		}
+		return &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Curve {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil
+		}
+		x, errX := base64.RawURLEncoding.DecodeString(k.X)
+		y, errY := base64.RawURLEncoding.DecodeString(k.Y)
+		if errX != nil || errY != nil {
+			return nil
+		}
+		key := &ecdsa.PublicKey{Curve: curve, X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+		if !curve.IsOnCurve(key.X, key.Y) {
+			return nil
+		}
+		return key
+	}
+	return nil
+}
@@ -0,0 +1,360 @@
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	ErrDiscovery      = errors.New("oidc discovery failed")
+	ErrExchange       = errors.New("oidc code exchange failed")
+	ErrInvalidIDToken = errors.New("invalid id token")
+)
+
+// DefaultScopes son los scopes que se piden si la configuración no indica otros.
+var DefaultScopes = []string{"openid", "email", "profile"}
+
+type Config struct {
+	// Name identifica al proveedor en user_identities, p. ej. "google".
+	Name         string
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+	HTTPClient   *http.Client
+}
+
+// Metadata es la parte del documento de descubrimiento que se usa.
+type Metadata struct {
+	Issuer                string   `json:"issuer"`
+	AuthorizationEndpoint string   `json:"authorization_endpoint"`
+	TokenEndpoint         string   `json:"token_endpoint"`
+	JWKSURI               string   `json:"jwks_uri"`
+	TokenAuthMethods      []string `json:"token_endpoint_auth_methods_supported"`
+}
+
+// Claims son los datos del ID token ya verificados.
+type Claims struct {
+	Issuer        string   `json:"iss"`
+	Subject       string   `json:"sub"`
+	Audience      audience `json:"aud"`
+	AuthorizedFor string   `json:"azp"`
+	Expiry        int64    `json:"exp"`
+	IssuedAt      int64    `json:"iat"`
+	Nonce         string   `json:"nonce"`
+	Email         string   `json:"email"`
+	EmailVerified boolean  `json:"email_verified"`
+	Name          string   `json:"name"`
+}
+
+// Provider implementa el flujo authorization code con PKCE contra un proveedor
+// OpenID Connect. El descubrimiento se hace en el primer uso y se cachea.
+type Provider struct {
+	config Config
+	client *http.Client
+
+	mu       sync.Mutex
+	metadata *Metadata
+	keys     *keySet
+}
+
+func NewProvider(config Config) *Provider {
+	if len(config.Scopes) == 0 {
+		config.Scopes = DefaultScopes
+	}
+	config.Issuer = strings.TrimSuffix(config.Issuer, "/")
+
+	client := config.HTTPClient
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+
+	return &Provider{
+		config: config,
+		client: client,
+	}
+}
+
+func (p *Provider) Name() string {
+	return p.config.Name
+}
+
+// AuthCodeURL construye la URL de autorización a la que se redirige al usuario.
+func (p *Provider) AuthCodeURL(ctx context.Context, state, nonce, codeVerifier string) (string, error) {
+	metadata, err := p.discover(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	params := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {p.config.ClientID},
+		"redirect_uri":          {p.config.RedirectURL},
+		"scope":                 {strings.Join(p.config.Scopes, " ")},
+		"state":                 {state},
+		"nonce":                 {nonce},
+		"code_challenge":        {CodeChallenge(codeVerifier)},
+		"code_challenge_method": {"S256"},
+	}
+
+	separator := "?"
+	if strings.Contains(metadata.AuthorizationEndpoint, "?") {
+		separator = "&"
+	}
+	return metadata.AuthorizationEndpoint + separator + params.Encode(), nil
+}
+
+// Exchange canjea el código de autorización y devuelve los claims del ID token
+// después de verificar firma, emisor, audiencia, caducidad y nonce.
+func (p *Provider) Exchange(ctx context.Context, code, codeVerifier, nonce string) (*Claims, error) {
+	metadata, err := p.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {p.config.RedirectURL},
+		"code_verifier": {codeVerifier},
+		"client_id":     {p.config.ClientID},
+	}
+
+	useBasicAuth := p.config.ClientSecret != "" && supportsBasicAuth(metadata.TokenAuthMethods)
+	if p.config.ClientSecret != "" && !useBasicAuth {
+		form.Set("client_secret", p.config.ClientSecret)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, metadata.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrExchange, err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if useBasicAuth {
+		req.SetBasicAuth(url.QueryEscape(p.config.ClientID), url.QueryEscape(p.config.ClientSecret))
+	}
+
+	var token struct {
+		IDToken          string `json:"id_token"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	status, err := p.getJSON(req, &token)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrExchange, err)
+	}
+	if status != http.StatusOK || token.Error != "" {
+		return nil, fmt.Errorf("%w: %d %s %s", ErrExchange, status, token.Error, token.ErrorDescription)
+	}
+	if token.IDToken == "" {
+		return nil, fmt.Errorf("%w: response without id_token", ErrExchange)
+	}
+
+	return p.verify(ctx, metadata, token.IDToken, nonce)
+}
+
+func (p *Provider) verify(ctx context.Context, metadata *Metadata, rawIDToken, nonce string) (*Claims, error) {
+	token, err := parseJWT(rawIDToken)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := p.key(ctx, metadata, token.header.KeyID)
+	if err != nil {
+		return nil, err
+	}
+	if err := token.verifySignature(key); err != nil {
+		return nil, err
+	}
+
+	var claims Claims
+	if err := json.Unmarshal(token.payload, &claims); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidIDToken, err)
+	}
+
+	// Margen para relojes algo desincronizados
+	const leeway = time.Minute
+	now := time.Now()
+
+	switch {
+	case claims.Issuer != metadata.Issuer:
+		return nil, fmt.Errorf("%w: unexpected issuer %q", ErrInvalidIDToken, claims.Issuer)
+	case !claims.Audience.contains(p.config.ClientID):
+		return nil, fmt.Errorf("%w: token not issued for this client", ErrInvalidIDToken)
+	case len(claims.Audience) > 1 && claims.AuthorizedFor != p.config.ClientID:
+		return nil, fmt.Errorf("%w: unexpected azp %q", ErrInvalidIDToken, claims.AuthorizedFor)
+	case now.After(time.Unix(claims.Expiry, 0).Add(leeway)):
+		return nil, fmt.Errorf("%w: token expired", ErrInvalidIDToken)
+	case claims.Nonce != nonce:
+		return nil, fmt.Errorf("%w: nonce mismatch", ErrInvalidIDToken)
+	case claims.Subject == "":
+		return nil, fmt.Errorf("%w: missing subject", ErrInvalidIDToken)
+	}
+
+	return &claims, nil
+}
+
+func (p *Provider) discover(ctx context.Context) (*Metadata, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.metadata != nil {
+		return p.metadata, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.config.Issuer+"/.well-known/openid-configuration", nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDiscovery, err)
+	}
+
+	var metadata Metadata
+	status, err := p.getJSON(req, &metadata)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDiscovery, err)
+	}
+	if status != http.StatusOK {
+		return nil, fmt.Errorf("%w: status %d", ErrDiscovery, status)
+	}
+	if strings.TrimSuffix(metadata.Issuer, "/") != p.config.Issuer {
+		return nil, fmt.Errorf("%w: issuer %q does not match %q", ErrDiscovery, metadata.Issuer, p.config.Issuer)
+	}
+	if metadata.AuthorizationEndpoint == "" || metadata.TokenEndpoint == "" || metadata.JWKSURI == "" {
+		return nil, fmt.Errorf("%w: incomplete provider metadata", ErrDiscovery)
+	}
+
+	p.metadata = &metadata
+	return p.metadata, nil
+}
+
+// key busca la clave pública del token; si no está se recargan las claves una
+// vez, por si el proveedor las ha rotado.
+func (p *Provider) key(ctx context.Context, metadata *Metadata, keyID string) (interface{}, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.keys != nil {
+		if key, ok := p.keys.find(keyID); ok {
+			return key, nil
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, metadata.JWKSURI, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDiscovery, err)
+	}
+
+	var set jsonWebKeySet
+	status, err := p.getJSON(req, &set)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDiscovery, err)
+	}
+	if status != http.StatusOK {
+		return nil, fmt.Errorf("%w: jwks status %d", ErrDiscovery, status)
+	}
+
+	p.keys = set.parse()
+	if key, ok := p.keys.find(keyID); ok {
+		return key, nil
+	}
+
+	return nil, fmt.Errorf("%w: unknown signing key %q", ErrInvalidIDToken, keyID)
+}
+
+func (p *Provider) getJSON(req *http.Request, v interface{}) (int, error) {
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return resp.StatusCode, err
+	}
+	if err := json.Unmarshal(body, v); err != nil && resp.StatusCode == http.StatusOK {
+		return resp.StatusCode, err
+	}
+
+	return resp.StatusCode, nil
+}
+
+func supportsBasicAuth(methods []string) bool {
+	// Según la especificación, client_secret_basic es el método por defecto
+	if len(methods) == 0 {
+		return true
+	}
+	for _, method := range methods {
+		if method == "client_secret_basic" {
+			return true
+		}
+	}
+	return false
+}
+
+// NewCodeVerifier genera un code_verifier de PKCE (RFC 7636).
+func NewCodeVerifier() (string, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(raw), nil
+}
+
+// CodeChallenge calcula el code_challenge S256 de un code_verifier.
+func CodeChallenge(codeVerifier string) string {
+	sum := sha256.Sum256([]byte(codeVerifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// audience acepta "aud" como cadena o como lista.
+type audience []string
+
+func (a *audience) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*a = audience{single}
+		return nil
+	}
+
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return err
+	}
+	*a = list
+	return nil
+}
+
+func (a audience) contains(value string) bool {
+	for _, item := range a {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
+
+// boolean acepta email_verified como booleano o como cadena, que algunos proveedores envían así.
+type boolean bool
+
+func (b *boolean) UnmarshalJSON(data []byte) error {
+	switch strings.Trim(string(data), `"`) {
+	case "true":
+		*b = true
+	default:
+		*b = false
+	}
+	return nil
+}
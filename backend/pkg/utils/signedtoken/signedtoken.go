@@ -0,0 +1,77 @@
+package signedtoken
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInvalidToken = errors.New("invalid signed token")
+	ErrExpiredToken = errors.New("signed token expired")
+)
+
+type claims struct {
+	Purpose   string `json:"p"`
+	Subject   string `json:"s"`
+	ExpiresAt int64  `json:"e"`
+}
+
+// Signer firma tokens sin estado con HMAC-SHA256. El propósito forma parte de la
+// firma, así que un token emitido para un uso no sirve para otro.
+type Signer struct {
+	key []byte
+}
+
+func New(key []byte) *Signer {
+	return &Signer{key: key}
+}
+
+// Sign devuelve un token URL-safe con el sujeto y la caducidad indicados.
+func (s *Signer) Sign(purpose, subject string, expiresAt time.Time) (string, error) {
+	payload, err := json.Marshal(claims{Purpose: purpose, Subject: subject, ExpiresAt: expiresAt.Unix()})
+	if err != nil {
+		return "", err
+	}
+
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	return encoded + "." + base64.RawURLEncoding.EncodeToString(s.mac(encoded)), nil
+}
+
+// Verify comprueba firma, propósito y caducidad y devuelve el sujeto.
+func (s *Signer) Verify(purpose, token string, now time.Time) (string, error) {
+	encoded, signature, ok := strings.Cut(token, ".")
+	if !ok {
+		return "", ErrInvalidToken
+	}
+
+	mac, err := base64.RawURLEncoding.DecodeString(signature)
+	if err != nil || !hmac.Equal(mac, s.mac(encoded)) {
+		return "", ErrInvalidToken
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", ErrInvalidToken
+	}
+
+	var c claims
+	if err := json.Unmarshal(payload, &c); err != nil || c.Purpose != purpose {
+		return "", ErrInvalidToken
+	}
+	if now.After(time.Unix(c.ExpiresAt, 0)) {
+		return "", ErrExpiredToken
+	}
+
+	return c.Subject, nil
+}
+
+func (s *Signer) mac(data string) []byte {
+	h := hmac.New(sha256.New, s.key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
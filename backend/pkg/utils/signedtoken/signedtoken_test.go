@@ -0,0 +1,75 @@
+package signedtoken
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSignAndVerify(t *testing.T) {
+	signer := New([]byte("clave"))
+	now := time.Now()
+
+	token, err := signer.Sign("email_verification", "42", now.Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	subject, err := signer.Verify("email_verification", token, now)
+	if err != nil || subject != "42" {
+		t.Fatalf("Verify = %q, %v, want 42", subject, err)
+	}
+}
+
+func TestVerifyRejects(t *testing.T) {
+	signer := New([]byte("clave"))
+	now := time.Now()
+	sign := func(s *Signer, purpose string, expiresAt time.Time) string {
+		token, err := s.Sign(purpose, "42", expiresAt)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return token
+	}
+	valid := sign(signer, "email_verification", now.Add(time.Hour))
+	payload, signature, _ := strings.Cut(valid, ".")
+
+	// Otro sujeto con la firma original y la firma con un bit cambiado
+	forged := base64.RawURLEncoding.EncodeToString(
+		[]byte(strings.Replace(string(mustDecode(t, payload)), `"s":"42"`, `"s":"1"`, 1)))
+	mac := mustDecode(t, signature)
+	mac[0] ^= 1
+	tampered := base64.RawURLEncoding.EncodeToString(mac)
+
+	tests := []struct {
+		name  string
+		token string
+		want  error
+	}{
+		{"expired", sign(signer, "email_verification", now.Add(-time.Second)), ErrExpiredToken},
+		{"wrong key", sign(New([]byte("otra clave")), "email_verification", now.Add(time.Hour)), ErrInvalidToken},
+		{"other purpose", sign(signer, "reauthentication", now.Add(time.Hour)), ErrInvalidToken},
+		{"tampered payload", forged + "." + signature, ErrInvalidToken},
+		{"tampered signature", payload + "." + tampered, ErrInvalidToken},
+		{"no signature", payload, ErrInvalidToken},
+		{"not base64", payload + ".***", ErrInvalidToken},
+		{"empty", "", ErrInvalidToken},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			subject, err := signer.Verify("email_verification", tt.token, now)
+			if err != tt.want {
+				t.Errorf("Verify = %q, %v, want %v", subject, err, tt.want)
+			}
+		})
+	}
+}
+
+func mustDecode(t *testing.T, s string) []byte {
+	t.Helper()
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
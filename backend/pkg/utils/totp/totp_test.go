@@ -0,0 +1,98 @@
+package totp
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// rfcSecret es la clave SHA-1 de los vectores de RFC 6238 ("12345678901234567890").
+const rfcSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestCodeRFC6238(t *testing.T) {
+	// El RFC da 8 dígitos; con 6 son los últimos 6
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+		{20000000000, "353130"},
+	}
+	for _, tt := range tests {
+		step := Step(time.Unix(tt.unix, 0))
+		got, err := Code(rfcSecret, step)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != tt.want {
+			t.Errorf("Code at %d = %s, want %s", tt.unix, got, tt.want)
+		}
+		// El secreto se acepta también en minúsculas
+		if lower, _ := Code(strings.ToLower(rfcSecret), step); lower != tt.want {
+			t.Errorf("Code with a lowercase secret at %d = %s, want %s", tt.unix, lower, tt.want)
+		}
+	}
+}
+
+func TestValidateWindow(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+	current := Step(now)
+	code := func(step int64) string {
+		c, err := Code(rfcSecret, step)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return c
+	}
+
+	tests := []struct {
+		name string
+		code string
+		ok   bool
+		step int64
+	}{
+		{"current step", code(current), true, current},
+		{"previous step", code(current - 1), true, current - 1},
+		{"next step", code(current + 1), true, current + 1},
+		{"two steps behind", code(current - 2), false, 0},
+		{"two steps ahead", code(current + 2), false, 0},
+		{"spaces", code(current)[:3] + " " + code(current)[3:], true, current},
+		{"too short", code(current)[:5], false, 0},
+		{"too long", code(current) + "0", false, 0},
+		{"empty", "", false, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			step, ok := Validate(rfcSecret, tt.code, now)
+			if ok != tt.ok || step != tt.step {
+				t.Errorf("Validate(%q) = %d, %v, want %d, %v", tt.code, step, ok, tt.step, tt.ok)
+			}
+		})
+	}
+
+	if _, ok := Validate("not base32!", code(current), now); ok {
+		t.Error("Validate accepted an invalid secret")
+	}
+}
+
+func TestGenerateSecret(t *testing.T) {
+	secret, err := GenerateSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := GenerateSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 160 bits son 32 caracteres base32 sin relleno
+	if len(secret) != 32 || secret == other {
+		t.Errorf("GenerateSecret = %q and %q, want two different 32-character secrets", secret, other)
+	}
+	if _, err := Code(secret, 1); err != nil {
+		t.Errorf("Code with a generated secret: %v", err)
+	}
+}
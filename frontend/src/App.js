@@ -9,6 +9,7 @@ import AddPayment from './pages/AddPayment';
 import AddIncome from './pages/AddIncome';
 import History from './pages/History';
 import ChangePassword from './pages/ChangePassword';
+import OidcCallback from './pages/OidcCallback';
 
 const PrivateRoute = ({ children }) => {
   const user = localStorage.getItem('user');
@@ -22,6 +23,7 @@ function App() {
         <Route path="/" element={<Login />} />
         <Route path="/register" element={<Register />} />
         <Route path="/forgot-password" element={<ForgotPassword />} />
+        <Route path="/oidc/callback" element={<OidcCallback />} />
         <Route path="/dashboard" element={<PrivateRoute><Dashboard /></PrivateRoute>} />
         <Route path="/add-debt" element={<PrivateRoute><AddDebt /></PrivateRoute>} />
         <Route path="/add-payment" element={<PrivateRoute><AddPayment /></PrivateRoute>} />
@@ -1,6 +1,6 @@
 import React from 'react';
 
-function Toast({ message, type }) {
+function Toast({ message, type, action }) {
   return (
     <div className={`toast ${type}`}>
       {type === 'success' && (
@@ -21,6 +21,11 @@ function Toast({ message, type }) {
       <div>
         <div className="message">{message}</div>
       </div>
+      {action && (
+        <button onClick={action.onClick} style={{ marginLeft: '12px', background: 'none', border: '1px solid currentColor', borderRadius: '6px', padding: '4px 10px', cursor: 'pointer', color: 'inherit' }}>
+          {action.label}
+        </button>
+      )}
     </div>
   );
 }
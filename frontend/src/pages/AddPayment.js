@@ -1,4 +1,4 @@
-import React, { useState, useEffect, useCallback } from 'react';
+import React, { useState, useEffect, useCallback, useRef } from 'react';
 import { useNavigate } from 'react-router-dom';
 import Toast from '../components/Toast';
 import { api, getCurrentUserId } from '../config/api';
@@ -9,6 +9,10 @@ function AddPayment() {
   const [formData, setFormData] = useState({ amount: '', debt_id: '', receipt: null });
   const [toast, setToast] = useState({ show: false, message: '', type: '' });
   const [loading, setLoading] = useState(false);
+  // Se reutiliza en los reintentos del mismo formulario para no registrar el pago dos veces
+  const idempotencyKey = useRef(null);
+
+  useEffect(() => { idempotencyKey.current = null; }, [formData]);
 
   const fetchDebts = useCallback(async () => {
     try {
@@ -31,7 +35,10 @@ function AddPayment() {
       data.append('user_id', getCurrentUserId());
       if (formData.receipt) data.append('receipt', formData.receipt);
 
-      await api.post('/finances/payment', data, { headers: { 'Content-Type': 'multipart/form-data' } });
+      if (!idempotencyKey.current) idempotencyKey.current = crypto.randomUUID();
+      await api.post('/finances/payment', data, {
+        headers: { 'Content-Type': 'multipart/form-data', 'Idempotency-Key': idempotencyKey.current }
+      });
       setToast({ show: true, message: '¡Pago guardado con éxito!', type: 'success' });
       setFormData({ amount: '', debt_id: '', receipt: null });
       fetchDebts();
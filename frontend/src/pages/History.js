@@ -39,9 +39,9 @@ function History() {
     return () => clearInterval(interval);
   }, [fetchData]);
 
-  const showToast = (message, type) => {
-    setToast({ show: true, message, type });
-    setTimeout(() => setToast({ show: false, message: '', type: '' }), 3000);
+  const showToast = (message, type, action = null) => {
+    setToast({ show: true, message, type, action });
+    setTimeout(() => setToast({ show: false, message: '', type: '' }), action ? 6000 : 3000);
   };
 
   const filterByDate = (items, dateField) => {
@@ -80,22 +80,29 @@ function History() {
 
   const handleSaveEdit = async () => {
     const { type, data } = editModal;
+    const config = data.version ? { headers: { 'If-Match': `"${data.version}"` } } : undefined;
     try {
       if (type === 'income') {
-        await api.put(`/finances/income/${data.id}`, { amount: parseFloat(data.amount), source: data.source, date: data.date });
+        await api.put(`/finances/income/${data.id}`, { amount: parseFloat(data.amount), source: data.source, date: data.date }, config);
       } else if (type === 'debt') {
         await api.put(`/finances/debt/${data.id}`, {
           name: data.name, total_amount: parseFloat(data.total_amount), remaining_amount: parseFloat(data.remaining_amount),
           installment_amount: parseFloat(data.installment_amount), payment_day: parseInt(data.payment_day),
           due_date: data.due_date?.split('T')[0] || data.due_date, num_installments: parseInt(data.num_installments),
           interest_rate: parseFloat(data.interest_rate || 0), paid: data.paid || false
-        });
+        }, config);
       }
       showToast('¡Actualizado con éxito!', 'success');
       setEditModal({ show: false, type: '', data: null });
       fetchData();
     } catch (error) {
       console.error('Error updating:', error.response?.data);
+      if (error.response?.status === 412) {
+        showToast('Otro cambio se guardó antes. Recargamos los datos.', 'error');
+        setEditModal({ show: false, type: '', data: null });
+        fetchData();
+        return;
+      }
       showToast('Error al actualizar', 'error');
     }
   };
@@ -106,7 +113,7 @@ function History() {
       if (type === 'income') await api.delete(`/finances/income/${id}`);
       else if (type === 'debt') await api.delete(`/finances/debt/${id}`);
       else if (type === 'payment') await api.delete(`/finances/payment/${id}`);
-      showToast('¡Eliminado con éxito!', 'success');
+      showToast('¡Eliminado con éxito!', 'success', { label: 'Deshacer', onClick: () => handleRestore(type, id) });
       setDeleteConfirm({ show: false, type: '', id: null });
       fetchData();
     } catch (error) {
@@ -114,6 +121,16 @@ function History() {
     }
   };
 
+  const handleRestore = async (type, id) => {
+    try {
+      await api.post(`/finances/${type}/${id}/restore`);
+      showToast('¡Restaurado con éxito!', 'success');
+      fetchData();
+    } catch (error) {
+      showToast('Error al restaurar', 'error');
+    }
+  };
+
   const getDebtName = (debtId) => debts.find(d => d.id === debtId)?.name || 'Desconocido';
   const clearFilters = () => { setSearchTerm(''); setDateFilter({ start: '', end: '' }); setDebtFilter(''); };
 
@@ -317,7 +334,7 @@ function History() {
         </div>
       )}
 
-      {toast.show && <Toast message={toast.message} type={toast.type} />}
+      {toast.show && <Toast message={toast.message} type={toast.type} action={toast.action} />}
     </div>
   );
 }
@@ -1,11 +1,14 @@
 import React, { useState } from 'react';
-import { useNavigate, Link } from 'react-router-dom';
+import { useNavigate, useLocation, Link } from 'react-router-dom';
 import { api, setUserData, clearUserData } from '../config/api';
 
 function Login() {
   const [email, setEmail] = useState('');
   const [password, setPassword] = useState('');
   const [loading, setLoading] = useState(false);
+  const location = useLocation();
+  const [challengeToken, setChallengeToken] = useState(location.state?.challengeToken || '');
+  const [code, setCode] = useState('');
   const navigate = useNavigate();
 
   const handleLogin = async (e) => {
@@ -16,7 +19,15 @@ function Login() {
     clearUserData();
     
     try {
-      const response = await api.post('/auth/login', { email, password });
+      const response = challengeToken
+        ? await api.post('/auth/login/2fa', { challenge_token: challengeToken, code })
+        : await api.post('/auth/login', { email, password });
+
+      // Con la verificación en dos pasos activada se pide el código antes de entrar
+      if (response.data.two_factor_required) {
+        setChallengeToken(response.data.challenge_token);
+        return;
+      }
       
       // Guardar datos del usuario incluyendo user_id
       setUserData({
@@ -26,12 +37,25 @@ function Login() {
       
       navigate('/dashboard');
     } catch (error) {
+      if (error.response?.data?.error === 'invalid_challenge') {
+        setChallengeToken('');
+        setCode('');
+      }
       alert(error.response?.data?.message || 'Error al iniciar sesión');
     } finally {
       setLoading(false);
     }
   };
 
+  const handleExternalLogin = async () => {
+    try {
+      const response = await api.post('/auth/oidc/login');
+      window.location.href = response.data.authorization_url;
+    } catch (error) {
+      alert(error.response?.data?.message || 'Error al iniciar sesión');
+    }
+  };
+
   return (
     <div className="auth-container">
       <h1 className="auth-title">PayVue APP</h1>
@@ -40,6 +64,23 @@ function Login() {
         <h2>Iniciar sesión</h2>
         <p>Ingrese su usuario y contraseña</p>
         
+        {challengeToken ? (
+        <form className="auth-form" onSubmit={handleLogin}>
+          <input
+            type="text"
+            className="form-input"
+            placeholder="Código de verificación o de recuperación"
+            value={code}
+            onChange={(e) => setCode(e.target.value)}
+            autoComplete="one-time-code"
+            autoFocus
+            required
+          />
+          <button type="submit" className="btn-primary" disabled={loading}>
+            {loading ? 'Cargando...' : 'Verificar'}
+          </button>
+        </form>
+        ) : (
         <form className="auth-form" onSubmit={handleLogin}>
           <input
             type="email"
@@ -61,12 +102,13 @@ function Login() {
             {loading ? 'Cargando...' : 'Iniciar Sesión'}
           </button>
         </form>
+        )}
 
         <div className="divider">
           <span>O inicia sesión con</span>
         </div>
 
-        <button className="btn-google">
+        <button className="btn-google" onClick={handleExternalLogin}>
           <svg viewBox="0 0 24 24" width="20" height="20">
             <path fill="#4285F4" d="M22.56 12.25c0-.78-.07-1.53-.2-2.25H12v4.26h5.92c-.26 1.37-1.04 2.53-2.21 3.31v2.77h3.57c2.08-1.92 3.28-4.74 3.28-8.09z"/>
             <path fill="#34A853" d="M12 23c2.97 0 5.46-.98 7.28-2.66l-3.57-2.77c-.98.66-2.23 1.06-3.71 1.06-2.86 0-5.29-1.93-6.16-4.53H2.18v2.84C3.99 20.53 7.7 23 12 23z"/>
@@ -0,0 +1,50 @@
+import React, { useEffect, useRef } from 'react';
+import { useNavigate, useSearchParams } from 'react-router-dom';
+import { api, setUserData } from '../config/api';
+
+// Recibe la redirección del proveedor OpenID Connect y la reenvía al backend
+function OidcCallback() {
+  const [searchParams] = useSearchParams();
+  const navigate = useNavigate();
+  const sent = useRef(false);
+
+  useEffect(() => {
+    // El código solo se puede canjear una vez
+    if (sent.current) return;
+    sent.current = true;
+
+    const code = searchParams.get('code');
+    const state = searchParams.get('state');
+    if (!code || !state) {
+      alert(searchParams.get('error_description') || 'Inicio de sesión cancelado');
+      navigate('/');
+      return;
+    }
+
+    api.post('/auth/oidc/callback', { code, state })
+      .then((response) => {
+        // Con 2FA activa el login continúa pidiendo el código
+        if (response.data.two_factor_required) {
+          navigate('/', { state: { challengeToken: response.data.challenge_token } });
+          return;
+        }
+        setUserData({
+          user_id: response.data.user_id,
+          email: response.data.email
+        });
+        navigate('/dashboard');
+      })
+      .catch((error) => {
+        alert(error.response?.data?.message || 'Error al iniciar sesión');
+        navigate('/');
+      });
+  }, [searchParams, navigate]);
+
+  return (
+    <div className="auth-container">
+      <p>Iniciando sesión...</p>
+    </div>
+  );
+}
+
+export default OidcCallback;